@@ -1,23 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
-	"net"
+	"net/http"
 	"os"
 	"time"
 
-	"google.golang.org/grpc"
 	"github.com/joho/godotenv"
 
-	authpb "admin-portal/proto/auth"
-
-	"admin-portal/internal/auth-module/handler"
+	"admin-portal/internal/app"
 	"admin-portal/internal/auth-module/middleware"
-	"admin-portal/internal/auth-module/repository"
-	"admin-portal/internal/auth-module/service"
+	"admin-portal/internal/auth-module/model"
+	authservice "admin-portal/internal/auth-module/service"
+	webhookservice "admin-portal/internal/webhook-module/service"
 
+	"admin-portal/internal/shared/config"
 	"admin-portal/internal/shared/database"
+	sharedgrpc "admin-portal/internal/shared/grpc"
+	"admin-portal/internal/shared/health"
+	"admin-portal/internal/shared/metrics"
+	"admin-portal/internal/shared/oidc"
 	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/tracing"
+	"admin-portal/internal/shared/webauthn"
+	"admin-portal/migrations"
 )
 
 func main() {
@@ -25,6 +33,16 @@ func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Fatal("❌ Failed to load .env:", err)
 	}
+	ctx := context.Background()
+
+	// ---------------------------
+	// Tracing
+	// ---------------------------
+	shutdownTracing, err := tracing.Setup(ctx, tracing.LoadConfig("admin-portal-api"))
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+
 	// ---------------------------
 	// Load DB & GORM
 	// ---------------------------
@@ -35,13 +53,42 @@ func main() {
 		log.Fatalf("failed to connect database: %v", err)
 	}
 
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatalf("failed to install tracing plugin: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("failed to get database pool: %v", err)
+	}
+
 	// ---------------------------
-	// Initialize repositories
+	// Optional auto-migrate
 	// ---------------------------
-	userRepo := repository.NewUserRepository(db)
-	passwordRepo := repository.NewPasswordRepository(db)
-	loginLogRepo := repository.NewLoginLogRepository(db)
-	userSessionRepo := repository.NewUserSessionRepository(db)
+	if config.Bool("DB_AUTO_MIGRATE", false) {
+		log.Println("🛠 Applying pending migrations...")
+		if err := database.MigrateUp(ctx, sqlDB, migrations.FS, database.MigrateOptions{}); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	}
+
+	// ---------------------------
+	// Health checks
+	// ---------------------------
+	migrationVersions, err := database.MigrationVersions(migrations.FS)
+	if err != nil {
+		log.Fatalf("failed to list migrations: %v", err)
+	}
+
+	healthChecker := health.NewChecker(health.LoadConfig(), sqlDB, migrationVersions)
+
+	// ---------------------------
+	// Metrics
+	// ---------------------------
+	registry := metrics.NewRegistry()
+	metrics.RegisterDBStats(registry, sqlDB, cfg.DBName)
+
+	metricsServer := metrics.NewServer(metrics.LoadConfig(), registry)
 
 	// ---------------------------
 	// JWT configuration
@@ -52,54 +99,160 @@ func main() {
 	}
 
 	jwtCfg := security.JWTConfig{
-		Secret:           JWTSecret,
-		AccessTokenTTL:   15 * time.Minute,
-		RefreshTokenTTL:  7 * 24 * time.Hour,
-		Issuer:           "admin-portal",
+		Secret:          JWTSecret,
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 7 * 24 * time.Hour,
+		Issuer:          "admin-portal",
+	}
+
+	cipher, err := security.LoadCipher()
+	if err != nil {
+		log.Fatalf("failed to load encryption key: %v", err)
 	}
 
 	// ---------------------------
-	// Initialize services
+	// TLS & service identities
 	// ---------------------------
-	tokenService := service.NewTokenService(jwtCfg, userSessionRepo)
+	grpcCfg := sharedgrpc.LoadConfig()
 
-	authService := service.NewAuthService(
-		db,
-		userRepo,
-		passwordRepo,
-		loginLogRepo,
-		tokenService,
-	)
+	tlsCfg, err := security.LoadTLSConfig()
+	if err != nil {
+		log.Fatalf("invalid TLS configuration: %v", err)
+	}
+
+	if tlsCfg.Enabled() {
+		reloader, err := security.NewCertReloader(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCAFile)
+		if err != nil {
+			log.Fatalf("failed to load TLS certificate: %v", err)
+		}
+		go reloader.Watch(ctx, tlsCfg.ReloadInterval)
+
+		grpcCfg.TLS = security.ServerTLS(tlsCfg, reloader)
+		log.Printf("🔐 TLS enabled (client auth: %s)", tlsCfg.ClientAuth)
+	}
+
+	proxies, err := middleware.ParseTrustedProxies(config.String("GRPC_TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatalf("invalid GRPC_TRUSTED_PROXIES: %v", err)
+	}
+
+	services, err := middleware.ParseServiceIdentities(config.String("GRPC_SERVICE_IDENTITIES", ""))
+	if err != nil {
+		log.Fatalf("invalid GRPC_SERVICE_IDENTITIES: %v", err)
+	}
+	if len(services) > 0 && (!tlsCfg.Enabled() || tlsCfg.ClientAuth == security.ClientAuthNone) {
+		log.Println("⚠️ GRPC_SERVICE_IDENTITIES is set but client certificates are not verified; ignoring it")
+	}
 
 	// ---------------------------
-	// Initialize handlers
+	// Single sign-on
 	// ---------------------------
-	authHandler := handler.NewAuthHandler(authService)
+	var idp *oidc.Provider
+
+	if oidcCfg := oidc.LoadConfig(); oidcCfg.Enabled() {
+		if err := oidcCfg.Validate(); err != nil {
+			log.Fatalf("invalid OIDC configuration: %v", err)
+		}
+		if role := config.String("OIDC_DEFAULT_ROLE", model.RoleUser); model.RoleRank(role) == 0 {
+			log.Fatalf("invalid OIDC_DEFAULT_ROLE %q", role)
+		}
+		idp = oidc.NewProvider(oidcCfg, nil)
+		log.Printf("🔑 SSO enabled with %s", oidcCfg.Issuer)
+	}
 
 	// ---------------------------
-	// gRPC server with interceptors
+	// Directory logins
 	// ---------------------------
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			middleware.JWTUnaryInterceptor(jwtCfg),
-		),
-	)
+	ldapCfg, err := authservice.LoadLDAPConfig()
+	if err != nil {
+		log.Fatalf("invalid LDAP configuration: %v", err)
+	}
+	if ldapCfg.Enabled() {
+		log.Printf("📒 LDAP logins enabled with %s", ldapCfg.URL)
+	}
 
 	// ---------------------------
-	// Register gRPC services
+	// Passkeys
 	// ---------------------------
-	authpb.RegisterAuthServiceServer(grpcServer, authHandler)
+	var rp *webauthn.RelyingParty
+
+	if webauthnCfg := webauthn.LoadConfig(); webauthnCfg.Enabled() {
+		if err := webauthnCfg.Validate(); err != nil {
+			log.Fatalf("invalid WebAuthn configuration: %v", err)
+		}
+		rp = webauthn.NewRelyingParty(webauthnCfg)
+		log.Printf("🗝️ Passkeys enabled for %s", webauthnCfg.RPID)
+	}
 
 	// ---------------------------
-	// Start server
+	// OAuth provider for other apps
 	// ---------------------------
-	lis, err := net.Listen("tcp", ":50051")
+	oauthCfg, err := authservice.LoadOAuthConfig()
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		log.Fatalf("invalid OAuth configuration: %v", err)
+	}
+	if oauthCfg.Enabled() {
+		log.Printf("🪪 OAuth provider enabled as %s (signing key %s)", oauthCfg.Issuer, oauthCfg.Key.ID)
+	}
+
+	// ---------------------------
+	// Modules & gRPC server
+	// ---------------------------
+	api := app.New(grpcCfg, app.Deps{
+		DB:             db,
+		JWT:            jwtCfg,
+		Cipher:         cipher,
+		Webhook:        webhookservice.LoadConfig(),
+		Metrics:        registry,
+		Services:       services,
+		TrustedProxies: proxies,
+		OIDC:           idp,
+		OAuth:          oauthCfg,
+		LDAP:           ldapCfg,
+		WebAuthn:       rp,
+	})
+	server := api.Server
+
+	var oauthServer *http.Server
+	if api.Auth.OAuthHandler != nil {
+		oauthServer = &http.Server{
+			Addr:              oauthCfg.Addr,
+			Handler:           api.Auth.OAuthHandler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	}
+
+	server.OnDrain(healthChecker.Shutdown)
+	server.OnShutdown(shutdownTracing)
+	server.OnShutdown(func(context.Context) error {
+		log.Println("🔌 Closing database pool")
+		return database.CloseGorm(db)
+	})
+	server.OnShutdown(metricsServer.Shutdown)
+	// Hooks run in reverse, so the OAuth endpoints stop before the pool
+	// they query is closed.
+	if oauthServer != nil {
+		server.OnShutdown(oauthServer.Shutdown)
+	}
+
+	server.RegisterModules(healthChecker)
+
+	// ---------------------------
+	// Start server
+	// ---------------------------
+	healthChecker.Start(ctx)
+	metricsServer.Start()
+
+	if oauthServer != nil {
+		go func() {
+			log.Printf("🌐 OAuth server started on %s", oauthServer.Addr)
+			if err := oauthServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("❌ OAuth server failed: %v", err)
+			}
+		}()
 	}
 
-	log.Println("🚀 gRPC server started on :50051")
-	if err := grpcServer.Serve(lis); err != nil {
+	if err := server.Run(ctx); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
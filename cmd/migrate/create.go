@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"admin-portal/internal/shared/database"
+)
+
+// createMigration writes an empty migration named name with the next free
+// version into dir and returns the paths it created.
+func createMigration(dir, name string, paired bool) ([]string, error) {
+	// Only the names matter here; a freshly created, still empty
+	// migration must not stop the next one from being created.
+	existing, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(existing))
+	for _, f := range existing {
+		versions = append(versions, database.MigrationVersion(f))
+	}
+	sort.Strings(versions)
+
+	next := 0
+	if len(versions) > 0 {
+		last := versions[len(versions)-1]
+		if _, err := fmt.Sscanf(last, "%d", &next); err != nil {
+			return nil, fmt.Errorf("cannot parse version %q: %w", last, err)
+		}
+		next++
+	}
+
+	slug := strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	prefix := fmt.Sprintf("%04d_%s", next, slug)
+
+	files := map[string]string{}
+	if paired {
+		files[filepath.Join(dir, prefix+".up.sql")] = "-- Write the forward migration here.\n"
+		files[filepath.Join(dir, prefix+".down.sql")] = "-- Write the rollback here.\n"
+	} else {
+		files[filepath.Join(dir, prefix+".sql")] = "-- +up\n\n-- +down\n"
+	}
+
+	var created []string
+	for path, body := range files {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+	sort.Strings(created)
+
+	return created, nil
+}
@@ -3,19 +3,105 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
-	"path/filepath"
-	"sort"
-	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 
+	"admin-portal/internal/auth-module/model"
 	"admin-portal/internal/shared/database"
+	"admin-portal/internal/shared/outbox"
+	"admin-portal/internal/shared/queue"
+	webhookmodel "admin-portal/internal/webhook-module/model"
+	"admin-portal/migrations"
 )
 
+const usage = `Usage: migrate [flags] <command> [args]
+
+Commands:
+  up [N|version]     apply all pending migrations, the next N, or up to version
+  down [N|version]   roll back the latest migration, the last N, or down to version
+                     ("all" rolls back everything but 0000, which holds
+                     the migration history)
+  status             list migrations and whether they are applied
+  redo               roll back and re-apply the latest migration
+  create <name>      create a new migration file in -dir (default "migrations")
+  verify             compare the live schema with the GORM models
+
+With no command, "up" is assumed. Migrations are read from the copy
+embedded in the binary unless -dir is given.
+
+Flags:
+`
+
+// verifyModels are the GORM models whose tables verify checks.
+var verifyModels = []interface{}{
+	&model.User{},
+	&model.PasswordMaster{},
+	&model.LoginLog{},
+	&model.UserSession{},
+	&model.APIKey{},
+	&model.UserIdentity{},
+	&model.SSOLoginState{},
+	&model.OAuthClient{},
+	&model.OAuthAuthorizationCode{},
+	&model.OAuthRefreshToken{},
+	&model.WebAuthnCredential{},
+	&model.WebAuthnChallenge{},
+	&model.Invitation{},
+	&queue.Job{},
+	&outbox.Event{},
+	&webhookmodel.WebhookSubscription{},
+	&webhookmodel.WebhookDelivery{},
+}
+
 func main() {
+	dir := flag.String("dir", "", "read migrations from this directory instead of the embedded copy")
+	paired := flag.Bool("paired", false, "create: write separate .up.sql and .down.sql files")
+	lockTimeout := flag.Duration("lock-timeout", time.Minute, "how long to wait for another migration run to finish")
+	allowDrift := flag.Bool("allow-drift", false, "warn instead of failing when an applied migration's checksum changed")
+	dryRun := flag.Bool("dry-run", false, "print the SQL that would run without executing it")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command, arg := "up", ""
+	if flag.NArg() > 0 {
+		command = flag.Arg(0)
+	}
+	if flag.NArg() > 1 {
+		arg = flag.Arg(1)
+	}
+
+	if command == "create" {
+		if arg == "" {
+			log.Fatal("❌ create requires a migration name")
+		}
+		target := *dir
+		if target == "" {
+			target = "migrations"
+		}
+		files, err := createMigration(target, arg, *paired)
+		if err != nil {
+			log.Fatal("❌ Failed to create migration:", err)
+		}
+		for _, f := range files {
+			log.Println("📝 Created", f)
+		}
+		return
+	}
+
+	var source fs.FS = migrations.FS
+	if *dir != "" {
+		source = os.DirFS(*dir)
+	}
+
 	log.Println("🚀 DB migration started")
 
 	if err := godotenv.Load(); err != nil {
@@ -38,6 +124,9 @@ func main() {
 	}
 
 	if !exists {
+		if *dryRun {
+			log.Fatalf("❌ Database %s does not exist; dry-run will not create it", cfg.DBName)
+		}
 		log.Printf("📦 Database %s not found, creating...\n", cfg.DBName)
 		if err := createDatabase(sysDB, cfg.DBName); err != nil {
 			log.Fatal(err)
@@ -56,14 +145,60 @@ func main() {
 	}
 	defer db.Close()
 
-	// 4️⃣ Ensure schema_migrations table
-	if err := ensureSchemaMigrations(db); err != nil {
+	// 4️⃣ Load migrations, rejecting bad file names before taking the lock
+	m, err := database.NewMigrator(db, source, database.MigrateOptions{
+		AllowDrift:  *allowDrift,
+		DryRun:      *dryRun,
+		LockTimeout: *lockTimeout,
+	})
+	if err != nil {
+		log.Fatal("❌ Failed to load migrations:", err)
+	}
+
+	// 5️⃣ Serialize concurrent runs
+	ctx := context.Background()
+
+	release, err := m.Lock(ctx)
+	if err != nil {
+		log.Fatal("❌ Failed to acquire migration lock:", err)
+	}
+	defer release()
+
+	// 6️⃣ Ensure schema_migrations table
+	if err := m.Prepare(ctx); err != nil {
 		log.Fatal(err)
 	}
 
-	// 5️⃣ Apply migrations safely
-	if err := applyMigrations(db); err != nil {
-		log.Fatal("❌ Migration failed:", err)
+	// 7️⃣ Make sure applied migrations still match their files
+	if command != "status" {
+		if err := m.VerifyChecksums(ctx); err != nil {
+			log.Fatal("❌ ", err)
+		}
+	}
+
+	switch command {
+	case "up":
+		err = m.Up(ctx, arg)
+	case "down":
+		err = m.Down(ctx, arg)
+	case "redo":
+		err = m.Redo(ctx)
+	case "status":
+		err = m.Status(ctx)
+	case "verify":
+		err = database.VerifySchema(ctx, db, verifyModels)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("❌ %s failed: %v", command, err)
+	}
+
+	if *dryRun {
+		log.Println("🧪 Dry run finished, nothing was executed")
+		return
 	}
 
 	log.Println("🎉 Migration completed successfully")
@@ -84,85 +219,3 @@ func createDatabase(db *sql.DB, name string) error {
 	_, err := db.Exec(fmt.Sprintf(`CREATE DATABASE "%s"`, name))
 	return err
 }
-
-func ensureSchemaMigrations(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version VARCHAR(50) PRIMARY KEY,
-			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
-		)
-	`)
-	return err
-}
-
-func applyMigrations(db *sql.DB) error {
-	files, err := filepath.Glob("migrations/*.sql")
-	if err != nil {
-		return err
-	}
-
-	sort.Strings(files)
-	ctx := context.Background()
-
-	for _, file := range files {
-		version := migrationVersion(file)
-
-		applied, err := isMigrationApplied(db, version)
-		if err != nil {
-			return err
-		}
-
-		if applied {
-			log.Println("⏭ Skipping", file)
-			continue
-		}
-
-		log.Println("▶ Applying", file)
-
-		sqlBytes, err := os.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
-			return err
-		}
-
-		if _, err := tx.ExecContext(ctx, string(sqlBytes)); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("migration %s failed: %w", file, err)
-		}
-
-		if _, err := tx.ExecContext(
-			ctx,
-			`INSERT INTO schema_migrations (version) VALUES ($1)`,
-			version,
-		); err != nil {
-			tx.Rollback()
-			return err
-		}
-
-		if err := tx.Commit(); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func migrationVersion(path string) string {
-	base := filepath.Base(path)
-	return strings.Split(base, "_")[0]
-}
-
-func isMigrationApplied(db *sql.DB, version string) (bool, error) {
-	var exists bool
-	err := db.QueryRow(
-		`SELECT EXISTS (
-			SELECT 1 FROM schema_migrations WHERE version = $1
-		)`,
-		version,
-	).Scan(&exists)
-	return exists, err
-}
@@ -0,0 +1,47 @@
+// Command mock-idp serves a mock OpenID provider for trying single
+// sign-on locally. It signs in the configured user without asking for
+// credentials. Point the API at it with:
+//
+//	OIDC_ISSUER=http://localhost:9400
+//	OIDC_CLIENT_ID=admin-portal
+//	OIDC_CLIENT_SECRET=mock-secret
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"admin-portal/internal/shared/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9400", "listen address")
+	issuer := flag.String("issuer", "", "issuer URL (default: http://<addr>)")
+	subject := flag.String("sub", "mock-user", "subject of the signed-in user")
+	email := flag.String("email", "mock.user@example.com", "email of the signed-in user")
+	name := flag.String("name", "Mock User", "name of the signed-in user")
+	username := flag.String("username", "", "preferred_username of the signed-in user")
+	flag.Parse()
+
+	if *issuer == "" {
+		*issuer = "http://" + *addr
+	}
+
+	idp, err := oidctest.New(*issuer)
+	if err != nil {
+		log.Fatal("❌ Failed to create mock IdP:", err)
+	}
+	idp.SetUser(oidctest.User{
+		Subject:           *subject,
+		Email:             *email,
+		EmailVerified:     true,
+		Name:              *name,
+		PreferredUsername: *username,
+	})
+
+	log.Printf("🧪 Mock IdP %s signing in %s (client %s, secret %s)", *issuer, *email, oidctest.ClientID, oidctest.ClientSecret)
+	if err := http.ListenAndServe(*addr, idp); err != nil {
+		log.Fatal("❌ Mock IdP stopped:", err)
+	}
+}
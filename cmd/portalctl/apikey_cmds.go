@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	authpb "admin-portal/proto/auth"
+)
+
+// stringList is a flag that may be repeated.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func runAPIKey(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("apikey requires a subcommand: create, list or revoke")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "create":
+		return runAPIKeyCreate(ctx, c, args)
+	case "list":
+		return runAPIKeyList(ctx, c, args)
+	case "revoke":
+		if len(args) != 1 {
+			return fmt.Errorf("apikey revoke requires a key ID")
+		}
+		return runAPIKeyRevoke(ctx, c, args[0])
+	default:
+		return fmt.Errorf("unknown apikey subcommand %q", sub)
+	}
+}
+
+func runAPIKeyCreate(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	name := fs.String("name", "", "what the key is for, e.g. nightly-export")
+	var scopes stringList
+	fs.Var(&scopes, "scope", "method or service the key may call, or * for all (repeatable; at least one)")
+	expires := fs.String("expires", "", "expiry time (default: never)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || len(scopes) == 0 {
+		return fmt.Errorf("apikey create requires -name and at least one -scope")
+	}
+
+	req := &authpb.CreateAPIKeyRequest{Name: *name, Scopes: scopes}
+	if *expires != "" {
+		t, err := parseExpiry(*expires)
+		if err != nil {
+			return err
+		}
+		req.ExpiresAt = timestamppb.New(t)
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resp, err := c.auth.CreateAPIKey(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if c.out.json {
+		return c.printJSON(resp)
+	}
+
+	// The key goes alone to stdout so it can be captured by a script.
+	k := resp.GetApiKey()
+	fmt.Fprintf(os.Stderr, "Created API key %s (%s). Store it now; it cannot be shown again.\n", k.GetId(), k.GetName())
+	fmt.Println(resp.GetKey())
+	return nil
+}
+
+func runAPIKeyList(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("apikey list", flag.ContinueOnError)
+	userID := fs.String("user", "", "user ID (default: yourself; others need admin)")
+	inactive := fs.Bool("inactive", false, "include revoked and expired keys")
+	pg := registerPageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	req := &authpb.ListAPIKeysRequest{
+		UserId:          *userID,
+		IncludeInactive: *inactive,
+		PageSize:        int32(pg.size),
+		PageToken:       pg.token,
+	}
+
+	all := &authpb.ListAPIKeysResponse{}
+	for {
+		resp, err := c.auth.ListAPIKeys(ctx, req)
+		if err != nil {
+			return err
+		}
+		all.ApiKeys = append(all.ApiKeys, resp.GetApiKeys()...)
+		all.TotalSize = resp.GetTotalSize()
+		all.NextPageToken = resp.GetNextPageToken()
+
+		if !pg.all || resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	rows := make([][]string, len(all.GetApiKeys()))
+	for i, k := range all.GetApiKeys() {
+		rows[i] = []string{
+			k.GetId(),
+			k.GetName(),
+			k.GetPrefix(),
+			strings.Join(k.GetScopes(), ","),
+			formatTime(k.GetExpiresAt()),
+			formatTime(k.GetLastUsedAt()),
+			formatBool(k.GetRevokedAt() != nil),
+		}
+	}
+	if err := c.out.print(all, []string{"ID", "NAME", "PREFIX", "SCOPES", "EXPIRES", "LAST USED", "REVOKED"}, rows); err != nil {
+		return err
+	}
+	c.out.pageFooter(len(rows), all.GetTotalSize(), all.GetNextPageToken())
+	return nil
+}
+
+func runAPIKeyRevoke(ctx context.Context, c *cli, id string) error {
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := c.auth.RevokeAPIKey(ctx, &authpb.RevokeAPIKeyRequest{Id: id}); err != nil {
+		return err
+	}
+
+	c.out.status("Revoked API key %s", id)
+	return nil
+}
+
+// parseExpiry accepts RFC 3339 or a duration from now.
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC 3339 nor a duration", s)
+	}
+	return time.Now().Add(d), nil
+}
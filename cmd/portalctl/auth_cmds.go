@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	authpb "admin-portal/proto/auth"
+)
+
+func runLogin(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	username := fs.String("u", env("USER", ""), "username")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	sso := fs.Bool("sso", false, "sign in with the identity provider instead of a password")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *sso {
+		return runSSOLogin(ctx, c)
+	}
+	if *username == "" {
+		return fmt.Errorf("login requires -u")
+	}
+
+	password, err := readPassword("Password: ", *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+
+	var header metadata.MD
+	resp, err := c.auth.Login(ctx, &authpb.LoginRequest{
+		Username: *username,
+		Password: password,
+	}, grpc.Header(&header))
+	if err != nil {
+		return err
+	}
+	if resp.GetSecondFactorOptions() != "" {
+		// The passkey lives with the browser, not with portalctl.
+		return fmt.Errorf("%s signs in with a passkey; use an API key (-api-key) with portalctl", *username)
+	}
+
+	if err := c.saveLogin(header, resp.GetUserId(), *username); err != nil {
+		return err
+	}
+
+	c.out.status("Logged in as %s (%s)", *username, resp.GetUserId())
+	return c.printJSON(resp)
+}
+
+// runSSOLogin prints the identity provider's login URL and completes the
+// login with the address the browser is sent back to, which the user
+// pastes in.
+func runSSOLogin(ctx context.Context, c *cli) error {
+	callCtx, cancel := c.call(ctx)
+	defer cancel()
+
+	var header metadata.MD
+	start, err := c.auth.StartSSOLogin(callCtx, &emptypb.Empty{}, grpc.Header(&header))
+	if err != nil {
+		return err
+	}
+	pending := &session{Cookies: map[string]string{}}
+	pending.apply(header)
+
+	fmt.Fprintf(os.Stderr, "Open this address in a browser and sign in:\n\n  %s\n\n", start.GetAuthorizationUrl())
+	fmt.Fprint(os.Stderr, "Then paste the address you were sent back to: ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("read callback address: %w", err)
+	}
+	req, err := parseSSOCallback(line)
+	if err != nil {
+		return err
+	}
+
+	// The login may take a while; give the completion a fresh timeout.
+	callCtx, cancel = c.call(ctx)
+	defer cancel()
+	callCtx = metadata.AppendToOutgoingContext(callCtx, "cookie", pending.cookieHeader())
+
+	header = nil
+	resp, err := c.auth.CompleteSSOLogin(callCtx, req, grpc.Header(&header))
+	if err != nil {
+		return err
+	}
+	if err := c.saveLogin(header, resp.GetUserId(), ""); err != nil {
+		return err
+	}
+
+	authCtx, authCancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer authCancel()
+
+	user, err := c.auth.WhoAmI(authCtx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+	c.creds.Username = user.GetUsername()
+	if err := c.creds.save(); err != nil {
+		return err
+	}
+
+	c.out.status("Logged in as %s (%s)", user.GetUsername(), resp.GetUserId())
+	return c.printJSON(resp)
+}
+
+// parseSSOCallback reads state and code, or the provider's error, from a
+// callback address or just its query string.
+func parseSSOCallback(s string) (*authpb.CompleteSSOLoginRequest, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "?"); i >= 0 {
+		s = s[i+1:]
+	}
+
+	q, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, fmt.Errorf("callback address: %w", err)
+	}
+	if q.Get("state") == "" {
+		return nil, fmt.Errorf("callback address has no state parameter")
+	}
+
+	return &authpb.CompleteSSOLoginRequest{
+		State:            q.Get("state"),
+		Code:             q.Get("code"),
+		Error:            q.Get("error"),
+		ErrorDescription: q.Get("error_description"),
+	}, nil
+}
+
+// saveLogin replaces the saved session with the cookies of a login
+// response.
+func (c *cli) saveLogin(header metadata.MD, userID, username string) error {
+	c.creds.Addr = c.addr
+	c.creds.UserID = userID
+	c.creds.Username = username
+	c.creds.Cookies = map[string]string{}
+	c.creds.apply(header)
+	return c.creds.save()
+}
+
+func runLogout(ctx context.Context, c *cli, _ []string) error {
+	if c.creds.Cookies["access_token"] != "" {
+		ctx, cancel, _ := c.authed(ctx)
+		defer cancel()
+
+		// Forget the session locally even if the server has already
+		// expired the access token.
+		if _, err := c.auth.Logout(ctx, &emptypb.Empty{}); err != nil {
+			c.out.status("Server logout failed: %s", describe(err))
+		}
+	}
+
+	if err := c.creds.remove(); err != nil {
+		return err
+	}
+
+	c.out.status("Logged out")
+	return nil
+}
+
+func runRefresh(ctx context.Context, c *cli, _ []string) error {
+	if c.creds.Cookies["refresh_token"] == "" {
+		return fmt.Errorf("no refresh token saved; run portalctl login")
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "cookie", c.creds.cookieHeader())
+
+	var header metadata.MD
+	resp, err := c.auth.Refresh(ctx, &emptypb.Empty{}, grpc.Header(&header))
+	if err != nil {
+		return err
+	}
+
+	c.creds.apply(header)
+	if err := c.creds.save(); err != nil {
+		return err
+	}
+
+	c.out.status("Tokens refreshed for %s", orDash(c.creds.Username))
+	return c.printJSON(resp)
+}
+
+func runActivate(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("activate", flag.ContinueOnError)
+	resend := fs.String("resend", "", "email a new activation link to this address instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+
+	if *resend != "" {
+		if fs.NArg() != 0 {
+			return fmt.Errorf("activate -resend takes no arguments")
+		}
+		if _, err := c.auth.ResendActivation(ctx, &authpb.ResendActivationRequest{Email: *resend}); err != nil {
+			return err
+		}
+		c.out.status("If %s has an account waiting for activation, a new link is on its way", *resend)
+		return nil
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("activate requires the token from the activation link")
+	}
+	if _, err := c.auth.Activate(ctx, &authpb.ActivateRequest{Token: fs.Arg(0)}); err != nil {
+		return err
+	}
+
+	c.out.status("Account activated; you can log in now")
+	return nil
+}
+
+func runWhoAmI(ctx context.Context, c *cli, _ []string) error {
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	user, err := c.auth.WhoAmI(ctx, &emptypb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	return c.out.print(user, userHeader, [][]string{userRow(user)})
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	sharedgrpc "admin-portal/internal/shared/grpc"
+	"admin-portal/internal/shared/security"
+	authpb "admin-portal/proto/auth"
+)
+
+const defaultAddr = "localhost:50051"
+
+type options struct {
+	addr        string
+	output      string
+	credentials string
+	apiKey      string
+	timeout     time.Duration
+
+	tls                bool
+	caFile             string
+	certFile           string
+	keyFile            string
+	serverName         string
+	insecureSkipVerify bool
+}
+
+func registerFlags(fs *flag.FlagSet) *options {
+	o := &options{}
+	fs.StringVar(&o.addr, "addr", env("ADDR", ""), "server address (default: the one saved at login, else "+defaultAddr+")")
+	fs.StringVar(&o.output, "o", env("OUTPUT", "table"), "output format: table or json")
+	fs.StringVar(&o.credentials, "credentials", env("CREDENTIALS", defaultCredentialsPath()), "where login saves the session")
+	fs.StringVar(&o.apiKey, "api-key", env("API_KEY", ""), "authenticate with this API key instead of the saved session")
+	fs.DurationVar(&o.timeout, "timeout", 10*time.Second, "per-request timeout")
+
+	fs.BoolVar(&o.tls, "tls", env("TLS", "") == "true", "connect with TLS (implied by -ca, -cert or -server-name)")
+	fs.StringVar(&o.caFile, "ca", env("CA", ""), "PEM file of CAs to verify the server with (default: system roots)")
+	fs.StringVar(&o.certFile, "cert", env("CERT", ""), "PEM client certificate for mutual TLS")
+	fs.StringVar(&o.keyFile, "key", env("KEY", ""), "PEM private key for -cert")
+	fs.StringVar(&o.serverName, "server-name", env("SERVER_NAME", ""), "override the TLS server name")
+	fs.BoolVar(&o.insecureSkipVerify, "insecure-skip-verify", false, "do not verify the server certificate")
+	return o
+}
+
+func env(name, def string) string {
+	if v, ok := os.LookupEnv("PORTALCTL_" + name); ok {
+		return v
+	}
+	return def
+}
+
+// cli is the state shared by every command.
+type cli struct {
+	opts  *options
+	addr  string
+	out   *printer
+	creds *session
+	conn  *grpc.ClientConn
+	auth  authpb.AuthServiceClient
+}
+
+func newCLI(o *options) (*cli, error) {
+	out, err := newPrinter(os.Stdout, o.output)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := loadCredentials(o.credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := o.addr
+	if addr == "" {
+		addr = creds.Addr
+	}
+	if addr == "" {
+		addr = defaultAddr
+	}
+
+	transport, _, err := sharedgrpc.ClientCredentials(o.tls, security.ClientTLSConfig{
+		CAFile:             o.caFile,
+		CertFile:           o.certFile,
+		KeyFile:            o.keyFile,
+		ServerName:         o.serverName,
+		InsecureSkipVerify: o.insecureSkipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, err
+	}
+
+	return &cli{
+		opts:  o,
+		addr:  addr,
+		out:   out,
+		creds: creds,
+		conn:  conn,
+		auth:  authpb.NewAuthServiceClient(conn),
+	}, nil
+}
+
+func (c *cli) Close() error {
+	return c.conn.Close()
+}
+
+// call returns a request context bounded by -timeout.
+func (c *cli) call(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.opts.timeout)
+}
+
+// authed is call with the API key, or else the saved session cookies,
+// attached.
+func (c *cli) authed(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if c.opts.apiKey != "" {
+		ctx, cancel := c.call(ctx)
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "ApiKey "+c.opts.apiKey), cancel, nil
+	}
+
+	if c.creds.Cookies["access_token"] == "" {
+		return nil, nil, errors.New("not logged in; run portalctl login")
+	}
+
+	ctx, cancel := c.call(ctx)
+	return metadata.AppendToOutgoingContext(ctx, "cookie", c.creds.cookieHeader()), cancel, nil
+}
+
+// describe renders a gRPC error with its reason and field violations.
+func describe(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s", st.Message(), st.Code())
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetReason() != "" {
+			fmt.Fprintf(&b, ", %s", info.GetReason())
+		}
+	}
+	b.WriteString(")")
+
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fmt.Fprintf(&b, "\n  %s: %s", v.GetField(), v.GetDescription())
+			}
+		}
+	}
+
+	if st.Code() == codes.Unauthenticated {
+		b.WriteString("\nhint: run portalctl refresh, or portalctl login if that fails")
+	}
+
+	return b.String()
+}
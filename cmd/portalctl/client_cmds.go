@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	authpb "admin-portal/proto/auth"
+)
+
+func runClient(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("client requires a subcommand: register, list or revoke")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "register":
+		return runClientRegister(ctx, c, args)
+	case "list":
+		return runClientList(ctx, c, args)
+	case "revoke":
+		if len(args) != 1 {
+			return fmt.Errorf("client revoke requires a client ID")
+		}
+		return runClientRevoke(ctx, c, args[0])
+	default:
+		return fmt.Errorf("unknown client subcommand %q", sub)
+	}
+}
+
+func runClientRegister(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("client register", flag.ContinueOnError)
+	name := fs.String("name", "", "the app's name, e.g. wiki")
+	var redirectURIs stringList
+	fs.Var(&redirectURIs, "redirect-uri", "where the app receives the authorization code (repeatable)")
+	public := fs.Bool("public", false, "register a public client (SPA or native app) without a secret")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || len(redirectURIs) == 0 {
+		return fmt.Errorf("client register requires -name and -redirect-uri")
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resp, err := c.auth.RegisterOAuthClient(ctx, &authpb.RegisterOAuthClientRequest{
+		Name:         *name,
+		RedirectUris: redirectURIs,
+		Public:       *public,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.out.json {
+		return c.printJSON(resp)
+	}
+
+	cl := resp.GetClient()
+	if cl.GetPublic() {
+		c.out.status("Registered public client %s", cl.GetName())
+		fmt.Println(cl.GetId())
+		return nil
+	}
+
+	// The secret goes alone to stdout so it can be captured by a script.
+	fmt.Fprintf(os.Stderr, "Registered client %s with ID %s. Store the secret now; it cannot be shown again.\n", cl.GetName(), cl.GetId())
+	fmt.Println(resp.GetClientSecret())
+	return nil
+}
+
+func runClientList(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("client list", flag.ContinueOnError)
+	revoked := fs.Bool("revoked", false, "include revoked clients")
+	pg := registerPageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	req := &authpb.ListOAuthClientsRequest{
+		IncludeRevoked: *revoked,
+		PageSize:       int32(pg.size),
+		PageToken:      pg.token,
+	}
+
+	all := &authpb.ListOAuthClientsResponse{}
+	for {
+		resp, err := c.auth.ListOAuthClients(ctx, req)
+		if err != nil {
+			return err
+		}
+		all.Clients = append(all.Clients, resp.GetClients()...)
+		all.TotalSize = resp.GetTotalSize()
+		all.NextPageToken = resp.GetNextPageToken()
+
+		if !pg.all || resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	rows := make([][]string, len(all.GetClients()))
+	for i, cl := range all.GetClients() {
+		rows[i] = []string{
+			cl.GetId(),
+			cl.GetName(),
+			formatBool(cl.GetPublic()),
+			strings.Join(cl.GetRedirectUris(), ","),
+			formatTime(cl.GetCreatedAt()),
+			formatBool(cl.GetRevokedAt() != nil),
+		}
+	}
+	if err := c.out.print(all, []string{"ID", "NAME", "PUBLIC", "REDIRECT URIS", "CREATED", "REVOKED"}, rows); err != nil {
+		return err
+	}
+	c.out.pageFooter(len(rows), all.GetTotalSize(), all.GetNextPageToken())
+	return nil
+}
+
+func runClientRevoke(ctx context.Context, c *cli, id string) error {
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := c.auth.RevokeOAuthClient(ctx, &authpb.RevokeOAuthClientRequest{ClientId: id}); err != nil {
+		return err
+	}
+
+	c.out.status("Revoked client %s", id)
+	return nil
+}
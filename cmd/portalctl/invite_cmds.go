@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	authpb "admin-portal/proto/auth"
+)
+
+func runInvite(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("invite requires a subcommand: send, list, revoke or accept")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "send":
+		return runInviteSend(ctx, c, args)
+	case "list":
+		return runInviteList(ctx, c, args)
+	case "revoke":
+		if len(args) != 1 {
+			return fmt.Errorf("invite revoke requires an invitation ID")
+		}
+		return runInviteRevoke(ctx, c, args[0])
+	case "accept":
+		return runInviteAccept(ctx, c, args)
+	default:
+		return fmt.Errorf("unknown invite subcommand %q", sub)
+	}
+}
+
+func runInviteSend(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("invite send", flag.ContinueOnError)
+	email := fs.String("email", "", "address to send the invitation to")
+	role := fs.String("role", "user", "user, admin or super-admin; at most your own")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return fmt.Errorf("invite send requires -email")
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	inv, err := c.auth.InviteUser(ctx, &authpb.InviteUserRequest{Email: *email, Role: *role})
+	if err != nil {
+		return err
+	}
+
+	c.out.status("Invited %s as %s (%s), valid until %s", inv.GetEmail(), inv.GetRole(), inv.GetId(), formatTime(inv.GetExpiresAt()))
+	return c.printJSON(inv)
+}
+
+func runInviteList(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("invite list", flag.ContinueOnError)
+	closed := fs.Bool("closed", false, "include accepted, revoked and expired invitations")
+	pg := registerPageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	req := &authpb.ListInvitationsRequest{
+		IncludeClosed: *closed,
+		PageSize:      int32(pg.size),
+		PageToken:     pg.token,
+	}
+
+	all := &authpb.ListInvitationsResponse{}
+	for {
+		resp, err := c.auth.ListInvitations(ctx, req)
+		if err != nil {
+			return err
+		}
+		all.Invitations = append(all.Invitations, resp.GetInvitations()...)
+		all.TotalSize = resp.GetTotalSize()
+		all.NextPageToken = resp.GetNextPageToken()
+
+		if !pg.all || resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	rows := make([][]string, len(all.GetInvitations()))
+	for i, inv := range all.GetInvitations() {
+		rows[i] = []string{
+			inv.GetId(),
+			inv.GetEmail(),
+			inv.GetRole(),
+			invitationState(inv),
+			orDash(inv.GetInvitedBy()),
+			formatTime(inv.GetCreatedAt()),
+			formatTime(inv.GetExpiresAt()),
+		}
+	}
+	if err := c.out.print(all, []string{"ID", "EMAIL", "ROLE", "STATE", "INVITED BY", "CREATED", "EXPIRES"}, rows); err != nil {
+		return err
+	}
+	c.out.pageFooter(len(rows), all.GetTotalSize(), all.GetNextPageToken())
+	return nil
+}
+
+func invitationState(inv *authpb.Invitation) string {
+	switch {
+	case inv.GetAcceptedAt() != nil:
+		return "accepted"
+	case inv.GetRevokedAt() != nil:
+		return "revoked"
+	case !inv.GetExpiresAt().AsTime().After(time.Now()):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+func runInviteRevoke(ctx context.Context, c *cli, id string) error {
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := c.auth.RevokeInvitation(ctx, &authpb.RevokeInvitationRequest{InvitationId: id}); err != nil {
+		return err
+	}
+
+	c.out.status("Revoked invitation %s", id)
+	return nil
+}
+
+func runInviteAccept(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("invite accept", flag.ContinueOnError)
+	username := fs.String("u", "", "username for the new account")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || fs.NArg() != 1 {
+		return fmt.Errorf("invite accept requires -u and the token from the invitation link")
+	}
+
+	password, err := readPassword("Password for "+*username+": ", *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+
+	resp, err := c.auth.AcceptInvitation(ctx, &authpb.AcceptInvitationRequest{
+		Token:    fs.Arg(0),
+		Username: *username,
+		Password: password,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.out.status("Created user %s (%s); you can log in now", *username, resp.GetUserId())
+	return c.printJSON(resp)
+}
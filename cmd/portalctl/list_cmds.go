@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	authpb "admin-portal/proto/auth"
+)
+
+type pageFlags struct {
+	size  int
+	token string
+	all   bool
+}
+
+func registerPageFlags(fs *flag.FlagSet) *pageFlags {
+	p := &pageFlags{}
+	fs.IntVar(&p.size, "page-size", 0, "results per page (server default 50, max 500)")
+	fs.StringVar(&p.token, "page-token", "", "continue from a previous page")
+	fs.BoolVar(&p.all, "all", false, "fetch every page")
+	return p
+}
+
+func runSessions(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("sessions", flag.ContinueOnError)
+	userID := fs.String("user", "", "user ID (default: yourself; others need admin)")
+	inactive := fs.Bool("inactive", false, "include revoked and expired sessions")
+	pg := registerPageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	req := &authpb.ListSessionsRequest{
+		UserId:          *userID,
+		IncludeInactive: *inactive,
+		PageSize:        int32(pg.size),
+		PageToken:       pg.token,
+	}
+
+	all := &authpb.ListSessionsResponse{}
+	for {
+		resp, err := c.auth.ListSessions(ctx, req)
+		if err != nil {
+			return err
+		}
+		all.Sessions = append(all.Sessions, resp.GetSessions()...)
+		all.TotalSize = resp.GetTotalSize()
+		all.NextPageToken = resp.GetNextPageToken()
+
+		if !pg.all || resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	rows := make([][]string, len(all.GetSessions()))
+	for i, s := range all.GetSessions() {
+		rows[i] = []string{
+			s.GetId(),
+			s.GetUserId(),
+			formatTime(s.GetCreatedAt()),
+			formatTime(s.GetExpiresAt()),
+			formatBool(s.GetIsRevoked()),
+		}
+	}
+	if err := c.out.print(all, []string{"ID", "USER", "CREATED", "EXPIRES", "REVOKED"}, rows); err != nil {
+		return err
+	}
+	c.out.pageFooter(len(rows), all.GetTotalSize(), all.GetNextPageToken())
+	return nil
+}
+
+func runLogs(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	userID := fs.String("user", "", "only logs of this user ID")
+	logType := fs.String("type", "", "warn, error, info or success")
+	since := fs.String("since", "", "only logs at or after this time")
+	until := fs.String("until", "", "only logs before this time")
+	pg := registerPageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	req := &authpb.ListLoginLogsRequest{
+		UserId:    *userID,
+		LogType:   *logType,
+		PageSize:  int32(pg.size),
+		PageToken: pg.token,
+	}
+	for _, bound := range []struct {
+		value string
+		dst   **timestamppb.Timestamp
+	}{
+		{*since, &req.Since},
+		{*until, &req.Until},
+	} {
+		t, err := parseTime(bound.value)
+		if err != nil {
+			return err
+		}
+		if !t.IsZero() {
+			*bound.dst = timestamppb.New(t)
+		}
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	all := &authpb.ListLoginLogsResponse{}
+	for {
+		resp, err := c.auth.ListLoginLogs(ctx, req)
+		if err != nil {
+			return err
+		}
+		all.Logs = append(all.Logs, resp.GetLogs()...)
+		all.TotalSize = resp.GetTotalSize()
+		all.NextPageToken = resp.GetNextPageToken()
+
+		if !pg.all || resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	rows := make([][]string, len(all.GetLogs()))
+	for i, l := range all.GetLogs() {
+		rows[i] = []string{
+			formatTime(l.GetCreatedAt()),
+			l.GetLogType(),
+			orDash(l.GetUserId()),
+			orDash(l.GetIpAddress()),
+			l.GetMessage(),
+		}
+	}
+	if err := c.out.print(all, []string{"TIME", "TYPE", "USER", "IP", "MESSAGE"}, rows); err != nil {
+		return err
+	}
+	c.out.pageFooter(len(rows), all.GetTotalSize(), all.GetNextPageToken())
+	return nil
+}
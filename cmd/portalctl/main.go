@@ -0,0 +1,152 @@
+// Command portalctl administers the admin portal over gRPC.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+const usage = `Usage: portalctl [flags] <command> [args]
+
+Commands:
+  login [-u user] [-password-stdin]      sign in and save the session cookies
+  login -sso                             sign in with the identity provider
+  logout                                 revoke the session and forget it
+  refresh                                trade the refresh token for new tokens
+  whoami                                 show the signed-in user
+  activate <token>                       activate an account with the token from its email
+  activate -resend email                 email a new activation link
+
+  user create -u user -email email [-activate] [-password-stdin]
+  user list [-role role] [-q text] [-page-size N] [-page-token T] [-all]
+  user activate <user-id>
+  user deactivate <user-id>
+  user set-role <user-id> <role>
+
+  sessions [-user user-id] [-inactive] [-page-size N] [-page-token T] [-all]
+  logs [-user user-id] [-type type] [-since t] [-until t] [-page-size N] [-page-token T] [-all]
+
+  apikey create -name name -scope s... [-expires t]
+  apikey list [-user user-id] [-inactive] [-page-size N] [-page-token T] [-all]
+  apikey revoke <key-id>
+
+  passkey list [-user user-id]
+  passkey delete [-user user-id] <passkey-id>
+
+  invite send -email email [-role role]
+  invite list [-closed] [-page-size N] [-page-token T] [-all]
+  invite revoke <invitation-id>
+  invite accept -u user [-password-stdin] <token>
+
+  client register -name name -redirect-uri uri... [-public]
+  client list [-revoked] [-page-size N] [-page-token T] [-all]
+  client revoke <client-id>
+
+Times for -since and -until are RFC 3339 or a duration ago, e.g. 24h;
+-expires is RFC 3339 or a duration from now. API key scopes are "*",
+"/pkg.Service/" or "/pkg.Service/Method"; keys need at least one, and
+only get every method with an explicit "*".
+New accounts have the user role and are activated with the link
+emailed to them, or by an admin with user activate or user create
+-activate; other roles are granted with invitations. Invitations need
+admin and cannot grant a role above your own; invitees accept them
+with the token from their email and get an activated account.
+Scripts can pass a key with -api-key instead of logging in.
+Passkeys are registered from a browser; an admin can delete the
+passkeys of a user who lost their device.
+Clients are apps that sign users in through the portal with OpenID
+Connect; registering them needs admin.
+Passwords are prompted for unless -password-stdin is given or
+PORTALCTL_PASSWORD is set.
+
+Flags (also PORTALCTL_<FLAG> in the environment, e.g. PORTALCTL_ADDR):
+`
+
+type command struct {
+	name string
+	run  func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands = []command{
+	{"login", runLogin},
+	{"logout", runLogout},
+	{"refresh", runRefresh},
+	{"whoami", runWhoAmI},
+	{"activate", runActivate},
+	{"user", runUser},
+	{"sessions", runSessions},
+	{"logs", runLogs},
+	{"apikey", runAPIKey},
+	{"passkey", runPasskey},
+	{"invite", runInvite},
+	{"client", runClient},
+}
+
+func main() {
+	opts := registerFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	name, args := flag.Arg(0), flag.Args()[1:]
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "portalctl: unknown command %q\n\n", name)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	c, err := newCLI(opts)
+	if err != nil {
+		fail(err)
+	}
+	defer c.Close()
+
+	if err := cmd.run(ctx, c, args); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	fmt.Fprintln(os.Stderr, "portalctl:", describe(err))
+	os.Exit(1)
+}
+
+// parseTime accepts RFC 3339 or a duration meaning that long ago.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC 3339 nor a duration", s)
+	}
+	return time.Now().Add(-d), nil
+}
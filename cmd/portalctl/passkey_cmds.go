@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	authpb "admin-portal/proto/auth"
+)
+
+// runPasskey lists and deletes passkeys. They are registered from a
+// browser, which holds the authenticator.
+func runPasskey(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("passkey requires a subcommand: list or delete")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "list":
+		return runPasskeyList(ctx, c, args)
+	case "delete":
+		return runPasskeyDelete(ctx, c, args)
+	default:
+		return fmt.Errorf("unknown passkey subcommand %q", sub)
+	}
+}
+
+func runPasskeyList(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("passkey list", flag.ContinueOnError)
+	userID := fs.String("user", "", "user ID (default: yourself; others need admin)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resp, err := c.auth.ListPasskeys(ctx, &authpb.ListPasskeysRequest{UserId: *userID})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, len(resp.GetPasskeys()))
+	for i, p := range resp.GetPasskeys() {
+		rows[i] = []string{
+			p.GetId(),
+			p.GetName(),
+			strings.Join(p.GetTransports(), ","),
+			formatBool(p.GetBackedUp()),
+			formatTime(p.GetCreatedAt()),
+			formatTime(p.GetLastUsedAt()),
+		}
+	}
+	return c.out.print(resp, []string{"ID", "NAME", "TRANSPORTS", "SYNCED", "CREATED", "LAST USED"}, rows)
+}
+
+func runPasskeyDelete(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("passkey delete", flag.ContinueOnError)
+	userID := fs.String("user", "", "owner's user ID (default: yourself; others need admin)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("passkey delete requires a passkey ID")
+	}
+	id := fs.Arg(0)
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := c.auth.DeletePasskey(ctx, &authpb.DeletePasskeyRequest{Id: id, UserId: *userID}); err != nil {
+		return err
+	}
+
+	c.out.status("Deleted passkey %s", id)
+	return nil
+}
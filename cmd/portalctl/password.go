@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readPassword takes the password from stdin, PORTALCTL_PASSWORD, or a
+// prompt on the terminal, in that order.
+func readPassword(prompt string, fromStdin bool) (string, error) {
+	if fromStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	if pw, ok := os.LookupEnv("PORTALCTL_PASSWORD"); ok {
+		return pw, nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	return readNoEcho(os.Stdin)
+}
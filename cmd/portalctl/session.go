@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// session is what login saves: the server it was opened against and
+// the cookies it set.
+type session struct {
+	path string
+
+	Addr     string            `json:"addr"`
+	UserID   string            `json:"user_id"`
+	Username string            `json:"username"`
+	Cookies  map[string]string `json:"cookies"`
+	SavedAt  time.Time         `json:"saved_at"`
+}
+
+func defaultCredentialsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".portalctl-credentials.json"
+	}
+	return filepath.Join(dir, "portalctl", "credentials.json")
+}
+
+// loadCredentials reads path. A missing file is an empty session.
+func loadCredentials(path string) (*session, error) {
+	c := &session{path: path, Cookies: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Cookies == nil {
+		c.Cookies = map[string]string{}
+	}
+	return c, nil
+}
+
+// save writes the session readable by the owner only.
+func (c *session) save() error {
+	c.SavedAt = time.Now()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+func (c *session) remove() error {
+	err := os.Remove(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// apply merges the set-cookie entries of a response header. Cookies the
+// server expires are dropped.
+func (c *session) apply(header metadata.MD) {
+	resp := http.Response{Header: http.Header{"Set-Cookie": header.Get("set-cookie")}}
+	for _, cookie := range resp.Cookies() {
+		if cookie.MaxAge < 0 || cookie.Value == "" {
+			delete(c.Cookies, cookie.Name)
+			continue
+		}
+		c.Cookies[cookie.Name] = cookie.Value
+	}
+}
+
+// cookieHeader renders the saved cookies as a Cookie request header.
+func (c *session) cookieHeader() string {
+	names := make([]string, 0, len(c.Cookies))
+	for name := range c.Cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + c.Cookies[name]
+	}
+	return strings.Join(pairs, "; ")
+}
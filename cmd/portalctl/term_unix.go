@@ -0,0 +1,36 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// readNoEcho reads a line with terminal echo switched off. Input that is
+// not a terminal is read as is.
+func readNoEcho(f *os.File) (string, error) {
+	fd := int(f.Fd())
+
+	old, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err == nil {
+		noEcho := *old
+		noEcho.Lflag &^= unix.ECHO
+		noEcho.Lflag |= unix.ICANON | unix.ISIG
+		if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &noEcho); err != nil {
+			return "", err
+		}
+		defer unix.IoctlSetTermios(fd, ioctlSetTermios, old)
+	}
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	authpb "admin-portal/proto/auth"
+)
+
+var userHeader = []string{"ID", "USERNAME", "EMAIL", "ROLE", "ACTIVE", "ACTIVATED", "CREATED"}
+
+func userRow(u *authpb.User) []string {
+	return []string{
+		u.GetId(),
+		u.GetUsername(),
+		orDash(u.GetEmail()),
+		u.GetRole(),
+		formatBool(u.GetIsActive()),
+		formatBool(u.GetIsActivated()),
+		formatTime(u.GetCreatedAt()),
+	}
+}
+
+func runUser(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("user requires a subcommand: create, list, activate, deactivate or set-role")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "create":
+		return runUserCreate(ctx, c, args)
+	case "list":
+		return runUserList(ctx, c, args)
+	case "activate", "deactivate":
+		if len(args) != 1 {
+			return fmt.Errorf("user %s requires a user ID", sub)
+		}
+		return runUserToggle(ctx, c, sub, args[0])
+	case "set-role":
+		if len(args) != 2 {
+			return fmt.Errorf("user set-role requires a user ID and a role")
+		}
+		return runUserSetRole(ctx, c, args[0], args[1])
+	default:
+		return fmt.Errorf("unknown user subcommand %q", sub)
+	}
+}
+
+func runUserCreate(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	username := fs.String("u", "", "username")
+	email := fs.String("email", "", "email address the activation link is sent to")
+	activate := fs.Bool("activate", false, "activate the account straight away (needs admin)")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || *email == "" {
+		return fmt.Errorf("user create requires -u and -email")
+	}
+
+	password, err := readPassword("Password for "+*username+": ", *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	// Register is public, but send the session anyway so the server can
+	// attribute the change; -activate needs it.
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	if c.creds.Cookies["access_token"] != "" {
+		authed, authCancel, _ := c.authed(ctx)
+		defer authCancel()
+		ctx = authed
+	}
+
+	resp, err := c.auth.Register(ctx, &authpb.RegisterRequest{
+		Username: *username,
+		Email:    *email,
+		Password: password,
+		Role:     "user",
+	})
+	if err != nil {
+		return err
+	}
+	c.out.status("Created user %s (%s)", *username, resp.GetUserId())
+
+	if *activate {
+		if _, err := c.auth.ActivateUser(ctx, &authpb.ActivateUserRequest{UserId: resp.GetUserId()}); err != nil {
+			return err
+		}
+		c.out.status("Activated user %s", resp.GetUserId())
+	}
+
+	return c.printJSON(resp)
+}
+
+func runUserList(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("user list", flag.ContinueOnError)
+	role := fs.String("role", "", "only users with this role")
+	query := fs.String("q", "", "only usernames containing this text")
+	pg := registerPageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	req := &authpb.ListUsersRequest{
+		Role:      *role,
+		Query:     *query,
+		PageSize:  int32(pg.size),
+		PageToken: pg.token,
+	}
+
+	all := &authpb.ListUsersResponse{}
+	for {
+		resp, err := c.auth.ListUsers(ctx, req)
+		if err != nil {
+			return err
+		}
+		all.Users = append(all.Users, resp.GetUsers()...)
+		all.TotalSize = resp.GetTotalSize()
+		all.NextPageToken = resp.GetNextPageToken()
+
+		if !pg.all || resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	rows := make([][]string, len(all.GetUsers()))
+	for i, u := range all.GetUsers() {
+		rows[i] = userRow(u)
+	}
+	if err := c.out.print(all, userHeader, rows); err != nil {
+		return err
+	}
+	c.out.pageFooter(len(rows), all.GetTotalSize(), all.GetNextPageToken())
+	return nil
+}
+
+func runUserToggle(ctx context.Context, c *cli, action, userID string) error {
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if action == "activate" {
+		_, err = c.auth.ActivateUser(ctx, &authpb.ActivateUserRequest{UserId: userID})
+	} else {
+		_, err = c.auth.Deactivate(ctx, &authpb.DeactivateRequest{UserId: userID})
+	}
+	if err != nil {
+		return err
+	}
+
+	c.out.status("User %s %sd", userID, action)
+	return nil
+}
+
+func runUserSetRole(ctx context.Context, c *cli, userID, role string) error {
+	ctx, cancel, err := c.authed(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if _, err := c.auth.ChangeRole(ctx, &authpb.ChangeRoleRequest{UserId: userID, Role: role}); err != nil {
+		return err
+	}
+
+	c.out.status("User %s is now %s", userID, role)
+	return nil
+}
+
+// printJSON prints msg only in JSON mode, where status lines are silent.
+func (c *cli) printJSON(msg proto.Message) error {
+	if !c.out.json {
+		return nil
+	}
+	return c.out.print(msg, nil, nil)
+}
@@ -1 +1,217 @@
-package worker
\ No newline at end of file
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	authmodule "admin-portal/internal/auth-module"
+	"admin-portal/internal/auth-module/jobs"
+	"admin-portal/internal/auth-module/repository"
+	authservice "admin-portal/internal/auth-module/service"
+	"admin-portal/internal/logger"
+
+	"admin-portal/internal/shared/config"
+	"admin-portal/internal/shared/database"
+	"admin-portal/internal/shared/mail"
+	"admin-portal/internal/shared/metrics"
+	"admin-portal/internal/shared/outbox"
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/scheduler"
+	"admin-portal/internal/shared/security"
+	webhookmodule "admin-portal/internal/webhook-module"
+	webhookservice "admin-portal/internal/webhook-module/service"
+)
+
+func main() {
+	log.Println("🚀 Worker starting...")
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("❌ Failed to load .env:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logs := logger.New("worker")
+
+	// ---------------------------
+	// Load DB & GORM
+	// ---------------------------
+	cfg := database.LoadConfig()
+
+	db, err := database.OpenGorm(cfg)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	defer database.CloseGorm(db)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("failed to get database pool: %v", err)
+	}
+
+	// ---------------------------
+	// Metrics
+	// ---------------------------
+	registry := metrics.NewRegistry()
+	metrics.RegisterDBStats(registry, sqlDB, cfg.DBName)
+	jobMetrics := metrics.NewJobMetrics(registry)
+	queueMetrics := metrics.NewQueueMetrics(registry)
+	outboxMetrics := metrics.NewOutboxMetrics(registry)
+
+	metricsCfg := metrics.LoadConfig()
+	metricsCfg.Addr = config.String("WORKER_METRICS_ADDR", ":9091")
+	metricsServer := metrics.NewServer(metricsCfg, registry)
+
+	// ---------------------------
+	// Queue
+	// ---------------------------
+	queueCfg := queue.LoadConfig()
+	queueStore := queue.NewStore(db)
+
+	queueWorker := queue.NewWorker(queueStore, queueCfg, logs, queueMetrics)
+
+	// ---------------------------
+	// Activation and invitation emails
+	// ---------------------------
+	// Activation tokens are signed with a key derived from the API's JWT
+	// secret, so the worker needs the same secret.
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET environment variable is not set")
+	}
+
+	mailCfg := mail.LoadConfig()
+	mailer, err := mail.New(mailCfg)
+	if err != nil {
+		log.Fatalf("invalid mail configuration: %v", err)
+	}
+	if mailCfg.Driver == mail.DriverFile {
+		log.Printf("✉️ Emails are written to %s instead of being sent", mailCfg.Dir)
+	}
+
+	activationMailer := authmodule.NewActivationMailer(db,
+		security.JWTConfig{Secret: jwtSecret, Issuer: "admin-portal"}, mailer)
+	queueWorker.Handle(authservice.KindActivationEmail, activationMailer.Handle)
+	queueWorker.Handle(authservice.KindInvitationEmail, authmodule.NewInvitationMailer(db, mailer).Handle)
+	queueWorker.Handle(
+		jobs.KindActivationReminder,
+		jobs.ActivationReminderHandler(repository.NewUserRepository(db), activationMailer),
+	)
+
+	cipher, err := security.LoadCipher()
+	if err != nil {
+		log.Fatalf("failed to load encryption key: %v", err)
+	}
+
+	deliverer := webhookmodule.NewDeliverer(db, cipher, webhookservice.LoadConfig())
+	queueWorker.Handle(webhookservice.KindDeliver, deliverer.Handle)
+
+	// ---------------------------
+	// Outbox relay
+	// ---------------------------
+	outboxCfg := outbox.LoadConfig()
+
+	sink, err := outbox.NewSink(outboxCfg)
+	if err != nil {
+		log.Fatalf("invalid outbox configuration: %v", err)
+	}
+
+	if c, ok := sink.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	var relay *outbox.Relay
+	if sink != nil {
+		relay = outbox.NewRelay(db, sink, outboxCfg, logs, outboxMetrics)
+	} else {
+		log.Println("⚠️ OUTBOX_SINK is not set; domain events will not be published")
+	}
+
+	// ---------------------------
+	// Scheduled jobs
+	// ---------------------------
+	jobCfg := jobs.LoadConfig()
+
+	logRetention, err := jobs.NewLoginLogRetention(
+		repository.NewLoginLogRepository(db),
+		jobCfg.LoginLogRetention,
+		jobCfg.LoginLogRetentionMode,
+		jobCfg.BatchSize,
+	)
+	if err != nil {
+		log.Fatalf("invalid job configuration: %v", err)
+	}
+
+	sched := scheduler.New(sqlDB, logs, jobMetrics)
+
+	sched.Add(
+		jobs.NewSessionPurge(repository.NewUserSessionRepository(db), jobCfg.SessionGrace, jobCfg.BatchSize),
+		jobCfg.SessionPurgeInterval, jobCfg.Timeout,
+	)
+	sched.Add(
+		jobs.NewSSOStatePurge(repository.NewSSOStateRepository(db), jobCfg.BatchSize),
+		jobCfg.SessionPurgeInterval, jobCfg.Timeout,
+	)
+	sched.Add(
+		jobs.NewOAuthGrantPurge(repository.NewOAuthGrantRepository(db), jobCfg.BatchSize),
+		jobCfg.SessionPurgeInterval, jobCfg.Timeout,
+	)
+	sched.Add(
+		jobs.NewWebAuthnChallengePurge(repository.NewWebAuthnRepository(db), jobCfg.BatchSize),
+		jobCfg.SessionPurgeInterval, jobCfg.Timeout,
+	)
+	sched.Add(logRetention, jobCfg.LoginLogInterval, jobCfg.Timeout)
+	sched.Add(
+		jobs.NewActivationReminder(db, jobCfg.ActivationReminderAfter, jobCfg.BatchSize),
+		jobCfg.ActivationReminderInterval, jobCfg.Timeout,
+	)
+	sched.Add(
+		queue.NewPurgeJob(queueStore, queueCfg.Retention, jobCfg.BatchSize),
+		jobCfg.SessionPurgeInterval, jobCfg.Timeout,
+	)
+	sched.Add(
+		outbox.NewPurgeJob(db, outboxCfg.Retention, jobCfg.BatchSize),
+		jobCfg.SessionPurgeInterval, jobCfg.Timeout,
+	)
+
+	// ---------------------------
+	// Run until signalled
+	// ---------------------------
+	metricsServer.Start()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sched.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		queueWorker.Run(ctx)
+	}()
+	if relay != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay.Run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	log.Println("🛑 Worker stopping")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("metrics server shutdown: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	authmodule "admin-portal/internal/auth-module"
+	"admin-portal/internal/auth-module/events"
+	"admin-portal/internal/auth-module/middleware"
+	authservice "admin-portal/internal/auth-module/service"
+	jobmodule "admin-portal/internal/job-module"
+	sharedgrpc "admin-portal/internal/shared/grpc"
+	"admin-portal/internal/shared/metrics"
+	"admin-portal/internal/shared/oidc"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/tracing"
+	"admin-portal/internal/shared/webauthn"
+	webhookmodule "admin-portal/internal/webhook-module"
+	webhookservice "admin-portal/internal/webhook-module/service"
+)
+
+// Deps are the process-wide resources the API modules are built from.
+type Deps struct {
+	DB      *gorm.DB
+	JWT     security.JWTConfig
+	Cipher  *security.Cipher
+	Webhook webhookservice.Config
+
+	// Metrics receives the gRPC and auth collectors. Nil disables them.
+	Metrics prometheus.Registerer
+
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For is
+	// believed. With none, the client address is the peer address.
+	TrustedProxies middleware.TrustedProxies
+
+	// Services lets mTLS clients authenticate by certificate. It only
+	// takes effect when the server verifies client certificates.
+	Services middleware.ServiceIdentities
+
+	// OIDC is the identity provider for single sign-on. Nil disables it.
+	OIDC *oidc.Provider
+
+	// OAuth configures the portal as an OAuth 2.0 / OpenID Connect
+	// provider for other apps. The zero value disables it.
+	OAuth authservice.OAuthConfig
+
+	// LDAP lets directory users log in with their directory password. The
+	// zero value disables it.
+	LDAP authservice.LDAPConfig
+
+	// WebAuthn is the relying party for passkey logins. Nil disables them.
+	WebAuthn *webauthn.RelyingParty
+}
+
+// App is the API server with every module registered. cmd/api and the
+// integration test harness both build it here, so tests exercise the
+// production interceptor chain.
+type App struct {
+	Server *sharedgrpc.Server
+
+	Auth     *authmodule.Module
+	Jobs     *jobmodule.Module
+	Webhooks *webhookmodule.Module
+}
+
+func New(cfg sharedgrpc.Config, deps Deps) *App {
+	var authMetrics authservice.Metrics
+	var interceptors []grpc.UnaryServerInterceptor
+
+	interceptors = append(interceptors, tracing.UnaryServerInterceptor())
+	if deps.Metrics != nil {
+		interceptors = append(interceptors, metrics.NewGRPCMetrics(deps.Metrics).UnaryInterceptor())
+		authMetrics = metrics.NewAuthMetrics(deps.Metrics)
+	}
+
+	// ---------------------------
+	// Initialize modules
+	// ---------------------------
+	webhookModule := webhookmodule.New(deps.DB, deps.Cipher, deps.Webhook, events.SecurityEventTypes)
+	authModule := authmodule.New(deps.DB, deps.JWT, authMetrics, webhookModule.Dispatcher, deps.OIDC, deps.OAuth, deps.LDAP, deps.WebAuthn)
+	jobModule := jobmodule.New(deps.DB)
+
+	// ---------------------------
+	// gRPC server with interceptors
+	// ---------------------------
+	interceptors = append(interceptors,
+		middleware.ClientUnaryInterceptor(deps.TrustedProxies),
+		middleware.ServiceIdentityUnaryInterceptor(deps.Services),
+		middleware.JWTUnaryInterceptor(deps.JWT, authModule.APIKeyService),
+		middleware.RBACUnaryInterceptor(authModule.Policy().Merge(
+			jobModule.Policy(),
+			webhookModule.Policy(),
+		)),
+	)
+
+	server := sharedgrpc.NewServer(cfg, interceptors...)
+	server.RegisterModules(authModule, jobModule, webhookModule)
+
+	return &App{
+		Server:   server,
+		Auth:     authModule,
+		Jobs:     jobModule,
+		Webhooks: webhookModule,
+	}
+}
@@ -0,0 +1,85 @@
+package events
+
+// AggregateUser is the aggregate type of every auth event; the aggregate
+// id is the user id.
+const AggregateUser = "user"
+
+// Event types written to the outbox by the auth service.
+const (
+	UserRegistered  = "auth.user.registered"
+	UserActivated   = "auth.user.activated"
+	UserDeactivated = "auth.user.deactivated"
+	UserRoleChanged = "auth.user.role_changed"
+	UserLoggedIn    = "auth.user.logged_in"
+)
+
+// Security event types. They are not written to the outbox but handed to
+// service.SecurityAlerts, which notifies webhook subscribers.
+const (
+	SecurityLoginFailures     = "security.login.repeated_failures"
+	SecurityLoginNewIP        = "security.login.new_ip"
+	SecuritySuperAdminGranted = "security.role.super_admin_granted"
+)
+
+// SecurityEventTypes lists every security event type, for validating
+// subscription filters.
+var SecurityEventTypes = []string{
+	SecurityLoginFailures,
+	SecurityLoginNewIP,
+	SecuritySuperAdminGranted,
+}
+
+// Payloads. ActorID is the authenticated caller that made the change and
+// is empty when there was none.
+
+type UserRegisteredPayload struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type UserActivatedPayload struct {
+	UserID  string `json:"user_id"`
+	ActorID string `json:"actor_id,omitempty"`
+}
+
+type UserDeactivatedPayload struct {
+	UserID  string `json:"user_id"`
+	ActorID string `json:"actor_id,omitempty"`
+}
+
+type UserRoleChangedPayload struct {
+	UserID  string `json:"user_id"`
+	OldRole string `json:"old_role"`
+	NewRole string `json:"new_role"`
+	ActorID string `json:"actor_id,omitempty"`
+}
+
+type UserLoggedInPayload struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+type LoginFailuresPayload struct {
+	// UserID is empty when the attempts used an unknown username; they
+	// are then counted per IP address.
+	UserID        string `json:"user_id,omitempty"`
+	Username      string `json:"username"`
+	IPAddress     string `json:"ip_address,omitempty"`
+	Failures      int64  `json:"failures"`
+	WindowSeconds int64  `json:"window_seconds"`
+}
+
+type LoginNewIPPayload struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+type SuperAdminGrantedPayload struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	OldRole  string `json:"old_role,omitempty"`
+	ActorID  string `json:"actor_id,omitempty"`
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	authpb "admin-portal/proto/auth"
+)
+
+func (h *AuthHandler) CreateAPIKey(
+	ctx context.Context,
+	req *authpb.CreateAPIKeyRequest,
+) (*authpb.CreateAPIKeyResponse, error) {
+
+	var expiresAt *time.Time
+	if req.GetExpiresAt() != nil {
+		t := req.GetExpiresAt().AsTime()
+		expiresAt = &t
+	}
+
+	key, secret, err := h.apiKeyService.Create(ctx, req.GetName(), req.GetScopes(), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authpb.CreateAPIKeyResponse{
+		ApiKey: apiKeyToProto(key),
+		Key:    secret,
+	}, nil
+}
+
+func (h *AuthHandler) ListAPIKeys(
+	ctx context.Context,
+	req *authpb.ListAPIKeysRequest,
+) (*authpb.ListAPIKeysResponse, error) {
+
+	limit, offset := page(req.GetPageSize(), req.GetPageToken())
+
+	keys, total, err := h.apiKeyService.List(ctx, repository.APIKeyFilter{
+		UserID:          req.GetUserId(),
+		IncludeInactive: req.GetIncludeInactive(),
+		Limit:           limit,
+		Offset:          offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &authpb.ListAPIKeysResponse{
+		TotalSize:     total,
+		NextPageToken: nextPageToken(offset, len(keys), total),
+	}
+	for _, k := range keys {
+		resp.ApiKeys = append(resp.ApiKeys, apiKeyToProto(k))
+	}
+
+	return resp, nil
+}
+
+func (h *AuthHandler) RevokeAPIKey(
+	ctx context.Context,
+	req *authpb.RevokeAPIKeyRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.apiKeyService.Revoke(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// apiKeyToProto never includes the hash.
+func apiKeyToProto(k *model.APIKey) *authpb.APIKey {
+	pb := &authpb.APIKey{
+		Id:        k.ID.String(),
+		UserId:    k.UserID.String(),
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    k.Scopes,
+		CreatedAt: timestamppb.New(k.CreatedAt),
+	}
+	if k.ExpiresAt != nil {
+		pb.ExpiresAt = timestamppb.New(*k.ExpiresAt)
+	}
+	if k.LastUsedAt != nil {
+		pb.LastUsedAt = timestamppb.New(*k.LastUsedAt)
+	}
+	if k.RevokedAt != nil {
+		pb.RevokedAt = timestamppb.New(*k.RevokedAt)
+	}
+	return pb
+}
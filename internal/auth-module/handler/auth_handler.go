@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"google.golang.org/grpc"
@@ -14,12 +15,32 @@ import (
 
 type AuthHandler struct {
 	authpb.UnimplementedAuthServiceServer
-	authService service.AuthService
+	authService   service.AuthService
+	userService   service.UserService
+	apiKeyService service.APIKeyService
+	ssoService    service.SSOService
+	oauthService  service.OAuthService
+	passkeys      service.PasskeyService
+	invitations   service.InvitationService
 }
 
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
+func NewAuthHandler(
+	authService service.AuthService,
+	userService service.UserService,
+	apiKeyService service.APIKeyService,
+	ssoService service.SSOService,
+	oauthService service.OAuthService,
+	passkeys service.PasskeyService,
+	invitations service.InvitationService,
+) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:   authService,
+		userService:   userService,
+		apiKeyService: apiKeyService,
+		ssoService:    ssoService,
+		oauthService:  oauthService,
+		passkeys:      passkeys,
+		invitations:   invitations,
 	}
 }
 
@@ -31,6 +52,7 @@ func (h *AuthHandler) Register(
 	user, err := h.authService.Register(
 		ctx,
 		req.GetUsername(),
+		req.GetEmail(),
 		req.GetPassword(),
 		req.GetRole(),
 	)
@@ -50,31 +72,19 @@ func (h *AuthHandler) Login(
 
 	user, accessToken, refreshToken, err :=
 		h.authService.Login(ctx, req.GetUsername(), req.GetPassword())
+
+	var secondFactor *service.SecondFactorRequired
+	if errors.As(err, &secondFactor) {
+		return &authpb.LoginResponse{
+			UserId:              secondFactor.UserID,
+			SecondFactorOptions: string(secondFactor.Options),
+		}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Access token cookie
-	grpc.SendHeader(ctx, metadata.Pairs(
-		"set-cookie",
-		buildCookie(
-			"access_token",
-			accessToken,
-			"/",
-			true,
-		),
-	))
-
-	// Refresh token cookie
-	grpc.SendHeader(ctx, metadata.Pairs(
-		"set-cookie",
-		buildCookie(
-			"refresh_token",
-			refreshToken,
-			"/auth/refresh",
-			true,
-		),
-	))
+	setTokenCookies(ctx, accessToken, refreshToken)
 
 	return &authpb.LoginResponse{
 		UserId: user.ID.String(),
@@ -87,18 +97,15 @@ func (h *AuthHandler) Logout(
 ) (*emptypb.Empty, error) {
 
 	// Extract refresh token from cookie
-	md, _ := metadata.FromIncomingContext(ctx)
-	for _, c := range md.Get("cookie") {
-		if token := extractCookie(c, "refresh_token"); token != "" {
-			_ = h.authService.Logout(ctx, token)
-		}
+	if token := refreshTokenFromMetadata(ctx); token != "" {
+		_ = h.authService.Logout(ctx, token)
 	}
 
 	// Clear cookies
-	grpc.SendHeader(ctx, metadata.Pairs(
+	grpc.SetHeader(ctx, metadata.Pairs(
 		"set-cookie", clearCookie("access_token", "/"),
 	))
-	grpc.SendHeader(ctx, metadata.Pairs(
+	grpc.SetHeader(ctx, metadata.Pairs(
 		"set-cookie", clearCookie("refresh_token", "/auth/refresh"),
 	))
 
@@ -110,6 +117,30 @@ func (h *AuthHandler) Activate(
 	req *authpb.ActivateRequest,
 ) (*emptypb.Empty, error) {
 
+	if err := h.authService.Activate(ctx, req.GetToken()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *AuthHandler) ResendActivation(
+	ctx context.Context,
+	req *authpb.ResendActivationRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.authService.ResendActivation(ctx, req.GetEmail()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *AuthHandler) ActivateUser(
+	ctx context.Context,
+	req *authpb.ActivateUserRequest,
+) (*emptypb.Empty, error) {
+
 	if err := h.authService.ActivateUser(ctx, req.GetUserId()); err != nil {
 		return nil, err
 	}
@@ -117,8 +148,72 @@ func (h *AuthHandler) Activate(
 	return &emptypb.Empty{}, nil
 }
 
+func (h *AuthHandler) Deactivate(
+	ctx context.Context,
+	req *authpb.DeactivateRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.authService.DeactivateUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *AuthHandler) ChangeRole(
+	ctx context.Context,
+	req *authpb.ChangeRoleRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.authService.ChangeRole(ctx, req.GetUserId(), req.GetRole()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *AuthHandler) Refresh(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*authpb.LoginResponse, error) {
+
+	user, accessToken, refreshToken, err :=
+		h.authService.Refresh(ctx, refreshTokenFromMetadata(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	setTokenCookies(ctx, accessToken, refreshToken)
+
+	return &authpb.LoginResponse{
+		UserId: user.ID.String(),
+	}, nil
+}
+
 //-------------------- Helper functions for cookie management --------------------//
 
+// setTokenCookies sends both token cookies with the response header.
+func setTokenCookies(ctx context.Context, accessToken, refreshToken string) {
+	grpc.SetHeader(ctx, metadata.Pairs(
+		"set-cookie", buildCookie("access_token", accessToken, "/", true),
+		"set-cookie", buildCookie("refresh_token", refreshToken, "/auth/refresh", true),
+	))
+}
+
+func refreshTokenFromMetadata(ctx context.Context) string {
+	return cookieFromMetadata(ctx, "refresh_token")
+}
+
+func cookieFromMetadata(ctx context.Context, name string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, c := range md.Get("cookie") {
+		if value := extractCookie(c, name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 func buildCookie(
 	name string,
 	value string,
@@ -0,0 +1,273 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/testharness"
+	authpb "admin-portal/proto/auth"
+)
+
+var ctx = context.Background()
+
+func wantCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("code = %s (%v), want %s", got, err, want)
+	}
+}
+
+// wantTokenCookie checks the attributes every token cookie must carry.
+func wantTokenCookie(t *testing.T, c *http.Cookie, name, path string) {
+	t.Helper()
+	if c == nil {
+		t.Fatalf("no %s cookie", name)
+	}
+	if c.Value == "" || c.Path != path || !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteStrictMode {
+		t.Errorf("%s cookie = %q, want a value, Path=%s, HttpOnly, Secure and SameSite=Strict", name, c.String(), path)
+	}
+}
+
+func cookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, c := range cookies {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	h := testharness.New(t)
+
+	resp, err := h.Auth.Register(ctx, &authpb.RegisterRequest{
+		Username: "alice",
+		Email:    "Alice@Example.com",
+		Password: testharness.DefaultPassword,
+		Role:     model.RoleUser,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Accounts cannot log in before they are activated.
+	_, err = h.Auth.Login(ctx, &authpb.LoginRequest{Username: "alice", Password: testharness.DefaultPassword})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	if err := h.App.Auth.AuthService.ActivateUser(ctx, resp.GetUserId()); err != nil {
+		t.Fatal(err)
+	}
+
+	var header metadata.MD
+	login, err := h.Auth.Login(ctx, &authpb.LoginRequest{
+		Username: "alice",
+		Password: testharness.DefaultPassword,
+	}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.GetUserId() != resp.GetUserId() {
+		t.Errorf("login user = %s, want %s", login.GetUserId(), resp.GetUserId())
+	}
+
+	cookies := testharness.Cookies(header)
+	wantTokenCookie(t, cookie(cookies, "access_token"), "access_token", "/")
+	wantTokenCookie(t, cookie(cookies, "refresh_token"), "refresh_token", "/auth/refresh")
+
+	s := &testharness.Session{UserID: login.GetUserId(), Cookies: cookies}
+	me, err := h.Auth.WhoAmI(s.Context(ctx), &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.GetUsername() != "alice" || me.GetEmail() != "alice@example.com" || me.GetRole() != model.RoleUser {
+		t.Errorf("whoami = %v", me)
+	}
+}
+
+func TestRegisterRejects(t *testing.T) {
+	h := testharness.New(t)
+	h.CreateUser(t, "alice", testharness.DefaultPassword, model.RoleUser, true)
+
+	cases := []struct {
+		name string
+		req  *authpb.RegisterRequest
+		want codes.Code
+	}{
+		{"bad username", &authpb.RegisterRequest{Username: "a b", Email: "ab@example.com", Password: testharness.DefaultPassword, Role: model.RoleUser}, codes.InvalidArgument},
+		{"short password", &authpb.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "short", Role: model.RoleUser}, codes.InvalidArgument},
+		{"bad email", &authpb.RegisterRequest{Username: "bob", Email: "bob", Password: testharness.DefaultPassword, Role: model.RoleUser}, codes.InvalidArgument},
+		{"unknown role", &authpb.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: testharness.DefaultPassword, Role: "root"}, codes.InvalidArgument},
+		{"admin role", &authpb.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: testharness.DefaultPassword, Role: model.RoleAdmin}, codes.InvalidArgument},
+		{"super-admin role", &authpb.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: testharness.DefaultPassword, Role: model.RoleSuperAdmin}, codes.InvalidArgument},
+		{"taken username", &authpb.RegisterRequest{Username: "alice", Email: "other@example.com", Password: testharness.DefaultPassword, Role: model.RoleUser}, codes.AlreadyExists},
+		{"taken email", &authpb.RegisterRequest{Username: "bob", Email: "ALICE@example.com", Password: testharness.DefaultPassword, Role: model.RoleUser}, codes.AlreadyExists},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := h.Auth.Register(ctx, tc.req)
+			wantCode(t, err, tc.want)
+		})
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	h := testharness.New(t)
+	h.CreateUser(t, "alice", testharness.DefaultPassword, model.RoleUser, true)
+
+	for _, req := range []*authpb.LoginRequest{
+		{Username: "alice", Password: "wrong-password"},
+		{Username: "nobody", Password: testharness.DefaultPassword},
+	} {
+		var header metadata.MD
+		_, err := h.Auth.Login(ctx, req, grpc.Header(&header))
+		wantCode(t, err, codes.Unauthenticated)
+		if cookies := header.Get("set-cookie"); len(cookies) > 0 {
+			t.Errorf("failed login for %s set cookies: %v", req.GetUsername(), cookies)
+		}
+	}
+}
+
+func TestResendActivationRevealsNothing(t *testing.T) {
+	h := testharness.New(t)
+	// alice was just sent her activation email, so a resend is throttled.
+	h.CreateUser(t, "alice", testharness.DefaultPassword, model.RoleUser, false)
+	h.CreateUser(t, "bob", testharness.DefaultPassword, model.RoleUser, true)
+
+	for _, email := range []string{"alice@example.com", "bob@example.com", "nobody@example.com"} {
+		if _, err := h.Auth.ResendActivation(ctx, &authpb.ResendActivationRequest{Email: email}); err != nil {
+			t.Errorf("resend to %s: %v", email, err)
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	h := testharness.New(t)
+	s := h.LoginAs(t, model.RoleUser)
+
+	var header metadata.MD
+	resp, err := h.Auth.Refresh(s.Context(ctx), &emptypb.Empty{}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetUserId() != s.UserID {
+		t.Errorf("refresh user = %s, want %s", resp.GetUserId(), s.UserID)
+	}
+
+	cookies := testharness.Cookies(header)
+	wantTokenCookie(t, cookie(cookies, "access_token"), "access_token", "/")
+	refresh := cookie(cookies, "refresh_token")
+	wantTokenCookie(t, refresh, "refresh_token", "/auth/refresh")
+	if refresh.Value == s.RefreshToken {
+		t.Error("refresh token was not rotated")
+	}
+
+	// The old refresh token was redeemed and cannot be used again.
+	_, err = h.Auth.Refresh(s.Context(ctx), &emptypb.Empty{})
+	wantCode(t, err, codes.Unauthenticated)
+
+	rotated := &testharness.Session{UserID: s.UserID, Cookies: cookies}
+	if _, err := h.Auth.Refresh(rotated.Context(ctx), &emptypb.Empty{}); err != nil {
+		t.Errorf("refresh with the rotated token: %v", err)
+	}
+}
+
+func TestRefreshWithoutCookie(t *testing.T) {
+	h := testharness.New(t)
+
+	_, err := h.Auth.Refresh(ctx, &emptypb.Empty{})
+	wantCode(t, err, codes.Unauthenticated)
+}
+
+func TestLogout(t *testing.T) {
+	h := testharness.New(t)
+	s := h.LoginAs(t, model.RoleUser)
+
+	var header metadata.MD
+	if _, err := h.Auth.Logout(s.Context(ctx), &emptypb.Empty{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := testharness.Cookies(header)
+	for name, path := range map[string]string{"access_token": "/", "refresh_token": "/auth/refresh"} {
+		c := cookie(cookies, name)
+		if c == nil || c.Value != "" || c.MaxAge >= 0 || c.Path != path {
+			t.Errorf("logout did not clear %s at %s: %v", name, path, header.Get("set-cookie"))
+		}
+	}
+
+	// The session is revoked, so its refresh token is dead.
+	_, err := h.Auth.Refresh(s.Context(ctx), &emptypb.Empty{})
+	wantCode(t, err, codes.Unauthenticated)
+}
+
+func TestWhoAmIRequiresLogin(t *testing.T) {
+	h := testharness.New(t)
+
+	_, err := h.Auth.WhoAmI(ctx, &emptypb.Empty{})
+	wantCode(t, err, codes.Unauthenticated)
+}
+
+func TestDeactivate(t *testing.T) {
+	h := testharness.New(t)
+	admin := h.LoginAs(t, model.RoleAdmin)
+	user := h.LoginAs(t, model.RoleUser)
+
+	// Only admins may deactivate.
+	_, err := h.Auth.Deactivate(user.Context(ctx), &authpb.DeactivateRequest{UserId: admin.UserID})
+	wantCode(t, err, codes.PermissionDenied)
+
+	if _, err := h.Auth.Deactivate(admin.Context(ctx), &authpb.DeactivateRequest{UserId: user.UserID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deactivation revokes the user's sessions.
+	_, err = h.Auth.Refresh(user.Context(ctx), &emptypb.Empty{})
+	wantCode(t, err, codes.Unauthenticated)
+}
+
+func TestAdminCannotManageSuperAdmin(t *testing.T) {
+	h := testharness.New(t)
+	admin := h.LoginAs(t, model.RoleAdmin)
+	root := h.LoginAs(t, model.RoleSuperAdmin)
+
+	_, err := h.Auth.ChangeRole(admin.Context(ctx), &authpb.ChangeRoleRequest{UserId: root.UserID, Role: model.RoleUser})
+	wantCode(t, err, codes.PermissionDenied)
+
+	_, err = h.Auth.Deactivate(admin.Context(ctx), &authpb.DeactivateRequest{UserId: root.UserID})
+	wantCode(t, err, codes.PermissionDenied)
+
+	_, err = h.Auth.ChangeRole(admin.Context(ctx), &authpb.ChangeRoleRequest{UserId: admin.UserID, Role: model.RoleSuperAdmin})
+	wantCode(t, err, codes.PermissionDenied)
+
+	// The super admin's session survived.
+	if _, err := h.Auth.Refresh(root.Context(ctx), &emptypb.Empty{}); err != nil {
+		t.Errorf("super admin session: %v", err)
+	}
+}
+
+func TestChangeRole(t *testing.T) {
+	h := testharness.New(t)
+	root := h.LoginAs(t, model.RoleSuperAdmin)
+	user := h.LoginAs(t, model.RoleUser)
+
+	if _, err := h.Auth.ChangeRole(root.Context(ctx), &authpb.ChangeRoleRequest{UserId: user.UserID, Role: model.RoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := h.Auth.ListUsers(root.Context(ctx), &authpb.ListUsersRequest{Role: model.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetUsers()) != 1 || resp.GetUsers()[0].GetId() != user.UserID {
+		t.Errorf("admins = %v, want only %s", resp.GetUsers(), user.UserID)
+	}
+}
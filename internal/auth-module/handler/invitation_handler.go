@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	authpb "admin-portal/proto/auth"
+)
+
+func (h *AuthHandler) InviteUser(
+	ctx context.Context,
+	req *authpb.InviteUserRequest,
+) (*authpb.Invitation, error) {
+
+	inv, err := h.invitations.Invite(ctx, req.GetEmail(), req.GetRole())
+	if err != nil {
+		return nil, err
+	}
+
+	return invitationToProto(inv), nil
+}
+
+func (h *AuthHandler) ListInvitations(
+	ctx context.Context,
+	req *authpb.ListInvitationsRequest,
+) (*authpb.ListInvitationsResponse, error) {
+
+	limit, offset := page(req.GetPageSize(), req.GetPageToken())
+
+	invitations, total, err := h.invitations.List(ctx, repository.InvitationFilter{
+		IncludeClosed: req.GetIncludeClosed(),
+		Limit:         limit,
+		Offset:        offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &authpb.ListInvitationsResponse{
+		TotalSize:     total,
+		NextPageToken: nextPageToken(offset, len(invitations), total),
+	}
+	for _, inv := range invitations {
+		resp.Invitations = append(resp.Invitations, invitationToProto(inv))
+	}
+
+	return resp, nil
+}
+
+func (h *AuthHandler) RevokeInvitation(
+	ctx context.Context,
+	req *authpb.RevokeInvitationRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.invitations.Revoke(ctx, req.GetInvitationId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *AuthHandler) AcceptInvitation(
+	ctx context.Context,
+	req *authpb.AcceptInvitationRequest,
+) (*authpb.AcceptInvitationResponse, error) {
+
+	user, err := h.invitations.Accept(ctx, req.GetToken(), req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, err
+	}
+
+	return &authpb.AcceptInvitationResponse{
+		UserId: user.ID.String(),
+	}, nil
+}
+
+// invitationToProto never includes the token hash.
+func invitationToProto(inv *model.Invitation) *authpb.Invitation {
+	pb := &authpb.Invitation{
+		Id:        inv.ID.String(),
+		Email:     inv.Email,
+		Role:      inv.Role,
+		CreatedAt: timestamppb.New(inv.CreatedAt),
+		ExpiresAt: timestamppb.New(inv.ExpiresAt),
+	}
+	if inv.InvitedBy != nil {
+		pb.InvitedBy = inv.InvitedBy.String()
+	}
+	if inv.AcceptedAt != nil {
+		pb.AcceptedAt = timestamppb.New(*inv.AcceptedAt)
+	}
+	if inv.AcceptedUserID != nil {
+		pb.AcceptedUserId = inv.AcceptedUserID.String()
+	}
+	if inv.RevokedAt != nil {
+		pb.RevokedAt = timestamppb.New(*inv.RevokedAt)
+	}
+	return pb
+}
@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/testharness"
+	authpb "admin-portal/proto/auth"
+)
+
+// invite has s invite email to role and returns a token that accepts
+// the invitation, as if its email had been sent.
+func invite(t *testing.T, h *testharness.Harness, s *testharness.Session, email, role string) string {
+	t.Helper()
+
+	inv, err := h.Auth.InviteUser(s.Bearer(ctx), &authpb.InviteUserRequest{Email: email, Role: role})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.Model(&model.Invitation{}).Where("id = ?", inv.GetId()).Update("token_hash", hash).Error; err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func accept(h *testharness.Harness, token, username string) (*authpb.AcceptInvitationResponse, error) {
+	return h.Auth.AcceptInvitation(ctx, &authpb.AcceptInvitationRequest{
+		Token:    token,
+		Username: username,
+		Password: testharness.DefaultPassword,
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	h := testharness.New(t)
+	admin := h.LoginAs(t, model.RoleAdmin)
+
+	token := invite(t, h, admin, "frank@example.com", model.RoleAdmin)
+	resp, err := accept(h, token, "frank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := h.Login(t, "frank", testharness.DefaultPassword); s.UserID != resp.GetUserId() {
+		t.Errorf("logged in as %s, want the invited account %s", s.UserID, resp.GetUserId())
+	}
+
+	_, err = accept(h, token, "frank2")
+	wantCode(t, err, codes.Unauthenticated)
+}
+
+func TestAcceptInvitationRechecksInviter(t *testing.T) {
+	cases := []struct {
+		name    string
+		changes map[string]any
+	}{
+		{"inviter demoted", map[string]any{"role": model.RoleUser}},
+		{"inviter deactivated", map[string]any{"is_active": false}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := testharness.New(t)
+			admin := h.LoginAs(t, model.RoleAdmin)
+			token := invite(t, h, admin, "grace@example.com", model.RoleAdmin)
+
+			if err := h.DB.Model(&model.User{}).Where("id = ?", admin.UserID).Updates(tc.changes).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := accept(h, token, "grace")
+			wantCode(t, err, codes.Unauthenticated)
+
+			var n int64
+			if err := h.DB.Model(&model.User{}).Where("username = ?", "grace").Count(&n).Error; err != nil {
+				t.Fatal(err)
+			}
+			if n != 0 {
+				t.Error("account created for a refused invitation")
+			}
+		})
+	}
+}
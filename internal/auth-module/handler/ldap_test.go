@@ -0,0 +1,115 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"admin-portal/internal/app"
+	"admin-portal/internal/auth-module/model"
+	authservice "admin-portal/internal/auth-module/service"
+	"admin-portal/internal/shared/ldap"
+	"admin-portal/internal/shared/ldap/ldaptest"
+	"admin-portal/internal/testharness"
+	authpb "admin-portal/proto/auth"
+)
+
+const staffDN = "cn=staff,ou=groups,dc=example,dc=com"
+
+// withDirectory starts a directory holding alice, a member of staff, and
+// points the API's LDAP logins at it.
+func withDirectory(t *testing.T, provision bool) testharness.Option {
+	t.Helper()
+
+	dir, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dir.Close() })
+
+	dir.AllowAnonymous(true)
+	dir.AddEntry("uid=alice,ou=people,dc=example,dc=com", "directory-secret",
+		ldap.Attribute{Name: "objectClass", Values: []string{"person"}},
+		ldap.Attribute{Name: "uid", Values: []string{"alice"}},
+		ldap.Attribute{Name: "mail", Values: []string{"alice@example.com"}},
+		ldap.Attribute{Name: "memberOf", Values: []string{staffDN}})
+
+	return func(deps *app.Deps) {
+		deps.LDAP = authservice.LDAPConfig{
+			URL:               dir.URL(),
+			Timeout:           5 * time.Second,
+			BaseDN:            "ou=people,dc=example,dc=com",
+			UserFilter:        "(objectClass=person)",
+			UsernameAttribute: "uid",
+			EmailAttribute:    "mail",
+			TrustEmail:        true,
+			GroupAttribute:    "memberOf",
+			RoleGroups:        map[string][]string{model.RoleUser: {staffDN}},
+			AutoProvision:     provision,
+		}
+	}
+}
+
+func TestLDAPLoginProvisionsUser(t *testing.T) {
+	h := testharness.New(t, withDirectory(t, true))
+
+	first, err := h.Auth.Login(ctx, &authpb.LoginRequest{Username: "alice", Password: "directory-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var user model.User
+	if err := h.DB.Where("username = ?", "alice").First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.ID.String() != first.GetUserId() {
+		t.Errorf("provisioned %s, logged in as %s", user.ID, first.GetUserId())
+	}
+	if user.Role != model.RoleUser || !user.IsActive || !user.IsActivated {
+		t.Errorf("provisioned user = role %s, active %v, activated %v", user.Role, user.IsActive, user.IsActivated)
+	}
+	if user.Email == nil || *user.Email != "alice@example.com" {
+		t.Errorf("provisioned email = %v", user.Email)
+	}
+
+	second, err := h.Auth.Login(ctx, &authpb.LoginRequest{Username: "alice", Password: "directory-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.GetUserId() != first.GetUserId() {
+		t.Errorf("second login as %s, want %s", second.GetUserId(), first.GetUserId())
+	}
+
+	var identities int64
+	if err := h.DB.Model(&model.UserIdentity{}).Where("user_id = ?", user.ID).Count(&identities).Error; err != nil {
+		t.Fatal(err)
+	}
+	if identities != 1 {
+		t.Errorf("%d identities linked, want 1", identities)
+	}
+
+	_, err = h.Auth.Login(ctx, &authpb.LoginRequest{Username: "alice", Password: "wrong"})
+	wantCode(t, err, codes.Unauthenticated)
+}
+
+func TestLDAPLoginWithoutProvisioning(t *testing.T) {
+	h := testharness.New(t, withDirectory(t, false))
+
+	_, err := h.Auth.Login(ctx, &authpb.LoginRequest{Username: "alice", Password: "directory-secret"})
+	wantCode(t, err, codes.PermissionDenied)
+
+	// A local account with the directory's verified email is linked.
+	userID := h.CreateUser(t, "alice.local", testharness.DefaultPassword, model.RoleUser, true)
+	if err := h.DB.Model(&model.User{}).Where("id = ?", userID).Update("email", "alice@example.com").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := h.Auth.Login(ctx, &authpb.LoginRequest{Username: "alice", Password: "directory-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetUserId() != userID {
+		t.Errorf("logged in as %s, want the linked %s", resp.GetUserId(), userID)
+	}
+}
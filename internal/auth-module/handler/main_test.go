@@ -0,0 +1,9 @@
+package handler_test
+
+import (
+	"testing"
+
+	"admin-portal/internal/testharness"
+)
+
+func TestMain(m *testing.M) { testharness.Main(m) }
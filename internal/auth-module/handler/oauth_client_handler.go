@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	authpb "admin-portal/proto/auth"
+)
+
+func (h *AuthHandler) RegisterOAuthClient(
+	ctx context.Context,
+	req *authpb.RegisterOAuthClientRequest,
+) (*authpb.RegisterOAuthClientResponse, error) {
+
+	client, secret, err := h.oauthService.RegisterClient(ctx, req.GetName(), req.GetRedirectUris(), req.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+
+	return &authpb.RegisterOAuthClientResponse{
+		Client:       oauthClientToProto(client),
+		ClientSecret: secret,
+	}, nil
+}
+
+func (h *AuthHandler) ListOAuthClients(
+	ctx context.Context,
+	req *authpb.ListOAuthClientsRequest,
+) (*authpb.ListOAuthClientsResponse, error) {
+
+	limit, offset := page(req.GetPageSize(), req.GetPageToken())
+
+	clients, total, err := h.oauthService.ListClients(ctx, repository.OAuthClientFilter{
+		IncludeRevoked: req.GetIncludeRevoked(),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &authpb.ListOAuthClientsResponse{
+		TotalSize:     total,
+		NextPageToken: nextPageToken(offset, len(clients), total),
+	}
+	for _, c := range clients {
+		resp.Clients = append(resp.Clients, oauthClientToProto(c))
+	}
+
+	return resp, nil
+}
+
+func (h *AuthHandler) RevokeOAuthClient(
+	ctx context.Context,
+	req *authpb.RevokeOAuthClientRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.oauthService.RevokeClient(ctx, req.GetClientId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// oauthClientToProto never includes the secret hash.
+func oauthClientToProto(c *model.OAuthClient) *authpb.OAuthClient {
+	pb := &authpb.OAuthClient{
+		Id:           c.ID.String(),
+		Name:         c.Name,
+		RedirectUris: c.RedirectURIs,
+		Public:       c.Public(),
+		CreatedAt:    timestamppb.New(c.CreatedAt),
+	}
+	if c.CreatedBy != nil {
+		pb.CreatedBy = c.CreatedBy.String()
+	}
+	if c.RevokedAt != nil {
+		pb.RevokedAt = timestamppb.New(*c.RevokedAt)
+	}
+	return pb
+}
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/service"
+	"admin-portal/internal/shared/oidc"
+	"admin-portal/internal/shared/security"
+)
+
+// OAuthHTTPHandler serves the authorization server over plain HTTP, where
+// OAuth clients and their libraries expect it. Users authorize with the
+// access_token cookie the portal's Login sets, so the endpoints must be
+// served on the portal's own domain.
+type OAuthHTTPHandler struct {
+	oauthService service.OAuthService
+	jwtCfg       security.JWTConfig
+	issuer       string
+	loginURL     string
+	mux          *http.ServeMux
+}
+
+func NewOAuthHTTPHandler(
+	oauthService service.OAuthService,
+	jwtCfg security.JWTConfig,
+	cfg service.OAuthConfig,
+) *OAuthHTTPHandler {
+	h := &OAuthHTTPHandler{
+		oauthService: oauthService,
+		jwtCfg:       jwtCfg,
+		issuer:       cfg.Issuer,
+		loginURL:     cfg.LoginURL,
+		mux:          http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("GET "+oidc.DiscoveryPath, cors(h.discovery))
+	h.mux.HandleFunc("GET /oauth/jwks", cors(h.jwks))
+	h.mux.HandleFunc("GET /oauth/authorize", h.authorize)
+	h.mux.HandleFunc("POST /oauth/authorize", h.authorize)
+	h.mux.HandleFunc("/oauth/token", cors(h.token))
+	h.mux.HandleFunc("/oauth/userinfo", cors(h.userinfo))
+	h.mux.HandleFunc("/oauth/revoke", cors(h.revoke))
+	return h
+}
+
+func (h *OAuthHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *OAuthHTTPHandler) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeOAuthJSON(w, http.StatusOK, h.oauthService.Metadata())
+}
+
+func (h *OAuthHTTPHandler) jwks(w http.ResponseWriter, _ *http.Request) {
+	set, err := h.oauthService.KeySet()
+	if err != nil {
+		h.serverError(w, "JWKS", err)
+		return
+	}
+	writeOAuthJSON(w, http.StatusOK, set)
+}
+
+//-------------------- Authorization endpoint --------------------//
+
+func (h *OAuthHTTPHandler) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid_request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := service.AuthorizeRequest{
+		ClientID:            r.Form.Get("client_id"),
+		RedirectURI:         r.Form.Get("redirect_uri"),
+		ResponseType:        r.Form.Get("response_type"),
+		Scope:               r.Form.Get("scope"),
+		Nonce:               r.Form.Get("nonce"),
+		CodeChallenge:       r.Form.Get("code_challenge"),
+		CodeChallengeMethod: r.Form.Get("code_challenge_method"),
+	}
+	state := r.Form.Get("state")
+
+	// Never redirect to a URI the client did not register.
+	if err := h.oauthService.CheckRedirect(r.Context(), req.ClientID, req.RedirectURI); err != nil {
+		http.Error(w, "invalid_request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	claims := h.signedInUser(r)
+	if claims == nil {
+		if h.loginURL == "" || r.Form.Get("prompt") == "none" {
+			h.redirectError(w, r, req.RedirectURI, state, oauthError("login_required", "sign in to the portal first"))
+			return
+		}
+		h.redirectToLogin(w, r)
+		return
+	}
+
+	authTime := time.Now()
+	if claims.IssuedAt != nil {
+		authTime = claims.IssuedAt.Time
+	}
+
+	code, err := h.oauthService.Authorize(r.Context(), req, claims.UserID, authTime)
+	if err != nil {
+		h.redirectError(w, r, req.RedirectURI, state, err)
+		return
+	}
+
+	h.redirect(w, r, req.RedirectURI, url.Values{"code": {code}, "state": {state}})
+}
+
+// signedInUser returns the portal user of the request, or nil.
+func (h *OAuthHTTPHandler) signedInUser(r *http.Request) *security.Claims {
+	cookie, err := r.Cookie("access_token")
+	if err != nil {
+		return nil
+	}
+	claims, err := middleware.ValidateAccessToken(h.jwtCfg, cookie.Value)
+	if err != nil {
+		return nil
+	}
+	return claims
+}
+
+// redirectToLogin sends the user to the login page, which returns them
+// to this same authorization request afterwards.
+func (h *OAuthHTTPHandler) redirectToLogin(w http.ResponseWriter, r *http.Request) {
+	returnTo := h.issuer + "/oauth/authorize?" + r.Form.Encode()
+
+	login, err := url.Parse(h.loginURL)
+	if err != nil {
+		h.serverError(w, "login URL", err)
+		return
+	}
+	q := login.Query()
+	q.Set("return_to", returnTo)
+	login.RawQuery = q.Encode()
+
+	http.Redirect(w, r, login.String(), http.StatusFound)
+}
+
+// redirectError reports a failed authorization to the client. Errors
+// that are not OAuth errors are logged and reported as server_error.
+func (h *OAuthHTTPHandler) redirectError(w http.ResponseWriter, r *http.Request, redirectURI, state string, err error) {
+	var oauthErr *oidc.TokenError
+	if !errors.As(err, &oauthErr) {
+		log.Printf("❌ OAuth authorization failed: %v", err)
+		oauthErr = &oidc.TokenError{Code: "server_error"}
+	}
+
+	v := url.Values{"error": {oauthErr.Code}, "state": {state}}
+	if oauthErr.Description != "" {
+		v.Set("error_description", oauthErr.Description)
+	}
+	h.redirect(w, r, redirectURI, v)
+}
+
+// redirect adds params and the issuer (RFC 9207) to the redirect URI.
+func (h *OAuthHTTPHandler) redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	back, err := url.Parse(redirectURI)
+	if err != nil {
+		h.serverError(w, "redirect URI", err)
+		return
+	}
+
+	q := back.Query()
+	for k, v := range params {
+		if len(v) > 0 && v[0] != "" {
+			q.Set(k, v[0])
+		}
+	}
+	q.Set("iss", h.issuer)
+	back.RawQuery = q.Encode()
+
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+//-------------------- Token, userinfo & revocation --------------------//
+
+func (h *OAuthHTTPHandler) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		h.tokenError(w, r, oauthError("invalid_request", err.Error()))
+		return
+	}
+
+	clientID, secret := clientCredentials(r)
+	tokens, err := h.oauthService.Token(r.Context(), service.TokenRequest{
+		ClientID:     clientID,
+		ClientSecret: secret,
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+	})
+	if err != nil {
+		h.tokenError(w, r, err)
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, tokens)
+}
+
+func (h *OAuthHTTPHandler) userinfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "bearer") || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+h.issuer+`"`)
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	info, err := h.oauthService.UserInfo(r.Context(), token)
+	var oauthErr *oidc.TokenError
+	if errors.As(err, &oauthErr) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="`+oauthErr.Code+`", error_description="`+oauthErr.Description+`"`)
+		writeOAuthJSON(w, http.StatusUnauthorized, oauthErr)
+		return
+	}
+	if err != nil {
+		h.serverError(w, "userinfo", err)
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, info)
+}
+
+func (h *OAuthHTTPHandler) revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		h.tokenError(w, r, oauthError("invalid_request", err.Error()))
+		return
+	}
+
+	clientID, secret := clientCredentials(r)
+	if err := h.oauthService.Revoke(r.Context(), clientID, secret, r.PostForm.Get("token")); err != nil {
+		h.tokenError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// clientCredentials reads client_secret_basic, whose parts are form
+// encoded (RFC 6749 section 2.3.1), or else client_secret_post and none.
+func clientCredentials(r *http.Request) (string, string) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		if u, err := url.QueryUnescape(id); err == nil {
+			id = u
+		}
+		if u, err := url.QueryUnescape(secret); err == nil {
+			secret = u
+		}
+		return id, secret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
+
+// tokenError writes an error response of the token and revocation
+// endpoints (RFC 6749 section 5.2).
+func (h *OAuthHTTPHandler) tokenError(w http.ResponseWriter, r *http.Request, err error) {
+	var oauthErr *oidc.TokenError
+	if !errors.As(err, &oauthErr) {
+		h.serverError(w, r.URL.Path, err)
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+		if _, _, ok := r.BasicAuth(); ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="`+h.issuer+`"`)
+		}
+	}
+	writeOAuthJSON(w, status, oauthErr)
+}
+
+func (h *OAuthHTTPHandler) serverError(w http.ResponseWriter, what string, err error) {
+	log.Printf("❌ OAuth %s failed: %v", what, err)
+	writeOAuthJSON(w, http.StatusInternalServerError, oidc.TokenError{Code: "server_error"})
+}
+
+// cors lets browser apps call an endpoint from their own origin. None of
+// them rely on cookies, so any origin may.
+func cors(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeOAuthJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func oauthError(code, description string) error {
+	return &oidc.TokenError{Code: code, Description: description}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"admin-portal/internal/auth-module/model"
+	authpb "admin-portal/proto/auth"
+)
+
+func (h *AuthHandler) BeginPasskeyRegistration(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*authpb.BeginPasskeyRegistrationResponse, error) {
+
+	options, err := h.passkeys.BeginRegistration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authpb.BeginPasskeyRegistrationResponse{
+		Options: string(options),
+	}, nil
+}
+
+func (h *AuthHandler) FinishPasskeyRegistration(
+	ctx context.Context,
+	req *authpb.FinishPasskeyRegistrationRequest,
+) (*authpb.Passkey, error) {
+
+	passkey, err := h.passkeys.FinishRegistration(ctx, req.GetName(), []byte(req.GetCredential()))
+	if err != nil {
+		return nil, err
+	}
+
+	return passkeyToProto(passkey), nil
+}
+
+func (h *AuthHandler) ListPasskeys(
+	ctx context.Context,
+	req *authpb.ListPasskeysRequest,
+) (*authpb.ListPasskeysResponse, error) {
+
+	passkeys, err := h.passkeys.List(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &authpb.ListPasskeysResponse{}
+	for _, p := range passkeys {
+		resp.Passkeys = append(resp.Passkeys, passkeyToProto(p))
+	}
+
+	return resp, nil
+}
+
+func (h *AuthHandler) DeletePasskey(
+	ctx context.Context,
+	req *authpb.DeletePasskeyRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.passkeys.Delete(ctx, req.GetUserId(), req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *AuthHandler) BeginPasskeyLogin(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*authpb.BeginPasskeyLoginResponse, error) {
+
+	options, err := h.passkeys.BeginLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authpb.BeginPasskeyLoginResponse{
+		Options: string(options),
+	}, nil
+}
+
+func (h *AuthHandler) FinishPasskeyLogin(
+	ctx context.Context,
+	req *authpb.FinishPasskeyLoginRequest,
+) (*authpb.LoginResponse, error) {
+
+	user, accessToken, refreshToken, err :=
+		h.passkeys.FinishLogin(ctx, []byte(req.GetCredential()))
+	if err != nil {
+		return nil, err
+	}
+
+	setTokenCookies(ctx, accessToken, refreshToken)
+
+	return &authpb.LoginResponse{
+		UserId: user.ID.String(),
+	}, nil
+}
+
+// passkeyToProto never includes the key material.
+func passkeyToProto(p *model.WebAuthnCredential) *authpb.Passkey {
+	pb := &authpb.Passkey{
+		Id:         p.ID.String(),
+		UserId:     p.UserID.String(),
+		Name:       p.Name,
+		Transports: p.Transports,
+		BackedUp:   p.BackupState,
+		CreatedAt:  timestamppb.New(p.CreatedAt),
+	}
+	if p.LastUsedAt != nil {
+		pb.LastUsedAt = timestamppb.New(*p.LastUsedAt)
+	}
+	return pb
+}
@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"admin-portal/internal/app"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/oidc/oidctest"
+	"admin-portal/internal/shared/webauthn"
+	"admin-portal/internal/shared/webauthn/webauthntest"
+	"admin-portal/internal/testharness"
+	authpb "admin-portal/proto/auth"
+)
+
+const passkeyOrigin = "https://portal.example.com"
+
+func withPasskeys(deps *app.Deps) {
+	deps.WebAuthn = webauthn.NewRelyingParty(webauthn.Config{
+		RPID:    "portal.example.com",
+		RPName:  "Admin Portal",
+		Origins: []string{passkeyOrigin},
+		Timeout: time.Minute,
+	})
+}
+
+// addPasskey registers a passkey on a for the signed-in user.
+func addPasskey(t *testing.T, h *testharness.Harness, s *testharness.Session, a *webauthntest.Authenticator) {
+	t.Helper()
+
+	begin, err := h.Auth.BeginPasskeyRegistration(s.Bearer(ctx), &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credential, err := a.Create([]byte(begin.GetOptions()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Auth.FinishPasskeyRegistration(s.Bearer(ctx), &authpb.FinishPasskeyRegistrationRequest{
+		Credential: string(credential),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// finishSecondFactor answers second factor options with a and returns
+// the finished login's cookies.
+func finishSecondFactor(t *testing.T, h *testharness.Harness, a *webauthntest.Authenticator, options string) (*authpb.LoginResponse, metadata.MD) {
+	t.Helper()
+
+	credential, err := a.Get([]byte(options))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header metadata.MD
+	resp, err := h.Auth.FinishPasskeyLogin(ctx, &authpb.FinishPasskeyLoginRequest{
+		Credential: string(credential),
+	}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, header
+}
+
+func TestPasswordLoginAsksForPasskey(t *testing.T) {
+	h := testharness.New(t, withPasskeys)
+	a := webauthntest.New(passkeyOrigin)
+
+	userID := h.CreateUser(t, "alice", testharness.DefaultPassword, model.RoleUser, true)
+	addPasskey(t, h, h.Login(t, "alice", testharness.DefaultPassword), a)
+
+	var header metadata.MD
+	resp, err := h.Auth.Login(ctx, &authpb.LoginRequest{Username: "alice", Password: testharness.DefaultPassword}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetSecondFactorOptions() == "" || len(header.Get("set-cookie")) != 0 {
+		t.Fatalf("login = %v with cookies %v, want second factor options and no cookies", resp, header.Get("set-cookie"))
+	}
+
+	finished, header := finishSecondFactor(t, h, a, resp.GetSecondFactorOptions())
+	if finished.GetUserId() != userID {
+		t.Errorf("finished login as %s, want %s", finished.GetUserId(), userID)
+	}
+	wantTokenCookie(t, cookie(testharness.Cookies(header), "access_token"), "access_token", "/")
+}
+
+func TestSSOLoginAsksForPasskey(t *testing.T) {
+	idp, opt := withIdP(t)
+	h := testharness.New(t, opt, withPasskeys)
+	a := webauthntest.New(passkeyOrigin)
+
+	userID := h.CreateUser(t, "bob", testharness.DefaultPassword, model.RoleUser, true)
+	addPasskey(t, h, h.Login(t, "bob", testharness.DefaultPassword), a)
+	idp.SetUser(oidctest.User{Subject: "idp-bob", Email: "bob@example.com", EmailVerified: true})
+
+	// The provider vouching for bob does not skip his passkey.
+	resp, err := ssoLogin(t, h, idp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetSecondFactorOptions() == "" {
+		t.Fatalf("SSO login = %v, want second factor options", resp)
+	}
+
+	finished, header := finishSecondFactor(t, h, a, resp.GetSecondFactorOptions())
+	if finished.GetUserId() != userID {
+		t.Errorf("finished login as %s, want %s", finished.GetUserId(), userID)
+	}
+	wantTokenCookie(t, cookie(testharness.Cookies(header), "access_token"), "access_token", "/")
+}
+
+func TestAdminCannotManageHigherRankPasskeys(t *testing.T) {
+	h := testharness.New(t, withPasskeys)
+	a := webauthntest.New(passkeyOrigin)
+
+	root := h.LoginAs(t, model.RoleSuperAdmin)
+	addPasskey(t, h, root, a)
+	list, err := h.Auth.ListPasskeys(root.Bearer(ctx), &authpb.ListPasskeysRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := h.LoginAs(t, model.RoleAdmin)
+	_, err = h.Auth.ListPasskeys(admin.Bearer(ctx), &authpb.ListPasskeysRequest{UserId: root.UserID})
+	wantCode(t, err, codes.PermissionDenied)
+	_, err = h.Auth.DeletePasskey(admin.Bearer(ctx), &authpb.DeletePasskeyRequest{Id: list.GetPasskeys()[0].GetId(), UserId: root.UserID})
+	wantCode(t, err, codes.PermissionDenied)
+
+	// The super-admin can still manage an admin's.
+	addPasskey(t, h, admin, webauthntest.New(passkeyOrigin))
+	if _, err := h.Auth.ListPasskeys(root.Bearer(ctx), &authpb.ListPasskeysRequest{UserId: admin.UserID}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"admin-portal/internal/auth-module/service"
+	authpb "admin-portal/proto/auth"
+)
+
+// ssoStateCookie binds an SSO login to the browser that started it, so a
+// callback URL cannot be replayed in someone else's browser.
+const ssoStateCookie = "sso_state"
+
+func (h *AuthHandler) StartSSOLogin(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*authpb.StartSSOLoginResponse, error) {
+
+	authURL, state, err := h.ssoService.StartLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(
+		"set-cookie", buildCookie(ssoStateCookie, state, "/", true),
+	))
+
+	return &authpb.StartSSOLoginResponse{
+		AuthorizationUrl: authURL,
+	}, nil
+}
+
+func (h *AuthHandler) CompleteSSOLogin(
+	ctx context.Context,
+	req *authpb.CompleteSSOLoginRequest,
+) (*authpb.LoginResponse, error) {
+
+	grpc.SetHeader(ctx, metadata.Pairs(
+		"set-cookie", clearCookie(ssoStateCookie, "/"),
+	))
+
+	if req.GetError() != "" {
+		return nil, service.ErrSSOFailed.
+			WithDetail("error", req.GetError()).
+			WithDetail("error_description", req.GetErrorDescription())
+	}
+	if req.GetCode() == "" {
+		return nil, service.ErrSSOFailed.WithDetail("error", "missing code")
+	}
+
+	cookie := cookieFromMetadata(ctx, ssoStateCookie)
+	if subtle.ConstantTimeCompare([]byte(cookie), []byte(req.GetState())) != 1 {
+		return nil, service.ErrInvalidSSOState
+	}
+
+	user, accessToken, refreshToken, err :=
+		h.ssoService.CompleteLogin(ctx, req.GetState(), req.GetCode())
+
+	var secondFactor *service.SecondFactorRequired
+	if errors.As(err, &secondFactor) {
+		return &authpb.LoginResponse{
+			UserId:              secondFactor.UserID,
+			SecondFactorOptions: string(secondFactor.Options),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setTokenCookies(ctx, accessToken, refreshToken)
+
+	return &authpb.LoginResponse{
+		UserId: user.ID.String(),
+	}, nil
+}
@@ -0,0 +1,119 @@
+package handler_test
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"admin-portal/internal/app"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/oidc"
+	"admin-portal/internal/shared/oidc/oidctest"
+	"admin-portal/internal/testharness"
+	authpb "admin-portal/proto/auth"
+)
+
+// withIdP starts a mock OpenID provider and points the API's SSO at it.
+func withIdP(t *testing.T) (*oidctest.Server, testharness.Option) {
+	t.Helper()
+
+	idp := oidctest.NewServer()
+	t.Cleanup(idp.Close)
+
+	return idp, func(deps *app.Deps) {
+		deps.OIDC = oidc.NewProvider(idp.Config("https://portal.example.com/sso/callback"), idp.Client())
+	}
+}
+
+// ssoLogin runs the browser's part of an SSO login.
+func ssoLogin(t *testing.T, h *testharness.Harness, idp *oidctest.Server) (*authpb.LoginResponse, error) {
+	t.Helper()
+
+	var header metadata.MD
+	start, err := h.Auth.StartSSOLogin(ctx, &emptypb.Empty{}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := idp.Authorize(start.GetAuthorizationUrl())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The callback must come from the browser holding the sso_state cookie.
+	browser := &testharness.Session{Cookies: testharness.Cookies(header)}
+	return h.Auth.CompleteSSOLogin(browser.Context(ctx), &authpb.CompleteSSOLoginRequest{State: state, Code: code})
+}
+
+func TestSSOLinksByVerifiedEmail(t *testing.T) {
+	idp, opt := withIdP(t)
+	h := testharness.New(t, opt)
+
+	userID := h.CreateUser(t, "bob", testharness.DefaultPassword, model.RoleUser, true)
+	idp.SetUser(oidctest.User{Subject: "idp-bob", Email: "Bob@Example.com", EmailVerified: true})
+
+	resp, err := ssoLogin(t, h, idp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetUserId() != userID {
+		t.Errorf("logged in as %s, want the account with that email, %s", resp.GetUserId(), userID)
+	}
+
+	// The link holds once the provider's email changes.
+	idp.SetUser(oidctest.User{Subject: "idp-bob", Email: "bob@elsewhere.example.com", EmailVerified: true})
+	if resp, err = ssoLogin(t, h, idp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetUserId() != userID {
+		t.Errorf("second login as %s, want %s", resp.GetUserId(), userID)
+	}
+}
+
+func TestSSODoesNotLinkUnverifiedEmail(t *testing.T) {
+	idp, opt := withIdP(t)
+	h := testharness.New(t, opt)
+
+	h.CreateUser(t, "carol", testharness.DefaultPassword, model.RoleUser, true)
+	idp.SetUser(oidctest.User{Subject: "idp-carol", Email: "carol@example.com"})
+
+	_, err := ssoLogin(t, h, idp)
+	wantCode(t, err, codes.PermissionDenied)
+}
+
+func TestSSODoesNotLinkUnactivatedAccount(t *testing.T) {
+	idp, opt := withIdP(t)
+	h := testharness.New(t, opt)
+
+	h.CreateUser(t, "dave", testharness.DefaultPassword, model.RoleUser, false)
+	idp.SetUser(oidctest.User{Subject: "idp-dave", Email: "dave@example.com", EmailVerified: true})
+
+	_, err := ssoLogin(t, h, idp)
+	wantCode(t, err, codes.PermissionDenied)
+}
+
+func TestSSOProvisionsUser(t *testing.T) {
+	t.Setenv("OIDC_AUTO_PROVISION", "true")
+	idp, opt := withIdP(t)
+	h := testharness.New(t, opt)
+
+	idp.SetUser(oidctest.User{Subject: "idp-erin", Email: "Erin@Example.com", EmailVerified: true, PreferredUsername: "erin"})
+
+	resp, err := ssoLogin(t, h, idp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var user model.User
+	if err := h.DB.Where("id = ?", resp.GetUserId()).First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != model.RoleUser || !user.IsActivated {
+		t.Errorf("provisioned user = role %s, activated %v", user.Role, user.IsActivated)
+	}
+	if user.Email == nil || *user.Email != "erin@example.com" {
+		t.Errorf("provisioned email = %v, want the verified email", user.Email)
+	}
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	authpb "admin-portal/proto/auth"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+func (h *AuthHandler) WhoAmI(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*authpb.User, error) {
+
+	user, err := h.userService.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return userToProto(user), nil
+}
+
+func (h *AuthHandler) ListUsers(
+	ctx context.Context,
+	req *authpb.ListUsersRequest,
+) (*authpb.ListUsersResponse, error) {
+
+	limit, offset := page(req.GetPageSize(), req.GetPageToken())
+
+	users, total, err := h.userService.ListUsers(ctx, repository.UserFilter{
+		Role:   req.GetRole(),
+		Query:  req.GetQuery(),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &authpb.ListUsersResponse{
+		TotalSize:     total,
+		NextPageToken: nextPageToken(offset, len(users), total),
+	}
+	for _, u := range users {
+		resp.Users = append(resp.Users, userToProto(u))
+	}
+
+	return resp, nil
+}
+
+func (h *AuthHandler) ListSessions(
+	ctx context.Context,
+	req *authpb.ListSessionsRequest,
+) (*authpb.ListSessionsResponse, error) {
+
+	limit, offset := page(req.GetPageSize(), req.GetPageToken())
+
+	sessions, total, err := h.userService.ListSessions(ctx, repository.SessionFilter{
+		UserID:          req.GetUserId(),
+		IncludeInactive: req.GetIncludeInactive(),
+		Limit:           limit,
+		Offset:          offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &authpb.ListSessionsResponse{
+		TotalSize:     total,
+		NextPageToken: nextPageToken(offset, len(sessions), total),
+	}
+	for _, s := range sessions {
+		// The token itself never leaves the server.
+		resp.Sessions = append(resp.Sessions, &authpb.Session{
+			Id:        s.ID.String(),
+			UserId:    s.UserID.String(),
+			IsRevoked: s.IsRevoked,
+			CreatedAt: timestamppb.New(s.CreatedAt),
+			ExpiresAt: timestamppb.New(s.ExpiresAt),
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *AuthHandler) ListLoginLogs(
+	ctx context.Context,
+	req *authpb.ListLoginLogsRequest,
+) (*authpb.ListLoginLogsResponse, error) {
+
+	limit, offset := page(req.GetPageSize(), req.GetPageToken())
+
+	f := repository.LoginLogFilter{
+		UserID:  req.GetUserId(),
+		LogType: req.GetLogType(),
+		Limit:   limit,
+		Offset:  offset,
+	}
+	if req.GetSince() != nil {
+		f.Since = req.GetSince().AsTime()
+	}
+	if req.GetUntil() != nil {
+		f.Until = req.GetUntil().AsTime()
+	}
+
+	logs, total, err := h.userService.ListLoginLogs(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &authpb.ListLoginLogsResponse{
+		TotalSize:     total,
+		NextPageToken: nextPageToken(offset, len(logs), total),
+	}
+	for _, l := range logs {
+		resp.Logs = append(resp.Logs, loginLogToProto(l))
+	}
+
+	return resp, nil
+}
+
+//-------------------- Helper functions for paging and conversion --------------------//
+
+// page turns a page size and offset token into LIMIT and OFFSET. The
+// validator only lets digits through as tokens.
+func page(size int32, token string) (limit, offset int) {
+	limit = int(size)
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	if token != "" {
+		offset, _ = strconv.Atoi(token)
+	}
+	return limit, offset
+}
+
+func nextPageToken(offset, n int, total int64) string {
+	if next := offset + n; int64(next) < total {
+		return strconv.Itoa(next)
+	}
+	return ""
+}
+
+func userToProto(u *model.User) *authpb.User {
+	pb := &authpb.User{
+		Id:          u.ID.String(),
+		Username:    u.Username,
+		Role:        u.Role,
+		IsActive:    u.IsActive,
+		IsActivated: u.IsActivated,
+		CreatedAt:   timestamppb.New(u.CreatedAt),
+		UpdatedAt:   timestamppb.New(u.UpdatedAt),
+	}
+	if u.Email != nil {
+		pb.Email = *u.Email
+	}
+	return pb
+}
+
+func loginLogToProto(l *model.LoginLog) *authpb.LoginLog {
+	pb := &authpb.LoginLog{
+		Id:        l.ID.String(),
+		Message:   l.Message,
+		LogType:   l.LogType,
+		CreatedAt: timestamppb.New(l.CreatedAt),
+	}
+	if l.UserID != nil {
+		pb.UserId = l.UserID.String()
+	}
+	if l.IPAddress != nil {
+		pb.IpAddress = hostAddress(*l.IPAddress)
+	}
+	if l.UserAgent != nil {
+		pb.UserAgent = *l.UserAgent
+	}
+	return pb
+}
+
+// hostAddress drops the /32 or /128 an inet column may come back with.
+func hostAddress(inet string) string {
+	if p, err := netip.ParsePrefix(inet); err == nil && p.IsSingleIP() {
+		return p.Addr().String()
+	}
+	return inet
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"regexp"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/validation"
+	authpb "admin-portal/proto/auth"
+)
+
+var (
+	usernamePattern  = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	pageTokenPattern = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+const (
+	usernameMaxLen    = 150 // users.username VARCHAR(150)
+	emailMaxLen       = 255 // users.email VARCHAR(255)
+	keyNameMaxLen     = 100 // api_keys.name VARCHAR(100)
+	clientNameMaxLen  = 100 // oauth_clients.name VARCHAR(100)
+	passkeyNameMaxLen = 100 // webauthn_credentials.name VARCHAR(100)
+	passwordMinLen    = 8
+	passwordMaxLen    = 72 // bcrypt ignores anything past 72 bytes
+
+	// activationTokenMaxLen bounds activation tokens, which are JWTs of
+	// a few hundred bytes.
+	activationTokenMaxLen = 2048
+	// invitationTokenMaxLen is generous for the 43-character opaque
+	// tokens invitations use.
+	invitationTokenMaxLen = 128
+
+	// credentialMaxBytes bounds WebAuthn response JSON, which is a few
+	// kilobytes at most.
+	credentialMaxBytes = 16 << 10
+)
+
+// RegisterValidators declares the request rules for every AuthService RPC.
+func RegisterValidators(r *validation.Registry) {
+	r.Register(&authpb.RegisterRequest{},
+		validation.Field("username",
+			validation.Required(),
+			validation.MinLen(3),
+			validation.MaxLen(usernameMaxLen),
+			validation.Pattern(usernamePattern, "may only contain letters, digits, '.', '_' and '-'"),
+		),
+		validation.Field("password",
+			validation.Required(),
+			validation.MinLen(passwordMinLen),
+			validation.MaxBytes(passwordMaxLen),
+		),
+		// Register is public; higher roles are granted by invitation.
+		validation.Field("role",
+			validation.Required(),
+			validation.OneOf(model.RoleUser),
+		),
+		validation.Field("email",
+			validation.Required(),
+			validation.MaxLen(emailMaxLen),
+			validation.Email(),
+		),
+	)
+
+	r.Register(&authpb.LoginRequest{},
+		validation.Field("username",
+			validation.Required(),
+			validation.MaxLen(usernameMaxLen),
+		),
+		validation.Field("password",
+			validation.Required(),
+			validation.MaxBytes(passwordMaxLen),
+		),
+	)
+
+	r.Register(&authpb.ActivateRequest{},
+		validation.Field("token",
+			validation.Required(),
+			validation.MaxBytes(activationTokenMaxLen),
+		),
+	)
+
+	r.Register(&authpb.ResendActivationRequest{},
+		validation.Field("email",
+			validation.Required(),
+			validation.MaxLen(emailMaxLen),
+		),
+	)
+
+	r.Register(&authpb.ActivateUserRequest{},
+		validation.Field("user_id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+
+	r.Register(&authpb.DeactivateRequest{},
+		validation.Field("user_id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+
+	r.Register(&authpb.ChangeRoleRequest{},
+		validation.Field("user_id",
+			validation.Required(),
+			validation.UUID(),
+		),
+		validation.Field("role",
+			validation.Required(),
+			validation.OneOf(model.RoleUser, model.RoleAdmin, model.RoleSuperAdmin),
+		),
+	)
+
+	r.Register(&authpb.ListUsersRequest{},
+		validation.Field("role",
+			validation.Optional(validation.OneOf(model.RoleUser, model.RoleAdmin, model.RoleSuperAdmin)),
+		),
+		validation.Field("query",
+			validation.MaxLen(usernameMaxLen),
+		),
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+
+	r.Register(&authpb.ListSessionsRequest{},
+		validation.Field("user_id",
+			validation.Optional(validation.UUID()),
+		),
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+
+	r.Register(&authpb.ListLoginLogsRequest{},
+		validation.Field("user_id",
+			validation.Optional(validation.UUID()),
+		),
+		validation.Field("log_type",
+			validation.Optional(validation.OneOf("warn", "error", "info", "success")),
+		),
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+
+	// Scopes are a repeated field; the service checks them.
+	r.Register(&authpb.CreateAPIKeyRequest{},
+		validation.Field("name",
+			validation.Required(),
+			validation.MaxLen(keyNameMaxLen),
+		),
+	)
+
+	r.Register(&authpb.ListAPIKeysRequest{},
+		validation.Field("user_id",
+			validation.Optional(validation.UUID()),
+		),
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+
+	r.Register(&authpb.RevokeAPIKeyRequest{},
+		validation.Field("id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+
+	r.Register(&authpb.CompleteSSOLoginRequest{},
+		validation.Field("state",
+			validation.Required(),
+			validation.MaxLen(64),
+		),
+		validation.Field("code",
+			validation.MaxLen(2048),
+		),
+		validation.Field("error",
+			validation.MaxLen(256),
+		),
+	)
+
+	// Redirect URIs are a repeated field; the service checks them.
+	r.Register(&authpb.RegisterOAuthClientRequest{},
+		validation.Field("name",
+			validation.Required(),
+			validation.MaxLen(clientNameMaxLen),
+		),
+	)
+
+	r.Register(&authpb.ListOAuthClientsRequest{},
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+
+	r.Register(&authpb.RevokeOAuthClientRequest{},
+		validation.Field("client_id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+
+	r.Register(&authpb.FinishPasskeyRegistrationRequest{},
+		validation.Field("name",
+			validation.MaxLen(passkeyNameMaxLen),
+		),
+		validation.Field("credential",
+			validation.Required(),
+			validation.MaxBytes(credentialMaxBytes),
+		),
+	)
+
+	r.Register(&authpb.ListPasskeysRequest{},
+		validation.Field("user_id",
+			validation.Optional(validation.UUID()),
+		),
+	)
+
+	r.Register(&authpb.DeletePasskeyRequest{},
+		validation.Field("id",
+			validation.Required(),
+			validation.UUID(),
+		),
+		validation.Field("user_id",
+			validation.Optional(validation.UUID()),
+		),
+	)
+
+	r.Register(&authpb.FinishPasskeyLoginRequest{},
+		validation.Field("credential",
+			validation.Required(),
+			validation.MaxBytes(credentialMaxBytes),
+		),
+	)
+
+	r.Register(&authpb.InviteUserRequest{},
+		validation.Field("email",
+			validation.Required(),
+			validation.MaxLen(emailMaxLen),
+			validation.Email(),
+		),
+		validation.Field("role",
+			validation.Required(),
+			validation.OneOf(model.RoleUser, model.RoleAdmin, model.RoleSuperAdmin),
+		),
+	)
+
+	r.Register(&authpb.ListInvitationsRequest{},
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+
+	r.Register(&authpb.RevokeInvitationRequest{},
+		validation.Field("invitation_id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+
+	r.Register(&authpb.AcceptInvitationRequest{},
+		validation.Field("token",
+			validation.Required(),
+			validation.MaxBytes(invitationTokenMaxLen),
+		),
+		validation.Field("username",
+			validation.Required(),
+			validation.MinLen(3),
+			validation.MaxLen(usernameMaxLen),
+			validation.Pattern(usernamePattern, "may only contain letters, digits, '.', '_' and '-'"),
+		),
+		validation.Field("password",
+			validation.Required(),
+			validation.MinLen(passwordMinLen),
+			validation.MaxBytes(passwordMaxLen),
+		),
+	)
+}
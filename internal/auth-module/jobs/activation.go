@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/database"
+	"admin-portal/internal/shared/queue"
+)
+
+// KindActivationReminder is the queue job that delivers one reminder.
+const KindActivationReminder = "auth.activation_reminder"
+
+type ActivationReminderPayload struct {
+	UserID string `json:"user_id"`
+}
+
+// ActivationNotifier delivers a reminder to a user who never activated
+// their account.
+type ActivationNotifier interface {
+	ActivationReminder(ctx context.Context, user *model.User) error
+}
+
+// ActivationReminder finds users that are still not activated after a
+// delay and queues one reminder for each. Marking the user and queueing
+// the job happen in one transaction, so each user is reminded once.
+type ActivationReminder struct {
+	db        *gorm.DB
+	users     repository.UserRepository
+	after     time.Duration
+	batchSize int
+}
+
+func NewActivationReminder(db *gorm.DB, after time.Duration, batchSize int) *ActivationReminder {
+	return &ActivationReminder{
+		db:        db,
+		users:     repository.NewUserRepository(db),
+		after:     after,
+		batchSize: batchSize,
+	}
+}
+
+func (j *ActivationReminder) Name() string { return "activation_reminders" }
+
+func (j *ActivationReminder) Run(ctx context.Context) (int64, error) {
+	pending, err := j.users.FindPendingActivation(ctx, time.Now().Add(-j.after), j.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var queued int64
+	for _, user := range pending {
+		err := database.Transaction(ctx, j.db, func(ctx context.Context) error {
+			id := user.ID.String()
+
+			if err := j.users.MarkActivationReminded(ctx, id, time.Now()); err != nil {
+				return err
+			}
+
+			_, err := queue.Enqueue(ctx, j.db, KindActivationReminder,
+				ActivationReminderPayload{UserID: id},
+				queue.WithUniqueKey(KindActivationReminder+":"+id),
+			)
+			if errors.Is(err, queue.ErrDuplicate) {
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			return queued, err
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+// ActivationReminderHandler delivers queued reminders through notifier.
+// Users that were activated or removed in the meantime are skipped.
+func ActivationReminderHandler(users repository.UserRepository, notifier ActivationNotifier) queue.Handler {
+	return func(ctx context.Context, job *queue.Job) error {
+		var payload ActivationReminderPayload
+		if err := job.Decode(&payload); err != nil {
+			return queue.Permanent(err)
+		}
+
+		user, err := users.FindByID(ctx, payload.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.IsActivated || !user.IsActive {
+			return nil
+		}
+
+		return notifier.ActivationReminder(ctx, user)
+	}
+}
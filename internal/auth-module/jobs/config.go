@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"time"
+
+	"admin-portal/internal/shared/config"
+)
+
+// Login log retention modes.
+const (
+	RetentionArchive = "archive"
+	RetentionDelete  = "delete"
+)
+
+// Config controls the auth housekeeping jobs run by cmd/worker.
+type Config struct {
+	// BatchSize caps the rows touched by a single statement.
+	BatchSize int
+	Timeout   time.Duration
+
+	SessionPurgeInterval time.Duration
+	// SessionGrace keeps expired or revoked sessions around for this long
+	// so recent refresh attempts can still be audited.
+	SessionGrace time.Duration
+
+	LoginLogInterval      time.Duration
+	LoginLogRetention     time.Duration
+	LoginLogRetentionMode string
+
+	ActivationReminderInterval time.Duration
+	// ActivationReminderAfter is how long an account may stay unactivated
+	// before a reminder is sent.
+	ActivationReminderAfter time.Duration
+}
+
+func LoadConfig() Config {
+	return Config{
+		BatchSize: config.Int("WORKER_BATCH_SIZE", 1000),
+		Timeout:   config.Duration("WORKER_JOB_TIMEOUT", 10*time.Minute),
+
+		SessionPurgeInterval: config.Duration("WORKER_SESSION_PURGE_INTERVAL", time.Hour),
+		SessionGrace:         config.Duration("WORKER_SESSION_GRACE", 24*time.Hour),
+
+		LoginLogInterval:      config.Duration("WORKER_LOGIN_LOG_INTERVAL", 6*time.Hour),
+		LoginLogRetention:     config.Duration("LOGIN_LOG_RETENTION", 90*24*time.Hour),
+		LoginLogRetentionMode: config.String("LOGIN_LOG_RETENTION_MODE", RetentionArchive),
+
+		ActivationReminderInterval: config.Duration("WORKER_ACTIVATION_REMINDER_INTERVAL", time.Hour),
+		ActivationReminderAfter:    config.Duration("ACTIVATION_REMINDER_AFTER", 72*time.Hour),
+	}
+}
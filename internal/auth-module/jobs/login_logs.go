@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"admin-portal/internal/auth-module/repository"
+)
+
+// LoginLogRetention archives or deletes login logs older than the
+// retention period.
+type LoginLogRetention struct {
+	logs      repository.LoginLogRepository
+	retention time.Duration
+	mode      string
+	batchSize int
+}
+
+func NewLoginLogRetention(logs repository.LoginLogRepository, retention time.Duration, mode string, batchSize int) (*LoginLogRetention, error) {
+	if mode != RetentionArchive && mode != RetentionDelete {
+		return nil, fmt.Errorf("unknown login log retention mode %q", mode)
+	}
+
+	return &LoginLogRetention{logs: logs, retention: retention, mode: mode, batchSize: batchSize}, nil
+}
+
+func (j *LoginLogRetention) Name() string { return "login_log_retention" }
+
+func (j *LoginLogRetention) Run(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-j.retention)
+
+	batch := j.logs.ArchiveOlderThan
+	if j.mode == RetentionDelete {
+		batch = j.logs.DeleteOlderThan
+	}
+
+	return drain(ctx, j.batchSize, func(ctx context.Context, limit int) (int64, error) {
+		return batch(ctx, before, limit)
+	})
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"admin-portal/internal/auth-module/repository"
+)
+
+// SessionPurge deletes sessions that expired or were revoked more than
+// grace ago.
+type SessionPurge struct {
+	sessions  repository.UserSessionRepository
+	grace     time.Duration
+	batchSize int
+}
+
+func NewSessionPurge(sessions repository.UserSessionRepository, grace time.Duration, batchSize int) *SessionPurge {
+	return &SessionPurge{sessions: sessions, grace: grace, batchSize: batchSize}
+}
+
+func (j *SessionPurge) Name() string { return "purge_sessions" }
+
+func (j *SessionPurge) Run(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-j.grace)
+
+	return drain(ctx, j.batchSize, func(ctx context.Context, limit int) (int64, error) {
+		return j.sessions.DeleteStale(ctx, before, limit)
+	})
+}
+
+// SSOStatePurge deletes SSO logins that were started but never finished.
+type SSOStatePurge struct {
+	states    repository.SSOStateRepository
+	batchSize int
+}
+
+func NewSSOStatePurge(states repository.SSOStateRepository, batchSize int) *SSOStatePurge {
+	return &SSOStatePurge{states: states, batchSize: batchSize}
+}
+
+func (j *SSOStatePurge) Name() string { return "purge_sso_states" }
+
+func (j *SSOStatePurge) Run(ctx context.Context) (int64, error) {
+	before := time.Now()
+
+	return drain(ctx, j.batchSize, func(ctx context.Context, limit int) (int64, error) {
+		return j.states.DeleteExpired(ctx, before, limit)
+	})
+}
+
+// OAuthGrantPurge deletes expired authorization codes and expired or
+// revoked OAuth refresh tokens.
+type OAuthGrantPurge struct {
+	grants    repository.OAuthGrantRepository
+	batchSize int
+}
+
+func NewOAuthGrantPurge(grants repository.OAuthGrantRepository, batchSize int) *OAuthGrantPurge {
+	return &OAuthGrantPurge{grants: grants, batchSize: batchSize}
+}
+
+func (j *OAuthGrantPurge) Name() string { return "purge_oauth_grants" }
+
+func (j *OAuthGrantPurge) Run(ctx context.Context) (int64, error) {
+	before := time.Now()
+
+	return drain(ctx, j.batchSize, func(ctx context.Context, limit int) (int64, error) {
+		return j.grants.DeleteExpired(ctx, before, limit)
+	})
+}
+
+// WebAuthnChallengePurge deletes passkey challenges that were handed out
+// but never answered.
+type WebAuthnChallengePurge struct {
+	webauthn  repository.WebAuthnRepository
+	batchSize int
+}
+
+func NewWebAuthnChallengePurge(webauthn repository.WebAuthnRepository, batchSize int) *WebAuthnChallengePurge {
+	return &WebAuthnChallengePurge{webauthn: webauthn, batchSize: batchSize}
+}
+
+func (j *WebAuthnChallengePurge) Name() string { return "purge_webauthn_challenges" }
+
+func (j *WebAuthnChallengePurge) Run(ctx context.Context) (int64, error) {
+	before := time.Now()
+
+	return drain(ctx, j.batchSize, func(ctx context.Context, limit int) (int64, error) {
+		return j.webauthn.DeleteExpiredChallenges(ctx, before, limit)
+	})
+}
+
+// drain calls batch until it affects fewer than batchSize rows.
+func drain(ctx context.Context, batchSize int, batch func(context.Context, int) (int64, error)) (int64, error) {
+	var total int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, err := batch(ctx, batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+
+		if n < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"admin-portal/internal/auth-module/model"
+)
+
+// APIKeyPrincipal is the caller an API key authenticates: its owner, with
+// the owner's current role, limited to the key's scopes.
+type APIKeyPrincipal struct {
+	KeyID    string
+	UserID   string
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// APIKeyAuthenticator resolves the key from an "Authorization: ApiKey"
+// header. service.APIKeyService implements it.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*APIKeyPrincipal, error)
+}
+
+type apiKeyKey struct{}
+
+func withAPIKey(ctx context.Context, p *APIKeyPrincipal) context.Context {
+	ctx = WithAuthContext(ctx, p.UserID, p.Username, p.Role)
+	return context.WithValue(ctx, apiKeyKey{}, p)
+}
+
+// APIKeyFromContext returns the key the caller authenticated with, if the
+// caller used one instead of a session.
+func APIKeyFromContext(ctx context.Context) (*APIKeyPrincipal, bool) {
+	p, ok := ctx.Value(apiKeyKey{}).(*APIKeyPrincipal)
+	return p, ok
+}
+
+// extractAPIKeyFromMetadata returns the key of an
+// "Authorization: ApiKey <key>" header.
+func extractAPIKeyFromMetadata(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, h := range md.Get("authorization") {
+		scheme, key, ok := strings.Cut(h, " ")
+		if ok && strings.EqualFold(scheme, "apikey") && key != "" {
+			return strings.TrimSpace(key), true
+		}
+	}
+	return "", false
+}
+
+// ScopeAllows reports whether scopes grant method. A scope is a full
+// method, a whole service ("/pkg.Service/") or model.ScopeAll.
+func ScopeAllows(scopes []string, method string) bool {
+	if slices.Contains(scopes, model.ScopeAll) || slices.Contains(scopes, method) {
+		return true
+	}
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		return slices.Contains(scopes, method[:i+1])
+	}
+	return false
+}
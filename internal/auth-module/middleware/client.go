@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type clientKey struct{}
+
+// Client describes where a request came from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+func ClientFromContext(ctx context.Context) Client {
+	c, _ := ctx.Value(clientKey{}).(Client)
+	return c
+}
+
+// TrustedProxies are the networks of the reverse proxies whose
+// X-Forwarded-For header is believed.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs and bare
+// addresses, e.g. "10.0.0.0/8,192.168.1.10".
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q is not an address or CIDR", entry)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q is not an address or CIDR", entry)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) contains(ip net.IP) bool {
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientUnaryInterceptor records the caller's address and user agent.
+// The address is the peer's, unless the peer is a trusted proxy: then
+// X-Forwarded-For is read from the right, skipping trusted proxies, and
+// the first other address wins. Entries left of it are whatever the
+// client sent and are ignored.
+func ClientUnaryInterceptor(trusted TrustedProxies) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+
+		var c Client
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+				c.IP = trusted.clientIP(host, md.Get("x-forwarded-for"))
+			}
+		}
+
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			c.UserAgent = ua[0]
+		}
+
+		return handler(context.WithValue(ctx, clientKey{}, c), req)
+	}
+}
+
+// clientIP walks back from the peer through X-Forwarded-For while the
+// hops are trusted proxies. It returns "" if peer is not an address.
+func (t TrustedProxies) clientIP(peer string, forwarded []string) string {
+	ip := net.ParseIP(peer)
+	if ip == nil {
+		return ""
+	}
+
+	var hops []string
+	for _, header := range forwarded {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0 && t.contains(ip); i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+	}
+
+	return ip.String()
+}
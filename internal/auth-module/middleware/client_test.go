@@ -0,0 +1,50 @@
+package middleware
+
+import "testing"
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		proxies   TrustedProxies
+		peer      string
+		forwarded []string
+		want      string
+	}{
+		{"no proxies configured", nil, "203.0.113.7", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"untrusted peer", trusted, "203.0.113.7", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"trusted peer", trusted, "10.1.2.3", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"spoofed entries left of the proxy", trusted, "10.1.2.3", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"proxy chain", trusted, "10.1.2.3", []string{"198.51.100.1, 192.168.1.10", "10.9.9.9"}, "198.51.100.1"},
+		{"only proxies", trusted, "10.1.2.3", []string{"10.2.2.2"}, "10.2.2.2"},
+		{"garbage hop", trusted, "10.1.2.3", []string{"198.51.100.1, nonsense"}, "10.1.2.3"},
+		{"no header", trusted, "10.1.2.3", nil, "10.1.2.3"},
+		{"peer is not an address", trusted, "pipe", nil, ""},
+	}
+
+	for _, tc := range cases {
+		if got := tc.proxies.clientIP(tc.peer, tc.forwarded); got != tc.want {
+			t.Errorf("%s: client IP = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/33", "proxy.internal", "10.0.0.1/8/2"} {
+		if _, err := ParseTrustedProxies(s); err == nil {
+			t.Errorf("%q: accepted", s)
+		}
+	}
+
+	proxies, err := ParseTrustedProxies("::1, 127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proxies) != 2 || proxies.clientIP("::1", []string{"2001:db8::1"}) != "2001:db8::1" {
+		t.Errorf("parsed %v", proxies)
+	}
+}
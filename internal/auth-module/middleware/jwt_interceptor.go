@@ -14,8 +14,11 @@ import (
 	"admin-portal/internal/shared/security"
 )
 
+// JWTUnaryInterceptor authenticates callers by access token, or by API
+// key when apiKeys is non-nil.
 func JWTUnaryInterceptor(
 	cfg security.JWTConfig,
+	apiKeys APIKeyAuthenticator,
 ) grpc.UnaryServerInterceptor {
 
 	return func(
@@ -30,12 +33,25 @@ func JWTUnaryInterceptor(
 			return handler(ctx, req)
 		}
 
+		// Already authenticated by client certificate
+		if _, ok := ServiceFromContext(ctx); ok {
+			return handler(ctx, req)
+		}
+
+		if key, ok := extractAPIKeyFromMetadata(ctx); ok && apiKeys != nil {
+			principal, err := apiKeys.AuthenticateAPIKey(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			return handler(withAPIKey(ctx, principal), req)
+		}
+
 		tokenStr, err := extractTokenFromMetadata(ctx)
 		if err != nil {
 			return nil, status.Error(codes.Unauthenticated, "missing auth token")
 		}
 
-		claims, err := validateJWT(cfg, tokenStr)
+		claims, err := ValidateAccessToken(cfg, tokenStr)
 		if err != nil {
 			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
 		}
@@ -52,7 +68,10 @@ func JWTUnaryInterceptor(
 	}
 }
 
-func validateJWT(
+// ValidateAccessToken verifies a portal access token. Tokens issued to
+// OAuth clients are signed with another key and algorithm, so they are
+// never accepted here.
+func ValidateAccessToken(
 	cfg security.JWTConfig,
 	tokenStr string,
 ) (*security.Claims, error) {
@@ -63,6 +82,7 @@ func validateJWT(
 		func(token *jwt.Token) (interface{}, error) {
 			return []byte(cfg.Secret), nil
 		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
 	)
 
 	if err != nil {
@@ -110,7 +130,15 @@ func isPublicMethod(method string) bool {
 	switch method {
 	case "/auth.AuthService/Login",
 		"/auth.AuthService/Register",
-		"/auth.AuthService/Activate":
+		"/auth.AuthService/Activate",
+		"/auth.AuthService/ResendActivation",
+		"/auth.AuthService/Refresh",
+		"/auth.AuthService/StartSSOLogin",
+		"/auth.AuthService/CompleteSSOLogin",
+		"/auth.AuthService/BeginPasskeyLogin",
+		"/auth.AuthService/FinishPasskeyLogin",
+		"/auth.AuthService/AcceptInvitation",
+		"/grpc.health.v1.Health/Check":
 		return true
 	default:
 		return false
@@ -2,13 +2,51 @@ package middleware
 
 import (
 	"context"
+	"slices"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"admin-portal/internal/auth-module/model"
 )
 
-func RBACUnaryInterceptor() grpc.UnaryServerInterceptor {
+// AdminRoles are the roles allowed to call administrative RPCs.
+var AdminRoles = []string{model.RoleAdmin, model.RoleSuperAdmin}
+
+// Policy maps a full method ("/pkg.Service/Method") or a whole service
+// ("/pkg.Service/") to the roles allowed to call it. Methods without an
+// entry are open to any authenticated caller.
+type Policy map[string][]string
+
+// Merge returns a policy holding the entries of p and others. Later
+// entries win.
+func (p Policy) Merge(others ...Policy) Policy {
+	merged := Policy{}
+	for _, src := range append([]Policy{p}, others...) {
+		for k, v := range src {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func (p Policy) roles(method string) ([]string, bool) {
+	if roles, ok := p[method]; ok {
+		return roles, true
+	}
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		roles, ok := p[method[:i+1]]
+		return roles, ok
+	}
+	return nil, false
+}
+
+// RBACUnaryInterceptor enforces policy, and the scopes of callers using
+// an API key. It must run after JWTUnaryInterceptor, which puts the
+// caller's role in the context.
+func RBACUnaryInterceptor(policy Policy) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
 		req interface{},
@@ -16,12 +54,27 @@ func RBACUnaryInterceptor() grpc.UnaryServerInterceptor {
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
 
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		if key, ok := APIKeyFromContext(ctx); ok && !ScopeAllows(key.Scopes, info.FullMethod) {
+			return nil, status.Error(codes.PermissionDenied, "method not in API key scopes")
+		}
+
+		allowed, ok := policy.roles(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+
 		role, ok := RoleFromContext(ctx)
 		if !ok {
 			return nil, status.Error(codes.PermissionDenied, "role not found")
 		}
 
-		_ = role // enforce rules later
+		if !slices.Contains(allowed, role) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
 
 		return handler(ctx, req)
 	}
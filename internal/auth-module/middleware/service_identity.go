@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"admin-portal/internal/auth-module/model"
+)
+
+// ServiceIdentities maps the identity in an mTLS client certificate to
+// the role the calling service acts with. An identity is a URI SAN (e.g.
+// spiffe://portal/worker), a DNS SAN or the subject common name.
+type ServiceIdentities map[string]string
+
+// ParseServiceIdentities parses "identity=role,identity=role".
+func ParseServiceIdentities(s string) (ServiceIdentities, error) {
+	ids := ServiceIdentities{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		identity, role, ok := strings.Cut(entry, "=")
+		identity, role = strings.TrimSpace(identity), strings.TrimSpace(role)
+		if !ok || identity == "" {
+			return nil, fmt.Errorf("service identity %q is not identity=role", entry)
+		}
+		if model.RoleRank(role) == 0 {
+			return nil, fmt.Errorf("service identity %q: unknown role %q", identity, role)
+		}
+		ids[identity] = role
+	}
+	return ids, nil
+}
+
+// match returns the first identity of cert that is mapped.
+func (ids ServiceIdentities) match(cert *x509.Certificate) (identity, role string, ok bool) {
+	var candidates []string
+	for _, u := range cert.URIs {
+		candidates = append(candidates, u.String())
+	}
+	candidates = append(candidates, cert.DNSNames...)
+	candidates = append(candidates, cert.Subject.CommonName)
+
+	for _, c := range candidates {
+		if role, ok := ids[c]; ok {
+			return c, role, true
+		}
+	}
+	return "", "", false
+}
+
+type serviceKey struct{}
+
+// ServiceFromContext returns the identity of a caller authenticated by
+// client certificate rather than as a user.
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(serviceKey{}).(string)
+	return identity, ok
+}
+
+// ServiceIdentityUnaryInterceptor authenticates internal callers by their
+// verified mTLS client certificate. Requests that carry a token or API
+// key are left to JWTUnaryInterceptor, which must run after this one.
+// Services appear in the auth context as user ID "service:<identity>".
+func ServiceIdentityUnaryInterceptor(ids ServiceIdentities) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+
+		if len(ids) == 0 {
+			return handler(ctx, req)
+		}
+
+		if _, err := extractTokenFromMetadata(ctx); err == nil {
+			return handler(ctx, req)
+		}
+		if _, ok := extractAPIKeyFromMetadata(ctx); ok {
+			return handler(ctx, req)
+		}
+
+		cert := verifiedPeerCertificate(ctx)
+		if cert == nil {
+			return handler(ctx, req)
+		}
+
+		identity, role, ok := ids.match(cert)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		ctx = WithAuthContext(ctx, "service:"+identity, identity, role)
+		ctx = context.WithValue(ctx, serviceKey{}, identity)
+
+		return handler(ctx, req)
+	}
+}
+
+// verifiedPeerCertificate returns the client certificate if the TLS
+// handshake verified it against the client CAs.
+func verifiedPeerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.PeerCertificates) == 0 {
+		return nil
+	}
+
+	return tlsInfo.State.PeerCertificates[0]
+}
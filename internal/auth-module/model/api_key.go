@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScopeAll lets an API key call every method its owner's role allows.
+const ScopeAll = "*"
+
+type APIKey struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name   string    `gorm:"type:varchar(100);not null"`
+
+	Prefix  string `gorm:"type:varchar(16);not null;uniqueIndex"`
+	KeyHash string `gorm:"type:varchar(64);not null"`
+
+	// Scopes are full methods ("/pkg.Service/Method"), whole services
+	// ("/pkg.Service/") or ScopeAll.
+	Scopes []string `gorm:"type:jsonb;not null;default:'[]';serializer:json"`
+
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Usable reports whether the key is neither revoked nor expired at t.
+func (k *APIKey) Usable(t time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(t))
+}
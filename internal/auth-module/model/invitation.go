@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation lets someone create an account with a given role without
+// registering. The token is emailed to them and only its hash is kept.
+type Invitation struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	Email string `gorm:"type:varchar(255);not null;index"`
+	Role  string `gorm:"type:varchar(20);not null;check:role IN ('user','admin','super-admin')"`
+
+	// TokenHash is nil until the invitation email is sent. Each email
+	// carries a new token, which replaces the one before.
+	TokenHash *string `gorm:"type:varchar(64);uniqueIndex"`
+
+	InvitedBy *uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt time.Time  `gorm:"not null"`
+
+	AcceptedAt     *time.Time
+	AcceptedUserID *uuid.UUID `gorm:"type:uuid"`
+	RevokedAt      *time.Time
+
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (Invitation) TableName() string {
+	return "invitations"
+}
+
+// Pending reports whether the invitation can still be accepted at t.
+func (i *Invitation) Pending(t time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && i.ExpiresAt.After(t)
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient is an app that signs its users in through the portal. Its
+// ID is the OAuth client_id.
+type OAuthClient struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	Name string `gorm:"type:varchar(100);not null"`
+
+	// SecretHash is nil for public clients, which authenticate with PKCE
+	// alone.
+	SecretHash *string `gorm:"type:varchar(64)"`
+
+	RedirectURIs []string `gorm:"type:jsonb;not null;default:'[]';serializer:json"`
+
+	CreatedBy *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt time.Time  `gorm:"not null;default:now()"`
+	RevokedAt *time.Time
+}
+
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// Public reports whether the client has no secret.
+func (c *OAuthClient) Public() bool {
+	return c.SecretHash == nil
+}
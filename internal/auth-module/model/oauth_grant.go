@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthAuthorizationCode is an approved authorization request waiting to
+// be redeemed. Only a hash of the code is stored.
+type OAuthAuthorizationCode struct {
+	CodeHash string `gorm:"type:varchar(64);primaryKey"`
+
+	ClientID uuid.UUID `gorm:"type:uuid;not null"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null"`
+
+	RedirectURI   string    `gorm:"type:text;not null"`
+	Scope         string    `gorm:"type:varchar(255);not null"`
+	Nonce         *string   `gorm:"type:varchar(255)"`
+	CodeChallenge string    `gorm:"type:varchar(128);not null"`
+	AuthTime      time.Time `gorm:"not null"`
+
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// OAuthRefreshToken is a refresh token held by a client. Only a hash of
+// the token is stored.
+type OAuthRefreshToken struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	TokenHash string `gorm:"type:varchar(64);not null;uniqueIndex"`
+
+	ClientID uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null"`
+
+	Scope    string    `gorm:"type:varchar(255);not null"`
+	AuthTime time.Time `gorm:"not null"`
+
+	ExpiresAt time.Time `gorm:"not null;index"`
+	RevokedAt *time.Time
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (OAuthRefreshToken) TableName() string {
+	return "oauth_refresh_tokens"
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// SSOLoginState remembers an SSO login between the redirect to the
+// identity provider and the callback.
+type SSOLoginState struct {
+	State        string    `gorm:"type:varchar(64);primaryKey"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (SSOLoginState) TableName() string {
+	return "sso_login_states"
+}
@@ -6,16 +6,46 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "super-admin"
+)
+
+// RoleRank orders roles by privilege. Unknown roles rank below RoleUser.
+func RoleRank(role string) int {
+	switch role {
+	case RoleUser:
+		return 1
+	case RoleAdmin:
+		return 2
+	case RoleSuperAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
 type User struct {
 	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 
 	Username string `gorm:"type:varchar(150);uniqueIndex;not null"`
 
+	// Email is stored lowercased. It is nil for users that registered
+	// before email activation and for provisioned users.
+	Email *string `gorm:"type:varchar(255);uniqueIndex"`
+
 	Role string `gorm:"type:varchar(20);not null;check:role IN ('user','admin','super-admin')"`
 
 	IsActive    bool `gorm:"not null;default:true"`
 	IsActivated bool `gorm:"not null;default:false"`
 
+	ActivationReminderSentAt *time.Time
+	// ActivationTokenID is the jti of the only activation token that is
+	// still valid. Activating clears it.
+	ActivationTokenID *uuid.UUID `gorm:"type:uuid"`
+	ActivationSentAt  *time.Time
+
 	CreatedAt time.Time `gorm:"not null;default:now()"`
 	UpdatedAt time.Time `gorm:"not null;default:now()"`
 
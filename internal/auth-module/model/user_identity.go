@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to their account at an external identity
+// provider, keyed by the provider's issuer and subject.
+type UserIdentity struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	Issuer  string  `gorm:"type:text;not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject string  `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Email   *string `gorm:"type:varchar(255)"`
+
+	CreatedAt   time.Time `gorm:"not null;default:now()"`
+	LastLoginAt *time.Time
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
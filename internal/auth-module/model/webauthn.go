@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ceremonies a WebAuthnChallenge is issued for.
+const (
+	CeremonyRegistration = "registration"
+	CeremonyLogin        = "login"
+	CeremonySecondFactor = "second_factor"
+)
+
+// WebAuthnCredential is a passkey or security key registered by a user.
+type WebAuthnCredential struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name   string    `gorm:"type:varchar(100);not null"`
+
+	CredentialID []byte `gorm:"type:bytea;not null;uniqueIndex"`
+	PublicKey    []byte `gorm:"type:bytea;not null"`
+	// SignCount is the last signature counter seen. It stays 0 for
+	// authenticators that do not keep one.
+	SignCount  int64    `gorm:"not null;default:0"`
+	Transports []string `gorm:"type:jsonb;not null;default:'[]';serializer:json"`
+	AAGUID     []byte   `gorm:"column:aaguid;type:bytea"`
+
+	BackupEligible bool `gorm:"not null;default:false"`
+	BackupState    bool `gorm:"not null;default:false"`
+
+	CreatedAt  time.Time `gorm:"not null;default:now()"`
+	LastUsedAt *time.Time
+}
+
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// WebAuthnChallenge remembers a challenge between the options sent to
+// the browser and its response. UserID is nil for passwordless logins,
+// where the user is only known from the response.
+type WebAuthnChallenge struct {
+	Challenge string     `gorm:"type:varchar(64);primaryKey"`
+	Ceremony  string     `gorm:"type:varchar(20);not null;check:ceremony IN ('registration','login','second_factor')"`
+	UserID    *uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt time.Time  `gorm:"not null;index"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (WebAuthnChallenge) TableName() string {
+	return "webauthn_challenges"
+}
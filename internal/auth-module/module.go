@@ -0,0 +1,178 @@
+package authmodule
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/handler"
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/auth-module/service"
+	"admin-portal/internal/shared/database"
+	"admin-portal/internal/shared/mail"
+	"admin-portal/internal/shared/oidc"
+	"admin-portal/internal/shared/outbox"
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/validation"
+	"admin-portal/internal/shared/webauthn"
+	authpb "admin-portal/proto/auth"
+)
+
+// Module wires the auth repositories, services and handler together.
+type Module struct {
+	AuthService   service.AuthService
+	UserService   service.UserService
+	TokenService  service.TokenService
+	APIKeyService service.APIKeyService
+	SSOService    service.SSOService
+	OAuthService  service.OAuthService
+	Passkeys      service.PasskeyService
+	Invitations   service.InvitationService
+
+	// OAuthHandler serves the OAuth and OpenID Connect endpoints. It is
+	// nil unless oauthCfg is enabled.
+	OAuthHandler http.Handler
+
+	handler *handler.AuthHandler
+}
+
+// New builds the module. A nil metrics disables domain instrumentation,
+// a nil alerts drops security alerts, a nil idp disables SSO and a nil rp
+// disables passkeys. Logins check local passwords first, then the
+// directory if ldapCfg is enabled, then any passkeys the user has.
+func New(
+	db *gorm.DB,
+	jwtCfg security.JWTConfig,
+	metrics service.Metrics,
+	alerts service.SecurityAlerts,
+	idp *oidc.Provider,
+	oauthCfg service.OAuthConfig,
+	ldapCfg service.LDAPConfig,
+	rp *webauthn.RelyingParty,
+) *Module {
+	if metrics == nil {
+		metrics = service.NopMetrics{}
+	}
+	if alerts == nil {
+		alerts = service.NopSecurityAlerts{}
+	}
+
+	// ---------------------------
+	// Initialize repositories
+	// ---------------------------
+	userRepo := repository.NewUserRepository(db)
+	passwordRepo := repository.NewPasswordRepository(db)
+	loginLogRepo := repository.NewLoginLogRepository(db)
+	userSessionRepo := repository.NewUserSessionRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	ssoStateRepo := repository.NewSSOStateRepository(db)
+	oauthClientRepo := repository.NewOAuthClientRepository(db)
+	oauthGrantRepo := repository.NewOAuthGrantRepository(db)
+	webauthnRepo := repository.NewWebAuthnRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+
+	// ---------------------------
+	// Initialize services
+	// ---------------------------
+	tokenService := service.NewTokenService(jwtCfg, userSessionRepo)
+
+	verifiers := []service.CredentialVerifier{
+		service.NewPasswordVerifier(userRepo, passwordRepo),
+	}
+	if ldapCfg.Enabled() {
+		verifiers = append(verifiers, service.NewLDAPVerifier(ldapCfg))
+	}
+
+	authService := service.NewAuthService(
+		database.NewTransactor(db),
+		outbox.NewRecorder(db),
+		queue.NewEnqueuer(db),
+		userRepo,
+		passwordRepo,
+		loginLogRepo,
+		identityRepo,
+		verifiers,
+		service.NewPasskeySecondFactor(rp, webauthnRepo),
+		tokenService,
+		jwtCfg,
+		service.LoadActivationConfig(),
+		metrics,
+		alerts,
+		service.LoadSecurityConfig(),
+	)
+
+	userService := service.NewUserService(userRepo, userSessionRepo, loginLogRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo)
+	ssoService := service.NewSSOService(idp, ssoStateRepo, authService, service.LoadSSOConfig())
+	oauthService := service.NewOAuthService(oauthClientRepo, oauthGrantRepo, userRepo, oauthCfg)
+	passkeys := service.NewPasskeyService(rp, webauthnRepo, userRepo, authService)
+	invitations := service.NewInvitationService(db, invitationRepo, userRepo, authService, service.LoadInvitationConfig())
+
+	m := &Module{
+		AuthService:   authService,
+		UserService:   userService,
+		TokenService:  tokenService,
+		APIKeyService: apiKeyService,
+		SSOService:    ssoService,
+		OAuthService:  oauthService,
+		Passkeys:      passkeys,
+		Invitations:   invitations,
+		handler:       handler.NewAuthHandler(authService, userService, apiKeyService, ssoService, oauthService, passkeys, invitations),
+	}
+	if oauthCfg.Enabled() {
+		m.OAuthHandler = handler.NewOAuthHTTPHandler(oauthService, jwtCfg, oauthCfg)
+	}
+
+	return m
+}
+
+// NewActivationMailer builds the worker's handler for
+// service.KindActivationEmail, which also delivers activation reminders.
+func NewActivationMailer(db *gorm.DB, jwtCfg security.JWTConfig, mailer mail.Mailer) *service.ActivationMailer {
+	return service.NewActivationMailer(repository.NewUserRepository(db), jwtCfg, mailer, service.LoadActivationConfig())
+}
+
+// NewInvitationMailer builds the worker's handler for
+// service.KindInvitationEmail.
+func NewInvitationMailer(db *gorm.DB, mailer mail.Mailer) *service.InvitationMailer {
+	return service.NewInvitationMailer(
+		repository.NewInvitationRepository(db),
+		repository.NewUserRepository(db),
+		mailer,
+		service.LoadInvitationConfig(),
+	)
+}
+
+func (m *Module) Name() string {
+	return "auth"
+}
+
+func (m *Module) Register(s *grpc.Server) {
+	authpb.RegisterAuthServiceServer(s, m.handler)
+}
+
+func (m *Module) RegisterValidators(r *validation.Registry) {
+	handler.RegisterValidators(r)
+}
+
+// Policy restricts account administration, invitations, the user and
+// login-log listings and OAuth client registration to administrators.
+func (m *Module) Policy() middleware.Policy {
+	return middleware.Policy{
+		"/auth.AuthService/ActivateUser":        middleware.AdminRoles,
+		"/auth.AuthService/Deactivate":          middleware.AdminRoles,
+		"/auth.AuthService/ChangeRole":          middleware.AdminRoles,
+		"/auth.AuthService/ListUsers":           middleware.AdminRoles,
+		"/auth.AuthService/ListLoginLogs":       middleware.AdminRoles,
+		"/auth.AuthService/RegisterOAuthClient": middleware.AdminRoles,
+		"/auth.AuthService/ListOAuthClients":    middleware.AdminRoles,
+		"/auth.AuthService/RevokeOAuthClient":   middleware.AdminRoles,
+		"/auth.AuthService/InviteUser":          middleware.AdminRoles,
+		"/auth.AuthService/ListInvitations":     middleware.AdminRoles,
+		"/auth.AuthService/RevokeInvitation":    middleware.AdminRoles,
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByID(ctx context.Context, id string) (*model.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+	List(ctx context.Context, f APIKeyFilter) ([]*model.APIKey, int64, error)
+}
+
+// APIKeyFilter narrows List to one user's keys.
+type APIKeyFilter struct {
+	UserID string
+	// IncludeInactive also returns revoked and expired keys.
+	IncludeInactive bool
+	Limit           int
+	Offset          int
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	return database.Conn(ctx, r.db).Create(key).Error
+}
+
+func (r *apiKeyRepository) FindByID(ctx context.Context, id string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := database.Conn(ctx, r.db).First(&key, "id = ?", id).Error
+	return &key, err
+}
+
+// FindByPrefix returns the key with the given prefix whether or not it is
+// still usable; callers check the hash and model.APIKey.Usable.
+func (r *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := database.Conn(ctx, r.db).First(&key, "prefix = ?", prefix).Error
+	return &key, err
+}
+
+// Revoke marks a key revoked. Revoking it again keeps the first time.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+// List returns a page of the user's keys, newest first, and the total.
+func (r *apiKeyRepository) List(ctx context.Context, f APIKeyFilter) ([]*model.APIKey, int64, error) {
+	q := database.Conn(ctx, r.db).
+		Model(&model.APIKey{}).
+		Where("user_id = ?", f.UserID)
+	if !f.IncludeInactive {
+		q = q.Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", time.Now())
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var keys []*model.APIKey
+	err := q.Order("created_at DESC, id").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&keys).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return keys, total, nil
+}
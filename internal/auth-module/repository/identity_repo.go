@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
+)
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *model.UserIdentity) error
+	FindBySubject(ctx context.Context, issuer, subject string) (*model.UserIdentity, error)
+	RecordLogin(ctx context.Context, id string, email *string, at time.Time) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	return database.Conn(ctx, r.db).Create(identity).Error
+}
+
+func (r *identityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := database.Conn(ctx, r.db).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).Error
+	return &identity, err
+}
+
+// RecordLogin stores the time of a login and the email the provider
+// claimed with it.
+func (r *identityRepository) RecordLogin(ctx context.Context, id string, email *string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+}
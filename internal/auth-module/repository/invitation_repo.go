@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *model.Invitation) error
+	FindByID(ctx context.Context, id string) (*model.Invitation, error)
+	FindPendingByEmail(ctx context.Context, email string, at time.Time) (*model.Invitation, error)
+	FindPendingByTokenHash(ctx context.Context, hash string, at time.Time) (*model.Invitation, error)
+	SetTokenHash(ctx context.Context, id, hash string) error
+	MarkAccepted(ctx context.Context, id, userID string, at time.Time) error
+	Revoke(ctx context.Context, id string, at time.Time) error
+	List(ctx context.Context, f InvitationFilter) ([]*model.Invitation, int64, error)
+}
+
+type InvitationFilter struct {
+	// IncludeClosed also returns accepted, revoked and expired
+	// invitations.
+	IncludeClosed bool
+	Limit         int
+	Offset        int
+}
+
+// pendingInvitation matches invitations that can still be accepted at
+// the time given as its argument.
+const pendingInvitation = "accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?"
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, inv *model.Invitation) error {
+	return database.Conn(ctx, r.db).Create(inv).Error
+}
+
+func (r *invitationRepository) FindByID(ctx context.Context, id string) (*model.Invitation, error) {
+	var inv model.Invitation
+	err := database.Conn(ctx, r.db).First(&inv, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *invitationRepository) FindPendingByEmail(ctx context.Context, email string, at time.Time) (*model.Invitation, error) {
+	var inv model.Invitation
+	err := database.Conn(ctx, r.db).
+		Where("email = ? AND "+pendingInvitation, email, at).
+		First(&inv).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *invitationRepository) FindPendingByTokenHash(ctx context.Context, hash string, at time.Time) (*model.Invitation, error) {
+	var inv model.Invitation
+	err := database.Conn(ctx, r.db).
+		Where("token_hash = ? AND "+pendingInvitation, hash, at).
+		First(&inv).Error
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// SetTokenHash replaces the token of an invitation, so only the newest
+// email works.
+func (r *invitationRepository) SetTokenHash(ctx context.Context, id, hash string) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.Invitation{}).
+		Where("id = ?", id).
+		Update("token_hash", hash).Error
+}
+
+// MarkAccepted records that userID was created from the invitation. It
+// returns gorm.ErrRecordNotFound unless the invitation was still pending
+// at at, so an invitation is accepted once.
+func (r *invitationRepository) MarkAccepted(ctx context.Context, id, userID string, at time.Time) error {
+	res := database.Conn(ctx, r.db).
+		Model(&model.Invitation{}).
+		Where("id = ? AND "+pendingInvitation, id, at).
+		Updates(map[string]interface{}{
+			"accepted_at":      at,
+			"accepted_user_id": userID,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Revoke marks an invitation revoked. Accepted or already revoked
+// invitations are left alone.
+func (r *invitationRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// List returns a page of invitations, newest first, and the total.
+func (r *invitationRepository) List(ctx context.Context, f InvitationFilter) ([]*model.Invitation, int64, error) {
+	q := database.Conn(ctx, r.db).Model(&model.Invitation{})
+	if !f.IncludeClosed {
+		q = q.Where(pendingInvitation, time.Now())
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var invitations []*model.Invitation
+	err := q.Order("created_at DESC, id").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&invitations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return invitations, total, nil
+}
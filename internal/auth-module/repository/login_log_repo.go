@@ -2,16 +2,34 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
 	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
 )
 
 type LoginLogRepository interface {
 	Create(ctx context.Context, log *model.LoginLog) error
 	GetAllByUserID(ctx context.Context, userID string) ([]*model.LoginLog, error)
 	GetAll(ctx context.Context) ([]*model.LoginLog, error)
+	DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error)
+	ArchiveOlderThan(ctx context.Context, before time.Time, limit int) (int64, error)
+	CountRecentFailures(ctx context.Context, userID, ip string, since time.Time) (int64, error)
+	CountSuccesses(ctx context.Context, userID, ip string) (int64, error)
+	List(ctx context.Context, f LoginLogFilter) ([]*model.LoginLog, int64, error)
+}
+
+// LoginLogFilter narrows List. Empty fields match everything.
+type LoginLogFilter struct {
+	UserID  string
+	LogType string
+	// Since is inclusive and Until exclusive.
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
 }
 
 type loginLogRepository struct {
@@ -23,12 +41,12 @@ func NewLoginLogRepository(db *gorm.DB) LoginLogRepository {
 }
 
 func (r *loginLogRepository) Create(ctx context.Context, log *model.LoginLog) error {
-	return r.db.WithContext(ctx).Create(log).Error
+	return database.Conn(ctx, r.db).Create(log).Error
 }
 
 func (r *loginLogRepository) GetAllByUserID(ctx context.Context, userID string) ([]*model.LoginLog, error) {
 	var logs []*model.LoginLog
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("user_id = ?", userID).
 		Find(&logs).Error
 	if err != nil {
@@ -39,7 +57,7 @@ func (r *loginLogRepository) GetAllByUserID(ctx context.Context, userID string)
 
 func (r *loginLogRepository) GetAll(ctx context.Context) ([]*model.LoginLog, error) {
 	var logs []*model.LoginLog
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Find(&logs).Error
 	if err != nil {
 		return nil, err
@@ -47,3 +65,107 @@ func (r *loginLogRepository) GetAll(ctx context.Context) ([]*model.LoginLog, err
 	return logs, nil
 }
 
+// DeleteOlderThan removes up to limit logs created before the given time.
+func (r *loginLogRepository) DeleteOlderThan(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := database.Conn(ctx, r.db).Exec(`
+		DELETE FROM login_logs
+		WHERE id IN (
+			SELECT id FROM login_logs
+			WHERE created_at < ?
+			ORDER BY created_at
+			LIMIT ?
+		)`, before, limit)
+	return res.RowsAffected, res.Error
+}
+
+// ArchiveOlderThan moves up to limit logs created before the given time
+// into login_logs_archive in a single statement.
+func (r *loginLogRepository) ArchiveOlderThan(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := database.Conn(ctx, r.db).Exec(`
+		WITH moved AS (
+			DELETE FROM login_logs
+			WHERE id IN (
+				SELECT id FROM login_logs
+				WHERE created_at < ?
+				ORDER BY created_at
+				LIMIT ?
+			)
+			RETURNING id, user_id, message, log_type, ip_address, user_agent, is_deleted, created_at
+		)
+		INSERT INTO login_logs_archive
+			(id, user_id, message, log_type, ip_address, user_agent, is_deleted, created_at)
+		SELECT id, user_id, message, log_type, ip_address, user_agent, is_deleted, created_at
+		FROM moved
+		ON CONFLICT (id) DO NOTHING`, before, limit)
+	return res.RowsAffected, res.Error
+}
+
+// CountRecentFailures counts failed logins since the given time, for
+// userID if set and otherwise for ip.
+func (r *loginLogRepository) CountRecentFailures(ctx context.Context, userID, ip string, since time.Time) (int64, error) {
+	q := database.Conn(ctx, r.db).
+		Model(&model.LoginLog{}).
+		Where("log_type = 'error' AND created_at >= ?", since)
+
+	if userID != "" {
+		q = q.Where("user_id = ?", userID)
+	} else {
+		q = q.Where("user_id IS NULL AND ip_address = ?", ip)
+	}
+
+	var n int64
+	err := q.Count(&n).Error
+	return n, err
+}
+
+// CountSuccesses counts successful logins of userID that recorded an
+// address, from ip if set. Logins written before addresses were recorded
+// do not count.
+func (r *loginLogRepository) CountSuccesses(ctx context.Context, userID, ip string) (int64, error) {
+	q := database.Conn(ctx, r.db).
+		Model(&model.LoginLog{}).
+		Where("log_type = 'success' AND user_id = ?", userID)
+
+	if ip != "" {
+		q = q.Where("ip_address = ?", ip)
+	} else {
+		q = q.Where("ip_address IS NOT NULL")
+	}
+
+	var n int64
+	err := q.Count(&n).Error
+	return n, err
+}
+
+// List returns a page of logs, newest first, and the total count.
+func (r *loginLogRepository) List(ctx context.Context, f LoginLogFilter) ([]*model.LoginLog, int64, error) {
+	q := database.Conn(ctx, r.db).Model(&model.LoginLog{})
+	if f.UserID != "" {
+		q = q.Where("user_id = ?", f.UserID)
+	}
+	if f.LogType != "" {
+		q = q.Where("log_type = ?", f.LogType)
+	}
+	if !f.Since.IsZero() {
+		q = q.Where("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		q = q.Where("created_at < ?", f.Until)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*model.LoginLog
+	err := q.Order("created_at DESC, id").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
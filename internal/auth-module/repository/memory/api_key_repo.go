@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type apiKeyRepository struct {
+	mu   sync.Mutex
+	keys []model.APIKey
+}
+
+func NewAPIKeyRepository() repository.APIKeyRepository {
+	return &apiKeyRepository{}
+}
+
+func (r *apiKeyRepository) Create(_ context.Context, key *model.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.ID = newID(key.ID)
+	for _, k := range r.keys {
+		if k.ID == key.ID || k.Prefix == key.Prefix {
+			return ErrDuplicatedKey
+		}
+	}
+
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	key.CreatedAt = now(key.CreatedAt)
+	r.keys = append(r.keys, copyKey(*key))
+	return nil
+}
+
+func (r *apiKeyRepository) FindByID(_ context.Context, id string) (*model.APIKey, error) {
+	kid, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.find(func(k *model.APIKey) bool { return k.ID == kid })
+}
+
+func (r *apiKeyRepository) FindByPrefix(_ context.Context, prefix string) (*model.APIKey, error) {
+	return r.find(func(k *model.APIKey) bool { return k.Prefix == prefix })
+}
+
+func (r *apiKeyRepository) find(match func(*model.APIKey) bool) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.keys {
+		if match(&r.keys[i]) {
+			k := copyKey(r.keys[i])
+			return &k, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *apiKeyRepository) Revoke(_ context.Context, id string, at time.Time) error {
+	return r.update(id, func(k *model.APIKey) {
+		if k.RevokedAt == nil {
+			k.RevokedAt = &at
+		}
+	})
+}
+
+func (r *apiKeyRepository) TouchLastUsed(_ context.Context, id string, at time.Time) error {
+	return r.update(id, func(k *model.APIKey) {
+		k.LastUsedAt = &at
+	})
+}
+
+func (r *apiKeyRepository) update(id string, fn func(*model.APIKey)) error {
+	kid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].ID == kid {
+			fn(&r.keys[i])
+		}
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) List(_ context.Context, f repository.APIKeyFilter) ([]*model.APIKey, int64, error) {
+	uid, err := parseID(f.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	var keys []*model.APIKey
+	for _, k := range r.keys {
+		if k.UserID != uid {
+			continue
+		}
+		if !f.IncludeInactive && !k.Usable(now) {
+			continue
+		}
+		k := copyKey(k)
+		keys = append(keys, &k)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return page(keys, f.Limit, f.Offset), int64(len(keys)), nil
+}
+
+// copyKey detaches the scopes so callers cannot change stored keys.
+func copyKey(k model.APIKey) model.APIKey {
+	k.Scopes = slices.Clone(k.Scopes)
+	return k
+}
@@ -0,0 +1,57 @@
+// Package memory implements the auth repositories in process memory for
+// unit tests. They mirror what the GORM versions do against Postgres:
+// generated IDs and timestamps, column defaults, unique usernames and
+// session tokens, and the expiry and revocation filters. They do not
+// take part in database.Transaction, so nothing rolls back.
+//
+// NewTransactor, NewOutbox and NewQueue stand in for the database in
+// the services that write through it, so those can be unit tested on
+// these repositories too.
+//
+// repotest runs the same contract suite against both implementations.
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrDuplicatedKey stands in for a unique-constraint violation. The GORM
+// repositories surface the driver's error instead, so callers must not
+// rely on either beyond err != nil.
+var ErrDuplicatedKey = errors.New("duplicate key value violates unique constraint")
+
+// newID fills a zero ID the way gen_random_uuid() does.
+func newID(id uuid.UUID) uuid.UUID {
+	if id == uuid.Nil {
+		return uuid.New()
+	}
+	return id
+}
+
+// now fills a zero timestamp the way GORM's create tracking does.
+func now(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+// parseID rejects what a uuid column would reject.
+func parseID(id string) (uuid.UUID, error) {
+	return uuid.Parse(id)
+}
+
+// page applies LIMIT and OFFSET to an already ordered result.
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type identityRepository struct {
+	mu         sync.Mutex
+	identities []model.UserIdentity
+}
+
+func NewIdentityRepository() repository.IdentityRepository {
+	return &identityRepository{}
+}
+
+func (r *identityRepository) Create(_ context.Context, identity *model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identity.ID = newID(identity.ID)
+	for _, i := range r.identities {
+		if i.ID == identity.ID || (i.Issuer == identity.Issuer && i.Subject == identity.Subject) {
+			return ErrDuplicatedKey
+		}
+	}
+
+	identity.CreatedAt = now(identity.CreatedAt)
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *identityRepository) FindBySubject(_ context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, i := range r.identities {
+		if i.Issuer == issuer && i.Subject == subject {
+			return &i, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *identityRepository) RecordLogin(_ context.Context, id string, email *string, at time.Time) error {
+	iid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.identities {
+		if r.identities[i].ID == iid {
+			r.identities[i].Email = email
+			r.identities[i].LastLoginAt = &at
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type invitationRepository struct {
+	mu          sync.Mutex
+	invitations []model.Invitation
+}
+
+func NewInvitationRepository() repository.InvitationRepository {
+	return &invitationRepository{}
+}
+
+func (r *invitationRepository) Create(_ context.Context, inv *model.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inv.ID = newID(inv.ID)
+	for _, i := range r.invitations {
+		if i.ID == inv.ID || (inv.TokenHash != nil && i.TokenHash != nil && *i.TokenHash == *inv.TokenHash) {
+			return ErrDuplicatedKey
+		}
+	}
+
+	inv.CreatedAt = now(inv.CreatedAt)
+	r.invitations = append(r.invitations, *inv)
+	return nil
+}
+
+func (r *invitationRepository) FindByID(_ context.Context, id string) (*model.Invitation, error) {
+	iid, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.find(func(i *model.Invitation) bool { return i.ID == iid })
+}
+
+func (r *invitationRepository) FindPendingByEmail(_ context.Context, email string, at time.Time) (*model.Invitation, error) {
+	return r.find(func(i *model.Invitation) bool { return i.Email == email && i.Pending(at) })
+}
+
+func (r *invitationRepository) FindPendingByTokenHash(_ context.Context, hash string, at time.Time) (*model.Invitation, error) {
+	return r.find(func(i *model.Invitation) bool {
+		return i.TokenHash != nil && *i.TokenHash == hash && i.Pending(at)
+	})
+}
+
+func (r *invitationRepository) SetTokenHash(_ context.Context, id, hash string) error {
+	iid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, i := range r.invitations {
+		if i.ID != iid && i.TokenHash != nil && *i.TokenHash == hash {
+			return ErrDuplicatedKey
+		}
+	}
+	for i := range r.invitations {
+		if r.invitations[i].ID == iid {
+			r.invitations[i].TokenHash = &hash
+		}
+	}
+	return nil
+}
+
+func (r *invitationRepository) MarkAccepted(_ context.Context, id, userID string, at time.Time) error {
+	iid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	uid, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.invitations {
+		if r.invitations[i].ID == iid && r.invitations[i].Pending(at) {
+			r.invitations[i].AcceptedAt = &at
+			r.invitations[i].AcceptedUserID = &uid
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *invitationRepository) Revoke(_ context.Context, id string, at time.Time) error {
+	iid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.invitations {
+		inv := &r.invitations[i]
+		if inv.ID == iid && inv.AcceptedAt == nil && inv.RevokedAt == nil {
+			inv.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *invitationRepository) List(_ context.Context, f repository.InvitationFilter) ([]*model.Invitation, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	var invitations []*model.Invitation
+	for _, i := range r.invitations {
+		if !f.IncludeClosed && !i.Pending(now) {
+			continue
+		}
+		i := i
+		invitations = append(invitations, &i)
+	}
+
+	sort.SliceStable(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+	return page(invitations, f.Limit, f.Offset), int64(len(invitations)), nil
+}
+
+func (r *invitationRepository) find(match func(i *model.Invitation) bool) (*model.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, i := range r.invitations {
+		if match(&i) {
+			return &i, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
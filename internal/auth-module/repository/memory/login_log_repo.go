@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type loginLogRepository struct {
+	mu      sync.Mutex
+	logs    []model.LoginLog
+	archive []model.LoginLog
+}
+
+func NewLoginLogRepository() repository.LoginLogRepository {
+	return &loginLogRepository{}
+}
+
+func (r *loginLogRepository) Create(_ context.Context, log *model.LoginLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.ID = newID(log.ID)
+	for _, l := range r.logs {
+		if l.ID == log.ID {
+			return ErrDuplicatedKey
+		}
+	}
+
+	log.CreatedAt = now(log.CreatedAt)
+
+	l := *log
+	l.User = nil
+	r.logs = append(r.logs, l)
+	return nil
+}
+
+func (r *loginLogRepository) GetAllByUserID(_ context.Context, userID string) ([]*model.LoginLog, error) {
+	uid, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var logs []*model.LoginLog
+	for _, l := range r.logs {
+		if l.UserID != nil && *l.UserID == uid {
+			l := l
+			logs = append(logs, &l)
+		}
+	}
+	return logs, nil
+}
+
+func (r *loginLogRepository) GetAll(_ context.Context) ([]*model.LoginLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logs := make([]*model.LoginLog, 0, len(r.logs))
+	for _, l := range r.logs {
+		l := l
+		logs = append(logs, &l)
+	}
+	return logs, nil
+}
+
+func (r *loginLogRepository) DeleteOlderThan(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.takeOlderThan(before, limit))), nil
+}
+
+func (r *loginLogRepository) ArchiveOlderThan(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var archived int64
+	for _, l := range r.takeOlderThan(before, limit) {
+		if !r.archived(l) {
+			r.archive = append(r.archive, l)
+			archived++
+		}
+	}
+	return archived, nil
+}
+
+func (r *loginLogRepository) CountRecentFailures(_ context.Context, userID, ip string, since time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for _, l := range r.logs {
+		if l.LogType != "error" || l.CreatedAt.Before(since) {
+			continue
+		}
+		if userID != "" {
+			if l.UserID != nil && l.UserID.String() == userID {
+				n++
+			}
+		} else if l.UserID == nil && l.IPAddress != nil && *l.IPAddress == ip {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *loginLogRepository) CountSuccesses(_ context.Context, userID, ip string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for _, l := range r.logs {
+		if l.LogType != "success" || l.UserID == nil || l.UserID.String() != userID {
+			continue
+		}
+		if l.IPAddress != nil && (ip == "" || *l.IPAddress == ip) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *loginLogRepository) List(_ context.Context, f repository.LoginLogFilter) ([]*model.LoginLog, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var logs []*model.LoginLog
+	for _, l := range r.logs {
+		if f.UserID != "" && (l.UserID == nil || l.UserID.String() != f.UserID) {
+			continue
+		}
+		if f.LogType != "" && l.LogType != f.LogType {
+			continue
+		}
+		if !f.Since.IsZero() && l.CreatedAt.Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && !l.CreatedAt.Before(f.Until) {
+			continue
+		}
+		l := l
+		logs = append(logs, &l)
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].CreatedAt.After(logs[j].CreatedAt) })
+	return page(logs, f.Limit, f.Offset), int64(len(logs)), nil
+}
+
+// takeOlderThan removes and returns the oldest logs created before the
+// given time, up to limit.
+func (r *loginLogRepository) takeOlderThan(before time.Time, limit int) []model.LoginLog {
+	sort.SliceStable(r.logs, func(i, j int) bool { return r.logs[i].CreatedAt.Before(r.logs[j].CreatedAt) })
+
+	var taken []model.LoginLog
+	kept := r.logs[:0]
+	for _, l := range r.logs {
+		if l.CreatedAt.Before(before) && len(taken) < limit {
+			taken = append(taken, l)
+			continue
+		}
+		kept = append(kept, l)
+	}
+	r.logs = kept
+
+	return taken
+}
+
+func (r *loginLogRepository) archived(log model.LoginLog) bool {
+	for _, l := range r.archive {
+		if l.ID == log.ID {
+			return true
+		}
+	}
+	return false
+}
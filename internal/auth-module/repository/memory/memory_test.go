@@ -0,0 +1,9 @@
+package memory_test
+
+import (
+	"testing"
+
+	"admin-portal/internal/auth-module/repository/repotest"
+)
+
+func TestMemoryRepos(t *testing.T) { repotest.Run(t, repotest.Memory) }
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type oauthClientRepository struct {
+	mu      sync.Mutex
+	clients []model.OAuthClient
+}
+
+func NewOAuthClientRepository() repository.OAuthClientRepository {
+	return &oauthClientRepository{}
+}
+
+func (r *oauthClientRepository) Create(_ context.Context, client *model.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client.ID = newID(client.ID)
+	for _, c := range r.clients {
+		if c.ID == client.ID {
+			return ErrDuplicatedKey
+		}
+	}
+
+	if client.RedirectURIs == nil {
+		client.RedirectURIs = []string{}
+	}
+	client.CreatedAt = now(client.CreatedAt)
+	r.clients = append(r.clients, copyClient(*client))
+	return nil
+}
+
+func (r *oauthClientRepository) FindByID(_ context.Context, id string) (*model.OAuthClient, error) {
+	cid, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.clients {
+		if c.ID == cid {
+			c := copyClient(c)
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *oauthClientRepository) Revoke(_ context.Context, id string, at time.Time) error {
+	cid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.clients {
+		if r.clients[i].ID == cid && r.clients[i].RevokedAt == nil {
+			r.clients[i].RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *oauthClientRepository) List(_ context.Context, f repository.OAuthClientFilter) ([]*model.OAuthClient, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var clients []*model.OAuthClient
+	for _, c := range r.clients {
+		if !f.IncludeRevoked && c.RevokedAt != nil {
+			continue
+		}
+		c := copyClient(c)
+		clients = append(clients, &c)
+	}
+
+	sort.SliceStable(clients, func(i, j int) bool { return clients[i].CreatedAt.After(clients[j].CreatedAt) })
+	return page(clients, f.Limit, f.Offset), int64(len(clients)), nil
+}
+
+// copyClient detaches the redirect URIs so callers cannot change stored
+// clients.
+func copyClient(c model.OAuthClient) model.OAuthClient {
+	c.RedirectURIs = slices.Clone(c.RedirectURIs)
+	return c
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type oauthGrantRepository struct {
+	mu     sync.Mutex
+	codes  map[string]model.OAuthAuthorizationCode
+	tokens []model.OAuthRefreshToken
+}
+
+func NewOAuthGrantRepository() repository.OAuthGrantRepository {
+	return &oauthGrantRepository{codes: map[string]model.OAuthAuthorizationCode{}}
+}
+
+func (r *oauthGrantRepository) CreateCode(_ context.Context, code *model.OAuthAuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.codes[code.CodeHash]; ok {
+		return ErrDuplicatedKey
+	}
+
+	code.CreatedAt = now(code.CreatedAt)
+	r.codes[code.CodeHash] = *code
+	return nil
+}
+
+func (r *oauthGrantRepository) ConsumeCode(_ context.Context, codeHash string) (*model.OAuthAuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.codes[codeHash]
+	if !ok || !c.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(r.codes, codeHash)
+	return &c, nil
+}
+
+func (r *oauthGrantRepository) CreateRefreshToken(_ context.Context, token *model.OAuthRefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = newID(token.ID)
+	for _, t := range r.tokens {
+		if t.ID == token.ID || t.TokenHash == token.TokenHash {
+			return ErrDuplicatedKey
+		}
+	}
+
+	token.CreatedAt = now(token.CreatedAt)
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+func (r *oauthGrantRepository) ConsumeRefreshToken(_ context.Context, tokenHash string, at time.Time) (*model.OAuthRefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.tokens {
+		t := &r.tokens[i]
+		if t.TokenHash == tokenHash && t.RevokedAt == nil && t.ExpiresAt.After(at) {
+			t.RevokedAt = &at
+			consumed := *t
+			return &consumed, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *oauthGrantRepository) RevokeRefreshToken(_ context.Context, tokenHash, clientID string, at time.Time) error {
+	cid, err := parseID(clientID)
+	if err != nil {
+		return err
+	}
+
+	r.revoke(func(t *model.OAuthRefreshToken) bool {
+		return t.TokenHash == tokenHash && t.ClientID == cid
+	}, at)
+	return nil
+}
+
+func (r *oauthGrantRepository) RevokeClientTokens(_ context.Context, clientID string, at time.Time) error {
+	cid, err := parseID(clientID)
+	if err != nil {
+		return err
+	}
+
+	r.revoke(func(t *model.OAuthRefreshToken) bool { return t.ClientID == cid }, at)
+	return nil
+}
+
+func (r *oauthGrantRepository) revoke(match func(*model.OAuthRefreshToken) bool, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.tokens {
+		if t := &r.tokens[i]; match(t) && t.RevokedAt == nil {
+			t.RevokedAt = &at
+		}
+	}
+}
+
+func (r *oauthGrantRepository) DeleteExpired(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var codes int64
+	for key, c := range r.codes {
+		if codes >= int64(limit) {
+			break
+		}
+		if c.ExpiresAt.Before(before) {
+			delete(r.codes, key)
+			codes++
+		}
+	}
+
+	var tokens int64
+	kept := r.tokens[:0]
+	for _, t := range r.tokens {
+		stale := t.ExpiresAt.Before(before) || (t.RevokedAt != nil && t.RevokedAt.Before(before))
+		if stale && tokens < int64(limit) {
+			tokens++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	r.tokens = kept
+
+	return codes + tokens, nil
+}
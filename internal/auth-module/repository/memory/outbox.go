@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"admin-portal/internal/shared/outbox"
+)
+
+// Outbox keeps recorded events so tests can check what a service emits.
+type Outbox struct {
+	mu     sync.Mutex
+	events []outbox.Event
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+func (o *Outbox) Record(_ context.Context, eventType, aggregateType, aggregateID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	o.events = append(o.events, outbox.Event{
+		ID:            uuid.New(),
+		Seq:           int64(len(o.events) + 1),
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	})
+	return nil
+}
+
+// Events returns the recorded events in order.
+func (o *Outbox) Events() []outbox.Event {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]outbox.Event(nil), o.events...)
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type passwordRepository struct {
+	mu        sync.Mutex
+	passwords []model.PasswordMaster
+}
+
+func NewPasswordRepository() repository.PasswordRepository {
+	return &passwordRepository{}
+}
+
+func (r *passwordRepository) Create(_ context.Context, password *model.PasswordMaster) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	password.ID = newID(password.ID)
+	for _, p := range r.passwords {
+		if p.ID == password.ID {
+			return ErrDuplicatedKey
+		}
+	}
+
+	// Like is_active on users, a false IsActive is replaced by the default.
+	password.IsActive = true
+	password.CreatedAt = now(password.CreatedAt)
+	password.UpdatedAt = now(password.UpdatedAt)
+
+	p := *password
+	p.User = model.User{}
+	r.passwords = append(r.passwords, p)
+	return nil
+}
+
+func (r *passwordRepository) DeactivateAllForUser(_ context.Context, userID string) error {
+	uid, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.passwords {
+		if r.passwords[i].UserID == uid && r.passwords[i].IsActive {
+			r.passwords[i].IsActive = false
+			r.passwords[i].UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (r *passwordRepository) FindActiveByUserID(_ context.Context, userID string) (*model.PasswordMaster, error) {
+	uid, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.passwords {
+		if p.UserID == uid && p.IsActive {
+			return &p, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
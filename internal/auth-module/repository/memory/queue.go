@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"admin-portal/internal/shared/queue"
+)
+
+// Queue keeps queued jobs so tests can check what a service queues. No
+// worker runs them, so they stay pending and keep their unique keys.
+type Queue struct {
+	mu   sync.Mutex
+	jobs []queue.Job
+}
+
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+func (q *Queue) Enqueue(_ context.Context, kind string, payload interface{}, opts ...queue.Option) (*queue.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &queue.Job{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     data,
+		Status:      queue.StatusPending,
+		MaxAttempts: queue.DefaultMaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if job.UniqueKey != nil {
+		for _, j := range q.jobs {
+			if j.UniqueKey != nil && *j.UniqueKey == *job.UniqueKey {
+				return nil, queue.ErrDuplicate
+			}
+		}
+	}
+
+	q.jobs = append(q.jobs, *job)
+	return job, nil
+}
+
+// Jobs returns the queued jobs in order.
+func (q *Queue) Jobs() []queue.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]queue.Job(nil), q.jobs...)
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type ssoStateRepository struct {
+	mu     sync.Mutex
+	states map[string]model.SSOLoginState
+}
+
+func NewSSOStateRepository() repository.SSOStateRepository {
+	return &ssoStateRepository{states: map[string]model.SSOLoginState{}}
+}
+
+func (r *ssoStateRepository) Create(_ context.Context, state *model.SSOLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.states[state.State]; ok {
+		return ErrDuplicatedKey
+	}
+
+	state.CreatedAt = now(state.CreatedAt)
+	r.states[state.State] = *state
+	return nil
+}
+
+func (r *ssoStateRepository) Consume(_ context.Context, state string) (*model.SSOLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.states[state]
+	if !ok || !s.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(r.states, state)
+	return &s, nil
+}
+
+func (r *ssoStateRepository) DeleteExpired(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, s := range r.states {
+		if deleted >= int64(limit) {
+			break
+		}
+		if s.ExpiresAt.Before(before) {
+			delete(r.states, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
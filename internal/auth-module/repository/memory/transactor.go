@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"context"
+
+	"admin-portal/internal/shared/database"
+)
+
+type transactor struct{}
+
+// NewTransactor returns a Transactor that runs fn straight away. Nothing
+// is rolled back when fn fails.
+func NewTransactor() database.Transactor {
+	return transactor{}
+}
+
+func (transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
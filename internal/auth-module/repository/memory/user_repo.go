@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type userRepository struct {
+	mu    sync.Mutex
+	users map[string]model.User
+}
+
+func NewUserRepository() repository.UserRepository {
+	return &userRepository{users: map[string]model.User{}}
+}
+
+func (r *userRepository) Create(_ context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.ID = newID(user.ID)
+	if _, ok := r.users[user.ID.String()]; ok || r.taken(user, "") {
+		return ErrDuplicatedKey
+	}
+
+	// GORM omits zero fields that have a column default, so false
+	// becomes is_active's default of true.
+	user.IsActive = true
+	user.CreatedAt = now(user.CreatedAt)
+	user.UpdatedAt = now(user.UpdatedAt)
+
+	r.users[user.ID.String()] = copyUser(user)
+	return nil
+}
+
+func (r *userRepository) FindByID(_ context.Context, id string) (*model.User, error) {
+	uid, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[uid.String()]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(_ context.Context, username string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *userRepository) FindByEmail(_ context.Context, email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email != nil && *user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// Update saves every field, inserting the user if it does not exist yet,
+// like GORM's Save.
+func (r *userRepository) Update(_ context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.ID = newID(user.ID)
+	if r.taken(user, user.ID.String()) {
+		return ErrDuplicatedKey
+	}
+
+	user.CreatedAt = now(user.CreatedAt)
+	user.UpdatedAt = time.Now()
+
+	r.users[user.ID.String()] = copyUser(user)
+	return nil
+}
+
+func (r *userRepository) FindPendingActivation(_ context.Context, createdBefore time.Time, limit int) ([]*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var users []*model.User
+	for _, user := range r.users {
+		if user.IsActive && !user.IsActivated && user.ActivationReminderSentAt == nil && user.CreatedAt.Before(createdBefore) {
+			u := user
+			users = append(users, &u)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	if limit >= 0 && len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *userRepository) MarkActivationReminded(_ context.Context, id string, at time.Time) error {
+	uid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[uid.String()]; ok {
+		user.ActivationReminderSentAt = &at
+		user.UpdatedAt = time.Now()
+		r.users[uid.String()] = user
+	}
+	return nil
+}
+
+func (r *userRepository) MarkActivationSent(_ context.Context, id string, at, sentBefore time.Time) error {
+	return r.updateWhere(id, func(user *model.User) bool {
+		if user.IsActivated || (user.ActivationSentAt != nil && !user.ActivationSentAt.Before(sentBefore)) {
+			return false
+		}
+		user.ActivationSentAt = &at
+		return true
+	})
+}
+
+func (r *userRepository) SetActivationToken(_ context.Context, id, tokenID string) error {
+	tid, err := parseID(tokenID)
+	if err != nil {
+		return err
+	}
+
+	err = r.updateWhere(id, func(user *model.User) bool {
+		user.ActivationTokenID = &tid
+		return true
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (r *userRepository) ConsumeActivationToken(_ context.Context, id, tokenID string) error {
+	tid, err := parseID(tokenID)
+	if err != nil {
+		return err
+	}
+
+	return r.updateWhere(id, func(user *model.User) bool {
+		if user.IsActivated || user.ActivationTokenID == nil || *user.ActivationTokenID != tid {
+			return false
+		}
+		user.IsActivated = true
+		user.ActivationTokenID = nil
+		return true
+	})
+}
+
+func (r *userRepository) List(_ context.Context, f repository.UserFilter) ([]*model.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := strings.ToLower(f.Query)
+
+	var users []*model.User
+	for _, user := range r.users {
+		if f.Role != "" && user.Role != f.Role {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(user.Username), query) {
+			continue
+		}
+		u := user
+		users = append(users, &u)
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return page(users, f.Limit, f.Offset), int64(len(users)), nil
+}
+
+// updateWhere applies update to the user with id and saves it if update
+// reports a change. Like a conditional UPDATE, it returns
+// gorm.ErrRecordNotFound when no row changed.
+func (r *userRepository) updateWhere(id string, update func(user *model.User) bool) error {
+	uid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[uid.String()]
+	if !ok || !update(&user) {
+		return gorm.ErrRecordNotFound
+	}
+
+	user.UpdatedAt = time.Now()
+	r.users[uid.String()] = user
+	return nil
+}
+
+// taken reports whether another user has the username or email of user.
+func (r *userRepository) taken(user *model.User, exceptID string) bool {
+	for id, other := range r.users {
+		if id == exceptID {
+			continue
+		}
+		if other.Username == user.Username {
+			return true
+		}
+		if user.Email != nil && other.Email != nil && *other.Email == *user.Email {
+			return true
+		}
+	}
+	return false
+}
+
+// copyUser drops relations, which the repositories never load.
+func copyUser(user *model.User) model.User {
+	u := *user
+	u.Passwords = nil
+	u.LoginLogs = nil
+	return u
+}
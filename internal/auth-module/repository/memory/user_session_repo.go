@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type userSessionRepository struct {
+	mu       sync.Mutex
+	sessions []model.UserSession
+}
+
+func NewUserSessionRepository() repository.UserSessionRepository {
+	return &userSessionRepository{}
+}
+
+func (r *userSessionRepository) Create(_ context.Context, token *model.UserSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = newID(token.ID)
+	for _, s := range r.sessions {
+		if s.ID == token.ID || s.Token == token.Token {
+			return ErrDuplicatedKey
+		}
+	}
+
+	token.CreatedAt = now(token.CreatedAt)
+	r.sessions = append(r.sessions, *token)
+	return nil
+}
+
+func (r *userSessionRepository) FindValid(_ context.Context, token string) (*model.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, s := range r.sessions {
+		if s.Token == token && !s.IsRevoked && s.ExpiresAt.After(now) {
+			return &s, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *userSessionRepository) Revoke(_ context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.sessions {
+		if r.sessions[i].Token == token {
+			revoke(&r.sessions[i])
+		}
+	}
+	return nil
+}
+
+func (r *userSessionRepository) RevokeAllForUser(_ context.Context, userID string) error {
+	uid, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.sessions {
+		if r.sessions[i].UserID == uid {
+			revoke(&r.sessions[i])
+		}
+	}
+	return nil
+}
+
+// revoke marks s revoked, keeping the time of the first revocation.
+func revoke(s *model.UserSession) {
+	if s.IsRevoked {
+		return
+	}
+	at := time.Now()
+	s.IsRevoked = true
+	s.RevokedAt = &at
+}
+
+func (r *userSessionRepository) DeleteStale(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	kept := r.sessions[:0]
+	for _, s := range r.sessions {
+		stale := s.ExpiresAt.Before(before) || (s.RevokedAt != nil && s.RevokedAt.Before(before))
+		if stale && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	r.sessions = kept
+
+	return deleted, nil
+}
+
+func (r *userSessionRepository) Consume(_ context.Context, token string) (*model.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i := range r.sessions {
+		s := &r.sessions[i]
+		if s.Token == token && !s.IsRevoked && s.ExpiresAt.After(now) {
+			revoke(s)
+			consumed := *s
+			return &consumed, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *userSessionRepository) List(_ context.Context, f repository.SessionFilter) ([]*model.UserSession, int64, error) {
+	uid, err := parseID(f.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	var sessions []*model.UserSession
+	for _, s := range r.sessions {
+		if s.UserID != uid {
+			continue
+		}
+		if !f.IncludeInactive && (s.IsRevoked || !s.ExpiresAt.After(now)) {
+			continue
+		}
+		s := s
+		sessions = append(sessions, &s)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	return page(sessions, f.Limit, f.Offset), int64(len(sessions)), nil
+}
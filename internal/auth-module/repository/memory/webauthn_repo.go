@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+type webAuthnRepository struct {
+	mu         sync.Mutex
+	creds      []model.WebAuthnCredential
+	challenges map[string]model.WebAuthnChallenge
+}
+
+func NewWebAuthnRepository() repository.WebAuthnRepository {
+	return &webAuthnRepository{challenges: map[string]model.WebAuthnChallenge{}}
+}
+
+func (r *webAuthnRepository) CreateCredential(_ context.Context, cred *model.WebAuthnCredential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cred.ID = newID(cred.ID)
+	for _, c := range r.creds {
+		if c.ID == cred.ID || bytes.Equal(c.CredentialID, cred.CredentialID) {
+			return ErrDuplicatedKey
+		}
+	}
+
+	if cred.Transports == nil {
+		cred.Transports = []string{}
+	}
+	cred.CreatedAt = now(cred.CreatedAt)
+	r.creds = append(r.creds, copyCredential(*cred))
+	return nil
+}
+
+func (r *webAuthnRepository) FindCredential(_ context.Context, credentialID []byte) (*model.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.creds {
+		if bytes.Equal(c.CredentialID, credentialID) {
+			c := copyCredential(c)
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *webAuthnRepository) ListCredentials(_ context.Context, userID string) ([]*model.WebAuthnCredential, error) {
+	uid, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var creds []*model.WebAuthnCredential
+	for _, c := range r.creds {
+		if c.UserID == uid {
+			c := copyCredential(c)
+			creds = append(creds, &c)
+		}
+	}
+
+	sort.SliceStable(creds, func(i, j int) bool { return creds[i].CreatedAt.Before(creds[j].CreatedAt) })
+	return creds, nil
+}
+
+func (r *webAuthnRepository) RecordUse(_ context.Context, id string, signCount int64, at time.Time) error {
+	cid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.creds {
+		c := &r.creds[i]
+		if c.ID != cid || !(c.SignCount < signCount || (c.SignCount == 0 && signCount == 0)) {
+			continue
+		}
+		c.SignCount = signCount
+		c.LastUsedAt = &at
+		return nil
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *webAuthnRepository) DeleteCredential(_ context.Context, id, userID string) error {
+	cid, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	uid, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.creds {
+		if c.ID == cid && c.UserID == uid {
+			r.creds = slices.Delete(r.creds, i, i+1)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *webAuthnRepository) CreateChallenge(_ context.Context, challenge *model.WebAuthnChallenge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.challenges[challenge.Challenge]; ok {
+		return ErrDuplicatedKey
+	}
+
+	challenge.CreatedAt = now(challenge.CreatedAt)
+	r.challenges[challenge.Challenge] = *challenge
+	return nil
+}
+
+func (r *webAuthnRepository) ConsumeChallenge(_ context.Context, challenge string) (*model.WebAuthnChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.challenges[challenge]
+	if !ok || !c.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(r.challenges, challenge)
+	return &c, nil
+}
+
+func (r *webAuthnRepository) DeleteExpiredChallenges(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, c := range r.challenges {
+		if deleted >= int64(limit) {
+			break
+		}
+		if c.ExpiresAt.Before(before) {
+			delete(r.challenges, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// copyCredential detaches the byte slices and transports so callers
+// cannot change stored credentials.
+func copyCredential(c model.WebAuthnCredential) model.WebAuthnCredential {
+	c.CredentialID = slices.Clone(c.CredentialID)
+	c.PublicKey = slices.Clone(c.PublicKey)
+	c.AAGUID = slices.Clone(c.AAGUID)
+	c.Transports = slices.Clone(c.Transports)
+	return c
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *model.OAuthClient) error
+	FindByID(ctx context.Context, id string) (*model.OAuthClient, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	List(ctx context.Context, f OAuthClientFilter) ([]*model.OAuthClient, int64, error)
+}
+
+type OAuthClientFilter struct {
+	IncludeRevoked bool
+	Limit          int
+	Offset         int
+}
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *model.OAuthClient) error {
+	return database.Conn(ctx, r.db).Create(client).Error
+}
+
+// FindByID returns the client whether or not it is revoked.
+func (r *oauthClientRepository) FindByID(ctx context.Context, id string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := database.Conn(ctx, r.db).First(&client, "id = ?", id).Error
+	return &client, err
+}
+
+// Revoke marks a client revoked. Revoking it again keeps the first time.
+func (r *oauthClientRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.OAuthClient{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// List returns a page of clients, newest first, and the total.
+func (r *oauthClientRepository) List(ctx context.Context, f OAuthClientFilter) ([]*model.OAuthClient, int64, error) {
+	q := database.Conn(ctx, r.db).Model(&model.OAuthClient{})
+	if !f.IncludeRevoked {
+		q = q.Where("revoked_at IS NULL")
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var clients []*model.OAuthClient
+	err := q.Order("created_at DESC, id").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&clients).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return clients, total, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
+)
+
+// OAuthGrantRepository stores what the OAuth token endpoint redeems:
+// authorization codes and refresh tokens.
+type OAuthGrantRepository interface {
+	CreateCode(ctx context.Context, code *model.OAuthAuthorizationCode) error
+	ConsumeCode(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error)
+
+	CreateRefreshToken(ctx context.Context, token *model.OAuthRefreshToken) error
+	ConsumeRefreshToken(ctx context.Context, tokenHash string, at time.Time) (*model.OAuthRefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tokenHash, clientID string, at time.Time) error
+	RevokeClientTokens(ctx context.Context, clientID string, at time.Time) error
+
+	DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+type oauthGrantRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthGrantRepository(db *gorm.DB) OAuthGrantRepository {
+	return &oauthGrantRepository{db: db}
+}
+
+func (r *oauthGrantRepository) CreateCode(ctx context.Context, code *model.OAuthAuthorizationCode) error {
+	return database.Conn(ctx, r.db).Create(code).Error
+}
+
+// ConsumeCode deletes an unexpired code and returns it, so each code is
+// redeemed once. It returns gorm.ErrRecordNotFound otherwise.
+func (r *oauthGrantRepository) ConsumeCode(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error) {
+	var codes []*model.OAuthAuthorizationCode
+	res := database.Conn(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("code_hash = ? AND expires_at > ?", codeHash, time.Now()).
+		Delete(&codes)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(codes) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return codes[0], nil
+}
+
+func (r *oauthGrantRepository) CreateRefreshToken(ctx context.Context, token *model.OAuthRefreshToken) error {
+	return database.Conn(ctx, r.db).Create(token).Error
+}
+
+// ConsumeRefreshToken revokes a valid token and returns it, so that
+// concurrent refreshes with the same token cannot both succeed. It
+// returns gorm.ErrRecordNotFound for unknown, revoked or expired tokens.
+func (r *oauthGrantRepository) ConsumeRefreshToken(ctx context.Context, tokenHash string, at time.Time) (*model.OAuthRefreshToken, error) {
+	var tokens []*model.OAuthRefreshToken
+	res := database.Conn(ctx, r.db).
+		Model(&tokens).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", tokenHash, at).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(tokens) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return tokens[0], nil
+}
+
+// RevokeRefreshToken revokes the token if the client holds it. Unknown
+// tokens are not an error, as RFC 7009 requires.
+func (r *oauthGrantRepository) RevokeRefreshToken(ctx context.Context, tokenHash, clientID string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.OAuthRefreshToken{}).
+		Where("token_hash = ? AND client_id = ? AND revoked_at IS NULL", tokenHash, clientID).
+		Update("revoked_at", at).Error
+}
+
+func (r *oauthGrantRepository) RevokeClientTokens(ctx context.Context, clientID string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.OAuthRefreshToken{}).
+		Where("client_id = ? AND revoked_at IS NULL", clientID).
+		Update("revoked_at", at).Error
+}
+
+// DeleteExpired removes up to limit codes and up to limit refresh tokens
+// that expired, or for tokens were revoked, before the given time.
+func (r *oauthGrantRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	codes := conn.Exec(`
+		DELETE FROM oauth_authorization_codes
+		WHERE code_hash IN (
+			SELECT code_hash FROM oauth_authorization_codes
+			WHERE expires_at < ?
+			LIMIT ?
+		)`, before, limit)
+	if codes.Error != nil {
+		return 0, codes.Error
+	}
+
+	tokens := conn.Exec(`
+		DELETE FROM oauth_refresh_tokens
+		WHERE id IN (
+			SELECT id FROM oauth_refresh_tokens
+			WHERE expires_at < ? OR revoked_at < ?
+			LIMIT ?
+		)`, before, before, limit)
+	return codes.RowsAffected + tokens.RowsAffected, tokens.Error
+}
@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 
 	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
 )
 
 type PasswordRepository interface {
@@ -23,11 +24,11 @@ func NewPasswordRepository(db *gorm.DB) PasswordRepository {
 }
 
 func (r *passwordRepository) Create(ctx context.Context, password *model.PasswordMaster) error {
-	return r.db.WithContext(ctx).Create(password).Error
+	return database.Conn(ctx, r.db).Create(password).Error
 }
 
 func (r *passwordRepository) DeactivateAllForUser(ctx context.Context, userID string) error {
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&model.PasswordMaster{}).
 		Where("user_id = ? AND is_active = TRUE", userID).
 		Update("is_active", false).Error
@@ -35,7 +36,7 @@ func (r *passwordRepository) DeactivateAllForUser(ctx context.Context, userID st
 
 func (r *passwordRepository) FindActiveByUserID(ctx context.Context, userID string) (*model.PasswordMaster, error) {
 	var password model.PasswordMaster
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("user_id = ? AND is_active = TRUE", userID).
 		First(&password).Error
 
@@ -0,0 +1,13 @@
+package repository_test
+
+import (
+	"testing"
+
+	"admin-portal/internal/auth-module/repository/repotest"
+	"admin-portal/internal/testharness"
+)
+
+func TestMain(m *testing.M) { testharness.Main(m) }
+
+// TestGORMRepos skips itself when no Postgres installation is available.
+func TestGORMRepos(t *testing.T) { repotest.Run(t, repotest.GORM) }
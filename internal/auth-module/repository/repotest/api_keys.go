@@ -0,0 +1,112 @@
+package repotest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+var apiKeyCases = map[string]func(t *testing.T, r Repos){
+	"FindByPrefix": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		key := &model.APIKey{UserID: user.ID, Name: "ci", Prefix: "p1", KeyHash: "h1", Scopes: []string{model.ScopeAll}}
+		must(t, r.APIKeys.Create(ctx, key))
+
+		got, err := r.APIKeys.FindByPrefix(ctx, "p1")
+		must(t, err)
+		if got.ID != key.ID || got.KeyHash != "h1" || !slices.Equal(got.Scopes, []string{model.ScopeAll}) {
+			t.Errorf("found %+v", got)
+		}
+		if got.CreatedAt.IsZero() || got.RevokedAt != nil || got.LastUsedAt != nil {
+			t.Errorf("defaults not applied: %+v", got)
+		}
+
+		byID, err := r.APIKeys.FindByID(ctx, key.ID.String())
+		must(t, err)
+		if byID.Prefix != "p1" {
+			t.Errorf("FindByID found %+v", byID)
+		}
+
+		_, err = r.APIKeys.FindByPrefix(ctx, "unknown")
+		wantNotFound(t, err)
+	},
+
+	"PrefixUnique": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		must(t, r.APIKeys.Create(ctx, &model.APIKey{UserID: user.ID, Name: "a", Prefix: "p", KeyHash: "h1"}))
+		if err := r.APIKeys.Create(ctx, &model.APIKey{UserID: user.ID, Name: "b", Prefix: "p", KeyHash: "h2"}); err == nil {
+			t.Fatal("duplicate prefix was accepted")
+		}
+	},
+
+	"RevokeAndTouch": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		key := &model.APIKey{UserID: user.ID, Name: "ci", Prefix: "p", KeyHash: "h"}
+		must(t, r.APIKeys.Create(ctx, key))
+
+		used := time.Now().Add(-time.Minute).Truncate(time.Microsecond)
+		must(t, r.APIKeys.TouchLastUsed(ctx, key.ID.String(), used))
+
+		first := time.Now().Truncate(time.Microsecond)
+		must(t, r.APIKeys.Revoke(ctx, key.ID.String(), first))
+		must(t, r.APIKeys.Revoke(ctx, key.ID.String(), first.Add(time.Hour)))
+
+		got, err := r.APIKeys.FindByPrefix(ctx, "p")
+		must(t, err)
+		if got.LastUsedAt == nil || !got.LastUsedAt.Equal(used) {
+			t.Errorf("last used = %v, want %v", got.LastUsedAt, used)
+		}
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(first) {
+			t.Errorf("revoked at = %v, want the first revocation %v", got.RevokedAt, first)
+		}
+		if got.Usable(time.Now()) {
+			t.Error("revoked key is usable")
+		}
+	},
+
+	"List": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+		now := time.Now()
+		past := now.Add(-time.Minute)
+
+		for _, k := range []*model.APIKey{
+			{UserID: alice.ID, Name: "old", Prefix: "a1", KeyHash: "h", CreatedAt: now.Add(-2 * time.Hour)},
+			{UserID: alice.ID, Name: "new", Prefix: "a2", KeyHash: "h", CreatedAt: now.Add(-time.Hour)},
+			{UserID: alice.ID, Name: "revoked", Prefix: "a3", KeyHash: "h", CreatedAt: now.Add(-3 * time.Hour)},
+			{UserID: alice.ID, Name: "expired", Prefix: "a4", KeyHash: "h", CreatedAt: now.Add(-4 * time.Hour), ExpiresAt: &past},
+			{UserID: bob.ID, Name: "bob", Prefix: "b1", KeyHash: "h"},
+		} {
+			must(t, r.APIKeys.Create(ctx, k))
+		}
+		revoked, err := r.APIKeys.FindByPrefix(ctx, "a3")
+		must(t, err)
+		must(t, r.APIKeys.Revoke(ctx, revoked.ID.String(), now))
+
+		got, total, err := r.APIKeys.List(ctx, repository.APIKeyFilter{UserID: alice.ID.String(), Limit: 10})
+		must(t, err)
+		if total != 2 || len(got) != 2 || got[0].Name != "new" || got[1].Name != "old" {
+			t.Errorf("active keys = %v of %d, want [new old] of 2", keyNames(got), total)
+		}
+
+		got, total, err = r.APIKeys.List(ctx, repository.APIKeyFilter{UserID: alice.ID.String(), IncludeInactive: true, Limit: 2, Offset: 2})
+		must(t, err)
+		if total != 4 || len(got) != 2 || got[0].Name != "revoked" || got[1].Name != "expired" {
+			t.Errorf("all keys page 2 = %v of %d, want [revoked expired] of 4", keyNames(got), total)
+		}
+	},
+}
+
+func keyNames(keys []*model.APIKey) []string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = k.Name
+	}
+	return out
+}
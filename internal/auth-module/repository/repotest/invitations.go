@@ -0,0 +1,157 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+var invitationCases = map[string]func(t *testing.T, r Repos){
+	"CreateAndFind": func(t *testing.T, r Repos) {
+		admin := createUser(t, r, "admin")
+		inv := &model.Invitation{
+			Email:     "jo@example.com",
+			Role:      model.RoleAdmin,
+			InvitedBy: &admin.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		must(t, r.Invitations.Create(ctx, inv))
+		if inv.ID == uuid.Nil || inv.CreatedAt.IsZero() {
+			t.Fatal("Create did not set the ID and created_at")
+		}
+
+		got, err := r.Invitations.FindByID(ctx, inv.ID.String())
+		must(t, err)
+		if got.Email != inv.Email || got.Role != model.RoleAdmin || got.InvitedBy == nil || *got.InvitedBy != admin.ID || got.TokenHash != nil {
+			t.Errorf("found %+v, want %+v", got, inv)
+		}
+
+		got, err = r.Invitations.FindPendingByEmail(ctx, "jo@example.com", time.Now())
+		must(t, err)
+		if got.ID != inv.ID {
+			t.Errorf("FindPendingByEmail = %s, want %s", got.ID, inv.ID)
+		}
+
+		_, err = r.Invitations.FindPendingByEmail(ctx, "jo@example.com", time.Now().Add(2*time.Hour))
+		wantNotFound(t, err)
+
+		_, err = r.Invitations.FindByID(ctx, uuid.NewString())
+		wantNotFound(t, err)
+	},
+
+	"TokenHash": func(t *testing.T, r Repos) {
+		inv := createInvitation(t, r, "jo@example.com", time.Hour)
+		id := inv.ID.String()
+
+		// Not findable before its email is sent.
+		_, err := r.Invitations.FindPendingByTokenHash(ctx, "first", time.Now())
+		wantNotFound(t, err)
+
+		must(t, r.Invitations.SetTokenHash(ctx, id, "first"))
+		must(t, r.Invitations.SetTokenHash(ctx, id, "second"))
+
+		_, err = r.Invitations.FindPendingByTokenHash(ctx, "first", time.Now())
+		wantNotFound(t, err)
+
+		got, err := r.Invitations.FindPendingByTokenHash(ctx, "second", time.Now())
+		must(t, err)
+		if got.ID != inv.ID {
+			t.Errorf("FindPendingByTokenHash = %s, want %s", got.ID, inv.ID)
+		}
+
+		_, err = r.Invitations.FindPendingByTokenHash(ctx, "second", time.Now().Add(2*time.Hour))
+		wantNotFound(t, err)
+	},
+
+	"AcceptOnce": func(t *testing.T, r Repos) {
+		inv := createInvitation(t, r, "jo@example.com", time.Hour)
+		id := inv.ID.String()
+		jo := createUser(t, r, "jo")
+
+		must(t, r.Invitations.MarkAccepted(ctx, id, jo.ID.String(), time.Now()))
+		wantNotFound(t, r.Invitations.MarkAccepted(ctx, id, jo.ID.String(), time.Now()))
+
+		got, err := r.Invitations.FindByID(ctx, id)
+		must(t, err)
+		if got.AcceptedAt == nil || got.AcceptedUserID == nil || *got.AcceptedUserID != jo.ID {
+			t.Errorf("accepted_at=%v accepted_user_id=%v, want set to jo", got.AcceptedAt, got.AcceptedUserID)
+		}
+
+		// Accepted invitations stay accepted.
+		must(t, r.Invitations.Revoke(ctx, id, time.Now()))
+		got, err = r.Invitations.FindByID(ctx, id)
+		must(t, err)
+		if got.RevokedAt != nil {
+			t.Error("accepted invitation was revoked")
+		}
+
+		expired := createInvitation(t, r, "old@example.com", -time.Minute)
+		wantNotFound(t, r.Invitations.MarkAccepted(ctx, expired.ID.String(), jo.ID.String(), time.Now()))
+	},
+
+	"Revoke": func(t *testing.T, r Repos) {
+		inv := createInvitation(t, r, "jo@example.com", time.Hour)
+		id := inv.ID.String()
+		must(t, r.Invitations.SetTokenHash(ctx, id, "hash"))
+
+		first := time.Now().Add(-time.Minute).Truncate(time.Microsecond)
+		must(t, r.Invitations.Revoke(ctx, id, first))
+		must(t, r.Invitations.Revoke(ctx, id, time.Now()))
+
+		got, err := r.Invitations.FindByID(ctx, id)
+		must(t, err)
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(first) {
+			t.Errorf("revoked_at = %v, want %v", got.RevokedAt, first)
+		}
+
+		_, err = r.Invitations.FindPendingByTokenHash(ctx, "hash", time.Now())
+		wantNotFound(t, err)
+		wantNotFound(t, r.Invitations.MarkAccepted(ctx, id, createUser(t, r, "jo").ID.String(), time.Now()))
+	},
+
+	"List": func(t *testing.T, r Repos) {
+		old := createInvitation(t, r, "old@example.com", time.Hour)
+		createInvitation(t, r, "expired@example.com", -time.Minute)
+		revoked := createInvitation(t, r, "revoked@example.com", time.Hour)
+		must(t, r.Invitations.Revoke(ctx, revoked.ID.String(), time.Now()))
+		recent := &model.Invitation{
+			Email:     "recent@example.com",
+			Role:      model.RoleUser,
+			ExpiresAt: time.Now().Add(time.Hour),
+			CreatedAt: time.Now().Add(time.Minute),
+		}
+		must(t, r.Invitations.Create(ctx, recent))
+
+		got, total, err := r.Invitations.List(ctx, repository.InvitationFilter{Limit: 10})
+		must(t, err)
+		if total != 2 || len(got) != 2 || got[0].ID != recent.ID || got[1].ID != old.ID {
+			t.Errorf("pending = %v of %d, want [recent old] of 2", invitationEmails(got), total)
+		}
+
+		got, total, err = r.Invitations.List(ctx, repository.InvitationFilter{IncludeClosed: true, Limit: 1})
+		must(t, err)
+		if total != 4 || len(got) != 1 || got[0].ID != recent.ID {
+			t.Errorf("all, first page = %v of %d, want [recent] of 4", invitationEmails(got), total)
+		}
+	},
+}
+
+func createInvitation(t *testing.T, r Repos, email string, ttl time.Duration) *model.Invitation {
+	t.Helper()
+
+	inv := &model.Invitation{Email: email, Role: model.RoleUser, ExpiresAt: time.Now().Add(ttl)}
+	must(t, r.Invitations.Create(ctx, inv))
+	return inv
+}
+
+func invitationEmails(invitations []*model.Invitation) []string {
+	emails := make([]string, len(invitations))
+	for i, inv := range invitations {
+		emails[i] = inv.Email
+	}
+	return emails
+}
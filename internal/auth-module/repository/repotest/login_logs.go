@@ -0,0 +1,163 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+func loginLog(userID *uuid.UUID, logType, ip string, at time.Time) *model.LoginLog {
+	l := &model.LoginLog{UserID: userID, Message: logType, LogType: logType, CreatedAt: at}
+	if ip != "" {
+		l.IPAddress = &ip
+	}
+	return l
+}
+
+var loginLogCases = map[string]func(t *testing.T, r Repos){
+	"ByUser": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+		now := time.Now()
+
+		must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "success", "", now)))
+		must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "error", "", now)))
+		must(t, r.LoginLogs.Create(ctx, loginLog(&bob.ID, "success", "", now)))
+		must(t, r.LoginLogs.Create(ctx, loginLog(nil, "error", "10.0.0.1", now)))
+
+		logs, err := r.LoginLogs.GetAllByUserID(ctx, alice.ID.String())
+		must(t, err)
+		if len(logs) != 2 {
+			t.Errorf("alice has %d logs, want 2", len(logs))
+		}
+
+		all, err := r.LoginLogs.GetAll(ctx)
+		must(t, err)
+		if len(all) != 4 {
+			t.Errorf("GetAll returned %d logs, want 4", len(all))
+		}
+	},
+
+	"CountRecentFailures": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		now := time.Now()
+		old := now.Add(-time.Hour)
+
+		for _, l := range []*model.LoginLog{
+			loginLog(&alice.ID, "error", "10.0.0.1", now),
+			loginLog(&alice.ID, "error", "10.0.0.2", now),
+			loginLog(&alice.ID, "error", "10.0.0.1", old),
+			loginLog(&alice.ID, "success", "10.0.0.1", now),
+			loginLog(nil, "error", "10.0.0.1", now),
+			loginLog(nil, "error", "10.0.0.1", old),
+			loginLog(nil, "error", "10.0.0.9", now),
+		} {
+			must(t, r.LoginLogs.Create(ctx, l))
+		}
+
+		since := now.Add(-time.Minute)
+
+		n, err := r.LoginLogs.CountRecentFailures(ctx, alice.ID.String(), "", since)
+		must(t, err)
+		if n != 2 {
+			t.Errorf("failures for alice = %d, want 2", n)
+		}
+
+		// Without a user, only failures for unknown usernames count.
+		n, err = r.LoginLogs.CountRecentFailures(ctx, "", "10.0.0.1", since)
+		must(t, err)
+		if n != 1 {
+			t.Errorf("failures for 10.0.0.1 = %d, want 1", n)
+		}
+	},
+
+	"CountSuccesses": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		now := time.Now()
+
+		must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "success", "10.0.0.1", now)))
+		must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "success", "10.0.0.2", now)))
+		must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "error", "10.0.0.3", now)))
+		// Logged before addresses were recorded.
+		must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "success", "", now)))
+
+		n, err := r.LoginLogs.CountSuccesses(ctx, alice.ID.String(), "")
+		must(t, err)
+		if n != 2 {
+			t.Errorf("successes with an address = %d, want 2", n)
+		}
+
+		n, err = r.LoginLogs.CountSuccesses(ctx, alice.ID.String(), "10.0.0.3")
+		must(t, err)
+		if n != 0 {
+			t.Errorf("successes from 10.0.0.3 = %d, want 0", n)
+		}
+	},
+
+	"Retention": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		now := time.Now()
+
+		for i := 1; i <= 5; i++ {
+			must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "success", "", now.Add(-time.Duration(i)*time.Hour))))
+		}
+		must(t, r.LoginLogs.Create(ctx, loginLog(&alice.ID, "success", "", now)))
+
+		before := now.Add(-30 * time.Minute)
+
+		n, err := r.LoginLogs.DeleteOlderThan(ctx, before, 2)
+		must(t, err)
+		if n != 2 {
+			t.Fatalf("deleted %d, want 2", n)
+		}
+
+		n, err = r.LoginLogs.ArchiveOlderThan(ctx, before, 10)
+		must(t, err)
+		if n != 3 {
+			t.Fatalf("archived %d, want 3", n)
+		}
+
+		logs, err := r.LoginLogs.GetAll(ctx)
+		must(t, err)
+		if len(logs) != 1 || logs[0].CreatedAt.Before(before) {
+			t.Errorf("remaining logs = %d, want only the recent one", len(logs))
+		}
+	},
+
+	"List": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+		now := time.Now()
+
+		for _, l := range []*model.LoginLog{
+			loginLog(&alice.ID, "success", "", now.Add(-3*time.Hour)),
+			loginLog(&alice.ID, "error", "", now.Add(-2*time.Hour)),
+			loginLog(&alice.ID, "success", "", now.Add(-time.Hour)),
+			loginLog(&bob.ID, "success", "", now),
+		} {
+			must(t, r.LoginLogs.Create(ctx, l))
+		}
+
+		got, total, err := r.LoginLogs.List(ctx, repository.LoginLogFilter{Limit: 2})
+		must(t, err)
+		if total != 4 || len(got) != 2 || *got[0].UserID != bob.ID || got[1].LogType != "success" {
+			t.Errorf("first page has %d of %d, want newest 2 of 4", len(got), total)
+		}
+
+		got, total, err = r.LoginLogs.List(ctx, repository.LoginLogFilter{
+			UserID:  alice.ID.String(),
+			LogType: "success",
+			Since:   now.Add(-4 * time.Hour),
+			Until:   now.Add(-time.Hour),
+			Limit:   10,
+		})
+		must(t, err)
+		if total != 1 || len(got) != 1 || !got[0].CreatedAt.Before(now.Add(-2*time.Hour)) {
+			t.Errorf("filtered = %d of %d, want the 3h old success only", len(got), total)
+		}
+	},
+}
@@ -0,0 +1,175 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+var oauthClientCases = map[string]func(t *testing.T, r Repos){
+	"CreateFind": func(t *testing.T, r Repos) {
+		hash := "h"
+		client := &model.OAuthClient{Name: "wiki", SecretHash: &hash, RedirectURIs: []string{"https://wiki.example/cb"}}
+		must(t, r.OAuthClients.Create(ctx, client))
+
+		got, err := r.OAuthClients.FindByID(ctx, client.ID.String())
+		must(t, err)
+		if got.Name != "wiki" || got.Public() || len(got.RedirectURIs) != 1 || got.CreatedAt.IsZero() {
+			t.Errorf("found %+v", got)
+		}
+
+		// Callers cannot change the stored URIs through the result.
+		got.RedirectURIs[0] = "https://evil.example/"
+		again, err := r.OAuthClients.FindByID(ctx, client.ID.String())
+		must(t, err)
+		if again.RedirectURIs[0] != "https://wiki.example/cb" {
+			t.Errorf("stored URIs changed to %v", again.RedirectURIs)
+		}
+	},
+
+	"RevokeList": func(t *testing.T, r Repos) {
+		base := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+		var ids []string
+		for i, name := range []string{"a", "b", "c"} {
+			c := &model.OAuthClient{Name: name, CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+			must(t, r.OAuthClients.Create(ctx, c))
+			ids = append(ids, c.ID.String())
+		}
+
+		first := time.Now().Truncate(time.Microsecond)
+		must(t, r.OAuthClients.Revoke(ctx, ids[1], first))
+		must(t, r.OAuthClients.Revoke(ctx, ids[1], first.Add(time.Minute)))
+
+		got, err := r.OAuthClients.FindByID(ctx, ids[1])
+		must(t, err)
+		if got.RevokedAt == nil || !got.RevokedAt.Equal(first) {
+			t.Errorf("revoked_at = %v, want the first revocation %v", got.RevokedAt, first)
+		}
+
+		live, total, err := r.OAuthClients.List(ctx, repository.OAuthClientFilter{Limit: 10})
+		must(t, err)
+		if total != 2 || len(live) != 2 || live[0].Name != "c" || live[1].Name != "a" {
+			t.Errorf("live clients: total %d, %+v", total, live)
+		}
+
+		all, total, err := r.OAuthClients.List(ctx, repository.OAuthClientFilter{IncludeRevoked: true, Limit: 1, Offset: 1})
+		must(t, err)
+		if total != 3 || len(all) != 1 || all[0].Name != "b" {
+			t.Errorf("second page with revoked: total %d, %+v", total, all)
+		}
+	},
+}
+
+var oauthGrantCases = map[string]func(t *testing.T, r Repos){
+	"ConsumeCodeOnce": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		client := &model.OAuthClient{Name: "wiki"}
+		must(t, r.OAuthClients.Create(ctx, client))
+
+		code := func(hash string, expires time.Time) *model.OAuthAuthorizationCode {
+			return &model.OAuthAuthorizationCode{
+				CodeHash: hash, ClientID: client.ID, UserID: user.ID,
+				RedirectURI: "https://wiki.example/cb", Scope: "openid", CodeChallenge: "c",
+				AuthTime: time.Now(), ExpiresAt: expires,
+			}
+		}
+		must(t, r.OAuthGrants.CreateCode(ctx, code("live", time.Now().Add(time.Minute))))
+		must(t, r.OAuthGrants.CreateCode(ctx, code("expired", time.Now().Add(-time.Minute))))
+
+		got, err := r.OAuthGrants.ConsumeCode(ctx, "live")
+		must(t, err)
+		if got.UserID != user.ID || got.ClientID != client.ID || got.RedirectURI != "https://wiki.example/cb" {
+			t.Errorf("consumed %+v", got)
+		}
+
+		_, err = r.OAuthGrants.ConsumeCode(ctx, "live")
+		wantNotFound(t, err)
+
+		_, err = r.OAuthGrants.ConsumeCode(ctx, "expired")
+		wantNotFound(t, err)
+	},
+
+	"RefreshTokens": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		wiki := &model.OAuthClient{Name: "wiki"}
+		must(t, r.OAuthClients.Create(ctx, wiki))
+		blog := &model.OAuthClient{Name: "blog"}
+		must(t, r.OAuthClients.Create(ctx, blog))
+
+		token := func(hash string, client *model.OAuthClient) {
+			must(t, r.OAuthGrants.CreateRefreshToken(ctx, &model.OAuthRefreshToken{
+				TokenHash: hash, ClientID: client.ID, UserID: user.ID,
+				Scope: "openid", AuthTime: time.Now(), ExpiresAt: time.Now().Add(time.Hour),
+			}))
+		}
+		token("w1", wiki)
+		token("w2", wiki)
+		token("w3", wiki)
+		token("b1", blog)
+
+		now := time.Now()
+		got, err := r.OAuthGrants.ConsumeRefreshToken(ctx, "w1", now)
+		must(t, err)
+		if got.ClientID != wiki.ID || got.UserID != user.ID {
+			t.Errorf("consumed %+v", got)
+		}
+		_, err = r.OAuthGrants.ConsumeRefreshToken(ctx, "w1", now)
+		wantNotFound(t, err)
+
+		// Another client cannot revoke the token.
+		must(t, r.OAuthGrants.RevokeRefreshToken(ctx, "w2", blog.ID.String(), now))
+		if _, err := r.OAuthGrants.ConsumeRefreshToken(ctx, "w2", now); err != nil {
+			t.Errorf("w2 revoked by the wrong client: %v", err)
+		}
+
+		must(t, r.OAuthGrants.RevokeRefreshToken(ctx, "w3", wiki.ID.String(), now))
+		_, err = r.OAuthGrants.ConsumeRefreshToken(ctx, "w3", now)
+		wantNotFound(t, err)
+
+		must(t, r.OAuthGrants.RevokeClientTokens(ctx, blog.ID.String(), now))
+		_, err = r.OAuthGrants.ConsumeRefreshToken(ctx, "b1", now)
+		wantNotFound(t, err)
+	},
+
+	"DeleteExpired": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		client := &model.OAuthClient{Name: "wiki"}
+		must(t, r.OAuthClients.Create(ctx, client))
+
+		past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+		for _, c := range []struct {
+			hash    string
+			expires time.Time
+		}{{"c1", past}, {"c2", past}, {"live", future}} {
+			must(t, r.OAuthGrants.CreateCode(ctx, &model.OAuthAuthorizationCode{
+				CodeHash: c.hash, ClientID: client.ID, UserID: user.ID,
+				RedirectURI: "u", Scope: "openid", CodeChallenge: "c", AuthTime: past, ExpiresAt: c.expires,
+			}))
+		}
+		for _, tk := range []struct {
+			hash    string
+			expires time.Time
+		}{{"t1", past}, {"revoked", future}, {"live", future}} {
+			must(t, r.OAuthGrants.CreateRefreshToken(ctx, &model.OAuthRefreshToken{
+				TokenHash: tk.hash, ClientID: client.ID, UserID: user.ID,
+				Scope: "openid", AuthTime: past, ExpiresAt: tk.expires,
+			}))
+		}
+		must(t, r.OAuthGrants.RevokeRefreshToken(ctx, "revoked", client.ID.String(), past))
+
+		n, err := r.OAuthGrants.DeleteExpired(ctx, time.Now(), 10)
+		must(t, err)
+		if n != 4 {
+			t.Fatalf("deleted %d, want 4", n)
+		}
+
+		if _, err := r.OAuthGrants.ConsumeCode(ctx, "live"); err != nil {
+			t.Errorf("live code deleted: %v", err)
+		}
+		if _, err := r.OAuthGrants.ConsumeRefreshToken(ctx, "live", time.Now()); err != nil {
+			t.Errorf("live token deleted: %v", err)
+		}
+	},
+}
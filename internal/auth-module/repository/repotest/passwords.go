@@ -0,0 +1,49 @@
+package repotest
+
+import (
+	"testing"
+
+	"admin-portal/internal/auth-module/model"
+)
+
+var passwordCases = map[string]func(t *testing.T, r Repos){
+	"ActivePassword": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		_, err := r.Passwords.FindActiveByUserID(ctx, user.ID.String())
+		wantNotFound(t, err)
+
+		must(t, r.Passwords.Create(ctx, &model.PasswordMaster{UserID: user.ID, PasswordHash: "old", IsActive: true}))
+
+		got, err := r.Passwords.FindActiveByUserID(ctx, user.ID.String())
+		must(t, err)
+		if got.PasswordHash != "old" {
+			t.Errorf("active hash = %q, want old", got.PasswordHash)
+		}
+	},
+
+	"Rotate": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		other := createUser(t, r, "bob")
+
+		must(t, r.Passwords.Create(ctx, &model.PasswordMaster{UserID: user.ID, PasswordHash: "old", IsActive: true}))
+		must(t, r.Passwords.Create(ctx, &model.PasswordMaster{UserID: other.ID, PasswordHash: "bob", IsActive: true}))
+
+		must(t, r.Passwords.DeactivateAllForUser(ctx, user.ID.String()))
+
+		_, err := r.Passwords.FindActiveByUserID(ctx, user.ID.String())
+		wantNotFound(t, err)
+
+		if _, err := r.Passwords.FindActiveByUserID(ctx, other.ID.String()); err != nil {
+			t.Errorf("other user's password was deactivated: %v", err)
+		}
+
+		must(t, r.Passwords.Create(ctx, &model.PasswordMaster{UserID: user.ID, PasswordHash: "new", IsActive: true}))
+
+		got, err := r.Passwords.FindActiveByUserID(ctx, user.ID.String())
+		must(t, err)
+		if got.PasswordHash != "new" {
+			t.Errorf("active hash = %q, want new", got.PasswordHash)
+		}
+	},
+}
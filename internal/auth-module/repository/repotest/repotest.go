@@ -0,0 +1,147 @@
+// Package repotest is the contract suite for the auth repositories. It
+// runs unchanged against the GORM and in-memory implementations so the
+// two cannot drift:
+//
+//	func TestMain(m *testing.M) { testharness.Main(m) }
+//
+//	func TestMemoryRepos(t *testing.T) { repotest.Run(t, repotest.Memory) }
+//	func TestGORMRepos(t *testing.T)   { repotest.Run(t, repotest.GORM) }
+//
+// GORM skips itself when no Postgres installation is available.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/auth-module/repository/memory"
+	"admin-portal/internal/testharness"
+)
+
+// Repos is one consistent set of repositories over the same storage.
+type Repos struct {
+	Users      repository.UserRepository
+	Passwords  repository.PasswordRepository
+	LoginLogs  repository.LoginLogRepository
+	Sessions   repository.UserSessionRepository
+	APIKeys    repository.APIKeyRepository
+	Identities repository.IdentityRepository
+	SSOStates  repository.SSOStateRepository
+
+	OAuthClients repository.OAuthClientRepository
+	OAuthGrants  repository.OAuthGrantRepository
+
+	WebAuthn repository.WebAuthnRepository
+
+	Invitations repository.InvitationRepository
+}
+
+// Factory returns empty repositories. It is called once per case.
+type Factory func(t *testing.T) Repos
+
+// Memory is the Factory for the in-memory implementations.
+func Memory(*testing.T) Repos {
+	return Repos{
+		Users:      memory.NewUserRepository(),
+		Passwords:  memory.NewPasswordRepository(),
+		LoginLogs:  memory.NewLoginLogRepository(),
+		Sessions:   memory.NewUserSessionRepository(),
+		APIKeys:    memory.NewAPIKeyRepository(),
+		Identities: memory.NewIdentityRepository(),
+		SSOStates:  memory.NewSSOStateRepository(),
+
+		OAuthClients: memory.NewOAuthClientRepository(),
+		OAuthGrants:  memory.NewOAuthGrantRepository(),
+
+		WebAuthn: memory.NewWebAuthnRepository(),
+
+		Invitations: memory.NewInvitationRepository(),
+	}
+}
+
+// GORM is the Factory for the GORM implementations, each case on its own
+// migrated database.
+func GORM(t *testing.T) Repos {
+	db := testharness.NewDB(t)
+	return Repos{
+		Users:      repository.NewUserRepository(db),
+		Passwords:  repository.NewPasswordRepository(db),
+		LoginLogs:  repository.NewLoginLogRepository(db),
+		Sessions:   repository.NewUserSessionRepository(db),
+		APIKeys:    repository.NewAPIKeyRepository(db),
+		Identities: repository.NewIdentityRepository(db),
+		SSOStates:  repository.NewSSOStateRepository(db),
+
+		OAuthClients: repository.NewOAuthClientRepository(db),
+		OAuthGrants:  repository.NewOAuthGrantRepository(db),
+
+		WebAuthn: repository.NewWebAuthnRepository(db),
+
+		Invitations: repository.NewInvitationRepository(db),
+	}
+}
+
+// Run runs every contract case against repositories from newRepos.
+func Run(t *testing.T, newRepos Factory) {
+	for _, group := range []struct {
+		name  string
+		cases map[string]func(t *testing.T, r Repos)
+	}{
+		{"Users", userCases},
+		{"Passwords", passwordCases},
+		{"Sessions", sessionCases},
+		{"LoginLogs", loginLogCases},
+		{"APIKeys", apiKeyCases},
+		{"Identities", identityCases},
+		{"SSOStates", ssoStateCases},
+		{"OAuthClients", oauthClientCases},
+		{"OAuthGrants", oauthGrantCases},
+		{"WebAuthnCredentials", webAuthnCredentialCases},
+		{"WebAuthnChallenges", webAuthnChallengeCases},
+		{"Invitations", invitationCases},
+	} {
+		t.Run(group.name, func(t *testing.T) {
+			for name, fn := range group.cases {
+				t.Run(name, func(t *testing.T) {
+					fn(t, newRepos(t))
+				})
+			}
+		})
+	}
+}
+
+//-------------------- Helpers --------------------//
+
+var ctx = context.Background()
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func wantNotFound(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("got %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+// createUser inserts a user, which the other tables reference.
+func createUser(t *testing.T, r Repos, username string) *model.User {
+	t.Helper()
+
+	user := &model.User{Username: username, Role: model.RoleUser, IsActive: true}
+	must(t, r.Users.Create(ctx, user))
+	if user.ID == uuid.Nil {
+		t.Fatal("Create did not assign an ID")
+	}
+	return user
+}
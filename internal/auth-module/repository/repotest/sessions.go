@@ -0,0 +1,173 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+var sessionCases = map[string]func(t *testing.T, r Repos){
+	"FindValid": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		must(t, r.Sessions.Create(ctx, &model.UserSession{UserID: user.ID, Token: "live", ExpiresAt: time.Now().Add(time.Hour)}))
+		must(t, r.Sessions.Create(ctx, &model.UserSession{UserID: user.ID, Token: "expired", ExpiresAt: time.Now().Add(-time.Minute)}))
+
+		got, err := r.Sessions.FindValid(ctx, "live")
+		must(t, err)
+		if got.UserID != user.ID || got.IsRevoked {
+			t.Errorf("found %+v", got)
+		}
+
+		_, err = r.Sessions.FindValid(ctx, "expired")
+		wantNotFound(t, err)
+
+		_, err = r.Sessions.FindValid(ctx, "unknown")
+		wantNotFound(t, err)
+	},
+
+	"TokenUnique": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		expires := time.Now().Add(time.Hour)
+
+		must(t, r.Sessions.Create(ctx, &model.UserSession{UserID: user.ID, Token: "t", ExpiresAt: expires}))
+		if err := r.Sessions.Create(ctx, &model.UserSession{UserID: user.ID, Token: "t", ExpiresAt: expires}); err == nil {
+			t.Fatal("duplicate token was accepted")
+		}
+	},
+
+	"Revoke": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+		expires := time.Now().Add(time.Hour)
+
+		for _, s := range []*model.UserSession{
+			{UserID: alice.ID, Token: "a1", ExpiresAt: expires},
+			{UserID: alice.ID, Token: "a2", ExpiresAt: expires},
+			{UserID: alice.ID, Token: "a3", ExpiresAt: expires},
+			{UserID: bob.ID, Token: "b1", ExpiresAt: expires},
+		} {
+			must(t, r.Sessions.Create(ctx, s))
+		}
+
+		must(t, r.Sessions.Revoke(ctx, "a1"))
+		// Revoking an unknown token is not an error; logout is idempotent.
+		must(t, r.Sessions.Revoke(ctx, "unknown"))
+
+		_, err := r.Sessions.FindValid(ctx, "a1")
+		wantNotFound(t, err)
+		if _, err := r.Sessions.FindValid(ctx, "a2"); err != nil {
+			t.Errorf("a2 revoked by Revoke(a1): %v", err)
+		}
+
+		must(t, r.Sessions.RevokeAllForUser(ctx, alice.ID.String()))
+
+		for _, token := range []string{"a2", "a3"} {
+			_, err := r.Sessions.FindValid(ctx, token)
+			wantNotFound(t, err)
+		}
+		if _, err := r.Sessions.FindValid(ctx, "b1"); err != nil {
+			t.Errorf("other user's session revoked: %v", err)
+		}
+	},
+
+	"DeleteStale": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		past := time.Now().Add(-time.Hour)
+
+		for _, s := range []*model.UserSession{
+			{UserID: user.ID, Token: "expired1", ExpiresAt: past},
+			{UserID: user.ID, Token: "expired2", ExpiresAt: past},
+			{UserID: user.ID, Token: "revoked", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: past},
+			{UserID: user.ID, Token: "live", ExpiresAt: time.Now().Add(time.Hour)},
+		} {
+			must(t, r.Sessions.Create(ctx, s))
+		}
+		must(t, r.Sessions.Revoke(ctx, "revoked"))
+
+		before := time.Now().Add(-time.Minute)
+
+		n, err := r.Sessions.DeleteStale(ctx, before, 2)
+		must(t, err)
+		if n != 2 {
+			t.Fatalf("first batch deleted %d, want 2", n)
+		}
+
+		// The revoked session is old, but it was revoked just now.
+		n, err = r.Sessions.DeleteStale(ctx, before, 10)
+		must(t, err)
+		if n != 0 {
+			t.Fatalf("second batch deleted %d, want 0", n)
+		}
+
+		n, err = r.Sessions.DeleteStale(ctx, time.Now().Add(time.Minute), 10)
+		must(t, err)
+		if n != 1 {
+			t.Fatalf("third batch deleted %d, want 1", n)
+		}
+
+		if _, err := r.Sessions.FindValid(ctx, "live"); err != nil {
+			t.Errorf("live session deleted: %v", err)
+		}
+	},
+
+	"Consume": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		must(t, r.Sessions.Create(ctx, &model.UserSession{UserID: user.ID, Token: "live", ExpiresAt: time.Now().Add(time.Hour)}))
+		must(t, r.Sessions.Create(ctx, &model.UserSession{UserID: user.ID, Token: "expired", ExpiresAt: time.Now().Add(-time.Minute)}))
+
+		got, err := r.Sessions.Consume(ctx, "live")
+		must(t, err)
+		if got.UserID != user.ID || got.Token != "live" {
+			t.Errorf("consumed %+v", got)
+		}
+
+		// A refresh token can be redeemed only once.
+		_, err = r.Sessions.Consume(ctx, "live")
+		wantNotFound(t, err)
+
+		_, err = r.Sessions.Consume(ctx, "expired")
+		wantNotFound(t, err)
+	},
+
+	"List": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+		now := time.Now()
+		expires := now.Add(time.Hour)
+
+		for _, s := range []*model.UserSession{
+			{UserID: alice.ID, Token: "old", ExpiresAt: expires, CreatedAt: now.Add(-2 * time.Hour)},
+			{UserID: alice.ID, Token: "new", ExpiresAt: expires, CreatedAt: now.Add(-time.Hour)},
+			{UserID: alice.ID, Token: "revoked", ExpiresAt: expires, CreatedAt: now.Add(-3 * time.Hour)},
+			{UserID: alice.ID, Token: "expired", ExpiresAt: now.Add(-time.Minute), CreatedAt: now.Add(-4 * time.Hour)},
+			{UserID: bob.ID, Token: "bob", ExpiresAt: expires},
+		} {
+			must(t, r.Sessions.Create(ctx, s))
+		}
+		must(t, r.Sessions.Revoke(ctx, "revoked"))
+
+		got, total, err := r.Sessions.List(ctx, repository.SessionFilter{UserID: alice.ID.String(), Limit: 10})
+		must(t, err)
+		if total != 2 || len(got) != 2 || got[0].Token != "new" || got[1].Token != "old" {
+			t.Errorf("active sessions = %v of %d, want [new old] of 2", tokens(got), total)
+		}
+
+		got, total, err = r.Sessions.List(ctx, repository.SessionFilter{UserID: alice.ID.String(), IncludeInactive: true, Limit: 2, Offset: 2})
+		must(t, err)
+		if total != 4 || len(got) != 2 || got[0].Token != "revoked" || got[1].Token != "expired" {
+			t.Errorf("all sessions page 2 = %v of %d, want [revoked expired] of 4", tokens(got), total)
+		}
+	},
+}
+
+func tokens(sessions []*model.UserSession) []string {
+	out := make([]string, len(sessions))
+	for i, s := range sessions {
+		out[i] = s.Token
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+)
+
+var identityCases = map[string]func(t *testing.T, r Repos){
+	"FindBySubject": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		identity := &model.UserIdentity{UserID: user.ID, Issuer: "https://idp.example", Subject: "42"}
+		must(t, r.Identities.Create(ctx, identity))
+
+		got, err := r.Identities.FindBySubject(ctx, "https://idp.example", "42")
+		must(t, err)
+		if got.ID != identity.ID || got.UserID != user.ID || got.CreatedAt.IsZero() {
+			t.Errorf("found %+v", got)
+		}
+
+		// The same subject at another issuer is someone else.
+		_, err = r.Identities.FindBySubject(ctx, "https://other.example", "42")
+		wantNotFound(t, err)
+	},
+
+	"SubjectUnique": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+
+		must(t, r.Identities.Create(ctx, &model.UserIdentity{UserID: alice.ID, Issuer: "iss", Subject: "42"}))
+		if err := r.Identities.Create(ctx, &model.UserIdentity{UserID: bob.ID, Issuer: "iss", Subject: "42"}); err == nil {
+			t.Fatal("duplicate issuer and subject was accepted")
+		}
+		must(t, r.Identities.Create(ctx, &model.UserIdentity{UserID: bob.ID, Issuer: "other", Subject: "42"}))
+	},
+
+	"RecordLogin": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+
+		identity := &model.UserIdentity{UserID: user.ID, Issuer: "iss", Subject: "42"}
+		must(t, r.Identities.Create(ctx, identity))
+
+		email := "alice@example.com"
+		at := time.Now().Truncate(time.Microsecond)
+		must(t, r.Identities.RecordLogin(ctx, identity.ID.String(), &email, at))
+
+		got, err := r.Identities.FindBySubject(ctx, "iss", "42")
+		must(t, err)
+		if got.Email == nil || *got.Email != email || got.LastLoginAt == nil || !got.LastLoginAt.Equal(at) {
+			t.Errorf("after RecordLogin: %+v", got)
+		}
+	},
+}
+
+var ssoStateCases = map[string]func(t *testing.T, r Repos){
+	"ConsumeOnce": func(t *testing.T, r Repos) {
+		must(t, r.SSOStates.Create(ctx, &model.SSOLoginState{State: "live", Nonce: "n", CodeVerifier: "v", ExpiresAt: time.Now().Add(time.Minute)}))
+		must(t, r.SSOStates.Create(ctx, &model.SSOLoginState{State: "expired", Nonce: "n", CodeVerifier: "v", ExpiresAt: time.Now().Add(-time.Minute)}))
+
+		got, err := r.SSOStates.Consume(ctx, "live")
+		must(t, err)
+		if got.Nonce != "n" || got.CodeVerifier != "v" {
+			t.Errorf("consumed %+v", got)
+		}
+
+		_, err = r.SSOStates.Consume(ctx, "live")
+		wantNotFound(t, err)
+
+		_, err = r.SSOStates.Consume(ctx, "expired")
+		wantNotFound(t, err)
+	},
+
+	"DeleteExpired": func(t *testing.T, r Repos) {
+		past := time.Now().Add(-time.Hour)
+		for _, s := range []string{"e1", "e2", "e3"} {
+			must(t, r.SSOStates.Create(ctx, &model.SSOLoginState{State: s, Nonce: "n", CodeVerifier: "v", ExpiresAt: past}))
+		}
+		must(t, r.SSOStates.Create(ctx, &model.SSOLoginState{State: "live", Nonce: "n", CodeVerifier: "v", ExpiresAt: time.Now().Add(time.Hour)}))
+
+		n, err := r.SSOStates.DeleteExpired(ctx, time.Now(), 2)
+		must(t, err)
+		if n != 2 {
+			t.Fatalf("first batch deleted %d, want 2", n)
+		}
+		n, err = r.SSOStates.DeleteExpired(ctx, time.Now(), 10)
+		must(t, err)
+		if n != 1 {
+			t.Fatalf("second batch deleted %d, want 1", n)
+		}
+
+		if _, err := r.SSOStates.Consume(ctx, "live"); err != nil {
+			t.Errorf("live state deleted: %v", err)
+		}
+	},
+}
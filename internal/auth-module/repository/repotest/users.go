@@ -0,0 +1,209 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+var userCases = map[string]func(t *testing.T, r Repos){
+	"CreateAndFind": func(t *testing.T, r Repos) {
+		user := &model.User{Username: "alice", Role: model.RoleAdmin}
+		must(t, r.Users.Create(ctx, user))
+
+		if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+			t.Error("Create did not set timestamps")
+		}
+
+		byID, err := r.Users.FindByID(ctx, user.ID.String())
+		must(t, err)
+		byName, err := r.Users.FindByUsername(ctx, "alice")
+		must(t, err)
+
+		for _, got := range []*model.User{byID, byName} {
+			if got.ID != user.ID || got.Username != "alice" || got.Role != model.RoleAdmin {
+				t.Errorf("found %+v, want %+v", got, user)
+			}
+			// A false is_active is not written, so the column default wins.
+			if !got.IsActive || got.IsActivated {
+				t.Errorf("is_active=%v is_activated=%v, want true false", got.IsActive, got.IsActivated)
+			}
+		}
+	},
+
+	"UsernameUnique": func(t *testing.T, r Repos) {
+		createUser(t, r, "alice")
+		if err := r.Users.Create(ctx, &model.User{Username: "alice", Role: model.RoleUser}); err == nil {
+			t.Fatal("duplicate username was accepted")
+		}
+	},
+
+	"EmailUnique": func(t *testing.T, r Repos) {
+		email := "alice@example.com"
+		alice := &model.User{Username: "alice", Email: &email, Role: model.RoleUser}
+		must(t, r.Users.Create(ctx, alice))
+
+		// Users without an email do not collide with each other.
+		createUser(t, r, "bob")
+		createUser(t, r, "carol")
+
+		got, err := r.Users.FindByEmail(ctx, email)
+		must(t, err)
+		if got.ID != alice.ID {
+			t.Errorf("FindByEmail = %s, want alice", got.Username)
+		}
+
+		if err := r.Users.Create(ctx, &model.User{Username: "mallory", Email: &email, Role: model.RoleUser}); err == nil {
+			t.Error("duplicate email was accepted")
+		}
+
+		_, err = r.Users.FindByEmail(ctx, "nobody@example.com")
+		wantNotFound(t, err)
+	},
+
+	"NotFound": func(t *testing.T, r Repos) {
+		_, err := r.Users.FindByID(ctx, uuid.NewString())
+		wantNotFound(t, err)
+
+		_, err = r.Users.FindByUsername(ctx, "nobody")
+		wantNotFound(t, err)
+	},
+
+	"Update": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		createUser(t, r, "bob")
+
+		user.Role = model.RoleSuperAdmin
+		user.IsActivated = true
+		must(t, r.Users.Update(ctx, user))
+
+		got, err := r.Users.FindByID(ctx, user.ID.String())
+		must(t, err)
+		if got.Role != model.RoleSuperAdmin || !got.IsActivated {
+			t.Errorf("update not persisted: %+v", got)
+		}
+
+		user.Username = "bob"
+		if err := r.Users.Update(ctx, user); err == nil {
+			t.Error("update to a taken username was accepted")
+		}
+	},
+
+	"PendingActivation": func(t *testing.T, r Repos) {
+		old := time.Now().Add(-48 * time.Hour)
+
+		first := &model.User{Username: "first", Role: model.RoleUser, CreatedAt: old.Add(-time.Hour)}
+		second := &model.User{Username: "second", Role: model.RoleUser, CreatedAt: old}
+		activated := &model.User{Username: "activated", Role: model.RoleUser, IsActivated: true, CreatedAt: old}
+		recent := &model.User{Username: "recent", Role: model.RoleUser}
+		for _, u := range []*model.User{first, second, activated, recent} {
+			must(t, r.Users.Create(ctx, u))
+		}
+
+		cutoff := time.Now().Add(-24 * time.Hour)
+
+		got, err := r.Users.FindPendingActivation(ctx, cutoff, 10)
+		must(t, err)
+		if len(got) != 2 || got[0].ID != first.ID || got[1].ID != second.ID {
+			t.Fatalf("pending = %v, want [first second]", usernames(got))
+		}
+
+		got, err = r.Users.FindPendingActivation(ctx, cutoff, 1)
+		must(t, err)
+		if len(got) != 1 || got[0].ID != first.ID {
+			t.Fatalf("pending with limit 1 = %v, want [first]", usernames(got))
+		}
+
+		must(t, r.Users.MarkActivationReminded(ctx, first.ID.String(), time.Now()))
+
+		got, err = r.Users.FindPendingActivation(ctx, cutoff, 10)
+		must(t, err)
+		if len(got) != 1 || got[0].ID != second.ID {
+			t.Fatalf("pending after reminder = %v, want [second]", usernames(got))
+		}
+
+		reminded, err := r.Users.FindByID(ctx, first.ID.String())
+		must(t, err)
+		if reminded.ActivationReminderSentAt == nil {
+			t.Error("activation_reminder_sent_at not set")
+		}
+	},
+
+	"ActivationToken": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		id := user.ID.String()
+
+		first, second := uuid.NewString(), uuid.NewString()
+		must(t, r.Users.SetActivationToken(ctx, id, first))
+		must(t, r.Users.SetActivationToken(ctx, id, second))
+
+		// Only the newest token activates, and only once.
+		wantNotFound(t, r.Users.ConsumeActivationToken(ctx, id, first))
+		must(t, r.Users.ConsumeActivationToken(ctx, id, second))
+		wantNotFound(t, r.Users.ConsumeActivationToken(ctx, id, second))
+
+		got, err := r.Users.FindByID(ctx, id)
+		must(t, err)
+		if !got.IsActivated || got.ActivationTokenID != nil {
+			t.Errorf("is_activated=%v activation_token_id=%v, want true nil", got.IsActivated, got.ActivationTokenID)
+		}
+	},
+
+	"ActivationSent": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		id := user.ID.String()
+
+		sent := time.Now().Add(-time.Minute)
+		must(t, r.Users.MarkActivationSent(ctx, id, sent, time.Now()))
+
+		// A second request within the interval is refused.
+		wantNotFound(t, r.Users.MarkActivationSent(ctx, id, time.Now(), sent))
+		must(t, r.Users.MarkActivationSent(ctx, id, time.Now(), time.Now()))
+
+		user.IsActivated = true
+		must(t, r.Users.Update(ctx, user))
+		wantNotFound(t, r.Users.MarkActivationSent(ctx, id, time.Now(), time.Now().Add(time.Hour)))
+	},
+
+	"List": func(t *testing.T, r Repos) {
+		for _, u := range []*model.User{
+			{Username: "carol", Role: model.RoleUser},
+			{Username: "alice", Role: model.RoleAdmin},
+			{Username: "bob", Role: model.RoleUser},
+			{Username: "al_x", Role: model.RoleUser},
+		} {
+			must(t, r.Users.Create(ctx, u))
+		}
+
+		got, total, err := r.Users.List(ctx, repository.UserFilter{Limit: 2, Offset: 1})
+		must(t, err)
+		if total != 4 || len(got) != 2 || got[0].Username != "alice" || got[1].Username != "bob" {
+			t.Errorf("page = %v of %d, want [alice bob] of 4", usernames(got), total)
+		}
+
+		got, total, err = r.Users.List(ctx, repository.UserFilter{Role: model.RoleUser, Limit: 10})
+		must(t, err)
+		if total != 3 || len(got) != 3 {
+			t.Errorf("role=user = %v of %d, want 3", usernames(got), total)
+		}
+
+		// LIKE wildcards in the query match literally.
+		got, _, err = r.Users.List(ctx, repository.UserFilter{Query: "L_", Limit: 10})
+		must(t, err)
+		if len(got) != 1 || got[0].Username != "al_x" {
+			t.Errorf("query L_ = %v, want [al_x]", usernames(got))
+		}
+	},
+}
+
+func usernames(users []*model.User) []string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Username
+	}
+	return names
+}
@@ -0,0 +1,134 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+)
+
+var webAuthnCredentialCases = map[string]func(t *testing.T, r Repos){
+	"CreateFindList": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+
+		base := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+		for i, id := range []string{"k1", "k2"} {
+			must(t, r.WebAuthn.CreateCredential(ctx, &model.WebAuthnCredential{
+				UserID: alice.ID, Name: id, CredentialID: []byte(id), PublicKey: []byte("pk"),
+				Transports: []string{"usb"}, CreatedAt: base.Add(time.Duration(i) * time.Minute),
+			}))
+		}
+		must(t, r.WebAuthn.CreateCredential(ctx, &model.WebAuthnCredential{UserID: bob.ID, Name: "k3", CredentialID: []byte("k3"), PublicKey: []byte("pk")}))
+
+		got, err := r.WebAuthn.FindCredential(ctx, []byte("k2"))
+		must(t, err)
+		if got.UserID != alice.ID || got.Name != "k2" || string(got.PublicKey) != "pk" || len(got.Transports) != 1 {
+			t.Errorf("found %+v", got)
+		}
+		_, err = r.WebAuthn.FindCredential(ctx, []byte("nope"))
+		wantNotFound(t, err)
+
+		creds, err := r.WebAuthn.ListCredentials(ctx, alice.ID.String())
+		must(t, err)
+		if len(creds) != 2 || creds[0].Name != "k1" || creds[1].Name != "k2" {
+			t.Errorf("listed %d credentials, want k1 then k2", len(creds))
+		}
+
+		// Credential IDs are unique across users.
+		dup := &model.WebAuthnCredential{UserID: bob.ID, Name: "dup", CredentialID: []byte("k1"), PublicKey: []byte("pk")}
+		if err := r.WebAuthn.CreateCredential(ctx, dup); err == nil {
+			t.Fatal("duplicate credential ID was accepted")
+		}
+	},
+
+	"RecordUse": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		cred := &model.WebAuthnCredential{UserID: user.ID, Name: "key", CredentialID: []byte("k"), PublicKey: []byte("pk"), SignCount: 5}
+		must(t, r.WebAuthn.CreateCredential(ctx, cred))
+
+		at := time.Now().Truncate(time.Microsecond)
+		must(t, r.WebAuthn.RecordUse(ctx, cred.ID.String(), 6, at))
+
+		got, err := r.WebAuthn.FindCredential(ctx, []byte("k"))
+		must(t, err)
+		if got.SignCount != 6 || got.LastUsedAt == nil || !got.LastUsedAt.Equal(at) {
+			t.Errorf("after RecordUse: %+v", got)
+		}
+
+		// A counter that did not increase is not stored.
+		wantNotFound(t, r.WebAuthn.RecordUse(ctx, cred.ID.String(), 6, at))
+		wantNotFound(t, r.WebAuthn.RecordUse(ctx, cred.ID.String(), 2, at))
+
+		// Authenticators without a counter always report 0.
+		zero := &model.WebAuthnCredential{UserID: user.ID, Name: "zero", CredentialID: []byte("z"), PublicKey: []byte("pk")}
+		must(t, r.WebAuthn.CreateCredential(ctx, zero))
+		must(t, r.WebAuthn.RecordUse(ctx, zero.ID.String(), 0, at))
+		must(t, r.WebAuthn.RecordUse(ctx, zero.ID.String(), 0, at))
+	},
+
+	"Delete": func(t *testing.T, r Repos) {
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob")
+		cred := &model.WebAuthnCredential{UserID: alice.ID, Name: "key", CredentialID: []byte("k"), PublicKey: []byte("pk")}
+		must(t, r.WebAuthn.CreateCredential(ctx, cred))
+
+		// Only the owner can delete it.
+		wantNotFound(t, r.WebAuthn.DeleteCredential(ctx, cred.ID.String(), bob.ID.String()))
+		must(t, r.WebAuthn.DeleteCredential(ctx, cred.ID.String(), alice.ID.String()))
+		wantNotFound(t, r.WebAuthn.DeleteCredential(ctx, cred.ID.String(), alice.ID.String()))
+
+		_, err := r.WebAuthn.FindCredential(ctx, []byte("k"))
+		wantNotFound(t, err)
+	},
+}
+
+var webAuthnChallengeCases = map[string]func(t *testing.T, r Repos){
+	"ConsumeOnce": func(t *testing.T, r Repos) {
+		user := createUser(t, r, "alice")
+		live := time.Now().Add(time.Minute)
+		must(t, r.WebAuthn.CreateChallenge(ctx, &model.WebAuthnChallenge{Challenge: "reg", Ceremony: model.CeremonyRegistration, UserID: &user.ID, ExpiresAt: live}))
+		must(t, r.WebAuthn.CreateChallenge(ctx, &model.WebAuthnChallenge{Challenge: "login", Ceremony: model.CeremonyLogin, ExpiresAt: live}))
+		must(t, r.WebAuthn.CreateChallenge(ctx, &model.WebAuthnChallenge{Challenge: "expired", Ceremony: model.CeremonyLogin, ExpiresAt: time.Now().Add(-time.Minute)}))
+
+		got, err := r.WebAuthn.ConsumeChallenge(ctx, "reg")
+		must(t, err)
+		if got.Ceremony != model.CeremonyRegistration || got.UserID == nil || *got.UserID != user.ID {
+			t.Errorf("consumed %+v", got)
+		}
+		_, err = r.WebAuthn.ConsumeChallenge(ctx, "reg")
+		wantNotFound(t, err)
+
+		got, err = r.WebAuthn.ConsumeChallenge(ctx, "login")
+		must(t, err)
+		if got.UserID != nil {
+			t.Errorf("passwordless challenge has user %v", got.UserID)
+		}
+
+		_, err = r.WebAuthn.ConsumeChallenge(ctx, "expired")
+		wantNotFound(t, err)
+	},
+
+	"DeleteExpired": func(t *testing.T, r Repos) {
+		past := time.Now().Add(-time.Hour)
+		for _, c := range []string{"e1", "e2", "e3"} {
+			must(t, r.WebAuthn.CreateChallenge(ctx, &model.WebAuthnChallenge{Challenge: c, Ceremony: model.CeremonyLogin, ExpiresAt: past}))
+		}
+		must(t, r.WebAuthn.CreateChallenge(ctx, &model.WebAuthnChallenge{Challenge: "live", Ceremony: model.CeremonyLogin, ExpiresAt: time.Now().Add(time.Hour)}))
+
+		n, err := r.WebAuthn.DeleteExpiredChallenges(ctx, time.Now(), 2)
+		must(t, err)
+		if n != 2 {
+			t.Fatalf("first batch deleted %d, want 2", n)
+		}
+		n, err = r.WebAuthn.DeleteExpiredChallenges(ctx, time.Now(), 10)
+		must(t, err)
+		if n != 1 {
+			t.Fatalf("second batch deleted %d, want 1", n)
+		}
+
+		if _, err := r.WebAuthn.ConsumeChallenge(ctx, "live"); err != nil {
+			t.Errorf("live challenge deleted: %v", err)
+		}
+	},
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
+)
+
+type SSOStateRepository interface {
+	Create(ctx context.Context, state *model.SSOLoginState) error
+	Consume(ctx context.Context, state string) (*model.SSOLoginState, error)
+	DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+type ssoStateRepository struct {
+	db *gorm.DB
+}
+
+func NewSSOStateRepository(db *gorm.DB) SSOStateRepository {
+	return &ssoStateRepository{db: db}
+}
+
+func (r *ssoStateRepository) Create(ctx context.Context, state *model.SSOLoginState) error {
+	return database.Conn(ctx, r.db).Create(state).Error
+}
+
+// Consume deletes an unexpired state and returns it, so each callback is
+// accepted once. It returns gorm.ErrRecordNotFound otherwise.
+func (r *ssoStateRepository) Consume(ctx context.Context, state string) (*model.SSOLoginState, error) {
+	var states []*model.SSOLoginState
+	res := database.Conn(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("state = ? AND expires_at > ?", state, time.Now()).
+		Delete(&states)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(states) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return states[0], nil
+}
+
+// DeleteExpired removes up to limit states that expired before the given
+// time.
+func (r *ssoStateRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := database.Conn(ctx, r.db).Exec(`
+		DELETE FROM sso_login_states
+		WHERE state IN (
+			SELECT state FROM sso_login_states
+			WHERE expires_at < ?
+			LIMIT ?
+		)`, before, limit)
+	return res.RowsAffected, res.Error
+}
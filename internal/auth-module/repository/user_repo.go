@@ -2,17 +2,36 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
 	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *model.User) error
 	FindByID(ctx context.Context, id string) (*model.User, error)
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
+	FindPendingActivation(ctx context.Context, createdBefore time.Time, limit int) ([]*model.User, error)
+	MarkActivationReminded(ctx context.Context, id string, at time.Time) error
+	MarkActivationSent(ctx context.Context, id string, at, sentBefore time.Time) error
+	SetActivationToken(ctx context.Context, id, tokenID string) error
+	ConsumeActivationToken(ctx context.Context, id, tokenID string) error
+	List(ctx context.Context, f UserFilter) ([]*model.User, int64, error)
+}
+
+// UserFilter narrows List. Empty fields match everything.
+type UserFilter struct {
+	Role string
+	// Query matches usernames containing it, ignoring case.
+	Query  string
+	Limit  int
+	Offset int
 }
 
 type userRepository struct {
@@ -24,12 +43,12 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return database.Conn(ctx, r.db).Create(user).Error
 }
 
 func (r *userRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("id = ?", id).
 		First(&user).Error
 
@@ -41,7 +60,7 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (*model.User,
 
 func (r *userRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("username = ?", username).
 		First(&user).Error
 
@@ -51,6 +70,115 @@ func (r *userRepository) FindByUsername(ctx context.Context, username string) (*
 	return &user, nil
 }
 
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	var user model.User
+	err := database.Conn(ctx, r.db).
+		Where("email = ?", email).
+		First(&user).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) Update(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return database.Conn(ctx, r.db).Save(user).Error
 }
+
+// FindPendingActivation returns active users created before createdBefore
+// that were never activated and have not been reminded yet.
+func (r *userRepository) FindPendingActivation(ctx context.Context, createdBefore time.Time, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := database.Conn(ctx, r.db).
+		Where("is_active = TRUE AND is_activated = FALSE AND activation_reminder_sent_at IS NULL AND created_at < ?", createdBefore).
+		Order("created_at").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) MarkActivationReminded(ctx context.Context, id string, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Update("activation_reminder_sent_at", at).Error
+}
+
+// MarkActivationSent records that an activation email was requested at
+// at, unless the user is activated or the last request was not before
+// sentBefore. It returns gorm.ErrRecordNotFound in those cases.
+func (r *userRepository) MarkActivationSent(ctx context.Context, id string, at, sentBefore time.Time) error {
+	res := database.Conn(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ? AND is_activated = FALSE AND (activation_sent_at IS NULL OR activation_sent_at < ?)", id, sentBefore).
+		Update("activation_sent_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetActivationToken makes tokenID the only activation token of the
+// user that is still valid.
+func (r *userRepository) SetActivationToken(ctx context.Context, id, tokenID string) error {
+	return database.Conn(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Update("activation_token_id", tokenID).Error
+}
+
+// ConsumeActivationToken activates the user if tokenID is their current
+// activation token, and forgets the token. It returns
+// gorm.ErrRecordNotFound if the token was replaced or already used.
+func (r *userRepository) ConsumeActivationToken(ctx context.Context, id, tokenID string) error {
+	res := database.Conn(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ? AND activation_token_id = ? AND is_activated = FALSE", id, tokenID).
+		Updates(map[string]interface{}{
+			"is_activated":        true,
+			"activation_token_id": nil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// List returns a page of users ordered by username, and the total count.
+func (r *userRepository) List(ctx context.Context, f UserFilter) ([]*model.User, int64, error) {
+	q := database.Conn(ctx, r.db).Model(&model.User{})
+	if f.Role != "" {
+		q = q.Where("role = ?", f.Role)
+	}
+	if f.Query != "" {
+		q = q.Where(`username ILIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(f.Query)+"%")
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*model.User
+	err := q.Order("username").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
 )
 
 type UserSessionRepository interface {
@@ -14,6 +16,18 @@ type UserSessionRepository interface {
 	FindValid(ctx context.Context, token string) (*model.UserSession, error)
 	Revoke(ctx context.Context, token string) error
 	RevokeAllForUser(ctx context.Context, userID string) error
+	DeleteStale(ctx context.Context, before time.Time, limit int) (int64, error)
+	Consume(ctx context.Context, token string) (*model.UserSession, error)
+	List(ctx context.Context, f SessionFilter) ([]*model.UserSession, int64, error)
+}
+
+// SessionFilter narrows List to one user's sessions.
+type SessionFilter struct {
+	UserID string
+	// IncludeInactive also returns revoked and expired sessions.
+	IncludeInactive bool
+	Limit           int
+	Offset          int
 }
 
 type refreshTokenRepository struct {
@@ -25,27 +39,91 @@ func NewUserSessionRepository(db *gorm.DB) UserSessionRepository {
 }
 
 func (r *refreshTokenRepository) Create(ctx context.Context, token *model.UserSession) error {
-	return r.db.WithContext(ctx).Create(token).Error
+	return database.Conn(ctx, r.db).Create(token).Error
 }
 
 func (r *refreshTokenRepository) FindValid(ctx context.Context, token string) (*model.UserSession, error) {
 	var rt model.UserSession
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("token = ? AND is_revoked = FALSE AND expires_at > ?", token, time.Now()).
 		First(&rt).Error
 	return &rt, err
 }
 
 func (r *refreshTokenRepository) Revoke(ctx context.Context, token string) error {
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&model.UserSession{}).
-		Where("token = ?", token).
-		Update("is_revoked", true).Error
+		Where("token = ? AND is_revoked = FALSE", token).
+		Updates(revoked()).Error
 }
 
 func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&model.UserSession{}).
-		Where("user_id = ?", userID).
-		Update("is_revoked", true).Error
+		Where("user_id = ? AND is_revoked = FALSE", userID).
+		Updates(revoked()).Error
+}
+
+// revoked is the update that revokes a session. Callers only apply it to
+// live sessions, so revoked_at keeps the time of the first revocation.
+func revoked() map[string]interface{} {
+	return map[string]interface{}{"is_revoked": true, "revoked_at": time.Now()}
+}
+
+// DeleteStale removes up to limit sessions that expired, or were revoked,
+// before the given time.
+func (r *refreshTokenRepository) DeleteStale(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := database.Conn(ctx, r.db).Exec(`
+		DELETE FROM user_sessions
+		WHERE id IN (
+			SELECT id FROM user_sessions
+			WHERE expires_at < ? OR revoked_at < ?
+			LIMIT ?
+		)`, before, before, limit)
+	return res.RowsAffected, res.Error
+}
+
+// Consume revokes a valid session and returns it, in one statement, so a
+// refresh token can be redeemed only once. It returns
+// gorm.ErrRecordNotFound if the token is unknown, revoked or expired.
+func (r *refreshTokenRepository) Consume(ctx context.Context, token string) (*model.UserSession, error) {
+	var sessions []*model.UserSession
+	res := database.Conn(ctx, r.db).
+		Model(&sessions).
+		Clauses(clause.Returning{}).
+		Where("token = ? AND is_revoked = FALSE AND expires_at > ?", token, time.Now()).
+		Updates(revoked())
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(sessions) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return sessions[0], nil
+}
+
+// List returns a page of the user's sessions, newest first, and the total.
+func (r *refreshTokenRepository) List(ctx context.Context, f SessionFilter) ([]*model.UserSession, int64, error) {
+	q := database.Conn(ctx, r.db).
+		Model(&model.UserSession{}).
+		Where("user_id = ?", f.UserID)
+	if !f.IncludeInactive {
+		q = q.Where("is_revoked = FALSE AND expires_at > ?", time.Now())
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var sessions []*model.UserSession
+	err := q.Order("created_at DESC, id").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
 }
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/database"
+)
+
+// WebAuthnRepository stores users' passkeys and the challenges of
+// ceremonies in progress.
+type WebAuthnRepository interface {
+	CreateCredential(ctx context.Context, cred *model.WebAuthnCredential) error
+	FindCredential(ctx context.Context, credentialID []byte) (*model.WebAuthnCredential, error)
+	ListCredentials(ctx context.Context, userID string) ([]*model.WebAuthnCredential, error)
+	RecordUse(ctx context.Context, id string, signCount int64, at time.Time) error
+	DeleteCredential(ctx context.Context, id, userID string) error
+
+	CreateChallenge(ctx context.Context, challenge *model.WebAuthnChallenge) error
+	ConsumeChallenge(ctx context.Context, challenge string) (*model.WebAuthnChallenge, error)
+	DeleteExpiredChallenges(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+type webAuthnRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnRepository(db *gorm.DB) WebAuthnRepository {
+	return &webAuthnRepository{db: db}
+}
+
+func (r *webAuthnRepository) CreateCredential(ctx context.Context, cred *model.WebAuthnCredential) error {
+	if cred.Transports == nil {
+		cred.Transports = []string{}
+	}
+	return database.Conn(ctx, r.db).Create(cred).Error
+}
+
+func (r *webAuthnRepository) FindCredential(ctx context.Context, credentialID []byte) (*model.WebAuthnCredential, error) {
+	var cred model.WebAuthnCredential
+	err := database.Conn(ctx, r.db).First(&cred, "credential_id = ?", credentialID).Error
+	return &cred, err
+}
+
+// ListCredentials returns the user's credentials, oldest first.
+func (r *webAuthnRepository) ListCredentials(ctx context.Context, userID string) ([]*model.WebAuthnCredential, error) {
+	var creds []*model.WebAuthnCredential
+	err := database.Conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at, id").
+		Find(&creds).Error
+	return creds, err
+}
+
+// RecordUse stores the signature counter and time of a login. The update
+// only applies while signCount is greater than the stored counter, or
+// both are 0, so two logins cannot accept the same counter; otherwise it
+// returns gorm.ErrRecordNotFound.
+func (r *webAuthnRepository) RecordUse(ctx context.Context, id string, signCount int64, at time.Time) error {
+	res := database.Conn(ctx, r.db).
+		Model(&model.WebAuthnCredential{}).
+		Where("id = ? AND (sign_count < ? OR (sign_count = 0 AND ? = 0))", id, signCount, signCount).
+		Updates(map[string]any{
+			"sign_count":   signCount,
+			"last_used_at": at,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteCredential deletes one of the user's credentials. It returns
+// gorm.ErrRecordNotFound when the user has no such credential.
+func (r *webAuthnRepository) DeleteCredential(ctx context.Context, id, userID string) error {
+	res := database.Conn(ctx, r.db).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.WebAuthnCredential{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *webAuthnRepository) CreateChallenge(ctx context.Context, challenge *model.WebAuthnChallenge) error {
+	return database.Conn(ctx, r.db).Create(challenge).Error
+}
+
+// ConsumeChallenge deletes an unexpired challenge and returns it, so each
+// response is accepted once; callers check its ceremony. It returns
+// gorm.ErrRecordNotFound otherwise.
+func (r *webAuthnRepository) ConsumeChallenge(ctx context.Context, challenge string) (*model.WebAuthnChallenge, error) {
+	var challenges []*model.WebAuthnChallenge
+	res := database.Conn(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("challenge = ? AND expires_at > ?", challenge, time.Now()).
+		Delete(&challenges)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(challenges) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return challenges[0], nil
+}
+
+// DeleteExpiredChallenges removes up to limit challenges that expired
+// before the given time.
+func (r *webAuthnRepository) DeleteExpiredChallenges(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := database.Conn(ctx, r.db).Exec(`
+		DELETE FROM webauthn_challenges
+		WHERE challenge IN (
+			SELECT challenge FROM webauthn_challenges
+			WHERE expires_at < ?
+			LIMIT ?
+		)`, before, limit)
+	return res.RowsAffected, res.Error
+}
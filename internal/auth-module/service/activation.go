@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/config"
+	"admin-portal/internal/shared/mail"
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/security"
+)
+
+// KindActivationEmail is the queue job that sends one activation email.
+const KindActivationEmail = "auth.activation_email"
+
+type ActivationEmailPayload struct {
+	UserID string `json:"user_id"`
+}
+
+// ActivationConfig tunes email activation.
+type ActivationConfig struct {
+	// TokenTTL is how long an activation link works.
+	TokenTTL time.Duration
+	// ResendInterval is the least time between two activation emails
+	// requested for the same user.
+	ResendInterval time.Duration
+	// URL is the page that activates accounts. The token is added as
+	// the token query parameter.
+	URL string
+}
+
+func LoadActivationConfig() ActivationConfig {
+	return ActivationConfig{
+		TokenTTL:       config.Duration("ACTIVATION_TOKEN_TTL", 48*time.Hour),
+		ResendInterval: config.Duration("ACTIVATION_RESEND_INTERVAL", 5*time.Minute),
+		URL:            config.String("ACTIVATION_URL", "http://localhost:8080/activate"),
+	}
+}
+
+// enqueueActivationEmail queues an activation email for userID in the
+// caller's transaction. A job already waiting for the user sends the
+// newest token anyway, so a second one is not queued.
+func enqueueActivationEmail(ctx context.Context, jobs queue.Enqueuer, userID string) error {
+	_, err := jobs.Enqueue(ctx, KindActivationEmail,
+		ActivationEmailPayload{UserID: userID},
+		queue.WithUniqueKey(KindActivationEmail+":"+userID),
+	)
+	if errors.Is(err, queue.ErrDuplicate) {
+		return nil
+	}
+	return err
+}
+
+// normalizeEmail is the form emails are stored and looked up in.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ActivationMailer sends activation emails from the worker. Every email
+// carries a fresh token, which replaces any sent before, so only the
+// newest link works. Register it with the queue worker for
+// KindActivationEmail; it also delivers activation reminders.
+type ActivationMailer struct {
+	users  repository.UserRepository
+	jwtCfg security.JWTConfig
+	mailer mail.Mailer
+	cfg    ActivationConfig
+}
+
+func NewActivationMailer(users repository.UserRepository, jwtCfg security.JWTConfig, mailer mail.Mailer, cfg ActivationConfig) *ActivationMailer {
+	return &ActivationMailer{users: users, jwtCfg: jwtCfg, mailer: mailer, cfg: cfg}
+}
+
+// Handle sends the email queued by Register or ResendActivation. Users
+// that were activated, deactivated or removed in the meantime are
+// skipped.
+func (m *ActivationMailer) Handle(ctx context.Context, job *queue.Job) error {
+	var payload ActivationEmailPayload
+	if err := job.Decode(&payload); err != nil {
+		return queue.Permanent(err)
+	}
+
+	user, err := m.users.FindByID(ctx, payload.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.IsActivated || !user.IsActive {
+		return nil
+	}
+
+	return m.send(ctx, user, "Activate your account",
+		"Welcome, %s. Open the link below to activate your account.")
+}
+
+// ActivationReminder implements jobs.ActivationNotifier.
+func (m *ActivationMailer) ActivationReminder(ctx context.Context, user *model.User) error {
+	return m.send(ctx, user, "Your account is waiting",
+		"Hello %s, your account is not activated yet. Open the link below to activate it.")
+}
+
+func (m *ActivationMailer) send(ctx context.Context, user *model.User, subject, greeting string) error {
+	// Accounts from before email activation have nowhere to send to.
+	if user.Email == nil {
+		return nil
+	}
+
+	token, tokenID, err := security.GenerateActivationToken(m.jwtCfg, user.ID.String(), m.cfg.TokenTTL)
+	if err != nil {
+		return err
+	}
+	if err := m.users.SetActivationToken(ctx, user.ID.String(), tokenID); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(m.cfg.URL)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("ACTIVATION_URL: %w", err))
+	}
+	q := link.Query()
+	q.Set("token", token)
+	link.RawQuery = q.Encode()
+
+	body := fmt.Sprintf(greeting, user.Username) + "\n\n" +
+		link.String() + "\n\n" +
+		fmt.Sprintf("The link works once and expires on %s. If you did not sign up, ignore this email.\n",
+			time.Now().Add(m.cfg.TokenTTL).UTC().Format("2 Jan 2006 15:04 MST"))
+
+	return m.mailer.Send(ctx, mail.Message{
+		To:      *user.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
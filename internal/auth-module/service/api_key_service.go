@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/security"
+)
+
+// lastUsedResolution bounds how often a busy key writes last_used_at.
+const lastUsedResolution = time.Minute
+
+var scopePattern = regexp.MustCompile(`^/[A-Za-z0-9_.]+/([A-Za-z0-9_]+)?$`)
+
+// APIKeyService manages API keys for automation and authenticates the
+// requests made with them.
+type APIKeyService interface {
+	Create(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (*model.APIKey, string, error)
+	List(ctx context.Context, f repository.APIKeyFilter) ([]*model.APIKey, int64, error)
+	Revoke(ctx context.Context, id string) error
+
+	middleware.APIKeyAuthenticator
+}
+
+type apiKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+}
+
+func NewAPIKeyService(
+	apiKeyRepo repository.APIKeyRepository,
+	userRepo repository.UserRepository,
+) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+/* Create issues a key owned by the caller, limited to scopes, which must not be empty. The key itself is returned only here; the server keeps just its hash. */
+func (s *apiKeyService) Create(
+	ctx context.Context,
+	name string,
+	scopes []string,
+	expiresAt *time.Time,
+) (*model.APIKey, string, error) {
+
+	if _, ok := middleware.ServiceFromContext(ctx); ok {
+		return nil, "", ErrNotAUser
+	}
+	// A leaked key must not be able to outlive its own revocation.
+	if _, ok := middleware.APIKeyFromContext(ctx); ok {
+		return nil, "", ErrAPIKeyNotAllowed
+	}
+
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, "", ErrInvalidCredential
+	}
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Least privilege: a key never gets every method by default.
+	if len(scopes) == 0 {
+		return nil, "", ErrScopeRequired
+	}
+	for _, scope := range scopes {
+		if scope != model.ScopeAll && !scopePattern.MatchString(scope) {
+			return nil, "", ErrInvalidScope.WithDetail("scope", scope)
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	secret, prefix, hash, err := security.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &model.APIKey{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+/* List returns a page of a user's keys. An empty UserID means the caller; only administrators may list anyone else's. */
+func (s *apiKeyService) List(ctx context.Context, f repository.APIKeyFilter) ([]*model.APIKey, int64, error) {
+	callerID, _ := middleware.UserIDFromContext(ctx)
+	if f.UserID == "" {
+		if _, ok := middleware.ServiceFromContext(ctx); ok {
+			return nil, 0, ErrNotAUser
+		}
+		f.UserID = callerID
+	}
+
+	if f.UserID != callerID && !isAdmin(ctx) {
+		return nil, 0, ErrAccessDenied.WithDetail("user_id", f.UserID)
+	}
+
+	return s.apiKeyRepo.List(ctx, f)
+}
+
+/* Revoke disables a key at once. Owners may revoke their own keys; administrators may revoke anyone's. */
+func (s *apiKeyService) Revoke(ctx context.Context, id string) error {
+	key, err := s.apiKeyRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAPIKeyNotFound.WithDetail("id", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	callerID, _ := middleware.UserIDFromContext(ctx)
+	if key.UserID.String() != callerID && !isAdmin(ctx) {
+		// Do not confirm that someone else's key exists.
+		return ErrAPIKeyNotFound.WithDetail("id", id)
+	}
+
+	return s.apiKeyRepo.Revoke(ctx, id, time.Now())
+}
+
+/* AuthenticateAPIKey resolves a presented key to its owner. The owner must still be active, and acts with their current role. */
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, secret string) (*middleware.APIKeyPrincipal, error) {
+	prefix, ok := security.APIKeyPrefix(secret)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.FindByPrefix(ctx, prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !security.CheckAPIKey(secret, key.KeyHash) || !key.Usable(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.FindByID(ctx, key.UserID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	if !user.IsActivated {
+		return nil, ErrUserNotActivated
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID.String(), now); err != nil {
+			log.Printf("⚠️ Failed to record use of API key %s: %v", key.ID, err)
+		}
+	}
+
+	return &middleware.APIKeyPrincipal{
+		KeyID:    key.ID.String(),
+		UserID:   user.ID.String(),
+		Username: user.Username,
+		Role:     user.Role,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+func (s *apiKeyService) findUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound.WithDetail("user_id", userID)
+	}
+	return user, err
+}
@@ -3,148 +3,387 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"admin-portal/internal/auth-module/events"
+	"admin-portal/internal/auth-module/middleware"
 	"admin-portal/internal/auth-module/model"
 	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/database"
+	"admin-portal/internal/shared/outbox"
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/tracing"
 )
 
 type AuthService interface {
-	Register(ctx context.Context, username, password, role string) (*model.User, error)
+	Register(ctx context.Context, username, email, password, role string) (*model.User, error)
+	RegisterInvited(ctx context.Context, inv *model.Invitation, username string, hashedPassword []byte) (*model.User, error)
+	Activate(ctx context.Context, token string) error
+	ResendActivation(ctx context.Context, email string) error
 	ActivateUser(ctx context.Context, userID string) error
+	DeactivateUser(ctx context.Context, userID string) error
+	ChangeRole(ctx context.Context, userID, role string) error
 	Login(ctx context.Context, username, password string) (*model.User, string, string, error)
 	Logout(ctx context.Context, refreshToken string) error
+	Refresh(ctx context.Context, refreshToken string) (*model.User, string, string, error)
+	LoginWithIdentity(ctx context.Context, id ExternalIdentity, policy ProvisionPolicy) (*model.User, string, string, error)
+	LoginWithPasskey(ctx context.Context, userID string, secondFactor bool) (*model.User, string, string, error)
 }
 
 type authService struct {
-	db           *gorm.DB
+	tx           database.Transactor
+	events       outbox.Recorder
+	jobs         queue.Enqueuer
 	userRepo     repository.UserRepository
 	passwordRepo repository.PasswordRepository
 	loginLogRepo repository.LoginLogRepository
+	identityRepo repository.IdentityRepository
+	verifiers    []CredentialVerifier
+	secondFactor SecondFactor
 	tokenService TokenService
+	jwtCfg       security.JWTConfig
+	activation   ActivationConfig
+	metrics      Metrics
+	alerts       SecurityAlerts
+	security     SecurityConfig
 }
 
 func NewAuthService(
-	db *gorm.DB,
+	tx database.Transactor,
+	events outbox.Recorder,
+	jobs queue.Enqueuer,
 	userRepo repository.UserRepository,
 	passwordRepo repository.PasswordRepository,
 	loginLogRepo repository.LoginLogRepository,
+	identityRepo repository.IdentityRepository,
+	verifiers []CredentialVerifier,
+	secondFactor SecondFactor,
 	tokenService TokenService,
+	jwtCfg security.JWTConfig,
+	activation ActivationConfig,
+	metrics Metrics,
+	alerts SecurityAlerts,
+	securityCfg SecurityConfig,
 ) AuthService {
 	return &authService{
-		db:           db,
+		tx:           tx,
+		events:       events,
+		jobs:         jobs,
 		userRepo:     userRepo,
 		passwordRepo: passwordRepo,
 		loginLogRepo: loginLogRepo,
+		identityRepo: identityRepo,
+		verifiers:    verifiers,
+		secondFactor: secondFactor,
 		tokenService: tokenService,
+		jwtCfg:       jwtCfg,
+		activation:   activation,
+		metrics:      metrics,
+		alerts:       alerts,
+		security:     securityCfg,
 	}
 }
 
-/* Register creates a new user with the given username, password, and role. */
+/* Register creates a new user with the given username, email, password, and role, and queues an activation email. Roles above user need a caller who could grant them, which the public Register RPC never has. */
 func (s *authService) Register(
 	ctx context.Context,
-	username, password, role string) (*model.User, error) {
-	//Check for user data existence
-	_, err := s.userRepo.FindByUsername(ctx, username)
-
-	if err == nil {
-		return nil, ErrUserAlreadyExists
+	username, email, password, role string) (user *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "authService.Register")
+	span.SetAttributes(attribute.String("user.role", role))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	if role != model.RoleUser && !canGrant(ctx, role) {
+		return nil, ErrRoleNotAllowed.WithDetail("role", role)
 	}
 
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
+	email = normalizeEmail(email)
+	hashedPassword, err := s.prepareUser(ctx, username, email, password)
+	if err != nil {
 		return nil, err
 	}
 
-	//Hash Password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	//Create User
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		user = &model.User{
+			Username:         username,
+			Email:            &email,
+			Role:             role,
+			IsActive:         true,
+			IsActivated:      false,
+			ActivationSentAt: &now,
+		}
+
+		if err := s.createUser(ctx, user, hashedPassword, actorID(ctx)); err != nil {
+			return err
+		}
+
+		return enqueueActivationEmail(ctx, s.jobs, user.ID.String())
+	})
+
 	if err != nil {
 		return nil, err
 	}
 
-	//Create User
-	var user *model.User
+	s.metrics.Registered(role)
+	return user, nil
+}
+
+/* RegisterInvited creates the account for an accepted invitation, with a password already hashed by HashPassword. The user gets the invitation's email and role and is activated straight away. The caller marks the invitation accepted in the same transaction. */
+func (s *authService) RegisterInvited(
+	ctx context.Context,
+	inv *model.Invitation,
+	username string,
+	hashedPassword []byte) (user *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "authService.RegisterInvited")
+	span.SetAttributes(attribute.String("user.role", inv.Role))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	if err := s.checkAvailable(ctx, username, inv.Email); err != nil {
+		return nil, err
+	}
+
+	// The inviter granted the role, not the anonymous invitee.
+	var inviter string
+	if inv.InvitedBy != nil {
+		inviter = inv.InvitedBy.String()
+	}
 
-	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
 		user = &model.User{
 			Username:    username,
-			Role:        role,
+			Email:       &inv.Email,
+			Role:        inv.Role,
 			IsActive:    true,
-			IsActivated: false,
+			IsActivated: true,
 		}
 
-		if err := s.userRepo.Create(ctx, user); err != nil {
+		return s.createUser(ctx, user, hashedPassword, inviter)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.Registered(inv.Role)
+	return user, nil
+}
+
+/* Activate activates the user an activation token was sent to. Each token works once, and only the newest one sent to the user works. */
+func (s *authService) Activate(ctx context.Context, token string) error {
+	userID, tokenID, err := security.ParseActivationToken(s.jwtCfg, token)
+	if err != nil {
+		return ErrInvalidActivationToken.Wrap(err)
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return ErrInvalidActivationToken.Wrap(err)
+	}
+
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		err := s.userRepo.ConsumeActivationToken(ctx, userID, tokenID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidActivationToken
+		}
+		if err != nil {
 			return err
 		}
 
-		passwordEntry := &model.PasswordMaster{
-			UserID:       user.ID,
-			PasswordHash: string(hashedPassword),
-			IsActive:     true,
-		}
+		return s.recordEvent(ctx, events.UserActivated, uid, events.UserActivatedPayload{
+			UserID: userID,
+		})
+	})
+}
 
-		if err := s.passwordRepo.Create(ctx, passwordEntry); err != nil {
+/* ResendActivation queues a new activation email for the user registered with email, at most once per resend interval. Unknown emails, activated users and requests within the interval all succeed without sending anything, so the call does not reveal which accounts exist. */
+func (s *authService) ResendActivation(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, normalizeEmail(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.metrics.ActivationResent(ResendOutcomeIgnored)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.IsActivated || !user.IsActive {
+		s.metrics.ActivationResent(ResendOutcomeIgnored)
+		return nil
+	}
+
+	throttled := false
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		err := s.userRepo.MarkActivationSent(ctx, user.ID.String(), now, now.Add(-s.activation.ResendInterval))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			throttled = true
+			return nil
+		}
+		if err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueActivationEmail(ctx, s.jobs, user.ID.String())
 	})
-
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return user, nil
+	if throttled {
+		s.metrics.ActivationResent(ResendOutcomeThrottled)
+	} else {
+		s.metrics.ActivationResent(ResendOutcomeSent)
+	}
+	return nil
 }
 
-/* ActivateUser sets the IsActivated flag of a user to true. */
+/* ActivateUser sets the IsActivated flag of a user to true. It is the administrator's way to activate an account without its activation email. */
 func (s *authService) ActivateUser(ctx context.Context, userID string) error {
-	user, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		return err
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.findUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if user.IsActivated {
+			return nil
+		}
+
+		user.IsActivated = true
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		return s.recordEvent(ctx, events.UserActivated, user.ID, events.UserActivatedPayload{
+			UserID:  userID,
+			ActorID: actorID(ctx),
+		})
+	})
+}
+
+/* DeactivateUser clears the IsActive flag of a user and revokes their sessions. Callers cannot deactivate a user whose role is above their own. */
+func (s *authService) DeactivateUser(ctx context.Context, userID string) error {
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.findUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !canGrant(ctx, user.Role) {
+			return ErrUserOutranks.WithDetail("user_id", userID)
+		}
+
+		if !user.IsActive {
+			return nil
+		}
+
+		user.IsActive = false
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.tokenService.RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+
+		return s.recordEvent(ctx, events.UserDeactivated, user.ID, events.UserDeactivatedPayload{
+			UserID:  userID,
+			ActorID: actorID(ctx),
+		})
+	})
+}
+
+/* ChangeRole assigns a new role to a user. Callers cannot grant a role above their own, nor change the role of a user whose role is above their own. */
+func (s *authService) ChangeRole(ctx context.Context, userID, role string) error {
+	if !canGrant(ctx, role) {
+		return ErrRoleNotAllowed.WithDetail("role", role)
 	}
 
-	user.IsActivated = true
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.findUser(ctx, userID)
+		if err != nil {
+			return err
+		}
 
-	return s.userRepo.Update(ctx, user)
+		if !canGrant(ctx, user.Role) {
+			return ErrUserOutranks.WithDetail("user_id", userID)
+		}
+
+		if user.Role == role {
+			return nil
+		}
+
+		return s.setRole(ctx, user, role)
+	})
 }
 
-/* Login authenticates a user with the given username and password. */
+/* Login authenticates a user with the given username and password, asking each credential verifier in turn. Directory users are linked or provisioned like identity provider users. Users with a second factor get a *SecondFactorRequired error instead of tokens. */
 func (s *authService) Login(
 	ctx context.Context,
 	username, password string,
-) (*model.User, string, string, error) {
-
-	user, err := s.userRepo.FindByUsername(ctx, username)
+) (user *model.User, access string, refresh string, err error) {
+	ctx, span := tracer.Start(ctx, "authService.Login")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	cred, err := s.verifyPassword(ctx, username, password)
 	if err != nil {
-		s.writeLoginLog(ctx, nil, "Invalid username", "error")
-		return nil, "", "", ErrInvalidCredential
+		var failure *CredentialFailure
+		if errors.As(err, &failure) {
+			s.loginFailed(ctx, failure.User, username, failure.Reason)
+			err = ErrInvalidCredential
+		}
+		s.metrics.LoginAttempt(loginOutcome(err))
+		return nil, "", "", err
 	}
 
-	if !user.IsActive || !user.IsActivated {
-		return nil, "", "", ErrUserInactive
-	}
+	var options []byte
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		user = cred.User
+		if user == nil {
+			if user, err = s.signInIdentity(ctx, *cred.Identity, cred.Policy); err != nil {
+				return err
+			}
+		}
 
-	pass, err := s.passwordRepo.FindActiveByUserID(ctx, user.ID.String())
-	if err != nil {
-		return nil, "", "", ErrInvalidCredential
-	}
+		options, err = s.secondFactor.Begin(ctx, user)
+		if err != nil {
+			return err
+		}
+		if options != nil {
+			return s.writeLoginLog(ctx, &user.ID, "Password accepted, passkey required", "info")
+		}
 
-	if err := bcrypt.CompareHashAndPassword(
-		[]byte(pass.PasswordHash),
-		[]byte(password),
-	); err != nil {
-		return nil, "", "", ErrInvalidCredential
+		access, refresh, err = s.issueLogin(ctx, user, "Login successful")
+		return err
+	})
+
+	if err == nil && options != nil {
+		// Counted when the second factor finishes the login.
+		return nil, "", "", &SecondFactorRequired{UserID: user.ID.String(), Options: options}
 	}
 
-	access, refresh, err := s.tokenService.IssueTokens(ctx, user)
+	s.metrics.LoginAttempt(loginOutcome(err))
 	if err != nil {
 		return nil, "", "", err
 	}
 
-	s.writeLoginLog(ctx, &user.ID, "Login successful", "success")
 	return user, access, refresh, nil
 }
 
@@ -158,17 +397,569 @@ func (s *authService) Logout(ctx context.Context, refreshToken string) error {
 	return s.tokenService.Logout(ctx, refreshToken)
 }
 
+/* Refresh redeems a refresh token for a new token pair. The presented token is revoked, so it works once. */
+func (s *authService) Refresh(
+	ctx context.Context,
+	refreshToken string,
+) (user *model.User, access string, refresh string, err error) {
+	ctx, span := tracer.Start(ctx, "authService.Refresh")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		session, err := s.tokenService.Consume(ctx, refreshToken)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefresh
+		}
+		if err != nil {
+			return err
+		}
+
+		user, err = s.findUser(ctx, session.UserID.String())
+		if err != nil {
+			return err
+		}
+
+		if !user.IsActive {
+			return ErrUserInactive
+		}
+
+		access, refresh, err = s.tokenService.IssueTokens(ctx, user)
+		return err
+	})
+
+	switch {
+	case err == nil:
+		s.metrics.TokenRefreshed(RefreshOutcomeSuccess)
+	case errors.Is(err, ErrInvalidRefresh):
+		s.metrics.TokenRefreshed(RefreshOutcomeInvalid)
+	case errors.Is(err, ErrUserInactive):
+		s.metrics.TokenRefreshed(RefreshOutcomeInactive)
+	default:
+		s.metrics.TokenRefreshed(RefreshOutcomeError)
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return user, access, refresh, nil
+}
+
+/* LoginWithIdentity signs in a user authenticated by an external identity provider. The identity is matched by issuer and subject, then linked to the user with its verified email, and otherwise provisioned as policy allows. As with Login, users with a second factor get a *SecondFactorRequired error instead of tokens. */
+func (s *authService) LoginWithIdentity(
+	ctx context.Context,
+	id ExternalIdentity,
+	policy ProvisionPolicy,
+) (user *model.User, access string, refresh string, err error) {
+	ctx, span := tracer.Start(ctx, "authService.LoginWithIdentity")
+	span.SetAttributes(attribute.String("identity.issuer", id.Issuer))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	var options []byte
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if user, err = s.signInIdentity(ctx, id, policy); err != nil {
+			return err
+		}
+
+		options, err = s.secondFactor.Begin(ctx, user)
+		if err != nil {
+			return err
+		}
+		if options != nil {
+			return s.writeLoginLog(ctx, &user.ID, "SSO login accepted, passkey required", "info")
+		}
+
+		access, refresh, err = s.issueLogin(ctx, user, "SSO login successful")
+		return err
+	})
+
+	if err == nil && options != nil {
+		// Counted when the second factor finishes the login.
+		return nil, "", "", &SecondFactorRequired{UserID: user.ID.String(), Options: options}
+	}
+
+	s.metrics.LoginAttempt(loginOutcome(err))
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return user, access, refresh, nil
+}
+
+/* LoginWithPasskey signs in a user whose passkey assertion was verified, either instead of a password or as the second factor after one. */
+func (s *authService) LoginWithPasskey(
+	ctx context.Context,
+	userID string,
+	secondFactor bool,
+) (user *model.User, access string, refresh string, err error) {
+	ctx, span := tracer.Start(ctx, "authService.LoginWithPasskey")
+	span.SetAttributes(attribute.Bool("login.second_factor", secondFactor))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	message := "Passkey login successful"
+	if secondFactor {
+		message = "Login successful with passkey"
+	}
+
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if user, err = s.findUser(ctx, userID); err != nil {
+			return err
+		}
+
+		if !user.IsActive {
+			return ErrUserInactive
+		}
+		if !user.IsActivated {
+			return ErrUserNotActivated
+		}
+
+		access, refresh, err = s.issueLogin(ctx, user, message)
+		return err
+	})
+
+	s.metrics.LoginAttempt(loginOutcome(err))
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return user, access, refresh, nil
+}
+
+// verifyPassword asks each verifier in turn. A failure for an unknown
+// username moves on to the next verifier; if none knows the username,
+// the first such failure is returned.
+func (s *authService) verifyPassword(ctx context.Context, username, password string) (*Credential, error) {
+	var unknown *CredentialFailure
+	for _, v := range s.verifiers {
+		cred, err := v.VerifyPassword(ctx, username, password)
+
+		var failure *CredentialFailure
+		if errors.As(err, &failure) && failure.Unknown {
+			if unknown == nil {
+				unknown = failure
+			}
+			continue
+		}
+		return cred, err
+	}
+
+	if unknown == nil {
+		unknown = &CredentialFailure{Reason: "Invalid username", Unknown: true}
+	}
+	return nil, unknown
+}
+
+// signInIdentity resolves an identity to an active user, applies the
+// role the provider grants if it manages roles, and records the login
+// against the identity. It runs in the caller's transaction.
+func (s *authService) signInIdentity(
+	ctx context.Context,
+	id ExternalIdentity,
+	policy ProvisionPolicy,
+) (*model.User, error) {
+	user, identity, err := s.resolveIdentity(ctx, id, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	if !user.IsActivated {
+		return nil, ErrUserNotActivated
+	}
+
+	if policy.SyncRole && user.Role != policy.Role {
+		if err := s.setRole(ctx, user, policy.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	var email *string
+	if id.Email != "" {
+		email = &id.Email
+	}
+	if err := s.identityRepo.RecordLogin(ctx, identity.ID.String(), email, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// resolveIdentity finds the user an identity belongs to, linking or
+// provisioning one if needed.
+func (s *authService) resolveIdentity(
+	ctx context.Context,
+	id ExternalIdentity,
+	policy ProvisionPolicy,
+) (*model.User, *model.UserIdentity, error) {
+	identity, err := s.identityRepo.FindBySubject(ctx, id.Issuer, id.Subject)
+	if err == nil {
+		user, err := s.findUser(ctx, identity.UserID.String())
+		return user, identity, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	// Link to the account registered with the same verified email. Only
+	// activated accounts have proven they own their email; anyone could
+	// have registered an unactivated one with it.
+	var user *model.User
+	if email := id.verifiedEmail(); email != "" {
+		user, err = s.userRepo.FindByEmail(ctx, email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+		if user != nil && !user.IsActivated {
+			return nil, nil, ErrIdentityNotLinked
+		}
+	}
+
+	if user == nil {
+		if !policy.Create {
+			return nil, nil, ErrIdentityNotLinked
+		}
+		if user, err = s.provisionUser(ctx, id, policy.Role); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	identity = &model.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  id.Issuer,
+		Subject: id.Subject,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, nil, err
+	}
+
+	return user, identity, nil
+}
+
+// provisionUser creates an activated user without a password for an
+// identity provider account, with the provider's email if it verified it.
+func (s *authService) provisionUser(ctx context.Context, id ExternalIdentity, role string) (*model.User, error) {
+	username := id.username()
+	if username == "" {
+		return nil, ErrIdentityNotLinked
+	}
+
+	_, err := s.userRepo.FindByUsername(ctx, username)
+	if err == nil {
+		// Taken by an account we cannot prove belongs to this identity.
+		return nil, ErrUserAlreadyExists.WithDetail("username", username)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user := &model.User{
+		Username:    username,
+		Role:        role,
+		IsActive:    true,
+		IsActivated: true,
+	}
+	if email := id.verifiedEmail(); email != "" {
+		user.Email = &email
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordEvent(ctx, events.UserRegistered, user.ID, events.UserRegisteredPayload{
+		UserID:   user.ID.String(),
+		Username: user.Username,
+		Role:     user.Role,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.metrics.Registered(role)
+	return user, nil
+}
 
 /*------------------------------Helpers----------------------------------*/
+
+// issueLogin issues tokens to a user who has proven who they are, then
+// checks the address, logs the login and records the event. It runs in
+// the caller's transaction.
+func (s *authService) issueLogin(ctx context.Context, user *model.User, message string) (string, string, error) {
+	access, refresh, err := s.tokenService.IssueTokens(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.checkNewIP(ctx, user); err != nil {
+		return "", "", err
+	}
+
+	if err := s.writeLoginLog(ctx, &user.ID, message, "success"); err != nil {
+		return "", "", err
+	}
+
+	err = s.recordEvent(ctx, events.UserLoggedIn, user.ID, events.UserLoggedInPayload{
+		UserID:   user.ID.String(),
+		Username: user.Username,
+	})
+	return access, refresh, err
+}
+
+// prepareUser checks that username and email are free and hashes the
+// password, which is slow enough to keep out of transactions.
+func (s *authService) prepareUser(ctx context.Context, username, email, password string) ([]byte, error) {
+	if err := s.checkAvailable(ctx, username, email); err != nil {
+		return nil, err
+	}
+	return HashPassword(ctx, password)
+}
+
+// checkAvailable checks that no user has username or email.
+func (s *authService) checkAvailable(ctx context.Context, username, email string) error {
+	//Check for user data existence
+	_, err := s.userRepo.FindByUsername(ctx, username)
+
+	if err == nil {
+		return ErrUserAlreadyExists.WithDetail("username", username)
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	_, err = s.userRepo.FindByEmail(ctx, email)
+
+	if err == nil {
+		return ErrEmailAlreadyExists.WithDetail("email", email)
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// HashPassword hashes password for storage. It is slow by design, so
+// callers hash before opening a transaction rather than inside one.
+func HashPassword(ctx context.Context, password string) ([]byte, error) {
+	_, hashSpan := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer hashSpan.End()
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// createUser inserts user with its password and records the
+// registration. actor is who granted the user's role, for the
+// super-admin alert.
+func (s *authService) createUser(ctx context.Context, user *model.User, hashedPassword []byte, actor string) error {
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+
+	passwordEntry := &model.PasswordMaster{
+		UserID:       user.ID,
+		PasswordHash: string(hashedPassword),
+		IsActive:     true,
+	}
+
+	if err := s.passwordRepo.Create(ctx, passwordEntry); err != nil {
+		return err
+	}
+
+	if user.Role == model.RoleSuperAdmin {
+		err := s.alerts.Dispatch(ctx, events.SecuritySuperAdminGranted, events.SuperAdminGrantedPayload{
+			UserID:   user.ID.String(),
+			Username: user.Username,
+			ActorID:  actor,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.recordEvent(ctx, events.UserRegistered, user.ID, events.UserRegisteredPayload{
+		UserID:   user.ID.String(),
+		Username: user.Username,
+		Role:     user.Role,
+	})
+}
+
+func (s *authService) findUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound.WithDetail("user_id", userID)
+	}
+	return user, err
+}
+
+// setRole saves a new role for a user, alerting when it is super-admin.
+// It runs in the caller's transaction.
+func (s *authService) setRole(ctx context.Context, user *model.User, role string) error {
+	oldRole := user.Role
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if role == model.RoleSuperAdmin {
+		err := s.alerts.Dispatch(ctx, events.SecuritySuperAdminGranted, events.SuperAdminGrantedPayload{
+			UserID:   user.ID.String(),
+			Username: user.Username,
+			OldRole:  oldRole,
+			ActorID:  actorID(ctx),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.recordEvent(ctx, events.UserRoleChanged, user.ID, events.UserRoleChangedPayload{
+		UserID:  user.ID.String(),
+		OldRole: oldRole,
+		NewRole: role,
+		ActorID: actorID(ctx),
+	})
+}
+
+// recordEvent writes a user event to the outbox in the caller's transaction.
+func (s *authService) recordEvent(ctx context.Context, eventType string, userID uuid.UUID, payload interface{}) error {
+	return s.events.Record(ctx, eventType, events.AggregateUser, userID.String(), payload)
+}
+
+// loginOutcome labels a login attempt for the metrics.
+func loginOutcome(err error) string {
+	switch {
+	case err == nil:
+		return LoginOutcomeSuccess
+	case errors.Is(err, ErrInvalidCredential), errors.Is(err, ErrIdentityNotLinked):
+		return LoginOutcomeInvalidCredentials
+	case errors.Is(err, ErrUserInactive):
+		return LoginOutcomeInactive
+	case errors.Is(err, ErrUserNotActivated):
+		return LoginOutcomeNotActivated
+	default:
+		return LoginOutcomeError
+	}
+}
+
+// canGrant reports whether the caller may hand out role, or manage a user
+// who holds it: no one may act above their own role. Callers without a
+// role, such as services, may do neither.
+func canGrant(ctx context.Context, role string) bool {
+	callerRole, ok := middleware.RoleFromContext(ctx)
+	return ok && model.RoleRank(role) <= model.RoleRank(callerRole)
+}
+
+func actorID(ctx context.Context) string {
+	id, _ := middleware.UserIDFromContext(ctx)
+	return id
+}
+
 func (s *authService) writeLoginLog(
 	ctx context.Context,
 	userID *uuid.UUID,
 	message string,
 	logType string,
-) {
-	_ = s.loginLogRepo.Create(ctx, &model.LoginLog{
-		UserID: userID,
+) error {
+	entry := &model.LoginLog{
+		UserID:  userID,
 		Message: message,
 		LogType: logType,
+	}
+
+	client := middleware.ClientFromContext(ctx)
+	if client.IP != "" {
+		entry.IPAddress = &client.IP
+	}
+	if client.UserAgent != "" {
+		entry.UserAgent = &client.UserAgent
+	}
+
+	return s.loginLogRepo.Create(ctx, entry)
+}
+
+// loginFailed logs a failed login and raises an alert, counted as a
+// lockout, on every FailureThreshold-th failure within FailureWindow. Failures with an
+// unknown username are counted per IP address. Errors are logged, not
+// returned, so the caller still sees invalid credentials.
+func (s *authService) loginFailed(ctx context.Context, user *model.User, username, message string) {
+	var lockedOut bool
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var userID *uuid.UUID
+		if user != nil {
+			userID = &user.ID
+		}
+
+		if err := s.writeLoginLog(ctx, userID, message, "error"); err != nil {
+			return err
+		}
+
+		payload := events.LoginFailuresPayload{
+			Username:      username,
+			IPAddress:     middleware.ClientFromContext(ctx).IP,
+			WindowSeconds: int64(s.security.FailureWindow / time.Second),
+		}
+		if user != nil {
+			payload.UserID = user.ID.String()
+		} else if payload.IPAddress == "" {
+			return nil
+		}
+
+		since := time.Now().Add(-s.security.FailureWindow)
+		n, err := s.loginLogRepo.CountRecentFailures(ctx, payload.UserID, payload.IPAddress, since)
+		if err != nil {
+			return err
+		}
+
+		if s.security.FailureThreshold <= 0 || n == 0 || n%int64(s.security.FailureThreshold) != 0 {
+			return nil
+		}
+
+		payload.Failures = n
+		lockedOut = true
+		return s.alerts.Dispatch(ctx, events.SecurityLoginFailures, payload)
+	})
+	if err != nil {
+		log.Printf("❌ failed to record login failure: %v", err)
+		return
+	}
+	if lockedOut {
+		s.metrics.LockedOut()
+	}
+}
+
+// checkNewIP raises an alert when a user who has logged in before does so
+// from an address never seen for them. Logins from before addresses were
+// recorded are ignored, so a user's first login after the upgrade only
+// establishes a baseline.
+func (s *authService) checkNewIP(ctx context.Context, user *model.User) error {
+	client := middleware.ClientFromContext(ctx)
+	if client.IP == "" {
+		return nil
+	}
+
+	total, err := s.loginLogRepo.CountSuccesses(ctx, user.ID.String(), "")
+	if err != nil || total == 0 {
+		return err
+	}
+
+	fromIP, err := s.loginLogRepo.CountSuccesses(ctx, user.ID.String(), client.IP)
+	if err != nil || fromIP > 0 {
+		return err
+	}
+
+	return s.alerts.Dispatch(ctx, events.SecurityLoginNewIP, events.LoginNewIPPayload{
+		UserID:    user.ID.String(),
+		Username:  user.Username,
+		IPAddress: client.IP,
+		UserAgent: client.UserAgent,
 	})
 }
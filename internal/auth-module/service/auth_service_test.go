@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"admin-portal/internal/auth-module/events"
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/auth-module/repository/memory"
+	"admin-portal/internal/auth-module/service"
+	"admin-portal/internal/shared/security"
+)
+
+const password = "correct-horse-battery"
+
+// countingMetrics counts lockouts and discards the rest.
+type countingMetrics struct {
+	service.NopMetrics
+	lockouts int
+}
+
+func (m *countingMetrics) LockedOut() { m.lockouts++ }
+
+// authFixture is an AuthService on in-memory repositories, with what it
+// wrote outside them.
+type authFixture struct {
+	service.AuthService
+	logs    repository.LoginLogRepository
+	metrics *countingMetrics
+	outbox  *memory.Outbox
+	queue   *memory.Queue
+}
+
+func newAuthService() *authFixture {
+	users := memory.NewUserRepository()
+	passwords := memory.NewPasswordRepository()
+	logs := memory.NewLoginLogRepository()
+	f := &authFixture{logs: logs, metrics: &countingMetrics{}, outbox: memory.NewOutbox(), queue: memory.NewQueue()}
+
+	jwtCfg := security.JWTConfig{
+		Secret:          "unit-test-secret",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Issuer:          "admin-portal",
+	}
+	f.AuthService = service.NewAuthService(
+		memory.NewTransactor(),
+		f.outbox,
+		f.queue,
+		users,
+		passwords,
+		logs,
+		memory.NewIdentityRepository(),
+		[]service.CredentialVerifier{service.NewPasswordVerifier(users, passwords)},
+		service.NewPasskeySecondFactor(nil, memory.NewWebAuthnRepository()),
+		service.NewTokenService(jwtCfg, memory.NewUserSessionRepository()),
+		jwtCfg,
+		service.ActivationConfig{TokenTTL: time.Hour, ResendInterval: time.Minute},
+		f.metrics,
+		service.NopSecurityAlerts{},
+		service.SecurityConfig{FailureThreshold: 5, FailureWindow: time.Minute},
+	)
+	return f
+}
+
+// eventTypes lists the types of the events recorded so far.
+func (f *authFixture) eventTypes() []string {
+	var types []string
+	for _, e := range f.outbox.Events() {
+		types = append(types, e.EventType)
+	}
+	return types
+}
+
+func TestRegisterOnMemoryRepos(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthService()
+
+	user, err := f.Register(ctx, "alice", "Alice@Example.com", password, model.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.IsActivated || user.Email == nil || *user.Email != "alice@example.com" {
+		t.Errorf("registered user = activated %v, email %v", user.IsActivated, user.Email)
+	}
+
+	jobs := f.queue.Jobs()
+	if len(jobs) != 1 || jobs[0].Kind != service.KindActivationEmail {
+		t.Errorf("queued %+v, want one activation email", jobs)
+	}
+	if got := f.eventTypes(); len(got) != 1 || got[0] != events.UserRegistered {
+		t.Errorf("events = %v, want %s", got, events.UserRegistered)
+	}
+
+	if _, err := f.Register(ctx, "alice", "other@example.com", password, model.RoleUser); !errors.Is(err, service.ErrUserAlreadyExists) {
+		t.Errorf("duplicate username: err = %v", err)
+	}
+	if _, err := f.Register(ctx, "mallory", "mallory@example.com", password, model.RoleSuperAdmin); !errors.Is(err, service.ErrRoleNotAllowed) {
+		t.Errorf("anonymous super-admin: err = %v", err)
+	}
+}
+
+func TestLoginAndRefreshOnMemoryRepos(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthService()
+
+	user, err := f.Register(ctx, "bob", "bob@example.com", password, model.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := f.Login(ctx, "bob", password); !errors.Is(err, service.ErrUserNotActivated) {
+		t.Fatalf("login before activation: err = %v", err)
+	}
+
+	admin := middleware.WithAuthContext(ctx, "00000000-0000-0000-0000-000000000001", "root", model.RoleSuperAdmin)
+	if err := f.ActivateUser(admin, user.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := f.Login(ctx, "bob", "wrong password"); !errors.Is(err, service.ErrInvalidCredential) {
+		t.Errorf("wrong password: err = %v", err)
+	}
+	failures, err := f.logs.CountRecentFailures(ctx, user.ID.String(), "", time.Now().Add(-time.Minute))
+	if err != nil || failures != 1 {
+		t.Errorf("recent failures = %d, %v, want 1", failures, err)
+	}
+
+	_, access, refresh, err := f.Login(ctx, "bob", password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := middleware.ValidateAccessToken(security.JWTConfig{Secret: "unit-test-secret"}, access); err != nil || claims.UserID != user.ID.String() {
+		t.Errorf("access token claims = %+v, %v", claims, err)
+	}
+
+	_, _, rotated, err := f.Refresh(ctx, refresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := f.Refresh(ctx, refresh); !errors.Is(err, service.ErrInvalidRefresh) {
+		t.Errorf("reused refresh token: err = %v", err)
+	}
+	if _, _, _, err := f.Refresh(ctx, rotated); err != nil {
+		t.Errorf("rotated refresh token: %v", err)
+	}
+
+	want := []string{events.UserRegistered, events.UserActivated, events.UserLoggedIn}
+	if got := f.eventTypes(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestRepeatedFailuresCountAsLockout(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthService()
+
+	user, err := f.Register(ctx, "carol", "carol@example.com", password, model.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := middleware.WithAuthContext(ctx, "00000000-0000-0000-0000-000000000001", "root", model.RoleSuperAdmin)
+	if err := f.ActivateUser(admin, user.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The fixture's threshold is 5 failures a minute.
+	for i := 1; i <= 10; i++ {
+		if _, _, _, err := f.Login(ctx, "carol", "wrong password"); !errors.Is(err, service.ErrInvalidCredential) {
+			t.Fatalf("attempt %d: err = %v", i, err)
+		}
+		if want := i / 5; f.metrics.lockouts != want {
+			t.Fatalf("after %d failures, lockouts = %d, want %d", i, f.metrics.lockouts, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+// CredentialVerifier checks a username and password against one store of
+// credentials. Login asks each verifier in turn until one knows the
+// username.
+type CredentialVerifier interface {
+	VerifyPassword(ctx context.Context, username, password string) (*Credential, error)
+}
+
+// Credential is a successful verification: a local User, or an Identity
+// in an external directory, which Login resolves to a user as policy
+// allows.
+type Credential struct {
+	User *model.User
+
+	Identity *ExternalIdentity
+	Policy   ProvisionPolicy
+}
+
+// CredentialFailure is a failed verification. Reason goes to the login
+// log; the caller only ever sees ErrInvalidCredential. Unknown means the
+// verifier has no such username, so the next one may.
+type CredentialFailure struct {
+	User    *model.User
+	Reason  string
+	Unknown bool
+}
+
+func (f *CredentialFailure) Error() string {
+	return f.Reason
+}
+
+type passwordVerifier struct {
+	userRepo     repository.UserRepository
+	passwordRepo repository.PasswordRepository
+}
+
+// NewPasswordVerifier checks the bcrypt hashes in password_master.
+// Inactive and not yet activated users fail with ErrUserInactive and
+// ErrUserNotActivated before their password is checked.
+func NewPasswordVerifier(
+	userRepo repository.UserRepository,
+	passwordRepo repository.PasswordRepository,
+) CredentialVerifier {
+	return &passwordVerifier{
+		userRepo:     userRepo,
+		passwordRepo: passwordRepo,
+	}
+}
+
+func (v *passwordVerifier) VerifyPassword(ctx context.Context, username, password string) (*Credential, error) {
+	user, err := v.userRepo.FindByUsername(ctx, username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &CredentialFailure{Reason: "Invalid username", Unknown: true}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	if !user.IsActivated {
+		return nil, ErrUserNotActivated
+	}
+
+	// Users provisioned from a directory or identity provider have no
+	// password here.
+	pass, err := v.passwordRepo.FindActiveByUserID(ctx, user.ID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &CredentialFailure{User: user, Reason: "No active password", Unknown: true}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, compareSpan := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+	err = bcrypt.CompareHashAndPassword(
+		[]byte(pass.PasswordHash),
+		[]byte(password),
+	)
+	compareSpan.End()
+	if err != nil {
+		return nil, &CredentialFailure{User: user, Reason: "Invalid password"}
+	}
+
+	return &Credential{User: user}, nil
+}
@@ -1,11 +1,43 @@
 package service
 
-import "errors"
+import apperrors "admin-portal/internal/errors"
 
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserInactive      = errors.New("user is inactive")
-	ErrUserNotActivated  = errors.New("user is not activated")
-	ErrInvalidCredential = errors.New("invalid credentials")
-	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserNotFound      = apperrors.New(apperrors.CodeNotFound, "USER_NOT_FOUND", "user not found")
+	ErrUserInactive      = apperrors.New(apperrors.CodeFailedPrecondition, "USER_INACTIVE", "user is inactive")
+	ErrUserNotActivated  = apperrors.New(apperrors.CodeFailedPrecondition, "USER_NOT_ACTIVATED", "user is not activated")
+	ErrInvalidCredential = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_CREDENTIALS", "invalid credentials")
+	ErrUserAlreadyExists = apperrors.New(apperrors.CodeAlreadyExists, "USER_ALREADY_EXISTS", "user already exists")
+	ErrRoleNotAllowed    = apperrors.New(apperrors.CodePermissionDenied, "ROLE_NOT_ALLOWED", "cannot grant a role above your own")
+	ErrUserOutranks      = apperrors.New(apperrors.CodePermissionDenied, "USER_OUTRANKS_CALLER", "cannot manage a user whose role is above your own")
+	ErrInvalidRefresh    = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_REFRESH_TOKEN", "refresh token is invalid or expired")
+	ErrAccessDenied      = apperrors.New(apperrors.CodePermissionDenied, "ACCESS_DENIED", "cannot access another user's data")
+	ErrNotAUser          = apperrors.New(apperrors.CodeFailedPrecondition, "NOT_A_USER", "caller is a service, not a user")
+	ErrInvalidAPIKey     = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_API_KEY", "API key is invalid, revoked or expired")
+	ErrAPIKeyNotFound    = apperrors.New(apperrors.CodeNotFound, "API_KEY_NOT_FOUND", "API key not found")
+	ErrAPIKeyNotAllowed  = apperrors.New(apperrors.CodePermissionDenied, "API_KEY_NOT_ALLOWED", "API keys cannot create API keys")
+	ErrInvalidScope      = apperrors.New(apperrors.CodeInvalidArgument, "INVALID_SCOPE", "scope must be \"*\", \"/pkg.Service/\" or \"/pkg.Service/Method\"")
+	ErrScopeRequired     = apperrors.New(apperrors.CodeInvalidArgument, "SCOPE_REQUIRED", "an API key needs at least one scope; use \"*\" for every method")
+	ErrInvalidExpiry     = apperrors.New(apperrors.CodeInvalidArgument, "INVALID_EXPIRY", "expiry must be in the future")
+	ErrIdentityNotLinked = apperrors.New(apperrors.CodePermissionDenied, "IDENTITY_NOT_LINKED", "no account is linked to this identity")
+	ErrSSODisabled       = apperrors.New(apperrors.CodeFailedPrecondition, "SSO_DISABLED", "single sign-on is not configured")
+	ErrInvalidSSOState   = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_SSO_STATE", "login request is unknown or expired")
+	ErrSSOFailed         = apperrors.New(apperrors.CodeUnauthenticated, "SSO_FAILED", "identity provider login failed")
+
+	ErrEmailAlreadyExists     = apperrors.New(apperrors.CodeAlreadyExists, "EMAIL_ALREADY_EXISTS", "email is already registered")
+	ErrInvalidActivationToken = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_ACTIVATION_TOKEN", "activation link is invalid, expired or already used")
+
+	ErrInvitationNotFound = apperrors.New(apperrors.CodeNotFound, "INVITATION_NOT_FOUND", "invitation not found")
+	ErrInvitationPending  = apperrors.New(apperrors.CodeAlreadyExists, "INVITATION_PENDING", "an invitation for this email is already pending")
+	ErrInvalidInvitation  = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_INVITATION", "invitation link is invalid, expired, revoked or already used")
+
+	ErrOAuthClientNotFound = apperrors.New(apperrors.CodeNotFound, "OAUTH_CLIENT_NOT_FOUND", "OAuth client not found")
+	ErrInvalidRedirectURI  = apperrors.New(apperrors.CodeInvalidArgument, "INVALID_REDIRECT_URI", "redirect URI must be a registered https URI, or http on the loopback interface")
+
+	ErrPasskeysDisabled        = apperrors.New(apperrors.CodeFailedPrecondition, "PASSKEYS_DISABLED", "passkeys are not configured")
+	ErrPasskeyNotAllowed       = apperrors.New(apperrors.CodePermissionDenied, "PASSKEY_NOT_ALLOWED", "API keys cannot register passkeys")
+	ErrPasskeyNotFound         = apperrors.New(apperrors.CodeNotFound, "PASSKEY_NOT_FOUND", "passkey not found")
+	ErrPasskeyExists           = apperrors.New(apperrors.CodeAlreadyExists, "PASSKEY_EXISTS", "passkey is already registered")
+	ErrInvalidPasskey          = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_PASSKEY", "passkey response is invalid")
+	ErrInvalidPasskeyChallenge = apperrors.New(apperrors.CodeUnauthenticated, "INVALID_PASSKEY_CHALLENGE", "passkey request is unknown or expired")
 )
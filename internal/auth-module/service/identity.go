@@ -0,0 +1,48 @@
+package service
+
+import (
+	"strings"
+)
+
+// ExternalIdentity is a user as an external identity provider vouched
+// for them.
+type ExternalIdentity struct {
+	// Issuer and Subject identify the account at the provider.
+	Issuer  string
+	Subject string
+
+	Email         string
+	EmailVerified bool
+	// Username is the provider's suggestion for a new local username.
+	Username string
+}
+
+// ProvisionPolicy says what to do with an identity that is not linked to
+// a user and matches no user by verified email, and whether the provider
+// decides the role of the users it signs in.
+type ProvisionPolicy struct {
+	// Create makes a new, already activated user with Role.
+	Create bool
+	Role   string
+	// SyncRole also sets Role on an existing user at each login, for
+	// providers whose groups map onto roles.
+	SyncRole bool
+}
+
+// username picks the username for a provisioned user: the verified email,
+// or else the provider's suggestion.
+func (id ExternalIdentity) username() string {
+	if email := id.verifiedEmail(); email != "" {
+		return email
+	}
+	return strings.TrimSpace(id.Username)
+}
+
+// verifiedEmail returns the email in the form emails are stored in, or ""
+// if the provider did not verify it.
+func (id ExternalIdentity) verifiedEmail() string {
+	if !id.EmailVerified {
+		return ""
+	}
+	return normalizeEmail(id.Email)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/config"
+	"admin-portal/internal/shared/mail"
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/security"
+)
+
+// KindInvitationEmail is the queue job that sends one invitation email.
+const KindInvitationEmail = "auth.invitation_email"
+
+type InvitationEmailPayload struct {
+	InvitationID string `json:"invitation_id"`
+}
+
+// InvitationConfig tunes invitations.
+type InvitationConfig struct {
+	// TTL is how long an invitation can be accepted.
+	TTL time.Duration
+	// URL is the page where invitees choose a username and password.
+	// The token is added as the token query parameter.
+	URL string
+}
+
+func LoadInvitationConfig() InvitationConfig {
+	return InvitationConfig{
+		TTL: config.Duration("INVITATION_TTL", 7*24*time.Hour),
+		URL: config.String("INVITATION_URL", "http://localhost:8080/invitations/accept"),
+	}
+}
+
+// enqueueInvitationEmail queues the email for an invitation in the
+// caller's transaction.
+func enqueueInvitationEmail(ctx context.Context, db *gorm.DB, invitationID string) error {
+	_, err := queue.Enqueue(ctx, db, KindInvitationEmail,
+		InvitationEmailPayload{InvitationID: invitationID},
+		queue.WithUniqueKey(KindInvitationEmail+":"+invitationID),
+	)
+	if errors.Is(err, queue.ErrDuplicate) {
+		return nil
+	}
+	return err
+}
+
+// InvitationMailer sends invitation emails from the worker. The token is
+// made when the email is sent and only its hash is stored, so it never
+// sits in the queue. Register it with the queue worker for
+// KindInvitationEmail.
+type InvitationMailer struct {
+	invitations repository.InvitationRepository
+	users       repository.UserRepository
+	mailer      mail.Mailer
+	cfg         InvitationConfig
+}
+
+func NewInvitationMailer(
+	invitations repository.InvitationRepository,
+	users repository.UserRepository,
+	mailer mail.Mailer,
+	cfg InvitationConfig,
+) *InvitationMailer {
+	return &InvitationMailer{invitations: invitations, users: users, mailer: mailer, cfg: cfg}
+}
+
+// Handle sends the email queued by Invite. Invitations that were
+// revoked, accepted or removed in the meantime, or have expired, are
+// skipped.
+func (m *InvitationMailer) Handle(ctx context.Context, job *queue.Job) error {
+	var payload InvitationEmailPayload
+	if err := job.Decode(&payload); err != nil {
+		return queue.Permanent(err)
+	}
+
+	inv, err := m.invitations.FindByID(ctx, payload.InvitationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !inv.Pending(time.Now()) {
+		return nil
+	}
+
+	inviter := "An administrator"
+	if inv.InvitedBy != nil {
+		user, err := m.users.FindByID(ctx, inv.InvitedBy.String())
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			inviter = user.Username
+		}
+	}
+
+	token, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := m.invitations.SetTokenHash(ctx, inv.ID.String(), hash); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(m.cfg.URL)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("INVITATION_URL: %w", err))
+	}
+	q := link.Query()
+	q.Set("token", token)
+	link.RawQuery = q.Encode()
+
+	body := fmt.Sprintf("%s invited you to the admin portal as %s. Open the link below to choose a username and password.", inviter, inv.Role) + "\n\n" +
+		link.String() + "\n\n" +
+		fmt.Sprintf("The link works once and expires on %s. If you did not expect this invitation, ignore this email.\n",
+			inv.ExpiresAt.UTC().Format("2 Jan 2006 15:04 MST"))
+
+	return m.mailer.Send(ctx, mail.Message{
+		To:      inv.Email,
+		Subject: "You are invited to the admin portal",
+		Body:    body,
+	})
+}
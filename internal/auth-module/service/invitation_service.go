@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/database"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/tracing"
+)
+
+// InvitationService lets admins onboard colleagues without
+// self-registration. The invitee sets their own username and password
+// and gets an account that is already activated.
+type InvitationService interface {
+	Invite(ctx context.Context, email, role string) (*model.Invitation, error)
+	List(ctx context.Context, f repository.InvitationFilter) ([]*model.Invitation, int64, error)
+	Revoke(ctx context.Context, id string) error
+	Accept(ctx context.Context, token, username, password string) (*model.User, error)
+}
+
+type invitationService struct {
+	db             *gorm.DB
+	invitationRepo repository.InvitationRepository
+	userRepo       repository.UserRepository
+	authService    AuthService
+	cfg            InvitationConfig
+}
+
+func NewInvitationService(
+	db *gorm.DB,
+	invitationRepo repository.InvitationRepository,
+	userRepo repository.UserRepository,
+	authService AuthService,
+	cfg InvitationConfig,
+) InvitationService {
+	return &invitationService{
+		db:             db,
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		authService:    authService,
+		cfg:            cfg,
+	}
+}
+
+/* Invite records an invitation to role for email and queues the email that carries its token. Only users invite, since the inviter is rechecked on acceptance. Callers cannot invite to a role above their own, nor invite an email that is registered or already has a pending invitation. */
+func (s *invitationService) Invite(ctx context.Context, email, role string) (*model.Invitation, error) {
+	if _, ok := middleware.ServiceFromContext(ctx); ok {
+		return nil, ErrNotAUser
+	}
+	if !canGrant(ctx, role) {
+		return nil, ErrRoleNotAllowed.WithDetail("role", role)
+	}
+
+	email = normalizeEmail(email)
+	_, err := s.userRepo.FindByEmail(ctx, email)
+	if err == nil {
+		return nil, ErrEmailAlreadyExists.WithDetail("email", email)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = s.invitationRepo.FindPendingByEmail(ctx, email, now)
+	if err == nil {
+		return nil, ErrInvitationPending.WithDetail("email", email)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	inv := &model.Invitation{
+		Email:     email,
+		Role:      role,
+		ExpiresAt: now.Add(s.cfg.TTL),
+	}
+	if userID, ok := middleware.UserIDFromContext(ctx); ok {
+		if id, err := uuid.Parse(userID); err == nil {
+			inv.InvitedBy = &id
+		}
+	}
+
+	err = database.Transaction(ctx, s.db, func(ctx context.Context) error {
+		if err := s.invitationRepo.Create(ctx, inv); err != nil {
+			return err
+		}
+		return enqueueInvitationEmail(ctx, s.db, inv.ID.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+func (s *invitationService) List(ctx context.Context, f repository.InvitationFilter) ([]*model.Invitation, int64, error) {
+	return s.invitationRepo.List(ctx, f)
+}
+
+/* Revoke stops an invitation from being accepted. Like Invite, it is limited to invitations for the caller's role or below. Accepted invitations are left alone. */
+func (s *invitationService) Revoke(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrInvitationNotFound.WithDetail("invitation_id", id)
+	}
+
+	inv, err := s.invitationRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvitationNotFound.WithDetail("invitation_id", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !canGrant(ctx, inv.Role) {
+		return ErrRoleNotAllowed.WithDetail("role", inv.Role)
+	}
+
+	return s.invitationRepo.Revoke(ctx, id, time.Now())
+}
+
+/* Accept creates the account an invitation token was emailed for, with the username and password the invitee chose. Each invitation is accepted once, and only with the token from its newest email. It is refused once the inviter is deactivated or removed, or no longer holds a role at least as high as the invitation's. */
+func (s *invitationService) Accept(ctx context.Context, token, username, password string) (user *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "invitationService.Accept")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	hash := security.HashAPIKey(token)
+
+	// Hash before the transaction so bcrypt does not hold it open.
+	hashedPassword, err := HashPassword(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(ctx, s.db, func(ctx context.Context) error {
+		inv, err := s.invitationRepo.FindPendingByTokenHash(ctx, hash, time.Now())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.checkInviter(ctx, inv); err != nil {
+			return err
+		}
+
+		user, err = s.authService.RegisterInvited(ctx, inv, username, hashedPassword)
+		if err != nil {
+			return err
+		}
+
+		// Another request may have accepted it since it was read.
+		err = s.invitationRepo.MarkAccepted(ctx, inv.ID.String(), user.ID.String(), time.Now())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkInviter checks that whoever sent inv could still send it: the
+// role was checked against theirs at invite time, but they may have been
+// demoted or deactivated since.
+func (s *invitationService) checkInviter(ctx context.Context, inv *model.Invitation) error {
+	if inv.InvitedBy == nil {
+		return ErrInvalidInvitation
+	}
+
+	inviter, err := s.userRepo.FindByID(ctx, inv.InvitedBy.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidInvitation
+	}
+	if err != nil {
+		return err
+	}
+
+	if !inviter.IsActive || model.RoleRank(inv.Role) > model.RoleRank(inviter.Role) {
+		return ErrInvalidInvitation
+	}
+	return nil
+}
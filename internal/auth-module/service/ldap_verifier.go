@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/shared/config"
+	"admin-portal/internal/shared/ldap"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/tracing"
+)
+
+// LDAPConfig configures password logins against an LDAP or Active
+// Directory server. It is off while URL is empty.
+type LDAPConfig struct {
+	// URL is an ldap:// or ldaps:// URL. It is also the issuer of the
+	// identities directory users are linked by.
+	URL      string
+	StartTLS bool
+	TLS      *tls.Config
+	Timeout  time.Duration
+
+	// BindDN and BindPassword are the service account users are looked up
+	// with. Without them the lookup is anonymous.
+	BindDN       string
+	BindPassword string
+
+	// Users are searched for under BaseDN with UserFilter and
+	// UsernameAttribute equal to the login username.
+	BaseDN            string
+	UserFilter        string
+	UsernameAttribute string
+	EmailAttribute    string
+	// TrustEmail treats the directory's email addresses as verified, so a
+	// directory user is linked to the local user named by their email.
+	TrustEmail bool
+	// IDAttribute holds a stable ID for entries, e.g. entryUUID. Without
+	// it users are identified by DN and lose their link when renamed.
+	IDAttribute string
+
+	// A user's groups are the values of GroupAttribute and, when
+	// GroupBaseDN is set, the groups under it whose GroupMemberAttribute
+	// names the user's DN.
+	GroupAttribute       string
+	GroupBaseDN          string
+	GroupMemberAttribute string
+
+	// RoleGroups maps each role to the DNs of the groups that grant it.
+	// The highest role granted wins; DefaultRole applies when none is,
+	// and an empty DefaultRole refuses the login.
+	RoleGroups  map[string][]string
+	DefaultRole string
+
+	// AutoProvision creates users on their first login.
+	AutoProvision bool
+}
+
+// LoadLDAPConfig reads the configuration and, when LDAP is enabled,
+// checks it and loads the CA to verify the server with.
+func LoadLDAPConfig() (LDAPConfig, error) {
+	cfg := LDAPConfig{
+		URL:                  config.String("LDAP_URL", ""),
+		StartTLS:             config.Bool("LDAP_START_TLS", false),
+		Timeout:              config.Duration("LDAP_TIMEOUT", 10*time.Second),
+		BindDN:               config.String("LDAP_BIND_DN", ""),
+		BindPassword:         config.String("LDAP_BIND_PASSWORD", ""),
+		BaseDN:               config.String("LDAP_BASE_DN", ""),
+		UserFilter:           config.String("LDAP_USER_FILTER", "(objectClass=person)"),
+		UsernameAttribute:    config.String("LDAP_USERNAME_ATTRIBUTE", "uid"),
+		EmailAttribute:       config.String("LDAP_EMAIL_ATTRIBUTE", "mail"),
+		TrustEmail:           config.Bool("LDAP_TRUST_EMAIL", false),
+		IDAttribute:          config.String("LDAP_ID_ATTRIBUTE", ""),
+		GroupAttribute:       config.String("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+		GroupBaseDN:          config.String("LDAP_GROUP_BASE_DN", ""),
+		GroupMemberAttribute: config.String("LDAP_GROUP_MEMBER_ATTRIBUTE", "member"),
+		RoleGroups: map[string][]string{
+			model.RoleUser:       splitDNs(config.String("LDAP_USER_GROUPS", "")),
+			model.RoleAdmin:      splitDNs(config.String("LDAP_ADMIN_GROUPS", "")),
+			model.RoleSuperAdmin: splitDNs(config.String("LDAP_SUPER_ADMIN_GROUPS", "")),
+		},
+		DefaultRole:   config.String("LDAP_DEFAULT_ROLE", ""),
+		AutoProvision: config.Bool("LDAP_AUTO_PROVISION", true),
+	}
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+
+	if caFile := config.String("LDAP_CA_FILE", ""); caFile != "" {
+		pool, err := security.LoadCertPool(caFile)
+		if err != nil {
+			return LDAPConfig{}, fmt.Errorf("LDAP CA: %w", err)
+		}
+		cfg.TLS = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return cfg, cfg.validate()
+}
+
+func (c LDAPConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+func (c LDAPConfig) validate() error {
+	if !strings.HasPrefix(c.URL, "ldap://") && !strings.HasPrefix(c.URL, "ldaps://") {
+		return fmt.Errorf("LDAP_URL must be an ldap:// or ldaps:// URL, got %q", c.URL)
+	}
+	if c.BaseDN == "" {
+		return errors.New("LDAP_BASE_DN is required with LDAP_URL")
+	}
+	if c.BindDN != "" && c.BindPassword == "" {
+		return errors.New("LDAP_BIND_PASSWORD is required with LDAP_BIND_DN")
+	}
+	if _, err := ldap.ParseFilter(c.UserFilter); err != nil {
+		return fmt.Errorf("LDAP_USER_FILTER: %w", err)
+	}
+	if c.DefaultRole != "" && model.RoleRank(c.DefaultRole) == 0 {
+		return fmt.Errorf("invalid LDAP_DEFAULT_ROLE %q", c.DefaultRole)
+	}
+	for _, groups := range c.RoleGroups {
+		if len(groups) > 0 {
+			return nil
+		}
+	}
+	if c.DefaultRole == "" {
+		return errors.New("LDAP group mappings or LDAP_DEFAULT_ROLE are required with LDAP_URL")
+	}
+	return nil
+}
+
+// splitDNs splits a list of DNs on semicolons, since DNs contain commas.
+func splitDNs(s string) []string {
+	var dns []string
+	for _, dn := range strings.Split(s, ";") {
+		if dn = strings.TrimSpace(dn); dn != "" {
+			dns = append(dns, dn)
+		}
+	}
+	return dns
+}
+
+type ldapVerifier struct {
+	cfg LDAPConfig
+}
+
+// NewLDAPVerifier checks passwords by binding to the directory as the
+// user. Users found there are signed in as external identities, with the
+// role their groups map onto; they are provisioned on first login if cfg
+// allows.
+func NewLDAPVerifier(cfg LDAPConfig) CredentialVerifier {
+	return &ldapVerifier{cfg: cfg}
+}
+
+func (v *ldapVerifier) VerifyPassword(ctx context.Context, username, password string) (cred *Credential, err error) {
+	ctx, span := tracer.Start(ctx, "ldapVerifier.VerifyPassword")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	conn, err := ldap.Dial(ctx, v.cfg.URL, ldap.Config{
+		TLS:      v.cfg.TLS,
+		StartTLS: v.cfg.StartTLS,
+		Timeout:  v.cfg.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if v.cfg.BindDN != "" {
+		if err := conn.Bind(ctx, v.cfg.BindDN, v.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service account bind: %w", err)
+		}
+	}
+
+	entry, err := v.findUser(ctx, conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// The user's own bind is the password check.
+	err = conn.Bind(ctx, entry.DN, password)
+	if ldap.IsResult(err, ldap.ResultInvalidCredentials) {
+		return nil, &CredentialFailure{Reason: "Invalid directory password"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := v.groups(ctx, conn, entry)
+	if err != nil {
+		return nil, err
+	}
+	role := v.role(groups)
+	if role == "" {
+		return nil, &CredentialFailure{Reason: "Not in a mapped directory group"}
+	}
+
+	subject := ldap.NormalizeDN(entry.DN)
+	if v.cfg.IDAttribute != "" {
+		if subject = entry.Value(v.cfg.IDAttribute); subject == "" {
+			return nil, fmt.Errorf("ldap: %s has no %s", entry.DN, v.cfg.IDAttribute)
+		}
+	}
+
+	name := entry.Value(v.cfg.UsernameAttribute)
+	if name == "" {
+		name = username
+	}
+	email := entry.Value(v.cfg.EmailAttribute)
+
+	return &Credential{
+		Identity: &ExternalIdentity{
+			Issuer:        v.cfg.URL,
+			Subject:       subject,
+			Email:         email,
+			EmailVerified: v.cfg.TrustEmail && email != "",
+			Username:      name,
+		},
+		Policy: ProvisionPolicy{
+			Create:   v.cfg.AutoProvision,
+			Role:     role,
+			SyncRole: true,
+		},
+	}, nil
+}
+
+// findUser looks up the entry for a login username.
+func (v *ldapVerifier) findUser(ctx context.Context, conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	filter, err := ldap.ParseFilter(fmt.Sprintf("(&%s(%s=%s))",
+		v.cfg.UserFilter, v.cfg.UsernameAttribute, ldap.EscapeFilter(username)))
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []string{v.cfg.UsernameAttribute, v.cfg.EmailAttribute, v.cfg.GroupAttribute}
+	if v.cfg.IDAttribute != "" {
+		attrs = append(attrs, v.cfg.IDAttribute)
+	}
+
+	entries, err := conn.Search(ctx, ldap.SearchRequest{
+		BaseDN:     v.cfg.BaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     filter,
+		Attributes: attrs,
+		SizeLimit:  2,
+	})
+	if ldap.IsResult(err, ldap.ResultSizeLimitExceeded) || len(entries) > 1 {
+		return nil, &CredentialFailure{Reason: "Ambiguous directory username"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, &CredentialFailure{Reason: "Invalid username", Unknown: true}
+	}
+	return entries[0], nil
+}
+
+// groups returns the DNs of the groups the user is a member of.
+func (v *ldapVerifier) groups(ctx context.Context, conn *ldap.Conn, entry *ldap.Entry) ([]string, error) {
+	groups := entry.Values(v.cfg.GroupAttribute)
+	if v.cfg.GroupBaseDN == "" {
+		return groups, nil
+	}
+
+	filter, err := ldap.ParseFilter(fmt.Sprintf("(%s=%s)",
+		v.cfg.GroupMemberAttribute, ldap.EscapeFilter(entry.DN)))
+	if err != nil {
+		return nil, err
+	}
+
+	// "1.1" asks for no attributes (RFC 4511 section 4.5.1.8).
+	entries, err := conn.Search(ctx, ldap.SearchRequest{
+		BaseDN:     v.cfg.GroupBaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     filter,
+		Attributes: []string{"1.1"},
+	})
+	if ldap.IsResult(err, ldap.ResultNoSuchObject) {
+		return groups, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		groups = append(groups, e.DN)
+	}
+	return groups, nil
+}
+
+// role picks the highest role the groups are mapped onto.
+func (v *ldapVerifier) role(groups []string) string {
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[ldap.NormalizeDN(g)] = true
+	}
+
+	role := v.cfg.DefaultRole
+	for r, dns := range v.cfg.RoleGroups {
+		if model.RoleRank(r) <= model.RoleRank(role) {
+			continue
+		}
+		for _, dn := range dns {
+			if member[ldap.NormalizeDN(dn)] {
+				role = r
+				break
+			}
+		}
+	}
+	return role
+}
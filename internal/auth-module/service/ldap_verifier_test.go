@@ -0,0 +1,225 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/service"
+	"admin-portal/internal/shared/ldap"
+	"admin-portal/internal/shared/ldap/ldaptest"
+)
+
+const (
+	serviceDN = "cn=portal,ou=services,dc=example,dc=com"
+	aliceDN   = "uid=alice,ou=people,dc=example,dc=com"
+	adminsDN  = "cn=admins,ou=groups,dc=example,dc=com"
+	staffDN   = "cn=staff,ou=groups,dc=example,dc=com"
+)
+
+func attr(name string, values ...string) ldap.Attribute {
+	return ldap.Attribute{Name: name, Values: values}
+}
+
+func newDirectory(t *testing.T) (*ldaptest.Server, service.LDAPConfig) {
+	t.Helper()
+
+	dir, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dir.Close() })
+
+	dir.AddEntry(serviceDN, "service-secret")
+	dir.AddEntry(aliceDN, "alice-secret",
+		attr("objectClass", "person"),
+		attr("uid", "alice"),
+		attr("mail", "alice@example.com"),
+		attr("entryUUID", "0b6c7b7e-1d1a-4a53-9d4e-2f6f1c1c0a01"),
+		attr("memberOf", staffDN))
+	dir.AddEntry("uid=bob,ou=people,dc=example,dc=com", "bob-secret",
+		attr("objectClass", "person"),
+		attr("uid", "bob"))
+	dir.AddEntry(adminsDN, "",
+		attr("objectClass", "groupOfNames"),
+		attr("member", "uid=bob,ou=people,dc=example,dc=com"))
+
+	return dir, service.LDAPConfig{
+		URL:                  dir.URL(),
+		Timeout:              5 * time.Second,
+		BindDN:               serviceDN,
+		BindPassword:         "service-secret",
+		BaseDN:               "ou=people,dc=example,dc=com",
+		UserFilter:           "(objectClass=person)",
+		UsernameAttribute:    "uid",
+		EmailAttribute:       "mail",
+		IDAttribute:          "entryUUID",
+		GroupAttribute:       "memberOf",
+		GroupMemberAttribute: "member",
+		RoleGroups: map[string][]string{
+			model.RoleUser:  {staffDN},
+			model.RoleAdmin: {adminsDN},
+		},
+		AutoProvision: true,
+	}
+}
+
+func wantFailure(t *testing.T, err error, unknown bool) {
+	t.Helper()
+
+	var failure *service.CredentialFailure
+	if !errors.As(err, &failure) {
+		t.Fatalf("err = %v, want a CredentialFailure", err)
+	}
+	if failure.Unknown != unknown {
+		t.Errorf("failure %q: Unknown = %v, want %v", failure.Reason, failure.Unknown, unknown)
+	}
+}
+
+func TestLDAPVerifyPassword(t *testing.T) {
+	_, cfg := newDirectory(t)
+	cfg.TrustEmail = true
+
+	cred, err := service.NewLDAPVerifier(cfg).VerifyPassword(context.Background(), "alice", "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := cred.Identity
+	if id == nil || cred.User != nil {
+		t.Fatalf("credential = %+v, want an external identity", cred)
+	}
+	if id.Issuer != cfg.URL || id.Subject != "0b6c7b7e-1d1a-4a53-9d4e-2f6f1c1c0a01" {
+		t.Errorf("identity = %s / %s", id.Issuer, id.Subject)
+	}
+	if id.Username != "alice" || id.Email != "alice@example.com" || !id.EmailVerified {
+		t.Errorf("identity = %+v", id)
+	}
+	if !cred.Policy.Create || !cred.Policy.SyncRole || cred.Policy.Role != model.RoleUser {
+		t.Errorf("policy = %+v", cred.Policy)
+	}
+}
+
+func TestLDAPSubjectIsDNWithoutIDAttribute(t *testing.T) {
+	_, cfg := newDirectory(t)
+	cfg.IDAttribute = ""
+
+	cred, err := service.NewLDAPVerifier(cfg).VerifyPassword(context.Background(), "alice", "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Identity.Subject != ldap.NormalizeDN(aliceDN) {
+		t.Errorf("subject = %q", cred.Identity.Subject)
+	}
+	if cred.Identity.EmailVerified {
+		t.Error("email verified without TrustEmail")
+	}
+}
+
+func TestLDAPBindFailure(t *testing.T) {
+	_, cfg := newDirectory(t)
+	ctx := context.Background()
+
+	_, err := service.NewLDAPVerifier(cfg).VerifyPassword(ctx, "alice", "wrong")
+	wantFailure(t, err, false)
+
+	_, err = service.NewLDAPVerifier(cfg).VerifyPassword(ctx, "nobody", "alice-secret")
+	wantFailure(t, err, true)
+
+	// A broken service account is a configuration error, not a bad login.
+	cfg.BindPassword = "wrong"
+	_, err = service.NewLDAPVerifier(cfg).VerifyPassword(ctx, "alice", "alice-secret")
+	if !ldap.IsResult(err, ldap.ResultInvalidCredentials) {
+		t.Errorf("service account bind: err = %v", err)
+	}
+	var failure *service.CredentialFailure
+	if errors.As(err, &failure) {
+		t.Errorf("service account bind failed as a login failure: %v", err)
+	}
+}
+
+func TestLDAPUsernameIsEscaped(t *testing.T) {
+	_, cfg := newDirectory(t)
+	ctx := context.Background()
+
+	// Unescaped, each of these would match alice or every user.
+	for _, username := range []string{"*", "al*", "*)(uid=alice", "alice)(|(uid=*"} {
+		_, err := service.NewLDAPVerifier(cfg).VerifyPassword(ctx, username, "alice-secret")
+		wantFailure(t, err, true)
+	}
+}
+
+func TestLDAPAmbiguousUser(t *testing.T) {
+	dir, cfg := newDirectory(t)
+	dir.AddEntry("uid=alice,ou=contractors,ou=people,dc=example,dc=com", "other-secret",
+		attr("objectClass", "person"),
+		attr("uid", "alice"),
+		attr("memberOf", adminsDN))
+
+	for _, password := range []string{"alice-secret", "other-secret"} {
+		_, err := service.NewLDAPVerifier(cfg).VerifyPassword(context.Background(), "alice", password)
+		wantFailure(t, err, false)
+	}
+}
+
+func TestLDAPRoleMapping(t *testing.T) {
+	dir, cfg := newDirectory(t)
+	ctx := context.Background()
+
+	dir.AddEntry("uid=carol,ou=people,dc=example,dc=com", "carol-secret",
+		attr("objectClass", "person"),
+		attr("uid", "carol"),
+		attr("memberOf", "CN=Staff, OU=Groups, DC=example, DC=com", adminsDN))
+
+	cases := []struct {
+		name        string
+		username    string
+		groupBase   string
+		defaultRole string
+		want        string
+	}{
+		{"memberOf", "alice", "", "", model.RoleUser},
+		{"highest role wins, DNs normalized", "carol", "", "", model.RoleAdmin},
+		{"unmapped user is refused", "bob", "", "", ""},
+		{"group search", "bob", "ou=groups,dc=example,dc=com", "", model.RoleAdmin},
+		{"missing group base", "bob", "ou=nowhere,dc=example,dc=com", "", ""},
+		{"default role", "bob", "", model.RoleUser, model.RoleUser},
+		{"mapped group beats default", "carol", "", model.RoleUser, model.RoleAdmin},
+	}
+
+	for _, tc := range cases {
+		cfg := cfg
+		cfg.IDAttribute = ""
+		cfg.GroupBaseDN = tc.groupBase
+		cfg.DefaultRole = tc.defaultRole
+
+		cred, err := service.NewLDAPVerifier(cfg).VerifyPassword(ctx, tc.username, tc.username+"-secret")
+		if tc.want == "" {
+			var failure *service.CredentialFailure
+			if !errors.As(err, &failure) {
+				t.Errorf("%s: err = %v, want a CredentialFailure", tc.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if cred.Policy.Role != tc.want {
+			t.Errorf("%s: role = %q, want %q", tc.name, cred.Policy.Role, tc.want)
+		}
+	}
+}
+
+func TestLDAPMissingIDAttribute(t *testing.T) {
+	_, cfg := newDirectory(t)
+	cfg.DefaultRole = model.RoleUser
+
+	// bob has no entryUUID; falling back to his DN would make a second,
+	// unlinked identity once one appears.
+	if _, err := service.NewLDAPVerifier(cfg).VerifyPassword(context.Background(), "bob", "bob-secret"); err == nil {
+		t.Error("verified a user without an ID")
+	}
+}
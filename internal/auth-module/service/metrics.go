@@ -0,0 +1,46 @@
+package service
+
+// Login outcomes reported to Metrics.LoginAttempt.
+const (
+	LoginOutcomeSuccess            = "success"
+	LoginOutcomeInvalidCredentials = "invalid_credentials"
+	LoginOutcomeInactive           = "inactive"
+	LoginOutcomeNotActivated       = "not_activated"
+	LoginOutcomeError              = "error"
+)
+
+// Refresh outcomes reported to Metrics.TokenRefreshed.
+const (
+	RefreshOutcomeSuccess  = "success"
+	RefreshOutcomeInvalid  = "invalid"
+	RefreshOutcomeInactive = "inactive"
+	RefreshOutcomeError    = "error"
+)
+
+// Resend outcomes reported to Metrics.ActivationResent. Only sent queues
+// an email, but the caller cannot tell the outcomes apart.
+const (
+	ResendOutcomeSent      = "sent"
+	ResendOutcomeThrottled = "throttled"
+	ResendOutcomeIgnored   = "ignored"
+)
+
+// Metrics receives auth domain events for instrumentation.
+type Metrics interface {
+	LoginAttempt(outcome string)
+	Registered(role string)
+	// LockedOut counts a user or address reaching the failed-login
+	// threshold of SecurityConfig, which also raises an alert.
+	LockedOut()
+	TokenRefreshed(outcome string)
+	ActivationResent(outcome string)
+}
+
+// NopMetrics discards everything.
+type NopMetrics struct{}
+
+func (NopMetrics) LoginAttempt(string)     {}
+func (NopMetrics) Registered(string)       {}
+func (NopMetrics) LockedOut()              {}
+func (NopMetrics) TokenRefreshed(string)   {}
+func (NopMetrics) ActivationResent(string) {}
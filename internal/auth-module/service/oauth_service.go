@@ -0,0 +1,667 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/config"
+	"admin-portal/internal/shared/oidc"
+	"admin-portal/internal/shared/security"
+)
+
+// Scopes clients may request. Tokens always carry the portal's own
+// claims; offline_access adds a refresh token.
+const (
+	ScopeOpenID        = "openid"
+	ScopeProfile       = "profile"
+	ScopeOfflineAccess = "offline_access"
+)
+
+// accessTokenType marks access tokens (RFC 9068) so an ID token cannot
+// be presented in their place.
+const accessTokenType = "at+jwt"
+
+const maxRedirectURIs = 10
+
+var supportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeOfflineAccess}
+
+// OAuthConfig configures the OAuth 2.0 authorization server other apps
+// sign their users in through. It is off while Issuer is empty.
+type OAuthConfig struct {
+	// Issuer is the public base URL of the HTTP endpoints, e.g.
+	// https://portal.example.com.
+	Issuer string
+	Addr   string
+	Key    *security.SigningKey
+
+	// LoginURL is where the authorization endpoint sends users who are
+	// not signed in. It receives the URL to come back to as return_to.
+	LoginURL string
+
+	CodeTTL         time.Duration
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// LoadOAuthConfig reads the configuration and, when the issuer is
+// enabled, its signing key.
+func LoadOAuthConfig() (OAuthConfig, error) {
+	cfg := OAuthConfig{
+		Issuer:          strings.TrimSuffix(config.String("OAUTH_ISSUER", ""), "/"),
+		Addr:            config.String("OAUTH_ADDR", ":8080"),
+		LoginURL:        config.String("OAUTH_LOGIN_URL", ""),
+		CodeTTL:         config.Duration("OAUTH_CODE_TTL", time.Minute),
+		AccessTokenTTL:  config.Duration("OAUTH_ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: config.Duration("OAUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+	}
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+
+	u, err := url.Parse(cfg.Issuer)
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return OAuthConfig{}, fmt.Errorf("OAUTH_ISSUER must be a scheme and host without a path, got %q", cfg.Issuer)
+	}
+
+	keyFile := config.String("OAUTH_SIGNING_KEY_FILE", "")
+	if keyFile == "" {
+		return OAuthConfig{}, fmt.Errorf("OAUTH_SIGNING_KEY_FILE is required when OAUTH_ISSUER is set")
+	}
+	if cfg.Key, err = security.LoadSigningKey(keyFile); err != nil {
+		return OAuthConfig{}, fmt.Errorf("signing key: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c OAuthConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// AuthorizeRequest is an authorization request (RFC 6749 section 4.1.1)
+// with PKCE, which is required of every client.
+type AuthorizeRequest struct {
+	ClientID            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// TokenRequest is a token endpoint request. ClientSecret is empty for
+// public clients.
+type TokenRequest struct {
+	ClientID     string
+	ClientSecret string
+
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// UserInfo is the userinfo endpoint response; it repeats the user claims
+// of the tokens.
+type UserInfo struct {
+	Subject           string `json:"sub"`
+	Username          string `json:"username"`
+	Role              string `json:"role"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// OAuthService is the authorization server: client registration for
+// administrators, and the protocol endpoints for clients. Protocol
+// failures are *oidc.TokenError values carrying the OAuth error code.
+type OAuthService interface {
+	RegisterClient(ctx context.Context, name string, redirectURIs []string, public bool) (*model.OAuthClient, string, error)
+	ListClients(ctx context.Context, f repository.OAuthClientFilter) ([]*model.OAuthClient, int64, error)
+	RevokeClient(ctx context.Context, id string) error
+
+	Enabled() bool
+	Metadata() oidc.Metadata
+	KeySet() (oidc.JSONWebKeySet, error)
+
+	CheckRedirect(ctx context.Context, clientID, redirectURI string) error
+	Authorize(ctx context.Context, req AuthorizeRequest, userID string, authTime time.Time) (string, error)
+	Token(ctx context.Context, req TokenRequest) (*oidc.Tokens, error)
+	UserInfo(ctx context.Context, accessToken string) (*UserInfo, error)
+	Revoke(ctx context.Context, clientID, clientSecret, token string) error
+}
+
+type oauthService struct {
+	clientRepo repository.OAuthClientRepository
+	grantRepo  repository.OAuthGrantRepository
+	userRepo   repository.UserRepository
+	cfg        OAuthConfig
+}
+
+func NewOAuthService(
+	clientRepo repository.OAuthClientRepository,
+	grantRepo repository.OAuthGrantRepository,
+	userRepo repository.UserRepository,
+	cfg OAuthConfig,
+) OAuthService {
+	return &oauthService{
+		clientRepo: clientRepo,
+		grantRepo:  grantRepo,
+		userRepo:   userRepo,
+		cfg:        cfg,
+	}
+}
+
+//-------------------- Client registration --------------------//
+
+/* RegisterClient registers an app. Confidential clients get a secret, returned only here; public clients (SPAs, native apps) rely on PKCE alone. */
+func (s *oauthService) RegisterClient(
+	ctx context.Context,
+	name string,
+	redirectURIs []string,
+	public bool,
+) (*model.OAuthClient, string, error) {
+
+	if len(redirectURIs) == 0 || len(redirectURIs) > maxRedirectURIs {
+		return nil, "", ErrInvalidRedirectURI.WithDetail("redirect_uris", fmt.Sprintf("give between 1 and %d", maxRedirectURIs))
+	}
+	for _, uri := range redirectURIs {
+		if err := checkRedirectURI(uri); err != nil {
+			return nil, "", ErrInvalidRedirectURI.WithDetail("redirect_uri", uri)
+		}
+	}
+
+	client := &model.OAuthClient{
+		Name:         name,
+		RedirectURIs: slices.Compact(slices.Clone(redirectURIs)),
+	}
+	if userID, ok := middleware.UserIDFromContext(ctx); ok {
+		if id, err := uuid.Parse(userID); err == nil {
+			client.CreatedBy = &id
+		}
+	}
+
+	var secret string
+	if !public {
+		var hash string
+		var err error
+		if secret, hash, err = security.GenerateOpaqueToken(); err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = &hash
+	}
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+func (s *oauthService) ListClients(ctx context.Context, f repository.OAuthClientFilter) ([]*model.OAuthClient, int64, error) {
+	return s.clientRepo.List(ctx, f)
+}
+
+/* RevokeClient stops a client from signing anyone in and revokes its refresh tokens. Access tokens already issued run out on their own. */
+func (s *oauthService) RevokeClient(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrOAuthClientNotFound.WithDetail("client_id", id)
+	}
+
+	_, err := s.clientRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOAuthClientNotFound.WithDetail("client_id", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.clientRepo.Revoke(ctx, id, now); err != nil {
+		return err
+	}
+	return s.grantRepo.RevokeClientTokens(ctx, id, now)
+}
+
+// checkRedirectURI accepts absolute https URIs, and http ones on the
+// loopback interface for native apps (RFC 8252 section 7.3).
+func checkRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	if u.Fragment != "" || u.Host == "" {
+		return errors.New("redirect URI must be absolute and have no fragment")
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if u.Hostname() == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(u.Hostname()); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+	return errors.New("redirect URI must use https, or http on the loopback interface")
+}
+
+//-------------------- Discovery --------------------//
+
+func (s *oauthService) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+func (s *oauthService) Metadata() oidc.Metadata {
+	iss := s.cfg.Issuer
+	alg := s.cfg.Key.Method.Alg()
+
+	return oidc.Metadata{
+		Issuer:                           iss,
+		AuthorizationEndpoint:            iss + "/oauth/authorize",
+		TokenEndpoint:                    iss + "/oauth/token",
+		UserinfoEndpoint:                 iss + "/oauth/userinfo",
+		RevocationEndpoint:               iss + "/oauth/revoke",
+		JWKSURI:                          iss + "/oauth/jwks",
+		ResponseTypesSupported:           []string{"code"},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{alg},
+		ScopesSupported:                  supportedScopes,
+		GrantTypesSupported:              []string{"authorization_code", "refresh_token"},
+		CodeChallengeMethodsSupported:    []string{"S256"},
+		TokenEndpointAuthMethods:         []string{"client_secret_basic", "client_secret_post", "none"},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "azp",
+			"username", "role", "preferred_username",
+		},
+	}
+}
+
+func (s *oauthService) KeySet() (oidc.JSONWebKeySet, error) {
+	jwk, err := oidc.NewJSONWebKey(s.cfg.Key.Key.Public(), s.cfg.Key.ID)
+	if err != nil {
+		return oidc.JSONWebKeySet{}, err
+	}
+	return oidc.JSONWebKeySet{Keys: []oidc.JSONWebKey{jwk}}, nil
+}
+
+//-------------------- Authorization endpoint --------------------//
+
+/* CheckRedirect verifies the client and that it registered the redirect URI. Until it passes, errors must be shown to the user rather than sent to the URI. */
+func (s *oauthService) CheckRedirect(ctx context.Context, clientID, redirectURI string) error {
+	client, err := s.findClient(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return ErrOAuthClientNotFound.WithDetail("client_id", clientID)
+	}
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		return ErrInvalidRedirectURI.WithDetail("redirect_uri", redirectURI)
+	}
+	return nil
+}
+
+/* Authorize approves a request for the signed-in user and returns the authorization code. Clients are registered by administrators, so there is no consent step. */
+func (s *oauthService) Authorize(
+	ctx context.Context,
+	req AuthorizeRequest,
+	userID string,
+	authTime time.Time,
+) (string, error) {
+
+	if err := s.CheckRedirect(ctx, req.ClientID, req.RedirectURI); err != nil {
+		return "", err
+	}
+
+	if req.ResponseType != "code" {
+		return "", oauthError("unsupported_response_type", "only code is supported")
+	}
+	scope, err := parseScope(req.Scope)
+	if err != nil {
+		return "", err
+	}
+	if req.CodeChallengeMethod != "S256" || len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128 {
+		return "", oauthError("invalid_request", "PKCE with code_challenge_method S256 is required")
+	}
+	if len(req.Nonce) > 255 {
+		return "", oauthError("invalid_request", "nonce is too long")
+	}
+
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", oauthError("access_denied", "account is inactive")
+	}
+
+	code, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	grant := &model.OAuthAuthorizationCode{
+		CodeHash:      hash,
+		ClientID:      uuid.MustParse(req.ClientID),
+		UserID:        user.ID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         scope,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      authTime,
+		ExpiresAt:     time.Now().Add(s.cfg.CodeTTL),
+	}
+	if req.Nonce != "" {
+		grant.Nonce = &req.Nonce
+	}
+	if err := s.grantRepo.CreateCode(ctx, grant); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+//-------------------- Token endpoint --------------------//
+
+/* Token redeems an authorization code or rotates a refresh token. Each refresh token works once; the response carries its replacement. */
+func (s *oauthService) Token(ctx context.Context, req TokenRequest) (*oidc.Tokens, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case "authorization_code":
+		return s.redeemCode(ctx, client, req)
+	case "refresh_token":
+		return s.refresh(ctx, client, req)
+	default:
+		return nil, oauthError("unsupported_grant_type", "")
+	}
+}
+
+func (s *oauthService) redeemCode(ctx context.Context, client *model.OAuthClient, req TokenRequest) (*oidc.Tokens, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, oauthError("invalid_request", "code and code_verifier are required")
+	}
+
+	grant, err := s.grantRepo.ConsumeCode(ctx, security.HashAPIKey(req.Code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, oauthError("invalid_grant", "unknown, used or expired code")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case grant.ClientID != client.ID:
+		return nil, oauthError("invalid_grant", "code was issued to another client")
+	case grant.RedirectURI != req.RedirectURI:
+		return nil, oauthError("invalid_grant", "redirect_uri does not match")
+	case !oidc.VerifyPKCE(grant.CodeChallenge, req.CodeVerifier):
+		return nil, oauthError("invalid_grant", "PKCE verification failed")
+	}
+
+	var nonce string
+	if grant.Nonce != nil {
+		nonce = *grant.Nonce
+	}
+	return s.issue(ctx, client, grant.UserID.String(), grant.Scope, nonce, grant.AuthTime)
+}
+
+func (s *oauthService) refresh(ctx context.Context, client *model.OAuthClient, req TokenRequest) (*oidc.Tokens, error) {
+	if req.RefreshToken == "" {
+		return nil, oauthError("invalid_request", "refresh_token is required")
+	}
+
+	token, err := s.grantRepo.ConsumeRefreshToken(ctx, security.HashAPIKey(req.RefreshToken), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, oauthError("invalid_grant", "unknown, used or expired refresh token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.ClientID != client.ID {
+		return nil, oauthError("invalid_grant", "refresh token was issued to another client")
+	}
+
+	// A refresh may narrow the scope but never widen it.
+	scope := token.Scope
+	if req.Scope != "" {
+		if scope, err = parseScope(req.Scope); err != nil {
+			return nil, err
+		}
+		granted := strings.Fields(token.Scope)
+		for _, sc := range strings.Fields(scope) {
+			if !slices.Contains(granted, sc) {
+				return nil, oauthError("invalid_scope", "scope exceeds the original grant")
+			}
+		}
+	}
+
+	return s.issue(ctx, client, token.UserID.String(), scope, "", token.AuthTime)
+}
+
+// issue builds the token response for a grant: an access token, an ID
+// token for openid, and a refresh token for offline_access.
+func (s *oauthService) issue(
+	ctx context.Context,
+	client *model.OAuthClient,
+	userID, scope, nonce string,
+	authTime time.Time,
+) (*oidc.Tokens, error) {
+
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, oauthError("invalid_grant", "account is inactive")
+	}
+
+	now := time.Now()
+	claims := func(ttl time.Duration) security.OIDCClaims {
+		return security.OIDCClaims{
+			Claims: security.Claims{
+				UserID:   user.ID.String(),
+				Username: user.Username,
+				Role:     user.Role,
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:    s.cfg.Issuer,
+					Audience:  jwt.ClaimStrings{client.ID.String()},
+					IssuedAt:  jwt.NewNumericDate(now),
+					ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+				},
+			},
+			PreferredUsername: user.Username,
+			AuthTime:          jwt.NewNumericDate(authTime),
+			AuthorizedParty:   client.ID.String(),
+		}
+	}
+
+	access := claims(s.cfg.AccessTokenTTL)
+	access.ID = uuid.NewString()
+	access.Scope = scope
+	accessToken, err := s.cfg.Key.Sign(accessTokenType, access)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &oidc.Tokens{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.cfg.AccessTokenTTL / time.Second),
+		Scope:       scope,
+	}
+
+	granted := strings.Fields(scope)
+	if slices.Contains(granted, ScopeOpenID) {
+		id := claims(s.cfg.AccessTokenTTL)
+		id.Nonce = nonce
+		if tokens.IDToken, err = s.cfg.Key.Sign("JWT", id); err != nil {
+			return nil, err
+		}
+	}
+
+	if slices.Contains(granted, ScopeOfflineAccess) {
+		refresh, hash, err := security.GenerateOpaqueToken()
+		if err != nil {
+			return nil, err
+		}
+		err = s.grantRepo.CreateRefreshToken(ctx, &model.OAuthRefreshToken{
+			TokenHash: hash,
+			ClientID:  client.ID,
+			UserID:    user.ID,
+			Scope:     scope,
+			AuthTime:  authTime,
+			ExpiresAt: now.Add(s.cfg.RefreshTokenTTL),
+		})
+		if err != nil {
+			return nil, err
+		}
+		tokens.RefreshToken = refresh
+	}
+
+	return tokens, nil
+}
+
+//-------------------- Userinfo & revocation --------------------//
+
+/* UserInfo returns the claims of the user an access token was issued for, as they are now. Deactivated users get invalid_token. */
+func (s *oauthService) UserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	var claims security.OIDCClaims
+	token, err := s.cfg.Key.Parse(accessToken, &claims,
+		jwt.WithIssuer(s.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || token.Header["typ"] != accessTokenType {
+		return nil, oauthError("invalid_token", "access token is invalid or expired")
+	}
+
+	user, err := s.activeUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, oauthError("invalid_token", "account is inactive")
+	}
+
+	return &UserInfo{
+		Subject:           user.ID.String(),
+		Username:          user.Username,
+		Role:              user.Role,
+		PreferredUsername: user.Username,
+	}, nil
+}
+
+/* Revoke revokes a refresh token held by the client (RFC 7009). Unknown tokens and access tokens, which simply expire, are not errors. */
+func (s *oauthService) Revoke(ctx context.Context, clientID, clientSecret, token string) error {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return oauthError("invalid_request", "token is required")
+	}
+
+	return s.grantRepo.RevokeRefreshToken(ctx, security.HashAPIKey(token), client.ID.String(), time.Now())
+}
+
+//-------------------- Helpers --------------------//
+
+// findClient returns the client if it exists and is not revoked, or nil.
+func (s *oauthService) findClient(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, nil
+	}
+
+	client, err := s.clientRepo.FindByID(ctx, clientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.RevokedAt != nil {
+		return nil, nil
+	}
+	return client, nil
+}
+
+// authenticateClient checks the secret of confidential clients. Public
+// clients must not send one.
+func (s *oauthService) authenticateClient(ctx context.Context, clientID, secret string) (*model.OAuthClient, error) {
+	client, err := s.findClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, oauthError("invalid_client", "unknown client")
+	}
+
+	if client.Public() {
+		if secret != "" {
+			return nil, oauthError("invalid_client", "public clients have no secret")
+		}
+		return client, nil
+	}
+	if secret == "" || !security.CheckAPIKey(secret, *client.SecretHash) {
+		return nil, oauthError("invalid_client", "wrong client secret")
+	}
+	return client, nil
+}
+
+// activeUser returns the user if they may still sign in, or nil.
+func (s *oauthService) activeUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive || !user.IsActivated {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// parseScope checks a requested scope and returns it normalized. An
+// empty scope means openid.
+func parseScope(scope string) (string, error) {
+	fields := strings.Fields(scope)
+	if len(fields) == 0 {
+		return ScopeOpenID, nil
+	}
+
+	var out []string
+	for _, f := range fields {
+		if !slices.Contains(supportedScopes, f) {
+			return "", oauthError("invalid_scope", fmt.Sprintf("unsupported scope %q", f))
+		}
+		if !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return strings.Join(out, " "), nil
+}
+
+func oauthError(code, description string) error {
+	return &oidc.TokenError{Code: code, Description: description}
+}
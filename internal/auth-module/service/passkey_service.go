@@ -0,0 +1,381 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/webauthn"
+)
+
+// defaultPasskeyName names passkeys registered without a name.
+const defaultPasskeyName = "Passkey"
+
+// PasskeyService manages users' passkeys and signs users in with them,
+// either on their own or as the second factor after a password. Options
+// and responses are the JSON the browser's WebAuthn API takes and
+// returns.
+type PasskeyService interface {
+	BeginRegistration(ctx context.Context) ([]byte, error)
+	FinishRegistration(ctx context.Context, name string, response []byte) (*model.WebAuthnCredential, error)
+	List(ctx context.Context, userID string) ([]*model.WebAuthnCredential, error)
+	Delete(ctx context.Context, userID, id string) error
+
+	BeginLogin(ctx context.Context) ([]byte, error)
+	FinishLogin(ctx context.Context, response []byte) (*model.User, string, string, error)
+}
+
+// SecondFactor starts the check a user must pass after their password.
+type SecondFactor interface {
+	// Begin returns the options for the user's second factor, or nil if
+	// they have none to check.
+	Begin(ctx context.Context, user *model.User) ([]byte, error)
+}
+
+// SecondFactorRequired is returned by AuthService.Login and
+// LoginWithIdentity instead of tokens when the password or identity
+// provider vouched for the user but the user has passkeys. Options go to
+// the browser, whose response finishes the login through
+// PasskeyService.FinishLogin.
+type SecondFactorRequired struct {
+	UserID  string
+	Options []byte
+}
+
+func (e *SecondFactorRequired) Error() string {
+	return "second factor required"
+}
+
+// passkeyCeremonies starts ceremonies, storing their challenges until the
+// browser answers.
+type passkeyCeremonies struct {
+	rp           *webauthn.RelyingParty
+	webauthnRepo repository.WebAuthnRepository
+}
+
+// NewPasskeySecondFactor asks users with passkeys for one after their
+// password. A nil rp disables it.
+func NewPasskeySecondFactor(rp *webauthn.RelyingParty, webauthnRepo repository.WebAuthnRepository) SecondFactor {
+	return &passkeyCeremonies{rp: rp, webauthnRepo: webauthnRepo}
+}
+
+func (c *passkeyCeremonies) Begin(ctx context.Context, user *model.User) ([]byte, error) {
+	if c.rp == nil {
+		return nil, nil
+	}
+
+	creds, err := c.webauthnRepo.ListCredentials(ctx, user.ID.String())
+	if err != nil || len(creds) == 0 {
+		return nil, err
+	}
+
+	challenge, err := c.newChallenge(ctx, model.CeremonySecondFactor, &user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return c.rp.RequestOptions(challenge, descriptors(creds), webauthn.UVPreferred)
+}
+
+// newChallenge stores a challenge for a ceremony that expires with the
+// browser's timeout.
+func (c *passkeyCeremonies) newChallenge(ctx context.Context, ceremony string, userID *uuid.UUID) (string, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.webauthnRepo.CreateChallenge(ctx, &model.WebAuthnChallenge{
+		Challenge: challenge,
+		Ceremony:  ceremony,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(c.rp.Timeout()),
+	})
+	return challenge, err
+}
+
+// consumeChallenge redeems the challenge a response was made for, which
+// must belong to one of the ceremonies given.
+func (c *passkeyCeremonies) consumeChallenge(ctx context.Context, challenge string, ceremonies ...string) (*model.WebAuthnChallenge, error) {
+	ch, err := c.webauthnRepo.ConsumeChallenge(ctx, challenge)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ceremony := range ceremonies {
+		if ch.Ceremony == ceremony {
+			return ch, nil
+		}
+	}
+	return nil, ErrInvalidPasskeyChallenge
+}
+
+type passkeyService struct {
+	passkeyCeremonies
+	userRepo    repository.UserRepository
+	authService AuthService
+}
+
+// NewPasskeyService returns the passkey service. Logins are completed
+// through authService. A nil rp disables passkeys.
+func NewPasskeyService(
+	rp *webauthn.RelyingParty,
+	webauthnRepo repository.WebAuthnRepository,
+	userRepo repository.UserRepository,
+	authService AuthService,
+) PasskeyService {
+	return &passkeyService{
+		passkeyCeremonies: passkeyCeremonies{rp: rp, webauthnRepo: webauthnRepo},
+		userRepo:          userRepo,
+		authService:       authService,
+	}
+}
+
+/* BeginRegistration returns the options for a new passkey for the caller. Passkeys they already have are excluded, so an authenticator cannot register twice. */
+func (s *passkeyService) BeginRegistration(ctx context.Context) ([]byte, error) {
+	if s.rp == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	user, err := s.registeringUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := s.webauthnRepo.ListCredentials(ctx, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.newChallenge(ctx, model.CeremonyRegistration, &user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rp.CreationOptions(challenge, webauthn.UserEntity{
+		ID:          user.ID[:],
+		Name:        user.Username,
+		DisplayName: user.Username,
+	}, descriptors(creds))
+}
+
+/* FinishRegistration verifies the browser's response to BeginRegistration and stores the new passkey under name. */
+func (s *passkeyService) FinishRegistration(ctx context.Context, name string, response []byte) (*model.WebAuthnCredential, error) {
+	if s.rp == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	user, err := s.registeringUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := webauthn.ParseRegistration(response)
+	if err != nil {
+		return nil, ErrInvalidPasskey.Wrap(err)
+	}
+
+	ch, err := s.consumeChallenge(ctx, reg.Challenge, model.CeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if ch.UserID == nil || *ch.UserID != user.ID {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+
+	cred, err := s.rp.VerifyRegistration(reg, ch.Challenge, webauthn.UVPreferred)
+	if err != nil {
+		return nil, ErrInvalidPasskey.Wrap(err)
+	}
+
+	_, err = s.webauthnRepo.FindCredential(ctx, cred.ID)
+	if err == nil {
+		return nil, ErrPasskeyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	passkey := &model.WebAuthnCredential{
+		UserID:         user.ID,
+		Name:           name,
+		CredentialID:   cred.ID,
+		PublicKey:      cred.PublicKey,
+		SignCount:      int64(cred.SignCount),
+		Transports:     cred.Transports,
+		AAGUID:         cred.AAGUID,
+		BackupEligible: cred.BackupEligible,
+		BackupState:    cred.BackupState,
+	}
+	if err := s.webauthnRepo.CreateCredential(ctx, passkey); err != nil {
+		return nil, err
+	}
+
+	return passkey, nil
+}
+
+/* List returns a user's passkeys. An empty userID means the caller; only administrators may list anyone else's, and not those of users above their role. */
+func (s *passkeyService) List(ctx context.Context, userID string) ([]*model.WebAuthnCredential, error) {
+	userID, err := s.ownerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.webauthnRepo.ListCredentials(ctx, userID)
+}
+
+/* Delete removes one of a user's passkeys. An empty userID means the caller; administrators may remove those of users up to their own role, e.g. for a user who lost their device. */
+func (s *passkeyService) Delete(ctx context.Context, userID, id string) error {
+	userID, err := s.ownerID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.webauthnRepo.DeleteCredential(ctx, id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPasskeyNotFound.WithDetail("id", id)
+	}
+	return err
+}
+
+/* BeginLogin returns the options for a passwordless login with any of the user's discoverable passkeys. */
+func (s *passkeyService) BeginLogin(ctx context.Context) ([]byte, error) {
+	if s.rp == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	challenge, err := s.newChallenge(ctx, model.CeremonyLogin, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rp.RequestOptions(challenge, nil, webauthn.UVRequired)
+}
+
+/* FinishLogin verifies the browser's response to BeginLogin, or to the second factor options Login returned, and signs its user in. A passwordless login must have verified the user, e.g. with a PIN or biometric. */
+func (s *passkeyService) FinishLogin(ctx context.Context, response []byte) (*model.User, string, string, error) {
+	if s.rp == nil {
+		return nil, "", "", ErrPasskeysDisabled
+	}
+
+	assertion, err := webauthn.ParseAssertion(response)
+	if err != nil {
+		return nil, "", "", ErrInvalidPasskey.Wrap(err)
+	}
+
+	ch, err := s.consumeChallenge(ctx, assertion.Challenge, model.CeremonyLogin, model.CeremonySecondFactor)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	cred, err := s.webauthnRepo.FindCredential(ctx, assertion.CredentialID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", "", ErrInvalidPasskey
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// A second factor must come from the user whose password was checked.
+	if ch.UserID != nil && *ch.UserID != cred.UserID {
+		return nil, "", "", ErrInvalidPasskey
+	}
+	if len(assertion.UserHandle) > 0 && !bytes.Equal(assertion.UserHandle, cred.UserID[:]) {
+		return nil, "", "", ErrInvalidPasskey
+	}
+
+	uv := webauthn.UVRequired
+	if ch.Ceremony == model.CeremonySecondFactor {
+		uv = webauthn.UVPreferred
+	}
+	signCount, err := s.rp.VerifyAssertion(assertion, ch.Challenge, cred.PublicKey, uint32(cred.SignCount), uv)
+	if err != nil {
+		return nil, "", "", ErrInvalidPasskey.Wrap(err)
+	}
+
+	err = s.webauthnRepo.RecordUse(ctx, cred.ID.String(), int64(signCount), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Another login accepted the same counter first.
+		return nil, "", "", ErrInvalidPasskey.Wrap(webauthn.ErrSignCount)
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return s.authService.LoginWithPasskey(ctx, cred.UserID.String(), ch.Ceremony == model.CeremonySecondFactor)
+}
+
+// registeringUser returns the caller, who must be a user signed in
+// without an API key: a leaked key must not be able to add a way in.
+func (s *passkeyService) registeringUser(ctx context.Context) (*model.User, error) {
+	if _, ok := middleware.ServiceFromContext(ctx); ok {
+		return nil, ErrNotAUser
+	}
+	if _, ok := middleware.APIKeyFromContext(ctx); ok {
+		return nil, ErrPasskeyNotAllowed
+	}
+
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrInvalidCredential
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound.WithDetail("user_id", userID)
+	}
+	return user, err
+}
+
+// ownerID resolves the user whose passkeys are managed: the caller when
+// userID is empty, and anyone not above the caller's role only for
+// administrators.
+func (s *passkeyService) ownerID(ctx context.Context, userID string) (string, error) {
+	callerID, _ := middleware.UserIDFromContext(ctx)
+	if userID == "" {
+		if _, ok := middleware.ServiceFromContext(ctx); ok {
+			return "", ErrNotAUser
+		}
+		userID = callerID
+	}
+
+	if userID == callerID {
+		return userID, nil
+	}
+	if !isAdmin(ctx) {
+		return "", ErrAccessDenied.WithDetail("user_id", userID)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrUserNotFound.WithDetail("user_id", userID)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !canGrant(ctx, user.Role) {
+		return "", ErrUserOutranks.WithDetail("user_id", userID)
+	}
+	return userID, nil
+}
+
+// descriptors lists credentials for allow and exclude lists.
+func descriptors(creds []*model.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	out := make([]webauthn.CredentialDescriptor, 0, len(creds))
+	for _, c := range creds {
+		out = append(out, webauthn.NewCredentialDescriptor(c.CredentialID, c.Transports))
+	}
+	return out
+}
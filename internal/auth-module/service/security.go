@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"admin-portal/internal/shared/config"
+)
+
+// SecurityAlerts receives suspicious auth activity (see the Security*
+// event types in the events package). Dispatch runs inside the caller's
+// transaction, so alerts are only sent for committed activity.
+type SecurityAlerts interface {
+	Dispatch(ctx context.Context, eventType string, payload interface{}) error
+}
+
+// NopSecurityAlerts discards every alert.
+type NopSecurityAlerts struct{}
+
+func (NopSecurityAlerts) Dispatch(context.Context, string, interface{}) error { return nil }
+
+// SecurityConfig tunes suspicious-activity detection.
+type SecurityConfig struct {
+	// An alert is raised on every FailureThreshold-th failed login
+	// within FailureWindow.
+	FailureThreshold int
+	FailureWindow    time.Duration
+}
+
+func LoadSecurityConfig() SecurityConfig {
+	return SecurityConfig{
+		FailureThreshold: config.Int("LOGIN_FAILURE_ALERT_THRESHOLD", 5),
+		FailureWindow:    config.Duration("LOGIN_FAILURE_ALERT_WINDOW", 15*time.Minute),
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+	"admin-portal/internal/shared/config"
+	"admin-portal/internal/shared/oidc"
+)
+
+// SSOConfig controls what single sign-on may do with unknown identities.
+type SSOConfig struct {
+	// AutoProvision creates users for identities that match no account.
+	AutoProvision bool
+	DefaultRole   string
+	// StateTTL is how long a user has to finish logging in at the
+	// provider.
+	StateTTL time.Duration
+}
+
+func LoadSSOConfig() SSOConfig {
+	return SSOConfig{
+		AutoProvision: config.Bool("OIDC_AUTO_PROVISION", false),
+		DefaultRole:   config.String("OIDC_DEFAULT_ROLE", model.RoleUser),
+		StateTTL:      config.Duration("OIDC_STATE_TTL", 10*time.Minute),
+	}
+}
+
+// SSOService runs the OpenID Connect login flow against the configured
+// identity provider and signs the user in through AuthService.
+type SSOService interface {
+	StartLogin(ctx context.Context) (authURL, state string, err error)
+	CompleteLogin(ctx context.Context, state, code string) (*model.User, string, string, error)
+}
+
+type ssoService struct {
+	provider    *oidc.Provider
+	stateRepo   repository.SSOStateRepository
+	authService AuthService
+	cfg         SSOConfig
+}
+
+// NewSSOService returns the SSO service. A nil provider disables SSO.
+func NewSSOService(
+	provider *oidc.Provider,
+	stateRepo repository.SSOStateRepository,
+	authService AuthService,
+	cfg SSOConfig,
+) SSOService {
+	return &ssoService{
+		provider:    provider,
+		stateRepo:   stateRepo,
+		authService: authService,
+		cfg:         cfg,
+	}
+}
+
+/* StartLogin stores a fresh state, nonce and PKCE verifier and returns the provider URL to send the browser to. */
+func (s *ssoService) StartLogin(ctx context.Context) (string, string, error) {
+	if s.provider == nil {
+		return "", "", ErrSSODisabled
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewPKCEVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.stateRepo.Create(ctx, &model.SSOLoginState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.cfg.StateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.PKCEChallenge(verifier))
+	if err != nil {
+		return "", "", ErrSSOFailed.Wrap(err)
+	}
+
+	return authURL, state, nil
+}
+
+/* CompleteLogin redeems the code the provider redirected back with, verifies the ID token and signs its subject in. Each state works once. Users with passkeys get a *SecondFactorRequired error, as from AuthService.Login. */
+func (s *ssoService) CompleteLogin(ctx context.Context, state, code string) (*model.User, string, string, error) {
+	if s.provider == nil {
+		return nil, "", "", ErrSSODisabled
+	}
+
+	login, err := s.stateRepo.Consume(ctx, state)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", "", ErrInvalidSSOState
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	tokens, err := s.provider.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		return nil, "", "", ErrSSOFailed.Wrap(err)
+	}
+
+	idToken, err := s.provider.VerifyIDToken(ctx, tokens.IDToken, login.Nonce)
+	if err != nil {
+		return nil, "", "", ErrSSOFailed.Wrap(err)
+	}
+
+	return s.authService.LoginWithIdentity(ctx, ExternalIdentity{
+		Issuer:        s.provider.Issuer(),
+		Subject:       idToken.Subject,
+		Email:         idToken.Email,
+		EmailVerified: idToken.EmailVerified,
+		Username:      idToken.PreferredUsername,
+	}, ProvisionPolicy{
+		Create: s.cfg.AutoProvision,
+		Role:   s.cfg.DefaultRole,
+	})
+}
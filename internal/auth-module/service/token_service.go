@@ -3,14 +3,19 @@ package service
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"admin-portal/internal/auth-module/model"
 	"admin-portal/internal/auth-module/repository"
 	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/tracing"
 )
 
 type TokenService interface {
 	IssueTokens(ctx context.Context, user *model.User) (access, refresh string, err error)
 	Logout(ctx context.Context, refreshToken string) error
+	RevokeAll(ctx context.Context, userID string) error
+	Consume(ctx context.Context, refreshToken string) (*model.UserSession, error)
 }
 
 type tokenService struct {
@@ -28,7 +33,13 @@ func NewTokenService(
 func (s *tokenService) IssueTokens(
 	ctx context.Context,
 	user *model.User,
-) (string, string, error) {
+) (_ string, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "tokenService.IssueTokens")
+	span.SetAttributes(attribute.String("user.id", user.ID.String()))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 
 	access, err := security.GenerateAccessToken(
 		s.cfg,
@@ -61,3 +72,13 @@ func (s *tokenService) IssueTokens(
 func (s *tokenService) Logout(ctx context.Context, refreshToken string) error {
 	return s.refreshRepo.Revoke(ctx, refreshToken)
 }
+
+// RevokeAll revokes every session of the user.
+func (s *tokenService) RevokeAll(ctx context.Context, userID string) error {
+	return s.refreshRepo.RevokeAllForUser(ctx, userID)
+}
+
+// Consume revokes a valid refresh token and returns its session.
+func (s *tokenService) Consume(ctx context.Context, refreshToken string) (*model.UserSession, error) {
+	return s.refreshRepo.Consume(ctx, refreshToken)
+}
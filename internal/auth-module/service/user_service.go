@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/auth-module/model"
+	"admin-portal/internal/auth-module/repository"
+)
+
+// UserService answers read-only questions about accounts: who the caller
+// is, and the user, session and login-log listings behind portalctl.
+type UserService interface {
+	CurrentUser(ctx context.Context) (*model.User, error)
+	ListUsers(ctx context.Context, f repository.UserFilter) ([]*model.User, int64, error)
+	ListSessions(ctx context.Context, f repository.SessionFilter) ([]*model.UserSession, int64, error)
+	ListLoginLogs(ctx context.Context, f repository.LoginLogFilter) ([]*model.LoginLog, int64, error)
+}
+
+type userService struct {
+	userRepo     repository.UserRepository
+	sessionRepo  repository.UserSessionRepository
+	loginLogRepo repository.LoginLogRepository
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	sessionRepo repository.UserSessionRepository,
+	loginLogRepo repository.LoginLogRepository,
+) UserService {
+	return &userService{
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		loginLogRepo: loginLogRepo,
+	}
+}
+
+/* CurrentUser loads the user the access token was issued to. */
+func (s *userService) CurrentUser(ctx context.Context) (*model.User, error) {
+	if _, ok := middleware.ServiceFromContext(ctx); ok {
+		return nil, ErrNotAUser
+	}
+
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, ErrInvalidCredential
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound.WithDetail("user_id", userID)
+	}
+	return user, err
+}
+
+/* ListUsers returns a page of users. */
+func (s *userService) ListUsers(ctx context.Context, f repository.UserFilter) ([]*model.User, int64, error) {
+	return s.userRepo.List(ctx, f)
+}
+
+/* ListSessions returns a page of a user's sessions. An empty UserID means the caller; only administrators may list anyone else's. */
+func (s *userService) ListSessions(ctx context.Context, f repository.SessionFilter) ([]*model.UserSession, int64, error) {
+	callerID, _ := middleware.UserIDFromContext(ctx)
+	if f.UserID == "" {
+		if _, ok := middleware.ServiceFromContext(ctx); ok {
+			return nil, 0, ErrNotAUser
+		}
+		f.UserID = callerID
+	}
+
+	if f.UserID != callerID && !isAdmin(ctx) {
+		return nil, 0, ErrAccessDenied.WithDetail("user_id", f.UserID)
+	}
+
+	return s.sessionRepo.List(ctx, f)
+}
+
+/* ListLoginLogs returns a page of login audit logs, newest first. */
+func (s *userService) ListLoginLogs(ctx context.Context, f repository.LoginLogFilter) ([]*model.LoginLog, int64, error) {
+	return s.loginLogRepo.List(ctx, f)
+}
+
+// isAdmin reports whether the caller's role is at least admin.
+func isAdmin(ctx context.Context) bool {
+	role, _ := middleware.RoleFromContext(ctx)
+	return model.RoleRank(role) >= model.RoleRank(model.RoleAdmin)
+}
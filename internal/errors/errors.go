@@ -1 +1,129 @@
-package errors
\ No newline at end of file
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+)
+
+// Code classifies an application error. Transport layers translate it
+// into their own status codes (see internal/shared/grpc/error_mapper.go).
+type Code int
+
+const (
+	CodeUnknown Code = iota
+	CodeInvalidArgument
+	CodeNotFound
+	CodeAlreadyExists
+	CodeUnauthenticated
+	CodePermissionDenied
+	CodeFailedPrecondition
+	CodeResourceExhausted
+	CodeInternal
+)
+
+func (c Code) String() string {
+	switch c {
+	case CodeInvalidArgument:
+		return "INVALID_ARGUMENT"
+	case CodeNotFound:
+		return "NOT_FOUND"
+	case CodeAlreadyExists:
+		return "ALREADY_EXISTS"
+	case CodeUnauthenticated:
+		return "UNAUTHENTICATED"
+	case CodePermissionDenied:
+		return "PERMISSION_DENIED"
+	case CodeFailedPrecondition:
+		return "FAILED_PRECONDITION"
+	case CodeResourceExhausted:
+		return "RESOURCE_EXHAUSTED"
+	case CodeInternal:
+		return "INTERNAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Error is the application error returned by services.
+//
+// Reason is a stable, machine readable identifier (e.g. "USER_NOT_FOUND")
+// that clients can switch on; Message is safe to show to callers.
+// Cause is never sent over the wire.
+type Error struct {
+	Code    Code
+	Reason  string
+	Message string
+	Details map[string]string
+	Cause   error
+}
+
+func New(code Code, reason, message string) *Error {
+	return &Error{
+		Code:    code,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// Wrap returns a copy of e carrying cause.
+func (e *Error) Wrap(cause error) *Error {
+	cp := e.clone()
+	cp.Cause = cause
+	return cp
+}
+
+// WithDetail returns a copy of e with key=value added to its details.
+func (e *Error) WithDetail(key, value string) *Error {
+	cp := e.clone()
+	cp.Details[key] = value
+	return cp
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is an *Error with the same code and reason,
+// so sentinel errors still match after Wrap or WithDetail.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code && e.Reason == t.Reason
+}
+
+func (e *Error) clone() *Error {
+	cp := *e
+	cp.Details = make(map[string]string, len(e.Details)+1)
+	for k, v := range e.Details {
+		cp.Details[k] = v
+	}
+	return &cp
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if stderrors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// Internal wraps an unexpected error. Its cause is logged, not returned.
+func Internal(cause error) *Error {
+	return &Error{
+		Code:    CodeInternal,
+		Reason:  "INTERNAL",
+		Message: "internal error",
+		Cause:   cause,
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"admin-portal/internal/job-module/service"
+	"admin-portal/internal/shared/queue"
+	jobpb "admin-portal/proto/job"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type JobHandler struct {
+	jobpb.UnimplementedJobServiceServer
+	jobService service.JobService
+}
+
+func NewJobHandler(jobService service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+func (h *JobHandler) ListJobs(
+	ctx context.Context,
+	req *jobpb.ListJobsRequest,
+) (*jobpb.ListJobsResponse, error) {
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	// Page tokens are offsets; the validator only lets digits through.
+	offset := 0
+	if req.GetPageToken() != "" {
+		offset, _ = strconv.Atoi(req.GetPageToken())
+	}
+
+	jobs, total, err := h.jobService.List(ctx, queue.Filter{
+		Status: req.GetStatus(),
+		Kind:   req.GetKind(),
+		Limit:  pageSize,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &jobpb.ListJobsResponse{TotalSize: total}
+	for _, j := range jobs {
+		resp.Jobs = append(resp.Jobs, toProto(j))
+	}
+	if next := offset + len(jobs); int64(next) < total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+
+	return resp, nil
+}
+
+func (h *JobHandler) GetJob(
+	ctx context.Context,
+	req *jobpb.GetJobRequest,
+) (*jobpb.Job, error) {
+
+	job, err := h.jobService.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(job), nil
+}
+
+func (h *JobHandler) RetryJob(
+	ctx context.Context,
+	req *jobpb.RetryJobRequest,
+) (*jobpb.Job, error) {
+
+	job, err := h.jobService.Retry(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(job), nil
+}
+
+//-------------------- Helper functions for conversion --------------------//
+
+func toProto(j *queue.Job) *jobpb.Job {
+	return &jobpb.Job{
+		Id:          j.ID.String(),
+		Kind:        j.Kind,
+		Status:      j.Status,
+		Payload:     string(j.Payload),
+		UniqueKey:   deref(j.UniqueKey),
+		Attempts:    int32(j.Attempts),
+		MaxAttempts: int32(j.MaxAttempts),
+		LastError:   deref(j.LastError),
+		LockedBy:    deref(j.LockedBy),
+		RunAt:       timestamppb.New(j.RunAt),
+		CreatedAt:   timestamppb.New(j.CreatedAt),
+		UpdatedAt:   timestamppb.New(j.UpdatedAt),
+		FinishedAt:  timestamp(j.FinishedAt),
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"regexp"
+
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/validation"
+	jobpb "admin-portal/proto/job"
+)
+
+var pageTokenPattern = regexp.MustCompile(`^[0-9]{1,9}$`)
+
+// RegisterValidators declares the request rules for every JobService RPC.
+func RegisterValidators(r *validation.Registry) {
+	r.Register(&jobpb.ListJobsRequest{},
+		validation.Field("status",
+			validation.Optional(validation.OneOf(
+				queue.StatusPending,
+				queue.StatusRunning,
+				queue.StatusSucceeded,
+				queue.StatusDead,
+			)),
+		),
+		validation.Field("kind",
+			validation.MaxLen(100), // jobs.kind VARCHAR(100)
+		),
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+
+	r.Register(&jobpb.GetJobRequest{},
+		validation.Field("id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+
+	r.Register(&jobpb.RetryJobRequest{},
+		validation.Field("id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+}
@@ -0,0 +1,48 @@
+package jobmodule
+
+import (
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/job-module/handler"
+	"admin-portal/internal/job-module/service"
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/validation"
+	jobpb "admin-portal/proto/job"
+)
+
+// Module exposes the admin API over the background job queue.
+type Module struct {
+	JobService service.JobService
+
+	handler *handler.JobHandler
+}
+
+func New(db *gorm.DB) *Module {
+	jobService := service.NewJobService(queue.NewStore(db))
+
+	return &Module{
+		JobService: jobService,
+		handler:    handler.NewJobHandler(jobService),
+	}
+}
+
+func (m *Module) Name() string {
+	return "job"
+}
+
+func (m *Module) Register(s *grpc.Server) {
+	jobpb.RegisterJobServiceServer(s, m.handler)
+}
+
+func (m *Module) RegisterValidators(r *validation.Registry) {
+	handler.RegisterValidators(r)
+}
+
+// Policy restricts the whole JobService to administrators.
+func (m *Module) Policy() middleware.Policy {
+	return middleware.Policy{
+		"/job.JobService/": middleware.AdminRoles,
+	}
+}
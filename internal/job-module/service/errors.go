@@ -0,0 +1,9 @@
+package service
+
+import apperrors "admin-portal/internal/errors"
+
+var (
+	ErrJobNotFound     = apperrors.New(apperrors.CodeNotFound, "JOB_NOT_FOUND", "job not found")
+	ErrJobNotRetryable = apperrors.New(apperrors.CodeFailedPrecondition, "JOB_NOT_RETRYABLE", "only dead jobs can be retried")
+	ErrJobDuplicate    = apperrors.New(apperrors.CodeAlreadyExists, "JOB_DUPLICATE", "a job with the same unique key is already queued")
+)
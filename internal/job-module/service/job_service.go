@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/shared/queue"
+)
+
+type JobService interface {
+	List(ctx context.Context, filter queue.Filter) ([]*queue.Job, int64, error)
+	Get(ctx context.Context, id string) (*queue.Job, error)
+	Retry(ctx context.Context, id string) (*queue.Job, error)
+}
+
+type jobService struct {
+	store *queue.Store
+}
+
+func NewJobService(store *queue.Store) JobService {
+	return &jobService{store: store}
+}
+
+func (s *jobService) List(ctx context.Context, filter queue.Filter) ([]*queue.Job, int64, error) {
+	return s.store.List(ctx, filter)
+}
+
+func (s *jobService) Get(ctx context.Context, id string) (*queue.Job, error) {
+	job, err := s.store.Get(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound.WithDetail("job_id", id)
+	}
+	return job, err
+}
+
+/* Retry re-queues a dead job with a fresh attempt budget. */
+func (s *jobService) Retry(ctx context.Context, id string) (*queue.Job, error) {
+	job, err := s.store.Retry(ctx, id)
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, ErrJobNotFound.WithDetail("job_id", id)
+	case errors.Is(err, queue.ErrNotRetryable):
+		return nil, ErrJobNotRetryable.WithDetail("job_id", id)
+	case errors.Is(err, queue.ErrDuplicate):
+		return nil, ErrJobDuplicate.WithDetail("job_id", id)
+	}
+
+	return job, err
+}
@@ -1 +1,53 @@
-package config
\ No newline at end of file
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// String returns the environment variable key, or def if it is unset.
+func String(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// Int returns key parsed as an int, or def if it is unset or invalid.
+func Int(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Float returns key parsed as a float64, or def if it is unset or invalid.
+func Float(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Duration returns key parsed with time.ParseDuration, or def if it is
+// unset or invalid.
+func Duration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Bool returns key parsed with strconv.ParseBool, or def if it is unset
+// or invalid.
+func Bool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -39,3 +39,12 @@ func OpenGorm(cfg Config) (*gorm.DB, error) {
 
 	return db, nil
 }
+
+// CloseGorm closes the connection pool behind db.
+func CloseGorm(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
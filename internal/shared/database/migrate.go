@@ -1 +1,792 @@
-package database
\ No newline at end of file
+package database
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/user"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Section markers for single-file migrations.
+const (
+	upMarker   = "-- +up"
+	downMarker = "-- +down"
+)
+
+// noTransactionDirective makes a migration run outside a transaction, one
+// statement at a time, for statements such as CREATE INDEX CONCURRENTLY.
+const noTransactionDirective = "-- +no-transaction"
+
+// bookkeepingVersion is the migration that creates schema_migrations. Down
+// and Redo leave it alone; Prepare keeps the table up to date.
+const bookkeepingVersion = "0000"
+
+// migrationLockKey identifies the advisory lock shared by every migrator.
+const migrationLockKey int64 = 0x61646d696e // "admin"
+
+var versionPattern = regexp.MustCompile(`^[0-9]+$`)
+
+type Migration struct {
+	Version string
+	Name    string
+	UpSQL   string
+	DownSQL string
+
+	// HasDown is false for migrations that cannot be rolled back.
+	HasDown bool
+
+	// NoTransaction is set by the -- +no-transaction directive.
+	NoTransaction bool
+
+	files []string
+}
+
+// Checksum is the hex SHA-256 of the up SQL, which is what defines the
+// schema once applied. Editing only the down section is not drift.
+func (m *Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.UpSQL))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationVersion returns the version prefix of a migration file name,
+// e.g. "0003" for "migrations/0003_login_logs.sql".
+func MigrationVersion(p string) string {
+	base := path.Base(p)
+	return strings.Split(base, "_")[0]
+}
+
+// MigrationVersions lists the distinct versions in source, sorted.
+func MigrationVersions(source fs.FS) ([]string, error) {
+	migrations, err := LoadMigrations(source)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+
+	return versions, nil
+}
+
+// LoadMigrations reads the *.sql files at the root of source and returns
+// the migrations sorted by version.
+//
+// A migration is either a pair of files, NNNN_name.up.sql and
+// NNNN_name.down.sql, or a single NNNN_name.sql that may be split into
+// "-- +up" and "-- +down" sections. A single file without markers is
+// treated as up-only. Versions must be numeric and unique.
+func LoadMigrations(source fs.FS) ([]*Migration, error) {
+	files, err := fs.Glob(source, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[string]*Migration)
+	get := func(version, name string) *Migration {
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		return m
+	}
+
+	for _, file := range files {
+		base := path.Base(file)
+		version := MigrationVersion(base)
+		if !strings.Contains(base, "_") || !versionPattern.MatchString(version) {
+			return nil, fmt.Errorf("%s: file name must start with a numeric version followed by '_'", base)
+		}
+
+		content, err := fs.ReadFile(source, file)
+		if err != nil {
+			return nil, err
+		}
+
+		var m *Migration
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			m = get(version, migrationName(base, ".up.sql"))
+			m.UpSQL = string(content)
+		case strings.HasSuffix(base, ".down.sql"):
+			m = get(version, migrationName(base, ".down.sql"))
+			m.DownSQL = string(content)
+			m.HasDown = true
+		default:
+			m = get(version, migrationName(base, ".sql"))
+			m.UpSQL, m.DownSQL, m.HasDown = splitSections(string(content))
+		}
+
+		m.files = append(m.files, base)
+		if hasDirective(string(content), noTransactionDirective) {
+			m.NoTransaction = true
+		}
+	}
+
+	for _, m := range byVersion {
+		if err := checkFiles(m); err != nil {
+			return nil, err
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.UpSQL) == "" {
+			return nil, fmt.Errorf("migration %s has no up SQL", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func migrationName(base, suffix string) string {
+	name := strings.TrimSuffix(base, suffix)
+	if i := strings.Index(name, "_"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// checkFiles rejects versions claimed by more than one migration: two
+// single files, a single file plus a pair, or a pair with different names.
+func checkFiles(m *Migration) error {
+	var single, up, down int
+	names := make(map[string]bool)
+	for _, f := range m.files {
+		switch {
+		case strings.HasSuffix(f, ".up.sql"):
+			up++
+			names[migrationName(f, ".up.sql")] = true
+		case strings.HasSuffix(f, ".down.sql"):
+			down++
+			names[migrationName(f, ".down.sql")] = true
+		default:
+			single++
+			names[migrationName(f, ".sql")] = true
+		}
+	}
+
+	if single == 0 && up == 0 {
+		return fmt.Errorf("migration %s has a down file but no up file", m.Version)
+	}
+	if len(m.files) == 1 && single == 1 {
+		return nil
+	}
+	if single == 0 && up == 1 && down <= 1 && len(names) == 1 {
+		return nil
+	}
+
+	sort.Strings(m.files)
+	return fmt.Errorf("duplicate migration version %s: %s", m.Version, strings.Join(m.files, ", "))
+}
+
+func hasDirective(content, directive string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSections splits a single-file migration on its section markers.
+// Anything before the first marker belongs to the up section.
+func splitSections(content string) (up, down string, hasDown bool) {
+	var upBuf, downBuf strings.Builder
+	current := &upBuf
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case noTransactionDirective:
+			continue
+		case upMarker:
+			current = &upBuf
+			continue
+		case downMarker:
+			current = &downBuf
+			hasDown = true
+			continue
+		}
+		current.WriteString(line)
+		current.WriteByte('\n')
+	}
+
+	return upBuf.String(), downBuf.String(), hasDown
+}
+
+/* ---------------- Migrator ---------------- */
+
+type MigrateOptions struct {
+	// AllowDrift logs checksum mismatches instead of failing.
+	AllowDrift bool
+
+	// DryRun prints the SQL that would run instead of executing it.
+	DryRun bool
+
+	// LockTimeout bounds the wait for another run's advisory lock.
+	LockTimeout time.Duration
+
+	// Out receives status tables and dry-run SQL. Defaults to os.Stdout.
+	Out io.Writer
+}
+
+type AppliedMigration struct {
+	Version   string
+	AppliedAt time.Time
+	Duration  time.Duration
+	AppliedBy string
+	Checksum  string
+}
+
+// dbConn is satisfied by *sql.DB and *sql.Conn.
+type dbConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+type Migrator struct {
+	db *sql.DB
+	// conn is db, or the locked connection while Lock is held, so a run
+	// never needs a second connection from the pool.
+	conn       dbConn
+	migrations []*Migration
+	opts       MigrateOptions
+	appliedBy  string
+}
+
+// NewMigrator loads and validates the migrations in source.
+func NewMigrator(db *sql.DB, source fs.FS, opts MigrateOptions) (*Migrator, error) {
+	migrations, err := LoadMigrations(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	if opts.LockTimeout == 0 {
+		opts.LockTimeout = time.Minute
+	}
+
+	return &Migrator{
+		db:         db,
+		conn:       db,
+		migrations: migrations,
+		opts:       opts,
+		appliedBy:  currentOperator(),
+	}, nil
+}
+
+// MigrateUp applies every pending migration in source under the advisory
+// lock. It is what cmd/api runs when auto-migrate is enabled.
+func MigrateUp(ctx context.Context, db *sql.DB, source fs.FS, opts MigrateOptions) error {
+	m, err := NewMigrator(db, source, opts)
+	if err != nil {
+		return err
+	}
+
+	release, err := m.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := m.Prepare(ctx); err != nil {
+		return err
+	}
+
+	if err := m.VerifyChecksums(ctx); err != nil {
+		return err
+	}
+
+	return m.Up(ctx, "")
+}
+
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Lock takes the migration advisory lock on a dedicated connection,
+// waiting up to LockTimeout for another run to finish. Until the returned
+// function releases it, every statement runs on that connection. The lock
+// is also released when the process exits.
+func (m *Migrator) Lock(ctx context.Context) (func(), error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(m.opts.LockTimeout)
+	waiting := false
+
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx,
+			`SELECT pg_try_advisory_lock($1)`, migrationLockKey,
+		).Scan(&locked); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		if locked {
+			m.conn = conn
+			return func() {
+				m.conn = m.db
+				conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+				conn.Close()
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			conn.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for the migration lock", m.opts.LockTimeout)
+		}
+
+		if !waiting {
+			log.Println("⏳ Another migration is running, waiting for the lock...")
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Prepare creates or upgrades the schema_migrations table. It does
+// nothing in dry-run mode.
+func (m *Migrator) Prepare(ctx context.Context) error {
+	if m.opts.DryRun {
+		return nil
+	}
+
+	_, err := m.conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(50) PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+
+		ALTER TABLE schema_migrations
+			ADD COLUMN IF NOT EXISTS duration_ms BIGINT,
+			ADD COLUMN IF NOT EXISTS applied_by TEXT,
+			ADD COLUMN IF NOT EXISTS checksum CHAR(64);
+	`)
+	return err
+}
+
+// Applied returns the rows of schema_migrations keyed by version. Rows
+// are read as JSON so a table created by an older migrator (and not yet
+// upgraded, as in dry-run) still reads cleanly.
+func (m *Migrator) Applied(ctx context.Context) (map[string]AppliedMigration, error) {
+	var exists bool
+	if err := m.conn.QueryRowContext(ctx,
+		`SELECT to_regclass('schema_migrations') IS NOT NULL`,
+	).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]AppliedMigration)
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := m.conn.QueryContext(ctx, `SELECT to_jsonb(s) FROM schema_migrations s`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		var row struct {
+			Version    string  `json:"version"`
+			AppliedAt  string  `json:"applied_at"`
+			DurationMS *int64  `json:"duration_ms"`
+			AppliedBy  *string `json:"applied_by"`
+			Checksum   *string `json:"checksum"`
+		}
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return nil, err
+		}
+
+		a := AppliedMigration{Version: row.Version}
+		a.AppliedAt, _ = time.Parse("2006-01-02T15:04:05.999999", row.AppliedAt)
+		if row.DurationMS != nil {
+			a.Duration = time.Duration(*row.DurationMS) * time.Millisecond
+		}
+		if row.AppliedBy != nil {
+			a.AppliedBy = *row.AppliedBy
+		}
+		if row.Checksum != nil {
+			a.Checksum = strings.TrimSpace(*row.Checksum)
+		}
+		applied[a.Version] = a
+	}
+
+	return applied, rows.Err()
+}
+
+// Up applies pending migrations. target is "" or "all" for everything, a
+// known version to stop after that version, or a count N.
+func (m *Migrator) Up(ctx context.Context, target string) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []*Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+
+	switch {
+	case target == "" || target == "all":
+	case m.isVersion(target):
+		n := 0
+		for n < len(pending) && pending[n].Version <= target {
+			n++
+		}
+		pending = pending[:n]
+	default:
+		n, err := strconv.Atoi(target)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid up target %q: want a version or a count", target)
+		}
+		if n < len(pending) {
+			pending = pending[:n]
+		}
+	}
+
+	if len(pending) == 0 {
+		log.Println("ℹ️ No migrations to apply")
+		return nil
+	}
+
+	for _, mig := range pending {
+		if err := m.apply(ctx, mig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down rolls back applied migrations, newest first. target is "" for one
+// step, "all" for everything, a known version to roll back everything
+// after it, or a count N. The bookkeeping migration is never rolled
+// back, since reverting it would drop the table that records it.
+func (m *Migrator) Down(ctx context.Context, target string) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rollback []*Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if m.migrations[i].Version == bookkeepingVersion {
+			continue
+		}
+		if _, ok := applied[m.migrations[i].Version]; ok {
+			rollback = append(rollback, m.migrations[i])
+		}
+	}
+
+	switch {
+	case target == "all":
+	case target == "":
+		rollback = rollback[:min(1, len(rollback))]
+	case m.isVersion(target):
+		n := 0
+		for n < len(rollback) && rollback[n].Version > target {
+			n++
+		}
+		rollback = rollback[:n]
+	default:
+		n, err := strconv.Atoi(target)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid down target %q: want a version or a count", target)
+		}
+		rollback = rollback[:min(n, len(rollback))]
+	}
+
+	if len(rollback) == 0 {
+		log.Println("ℹ️ No migrations to roll back")
+		return nil
+	}
+
+	// Refuse up front rather than stopping half way.
+	for _, mig := range rollback {
+		if !mig.HasDown {
+			return fmt.Errorf("migration %s_%s has no down section", mig.Version, mig.Name)
+		}
+	}
+
+	for _, mig := range rollback {
+		if err := m.revert(ctx, mig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Redo rolls back the latest applied migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok || mig.Version == bookkeepingVersion {
+			continue
+		}
+		if !mig.HasDown {
+			return fmt.Errorf("migration %s_%s has no down section", mig.Version, mig.Name)
+		}
+		if err := m.revert(ctx, mig); err != nil {
+			return err
+		}
+		return m.apply(ctx, mig)
+	}
+
+	log.Println("ℹ️ No applied migration to redo")
+	return nil
+}
+
+// Status prints every known migration and whether it is applied.
+func (m *Migrator) Status(ctx context.Context) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := m.opts.Out
+	fmt.Fprintf(out, "%-8s %-32s %-9s %-20s %-10s %s\n", "VERSION", "NAME", "STATE", "APPLIED AT", "DURATION", "APPLIED BY")
+
+	known := make(map[string]bool)
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+
+		a, ok := applied[mig.Version]
+		if !ok {
+			fmt.Fprintf(out, "%-8s %-32s %-9s\n", mig.Version, mig.Name, "pending")
+			continue
+		}
+		state := "applied"
+		if a.Checksum != "" && a.Checksum != mig.Checksum() {
+			state = "drifted"
+		}
+		fmt.Fprintf(out, "%-8s %-32s %-9s %-20s %-10s %s\n",
+			mig.Version, mig.Name, state,
+			a.AppliedAt.Format("2006-01-02 15:04:05"), a.Duration, a.AppliedBy)
+	}
+
+	for version, a := range applied {
+		if !known[version] {
+			fmt.Fprintf(out, "%-8s %-32s %-9s %-20s %-10s %s\n",
+				version, "?", "missing",
+				a.AppliedAt.Format("2006-01-02 15:04:05"), a.Duration, a.AppliedBy)
+		}
+	}
+
+	return nil
+}
+
+// VerifyChecksums compares every applied migration with its file.
+// Migrations applied before checksums were recorded get theirs filled in.
+// A mismatch is fatal unless AllowDrift is set, in which case it is only
+// logged.
+func (m *Migrator) VerifyChecksums(ctx context.Context) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	var drifted []string
+	for _, mig := range m.migrations {
+		a, ok := applied[mig.Version]
+		if !ok {
+			continue
+		}
+
+		sum := mig.Checksum()
+		switch a.Checksum {
+		case sum:
+		case "":
+			if m.opts.DryRun {
+				continue
+			}
+			log.Printf("🔏 Recording checksum for %s_%s", mig.Version, mig.Name)
+			if _, err := m.conn.ExecContext(ctx,
+				`UPDATE schema_migrations SET checksum = $1 WHERE version = $2`,
+				sum, mig.Version,
+			); err != nil {
+				return err
+			}
+		default:
+			log.Printf("⚠️ Checksum mismatch for %s_%s: applied %s, file %s",
+				mig.Version, mig.Name, a.Checksum, sum)
+			drifted = append(drifted, mig.Version)
+		}
+	}
+
+	if len(drifted) > 0 && !m.opts.AllowDrift {
+		return fmt.Errorf("%d applied migration(s) changed since they ran (%v); "+
+			"restore the files or rerun with -allow-drift", len(drifted), drifted)
+	}
+
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, mig *Migration) error {
+	if m.opts.DryRun {
+		m.printDryRun("up", mig, mig.UpSQL)
+		return nil
+	}
+
+	log.Printf("▶ Applying %s_%s", mig.Version, mig.Name)
+
+	start := time.Now()
+
+	return m.run(ctx, mig, mig.UpSQL, func(ex execer) error {
+		_, err := ex.ExecContext(
+			ctx,
+			`INSERT INTO schema_migrations (version, duration_ms, applied_by, checksum) VALUES ($1, $2, $3, $4)`,
+			mig.Version,
+			time.Since(start).Milliseconds(),
+			m.appliedBy,
+			mig.Checksum(),
+		)
+		return err
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, mig *Migration) error {
+	if m.opts.DryRun {
+		m.printDryRun("down", mig, mig.DownSQL)
+		return nil
+	}
+
+	log.Printf("◀ Rolling back %s_%s", mig.Version, mig.Name)
+
+	return m.run(ctx, mig, mig.DownSQL, func(ex execer) error {
+		_, err := ex.ExecContext(
+			ctx,
+			`DELETE FROM schema_migrations WHERE version = $1`,
+			mig.Version,
+		)
+		return err
+	})
+}
+
+func (m *Migrator) printDryRun(direction string, mig *Migration, script string) {
+	mode := "transaction"
+	if mig.NoTransaction {
+		mode = "no transaction"
+	}
+
+	fmt.Fprintf(m.opts.Out, "-- ===== %s %s_%s (%s) =====\n", direction, mig.Version, mig.Name, mode)
+	fmt.Fprintln(m.opts.Out, strings.TrimRight(script, "\n"))
+	fmt.Fprintln(m.opts.Out)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// run executes script and then record. Normally both happen in one
+// transaction; -- +no-transaction migrations run statement by statement
+// and are recorded only once every statement has succeeded.
+func (m *Migrator) run(ctx context.Context, mig *Migration, script string, record func(execer) error) error {
+	if mig.NoTransaction {
+		for i, stmt := range splitStatements(script) {
+			if _, err := m.conn.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("migration %s failed at statement %d (no transaction, "+
+					"earlier statements are not rolled back): %w", mig.Version, i+1, err)
+			}
+		}
+		return record(m.conn)
+	}
+
+	tx, err := m.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %s failed: %w", mig.Version, err)
+	}
+
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) isVersion(s string) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == s {
+			return true
+		}
+	}
+	return false
+}
+
+// currentOperator identifies who is running the migration, preferring
+// MIGRATE_APPLIED_BY so CI can record the pipeline instead of the host.
+func currentOperator() string {
+	if by := os.Getenv("MIGRATE_APPLIED_BY"); by != "" {
+		return by
+	}
+
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+
+	return name
+}
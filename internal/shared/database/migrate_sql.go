@@ -0,0 +1,109 @@
+package database
+
+import "strings"
+
+// splitStatements splits a SQL script on top-level semicolons. It
+// understands quoted identifiers and strings, dollar-quoted bodies and
+// comments, which is enough for migrations that must run statement by
+// statement outside a transaction.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" && !onlyComments(stmt) {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i - 2
+			} else {
+				end += 2
+			}
+			current.WriteString(script[i : i+2+end])
+			i += 2 + end
+
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(script) {
+				if script[end] == c {
+					if end+1 < len(script) && script[end+1] == c {
+						end += 2 // escaped quote
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(script))
+			current.WriteString(script[i:end])
+			i = end
+
+		case c == '$':
+			if tag, ok := dollarTag(script[i:]); ok {
+				body := strings.Index(script[i+len(tag):], tag)
+				end := len(script)
+				if body >= 0 {
+					end = i + len(tag) + body + len(tag)
+				}
+				current.WriteString(script[i:end])
+				i = end
+				continue
+			}
+			current.WriteByte(c)
+			i++
+
+		case c == ';':
+			flush()
+			i++
+
+		default:
+			current.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// dollarTag returns the opening tag ($$ or $name$) at the start of s.
+func dollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		ch := s[j]
+		if ch == '$' {
+			return s[:j+1], true
+		}
+		if !(ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || j > 1 && ch >= '0' && ch <= '9') {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func onlyComments(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
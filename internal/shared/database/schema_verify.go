@@ -0,0 +1,409 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+type liveColumn struct {
+	udtName   string
+	nullable  bool
+	maxLength sql.NullInt64
+}
+
+type liveIndex struct {
+	name    string
+	columns string
+	unique  bool
+}
+
+type liveForeignKey struct {
+	name     string
+	columns  string
+	refTable string
+	onDelete string
+}
+
+var typeLengthPattern = regexp.MustCompile(`^\s*([a-zA-Z ]+?)\s*(?:\((\d+)\))?\s*$`)
+
+// VerifySchema compares the live schema with models and reports missing
+// or mismatched columns, indexes and constraints. Objects that exist only
+// in the database are reported but do not fail verification.
+func VerifySchema(ctx context.Context, db *sql.DB, models []interface{}) error {
+	cache := &sync.Map{}
+	namer := schema.NamingStrategy{}
+
+	var problems, notes []string
+
+	for _, m := range models {
+		s, err := schema.Parse(m, cache, namer)
+		if err != nil {
+			return fmt.Errorf("parse model %T: %w", m, err)
+		}
+
+		p, n, err := verifyTable(ctx, db, s)
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", s.Table, err)
+		}
+		problems = append(problems, p...)
+		notes = append(notes, n...)
+	}
+
+	for _, n := range notes {
+		log.Println("ℹ️", n)
+	}
+	for _, p := range problems {
+		log.Println("❌", p)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("schema differs from models in %d place(s)", len(problems))
+	}
+
+	log.Println("✅ Live schema matches the models")
+	return nil
+}
+
+func verifyTable(ctx context.Context, db *sql.DB, s *schema.Schema) (problems, notes []string, err error) {
+	columns, err := loadColumns(ctx, db, s.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(columns) == 0 {
+		return []string{fmt.Sprintf("table %s does not exist", s.Table)}, nil, nil
+	}
+
+	// Columns
+	modelColumns := make(map[string]bool)
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		modelColumns[f.DBName] = true
+
+		live, ok := columns[f.DBName]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s: column missing", s.Table, f.DBName))
+			continue
+		}
+
+		if want := f.NotNull || f.PrimaryKey; want == live.nullable {
+			problems = append(problems, fmt.Sprintf("%s.%s: model NOT NULL=%v, database NOT NULL=%v",
+				s.Table, f.DBName, want, !live.nullable))
+		}
+
+		if msg := compareType(f, live); msg != "" {
+			problems = append(problems, fmt.Sprintf("%s.%s: %s", s.Table, f.DBName, msg))
+		}
+	}
+	for name := range columns {
+		if !modelColumns[name] {
+			notes = append(notes, fmt.Sprintf("%s.%s: column not declared in model", s.Table, name))
+		}
+	}
+
+	// Indexes, matched by column list and uniqueness rather than name
+	indexes, err := loadIndexes(ctx, db, s.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, idx := range s.ParseIndexes() {
+		cols := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			cols = append(cols, f.DBName)
+		}
+		key := indexKey(strings.Join(cols, ","), idx.Class == "UNIQUE")
+		wanted[key] = true
+
+		if !hasIndex(indexes, key) {
+			problems = append(problems, fmt.Sprintf("%s: %s index %s on (%s) missing",
+				s.Table, strings.ToLower(orDefault(idx.Class, "plain")), idx.Name, strings.Join(cols, ", ")))
+		}
+	}
+	for _, f := range s.Fields {
+		if f.Unique {
+			key := indexKey(f.DBName, true)
+			wanted[key] = true
+			if !hasIndex(indexes, key) {
+				problems = append(problems, fmt.Sprintf("%s.%s: unique constraint missing", s.Table, f.DBName))
+			}
+		}
+	}
+	for _, idx := range indexes {
+		if idx.name == s.Table+"_pkey" {
+			continue
+		}
+		if !wanted[indexKey(idx.columns, idx.unique)] {
+			notes = append(notes, fmt.Sprintf("%s: index %s on (%s) not declared in model", s.Table, idx.name, idx.columns))
+		}
+	}
+
+	// Check constraints, matched by column
+	checks, err := loadCheckColumns(ctx, db, s.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, chk := range s.ParseCheckConstraints() {
+		if !checks[chk.Field.DBName] {
+			problems = append(problems, fmt.Sprintf("%s.%s: check constraint missing (%s)",
+				s.Table, chk.Field.DBName, chk.Constraint))
+		}
+	}
+
+	// Foreign keys declared on this table
+	fks, err := loadForeignKeys(ctx, db, s.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool)
+	for _, rel := range s.Relationships.Relations {
+		c := rel.ParseConstraint()
+		if c == nil || c.Schema.Table != s.Table {
+			continue
+		}
+
+		cols := make([]string, 0, len(c.ForeignKeys))
+		for _, f := range c.ForeignKeys {
+			cols = append(cols, f.DBName)
+		}
+		key := strings.Join(cols, ",") + "->" + c.ReferenceSchema.Table
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		fk, ok := findForeignKey(fks, strings.Join(cols, ","), c.ReferenceSchema.Table)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: foreign key (%s) -> %s missing",
+				s.Table, strings.Join(cols, ", "), c.ReferenceSchema.Table))
+			continue
+		}
+
+		if want := orDefault(strings.ToUpper(c.OnDelete), "NO ACTION"); want != fk.onDelete {
+			problems = append(problems, fmt.Sprintf("%s: foreign key %s ON DELETE is %s, model wants %s",
+				s.Table, fk.name, fk.onDelete, want))
+		}
+	}
+	for _, fk := range fks {
+		if !seen[fk.columns+"->"+fk.refTable] {
+			notes = append(notes, fmt.Sprintf("%s: foreign key %s (%s) -> %s not declared in model",
+				s.Table, fk.name, fk.columns, fk.refTable))
+		}
+	}
+
+	sort.Strings(problems)
+	sort.Strings(notes)
+	return problems, notes, nil
+}
+
+// compareType checks the parts of a column type that GORM models express:
+// the base type and, for varchar, the length.
+func compareType(f *schema.Field, live liveColumn) string {
+	want := strings.ToLower(string(f.DataType))
+
+	switch f.DataType {
+	case schema.Bool:
+		want = "bool"
+	case schema.Time:
+		if !strings.HasPrefix(live.udtName, "timestamp") {
+			return fmt.Sprintf("type is %s, model wants a timestamp", live.udtName)
+		}
+		return ""
+	case schema.String:
+		if live.udtName != "text" && live.udtName != "varchar" {
+			return fmt.Sprintf("type is %s, model wants a string", live.udtName)
+		}
+		return ""
+	case schema.Int, schema.Uint:
+		if !strings.HasPrefix(live.udtName, "int") {
+			return fmt.Sprintf("type is %s, model wants an integer", live.udtName)
+		}
+		return ""
+	}
+
+	m := typeLengthPattern.FindStringSubmatch(want)
+	if m == nil {
+		return ""
+	}
+
+	base := normalizeType(m[1])
+	if base != live.udtName {
+		return fmt.Sprintf("type is %s, model wants %s", live.udtName, want)
+	}
+
+	if m[2] != "" && live.maxLength.Valid && fmt.Sprint(live.maxLength.Int64) != m[2] {
+		return fmt.Sprintf("length is %d, model wants %s", live.maxLength.Int64, m[2])
+	}
+
+	return ""
+}
+
+func normalizeType(t string) string {
+	switch strings.TrimSpace(t) {
+	case "character varying":
+		return "varchar"
+	case "boolean":
+		return "bool"
+	case "integer":
+		return "int4"
+	case "bigint":
+		return "int8"
+	case "smallint":
+		return "int2"
+	default:
+		return strings.TrimSpace(t)
+	}
+}
+
+func loadColumns(ctx context.Context, db *sql.DB, table string) (map[string]liveColumn, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT column_name, udt_name, is_nullable = 'YES', character_maximum_length
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]liveColumn)
+	for rows.Next() {
+		var name string
+		var c liveColumn
+		if err := rows.Scan(&name, &c.udtName, &c.nullable, &c.maxLength); err != nil {
+			return nil, err
+		}
+		columns[name] = c
+	}
+	return columns, rows.Err()
+}
+
+func loadIndexes(ctx context.Context, db *sql.DB, table string) ([]liveIndex, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT i.relname,
+		       string_agg(a.attname, ',' ORDER BY k.ord),
+		       ix.indisunique
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		CROSS JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = current_schema() AND t.relname = $1
+		GROUP BY i.relname, ix.indisunique
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []liveIndex
+	for rows.Next() {
+		var idx liveIndex
+		if err := rows.Scan(&idx.name, &idx.columns, &idx.unique); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, rows.Err()
+}
+
+func loadCheckColumns(ctx context.Context, db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT a.attname
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY (c.conkey)
+		WHERE c.contype = 'c' AND n.nspname = current_schema() AND t.relname = $1
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+func loadForeignKeys(ctx context.Context, db *sql.DB, table string) ([]liveForeignKey, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.conname,
+		       (SELECT string_agg(a.attname, ',' ORDER BY k.ord)
+		          FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+		          JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum),
+		       r.relname,
+		       CASE c.confdeltype
+		           WHEN 'c' THEN 'CASCADE'
+		           WHEN 'n' THEN 'SET NULL'
+		           WHEN 'd' THEN 'SET DEFAULT'
+		           WHEN 'r' THEN 'RESTRICT'
+		           ELSE 'NO ACTION'
+		       END
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_class r ON r.oid = c.confrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE c.contype = 'f' AND n.nspname = current_schema() AND t.relname = $1
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fks []liveForeignKey
+	for rows.Next() {
+		var fk liveForeignKey
+		if err := rows.Scan(&fk.name, &fk.columns, &fk.refTable, &fk.onDelete); err != nil {
+			return nil, err
+		}
+		fks = append(fks, fk)
+	}
+	return fks, rows.Err()
+}
+
+func indexKey(columns string, unique bool) string {
+	return fmt.Sprintf("%s|%v", columns, unique)
+}
+
+func hasIndex(indexes []liveIndex, key string) bool {
+	for _, idx := range indexes {
+		if indexKey(idx.columns, idx.unique) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func findForeignKey(fks []liveForeignKey, columns, refTable string) (liveForeignKey, bool) {
+	for _, fk := range fks {
+		if fk.columns == columns && fk.refTable == refTable {
+			return fk, true
+		}
+	}
+	return liveForeignKey{}, false
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
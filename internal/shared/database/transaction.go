@@ -1 +1,51 @@
-package database
\ No newline at end of file
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// Transaction runs fn in a transaction on db. The transaction travels in
+// the context passed to fn, so repositories that resolve their handle
+// with Conn take part in it without being rebuilt. Nested calls reuse
+// the outer transaction.
+func Transaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Transactor runs fn in a transaction. Services take one instead of a
+// *gorm.DB so that unit tests can run them on in-memory repositories.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NewTransactor returns a Transactor that calls Transaction on db.
+func NewTransactor(db *gorm.DB) Transactor {
+	return transactor{db: db}
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func (t transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Transaction(ctx, t.db, fn)
+}
+
+// Conn returns the transaction carried by ctx, or db if there is none,
+// bound to ctx.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,24 @@
+package grpc
+
+import (
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"admin-portal/internal/shared/security"
+)
+
+// ClientCredentials returns TLS credentials for cfg, or plaintext when
+// enableTLS is false and cfg names no TLS files. The reloader is non-nil
+// when a client certificate is used.
+func ClientCredentials(enableTLS bool, cfg security.ClientTLSConfig) (credentials.TransportCredentials, *security.CertReloader, error) {
+	if !enableTLS && cfg == (security.ClientTLSConfig{}) {
+		return insecure.NewCredentials(), nil, nil
+	}
+
+	tlsCfg, reloader, err := security.ClientTLS(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return credentials.NewTLS(tlsCfg), reloader, nil
+}
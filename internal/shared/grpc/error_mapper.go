@@ -1 +1,123 @@
-package grpc
\ No newline at end of file
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apperrors "admin-portal/internal/errors"
+	"admin-portal/internal/shared/validation"
+)
+
+// ErrorDomain is reported in google.rpc.ErrorInfo.domain.
+const ErrorDomain = "admin-portal"
+
+var codeMap = map[apperrors.Code]codes.Code{
+	apperrors.CodeInvalidArgument:    codes.InvalidArgument,
+	apperrors.CodeNotFound:           codes.NotFound,
+	apperrors.CodeAlreadyExists:      codes.AlreadyExists,
+	apperrors.CodeUnauthenticated:    codes.Unauthenticated,
+	apperrors.CodePermissionDenied:   codes.PermissionDenied,
+	apperrors.CodeFailedPrecondition: codes.FailedPrecondition,
+	apperrors.CodeResourceExhausted:  codes.ResourceExhausted,
+	apperrors.CodeInternal:           codes.Internal,
+}
+
+// ToStatus converts err into a gRPC status.
+//
+// Errors that already carry a status pass through unchanged. Application
+// errors are mapped by code and annotated with google.rpc.ErrorInfo.
+// Anything else is logged and masked as codes.Internal.
+func ToStatus(method string, err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, "deadline exceeded")
+	}
+
+	appErr, ok := apperrors.As(err)
+	if !ok || appErr.Code == apperrors.CodeUnknown {
+		log.Printf("❌ %s: unhandled error: %v", method, err)
+		return status.New(codes.Internal, "internal error")
+	}
+
+	code, ok := codeMap[appErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+
+	if code == codes.Internal && appErr.Cause != nil {
+		log.Printf("❌ %s: internal error: %v", method, appErr.Cause)
+	}
+
+	st := status.New(code, appErr.Message)
+
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   appErr.Reason,
+		Domain:   ErrorDomain,
+		Metadata: appErr.Details,
+	})
+	if err != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// Code returns the gRPC code err will be reported with, without logging.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+
+	if appErr, ok := apperrors.As(err); ok {
+		if code, ok := codeMap[appErr.Code]; ok {
+			return code
+		}
+	}
+
+	return codes.Internal
+}
+
+func invalidArgument(violations []validation.Violation) *status.Status {
+	st := status.New(codes.InvalidArgument, "invalid request")
+
+	br := &errdetails.BadRequest{}
+	for _, v := range violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	withDetails, err := st.WithDetails(br)
+	if err != nil {
+		return st
+	}
+
+	return withDetails
+}
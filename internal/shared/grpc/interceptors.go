@@ -1 +1,52 @@
-package grpc
\ No newline at end of file
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"admin-portal/internal/shared/validation"
+)
+
+// ErrorUnaryInterceptor converts handler errors into gRPC statuses.
+// It should be the outermost interceptor so it sees every error.
+func ErrorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, ToStatus(info.FullMethod, err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// ValidationUnaryInterceptor rejects requests that break the rules in
+// registry with codes.InvalidArgument and google.rpc.BadRequest details.
+func ValidationUnaryInterceptor(registry *validation.Registry) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		if violations := registry.Validate(msg); len(violations) > 0 {
+			return nil, invalidArgument(violations).Err()
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -1 +1,221 @@
-package grpc
\ No newline at end of file
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+
+	"admin-portal/internal/shared/config"
+	"admin-portal/internal/shared/validation"
+)
+
+type Config struct {
+	Addr string
+
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	KeepaliveTime     time.Duration
+	KeepaliveTimeout  time.Duration
+	KeepaliveMinTime  time.Duration
+	MaxConnectionIdle time.Duration
+
+	ShutdownTimeout time.Duration
+
+	// TLS secures the listener when set (see security.ServerTLS). It is
+	// not read from the environment; cmd/api builds it.
+	TLS *tls.Config
+}
+
+func LoadConfig() Config {
+	return Config{
+		Addr:              config.String("GRPC_ADDR", ":50051"),
+		MaxRecvMsgSize:    config.Int("GRPC_MAX_RECV_MSG_SIZE", 4<<20),
+		MaxSendMsgSize:    config.Int("GRPC_MAX_SEND_MSG_SIZE", 4<<20),
+		KeepaliveTime:     config.Duration("GRPC_KEEPALIVE_TIME", 2*time.Hour),
+		KeepaliveTimeout:  config.Duration("GRPC_KEEPALIVE_TIMEOUT", 20*time.Second),
+		KeepaliveMinTime:  config.Duration("GRPC_KEEPALIVE_MIN_TIME", 5*time.Minute),
+		MaxConnectionIdle: config.Duration("GRPC_MAX_CONNECTION_IDLE", 15*time.Minute),
+		ShutdownTimeout:   config.Duration("GRPC_SHUTDOWN_TIMEOUT", 15*time.Second),
+	}
+}
+
+// Module is a feature area that exposes gRPC services.
+type Module interface {
+	Name() string
+	Register(s *grpc.Server)
+}
+
+// ValidatingModule is implemented by modules that declare request rules.
+type ValidatingModule interface {
+	Module
+	RegisterValidators(r *validation.Registry)
+}
+
+// Server wraps grpc.Server with the shared interceptor chain and a
+// graceful shutdown sequence.
+type Server struct {
+	cfg        Config
+	server     *grpc.Server
+	validators *validation.Registry
+	onDrain    []func()
+	onShutdown []func(ctx context.Context) error
+}
+
+// NewServer builds a server whose chain is: error mapping, the given
+// interceptors (e.g. authentication), then request validation.
+func NewServer(cfg Config, interceptors ...grpc.UnaryServerInterceptor) *Server {
+	validators := validation.NewRegistry()
+
+	chain := []grpc.UnaryServerInterceptor{ErrorUnaryInterceptor()}
+	chain = append(chain, interceptors...)
+	chain = append(chain, ValidationUnaryInterceptor(validators))
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(chain...),
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.MaxSendMsgSize),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: cfg.MaxConnectionIdle,
+			Time:              cfg.KeepaliveTime,
+			Timeout:           cfg.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+	}
+	if cfg.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(cfg.TLS)))
+	}
+
+	server := grpc.NewServer(opts...)
+
+	return &Server{
+		cfg:        cfg,
+		server:     server,
+		validators: validators,
+	}
+}
+
+// GRPC exposes the underlying server for services that are not modules.
+func (s *Server) GRPC() *grpc.Server {
+	return s.server
+}
+
+func (s *Server) RegisterModules(modules ...Module) {
+	for _, m := range modules {
+		m.Register(s.server)
+		if vm, ok := m.(ValidatingModule); ok {
+			vm.RegisterValidators(s.validators)
+		}
+		log.Printf("📦 Registered module %s", m.Name())
+	}
+}
+
+// OnDrain registers fn to run as soon as shutdown begins, before
+// in-flight RPCs are drained (e.g. to fail health checks).
+func (s *Server) OnDrain(fn func()) {
+	s.onDrain = append(s.onDrain, fn)
+}
+
+// OnShutdown registers fn to run after the server has stopped. Hooks run
+// in reverse registration order, like defers.
+func (s *Server) OnShutdown(fn func(ctx context.Context) error) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Run listens on cfg.Addr and serves until SIGINT or SIGTERM.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	lis, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	if s.cfg.TLS != nil {
+		log.Printf("🚀 gRPC server started on %s (TLS)", s.cfg.Addr)
+	} else {
+		log.Printf("🚀 gRPC server started on %s", s.cfg.Addr)
+	}
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves on lis until ctx is done, then shuts down gracefully.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.server.Serve(lis)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return errors.Join(err, s.runShutdownHooks())
+	case <-ctx.Done():
+	}
+
+	log.Println("🛑 Shutdown signal received, draining connections...")
+	for _, fn := range s.onDrain {
+		fn()
+	}
+	s.gracefulStop()
+
+	err := <-serveErr
+	if errors.Is(err, grpc.ErrServerStopped) {
+		err = nil
+	}
+
+	if hookErr := s.runShutdownHooks(); err == nil {
+		err = hookErr
+	}
+
+	log.Println("👋 gRPC server stopped")
+	return err
+}
+
+// gracefulStop waits for in-flight RPCs up to cfg.ShutdownTimeout, then
+// closes any remaining connections.
+func (s *Server) gracefulStop() {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(s.cfg.ShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		log.Println("⚠️ Graceful shutdown timed out, forcing stop")
+		s.server.Stop()
+		<-done
+	}
+}
+
+func (s *Server) runShutdownHooks() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(s.onShutdown) - 1; i >= 0; i-- {
+		if err := s.onShutdown[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.onShutdown = nil
+
+	return errors.Join(errs...)
+}
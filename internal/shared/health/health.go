@@ -0,0 +1,182 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"admin-portal/internal/shared/config"
+)
+
+// Service names understood by the health service. The empty name reports
+// the overall status, which follows readiness.
+const (
+	LivenessService  = "liveness"
+	ReadinessService = "readiness"
+)
+
+type Config struct {
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+func LoadConfig() Config {
+	return Config{
+		Interval: config.Duration("HEALTH_CHECK_INTERVAL", 10*time.Second),
+		Timeout:  config.Duration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+	}
+}
+
+// Checker drives the standard grpc.health.v1 service.
+//
+// Liveness is SERVING for as long as the process runs. Readiness is
+// SERVING only while the database answers pings and every expected
+// migration has been applied.
+type Checker struct {
+	cfg        Config
+	db         *sql.DB
+	migrations []string
+	server     *grpchealth.Server
+
+	mu       sync.Mutex
+	lastErr  error
+	checked  bool
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func NewChecker(cfg Config, db *sql.DB, migrations []string) *Checker {
+	c := &Checker{
+		cfg:        cfg,
+		db:         db,
+		migrations: migrations,
+		server:     grpchealth.NewServer(),
+		stop:       make(chan struct{}),
+	}
+
+	c.server.SetServingStatus(LivenessService, healthpb.HealthCheckResponse_SERVING)
+	c.setReady(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return c
+}
+
+func (c *Checker) Name() string {
+	return "health"
+}
+
+func (c *Checker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, c.server)
+}
+
+// Start runs a check immediately and then every cfg.Interval until ctx is
+// done or Shutdown is called.
+func (c *Checker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			c.runCheck(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown marks every service NOT_SERVING and stops further checks.
+// Call it before draining connections so load balancers stop routing.
+func (c *Checker) Shutdown() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		c.server.Shutdown()
+	})
+}
+
+func (c *Checker) runCheck(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	err := c.check(ctx)
+
+	c.mu.Lock()
+	changed := !c.checked || (err == nil) != (c.lastErr == nil)
+	c.lastErr = err
+	c.checked = true
+	c.mu.Unlock()
+
+	if err != nil {
+		if changed {
+			log.Printf("⚠️ Readiness check failed: %v", err)
+		}
+		c.setReady(healthpb.HealthCheckResponse_NOT_SERVING)
+		return
+	}
+
+	if changed {
+		log.Println("✅ Readiness check passed")
+	}
+	c.setReady(healthpb.HealthCheckResponse_SERVING)
+}
+
+func (c *Checker) check(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database ping: %w", err)
+	}
+
+	pending, err := c.pendingMigrations(ctx)
+	if err != nil {
+		return fmt.Errorf("read schema_migrations: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migration(s), first is %s", len(pending), pending[0])
+	}
+
+	return nil
+}
+
+func (c *Checker) pendingMigrations(ctx context.Context) ([]string, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, v := range c.migrations {
+		if !applied[v] {
+			pending = append(pending, v)
+		}
+	}
+
+	return pending, nil
+}
+
+func (c *Checker) setReady(status healthpb.HealthCheckResponse_ServingStatus) {
+	c.server.SetServingStatus("", status)
+	c.server.SetServingStatus(ReadinessService, status)
+}
@@ -0,0 +1,270 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BER classes.
+const (
+	ClassUniversal   byte = 0x00
+	ClassApplication byte = 0x40
+	ClassContext     byte = 0x80
+)
+
+// Universal tags LDAP uses.
+const (
+	TagBoolean     = 1
+	TagInteger     = 2
+	TagOctetString = 4
+	TagNull        = 5
+	TagEnumerated  = 10
+	TagSequence    = 16
+	TagSet         = 17
+)
+
+// maxPacketSize bounds what ReadPacket accepts from a peer.
+const maxPacketSize = 16 << 20
+
+var errMalformed = errors.New("ldap: malformed BER")
+
+// Packet is a BER element: a primitive with a Value, or a constructed
+// element with Children. LDAP only uses the definite length form (RFC
+// 4511 section 5.1) and tags below 31, which is all this supports.
+type Packet struct {
+	Class       byte
+	Constructed bool
+	Tag         int
+	Value       []byte
+	Children    []*Packet
+}
+
+func NewSequence(children ...*Packet) *Packet {
+	return &Packet{Class: ClassUniversal, Constructed: true, Tag: TagSequence, Children: children}
+}
+
+func NewSet(children ...*Packet) *Packet {
+	return &Packet{Class: ClassUniversal, Constructed: true, Tag: TagSet, Children: children}
+}
+
+// NewConstructed is a constructed element of an application or context
+// class, e.g. an LDAP operation.
+func NewConstructed(class byte, tag int, children ...*Packet) *Packet {
+	return &Packet{Class: class, Constructed: true, Tag: tag, Children: children}
+}
+
+func NewOctetString(s string) *Packet {
+	return NewPrimitive(ClassUniversal, TagOctetString, []byte(s))
+}
+
+func NewPrimitive(class byte, tag int, value []byte) *Packet {
+	return &Packet{Class: class, Tag: tag, Value: value}
+}
+
+func NewInteger(v int64) *Packet {
+	return NewPrimitive(ClassUniversal, TagInteger, encodeInt(v))
+}
+
+func NewEnumerated(v int64) *Packet {
+	return NewPrimitive(ClassUniversal, TagEnumerated, encodeInt(v))
+}
+
+func NewBoolean(v bool) *Packet {
+	b := byte(0x00)
+	if v {
+		b = 0xff
+	}
+	return NewPrimitive(ClassUniversal, TagBoolean, []byte{b})
+}
+
+// Is reports whether p has the given class and tag.
+func (p *Packet) Is(class byte, tag int) bool {
+	return p.Class == class && p.Tag == tag
+}
+
+// Int decodes an INTEGER or ENUMERATED value.
+func (p *Packet) Int() (int64, error) {
+	if p.Constructed || len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, errMalformed
+	}
+	v := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// Bool decodes a BOOLEAN; any non-zero byte is true.
+func (p *Packet) Bool() (bool, error) {
+	if p.Constructed || len(p.Value) != 1 {
+		return false, errMalformed
+	}
+	return p.Value[0] != 0, nil
+}
+
+// Child returns the i-th child, or an error if there is none.
+func (p *Packet) Child(i int) (*Packet, error) {
+	if !p.Constructed || i >= len(p.Children) {
+		return nil, errMalformed
+	}
+	return p.Children[i], nil
+}
+
+// Bytes encodes p.
+func (p *Packet) Bytes() []byte {
+	content := p.Value
+	if p.Constructed {
+		content = nil
+		for _, c := range p.Children {
+			content = append(content, c.Bytes()...)
+		}
+	}
+
+	id := p.Class | byte(p.Tag)
+	if p.Constructed {
+		id |= 0x20
+	}
+
+	out := append([]byte{id}, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+// ReadPacket reads one element from r.
+func ReadPacket(r *bufio.Reader) (*Packet, error) {
+	id, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, n)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+
+	return decode(id, content)
+}
+
+// ParsePacket decodes a single element that fills b.
+func ParsePacket(b []byte) (*Packet, error) {
+	p, rest, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errMalformed
+	}
+	return p, nil
+}
+
+func parse(b []byte) (*Packet, []byte, error) {
+	if len(b) < 2 {
+		return nil, nil, errMalformed
+	}
+	id := b[0]
+
+	n, size := int(b[1]), 1
+	if n&0x80 != 0 {
+		size = 1 + n&0x7f
+		if size == 1 || size > 5 || len(b) < 1+size {
+			return nil, nil, errMalformed
+		}
+		n = 0
+		for _, c := range b[2 : 1+size] {
+			n = n<<8 | int(c)
+		}
+	}
+
+	b = b[1+size:]
+	if n < 0 || n > len(b) {
+		return nil, nil, errMalformed
+	}
+
+	p, err := decode(id, b[:n])
+	return p, b[n:], err
+}
+
+func decode(id byte, content []byte) (*Packet, error) {
+	if id&0x1f == 0x1f {
+		return nil, fmt.Errorf("ldap: high tag numbers are not supported")
+	}
+
+	p := &Packet{
+		Class:       id & 0xc0,
+		Constructed: id&0x20 != 0,
+		Tag:         int(id & 0x1f),
+	}
+	if !p.Constructed {
+		p.Value = content
+		return p, nil
+	}
+
+	for len(content) > 0 {
+		child, rest, err := parse(content)
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, child)
+		content = rest
+	}
+	return p, nil
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b&0x80 == 0 {
+		return int(b), nil
+	}
+
+	size := int(b & 0x7f)
+	if size == 0 {
+		return 0, fmt.Errorf("ldap: indefinite lengths are not allowed")
+	}
+	if size > 4 {
+		return 0, errMalformed
+	}
+
+	n := 0
+	for range size {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n = n<<8 | int(c)
+	}
+	if n > maxPacketSize {
+		return 0, fmt.Errorf("ldap: message of %d bytes is too large", n)
+	}
+	return n, nil
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+
+	var b []byte
+	for v := n; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// encodeInt is the minimal two's complement encoding of v.
+func encodeInt(v int64) []byte {
+	b := []byte{byte(v)}
+	for (v > 0x7f || v < -0x80) && len(b) < 8 {
+		v >>= 8
+		b = append([]byte{byte(v)}, b...)
+	}
+	return b
+}
@@ -0,0 +1,419 @@
+// Package ldap is a minimal LDAPv3 client (RFC 4511): simple bind,
+// search, and StartTLS, which is what verifying a password against a
+// directory needs. Package ldaptest is an in-process directory for tests.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Application tags of the protocol operations.
+const (
+	TagBindRequest           = 0
+	TagBindResponse          = 1
+	TagUnbindRequest         = 2
+	TagSearchRequest         = 3
+	TagSearchResultEntry     = 4
+	TagSearchResultDone      = 5
+	TagSearchResultReference = 19
+	TagExtendedRequest       = 23
+	TagExtendedResponse      = 24
+)
+
+// Result codes this package and its callers care about.
+const (
+	ResultSuccess                  = 0
+	ResultProtocolError            = 2
+	ResultSizeLimitExceeded        = 4
+	ResultNoSuchObject             = 32
+	ResultInvalidCredentials       = 49
+	ResultInsufficientAccessRights = 50
+	ResultUnwillingToPerform       = 53
+)
+
+// Search scopes.
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Error is a non-success LDAPResult.
+type Error struct {
+	ResultCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+	}
+	return fmt.Sprintf("ldap: result code %d", e.ResultCode)
+}
+
+// IsResult reports whether err is an Error with the given result code.
+func IsResult(err error, code int) bool {
+	var ldapErr *Error
+	return errors.As(err, &ldapErr) && ldapErr.ResultCode == code
+}
+
+// Config tunes Dial.
+type Config struct {
+	// TLS is used by ldaps:// URLs and StartTLS. Its ServerName defaults
+	// to the URL's host.
+	TLS *tls.Config
+	// StartTLS upgrades an ldap:// connection before anything is sent.
+	StartTLS bool
+	// Timeout bounds dialing and each operation. Zero means no limit
+	// beyond the context's.
+	Timeout time.Duration
+}
+
+// Conn is a connection to a directory server. Operations run one at a
+// time.
+type Conn struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+	nextID  int64
+}
+
+// Dial connects to an ldap:// or ldaps:// URL.
+func Dial(ctx context.Context, rawURL string, cfg Config) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+
+	var port string
+	switch u.Scheme {
+	case "ldap":
+		port = "389"
+	case "ldaps":
+		port = "636"
+	default:
+		return nil, fmt.Errorf("ldap: unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+
+	tlsCfg := &tls.Config{}
+	if cfg.TLS != nil {
+		tlsCfg = cfg.TLS.Clone()
+	}
+	if tlsCfg.ServerName == "" {
+		tlsCfg.ServerName = u.Hostname()
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	var nc net.Conn
+	if u.Scheme == "ldaps" {
+		nc, err = (&tls.Dialer{Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		nc, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+
+	c := &Conn{conn: nc, r: bufio.NewReader(nc), timeout: cfg.Timeout}
+	if cfg.StartTLS && u.Scheme == "ldap" {
+		if err := c.startTLS(ctx, tlsCfg); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Close sends an unbind and closes the connection.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	c.send(NewPrimitive(ClassApplication, TagUnbindRequest, nil))
+	return c.conn.Close()
+}
+
+// Bind authenticates as dn with a simple bind. An empty password is
+// refused here: servers treat it as an unauthenticated bind (RFC 4513
+// section 5.1.2) that succeeds for any DN.
+func (c *Conn) Bind(ctx context.Context, dn, password string) error {
+	if password == "" {
+		return &Error{ResultCode: ResultInvalidCredentials, Message: "empty password"}
+	}
+
+	op := NewConstructed(ClassApplication, TagBindRequest,
+		NewInteger(3),
+		NewOctetString(dn),
+		NewPrimitive(ClassContext, 0, []byte(password)),
+	)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, err := c.roundTrip(ctx, op, TagBindResponse)
+	if err != nil {
+		return err
+	}
+	return resultError(resp)
+}
+
+// SearchRequest selects entries under BaseDN.
+type SearchRequest struct {
+	BaseDN     string
+	Scope      int
+	Filter     Filter
+	Attributes []string
+	// SizeLimit caps the entries returned; past it the search fails with
+	// ResultSizeLimitExceeded. Zero means the server's limit.
+	SizeLimit int
+}
+
+// Entry is a search result.
+type Entry struct {
+	DN         string
+	Attributes []Attribute
+}
+
+type Attribute struct {
+	Name   string
+	Values []string
+}
+
+// Values returns the values of an attribute; names compare
+// case-insensitively.
+func (e *Entry) Values(name string) []string {
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, name) {
+			return a.Values
+		}
+	}
+	return nil
+}
+
+// Value returns the first value of an attribute, or "".
+func (e *Entry) Value(name string) string {
+	if v := e.Values(name); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Search returns the matching entries. Referrals to other servers are
+// not followed. If the size limit is hit, the entries read so far are
+// returned along with the error.
+func (c *Conn) Search(ctx context.Context, req SearchRequest) ([]*Entry, error) {
+	attrs := NewSequence()
+	for _, a := range req.Attributes {
+		attrs.Children = append(attrs.Children, NewOctetString(a))
+	}
+
+	op := NewConstructed(ClassApplication, TagSearchRequest,
+		NewOctetString(req.BaseDN),
+		NewEnumerated(int64(req.Scope)),
+		NewEnumerated(0), // neverDerefAliases
+		NewInteger(int64(req.SizeLimit)),
+		NewInteger(0),
+		NewBoolean(false),
+		req.Filter.Packet(),
+		attrs,
+	)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, stop, err := c.begin(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	var entries []*Entry
+	for {
+		resp, err := c.receive(id)
+		if err != nil {
+			return nil, c.wrap(ctx, err)
+		}
+
+		switch {
+		case resp.Is(ClassApplication, TagSearchResultEntry):
+			e, err := decodeEntry(resp)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		case resp.Is(ClassApplication, TagSearchResultReference):
+			continue
+		case resp.Is(ClassApplication, TagSearchResultDone):
+			return entries, resultError(resp)
+		default:
+			return nil, fmt.Errorf("ldap: unexpected response tag %d", resp.Tag)
+		}
+	}
+}
+
+func decodeEntry(p *Packet) (*Entry, error) {
+	if len(p.Children) != 2 {
+		return nil, errMalformed
+	}
+
+	e := &Entry{DN: string(p.Children[0].Value)}
+	for _, a := range p.Children[1].Children {
+		if len(a.Children) != 2 {
+			return nil, errMalformed
+		}
+		attr := Attribute{Name: string(a.Children[0].Value)}
+		for _, v := range a.Children[1].Children {
+			attr.Values = append(attr.Values, string(v.Value))
+		}
+		e.Attributes = append(e.Attributes, attr)
+	}
+	return e, nil
+}
+
+func (c *Conn) startTLS(ctx context.Context, cfg *tls.Config) error {
+	op := NewConstructed(ClassApplication, TagExtendedRequest,
+		NewPrimitive(ClassContext, 0, []byte(startTLSOID)),
+	)
+
+	resp, err := c.roundTrip(ctx, op, TagExtendedResponse)
+	if err != nil {
+		return err
+	}
+	if err := resultError(resp); err != nil {
+		return fmt.Errorf("ldap: StartTLS: %w", err)
+	}
+
+	tc := tls.Client(c.conn, cfg)
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("ldap: StartTLS: %w", err)
+	}
+	c.conn = tc
+	c.r = bufio.NewReader(tc)
+	return nil
+}
+
+//-------------------- Messages --------------------//
+
+// roundTrip sends op and reads its single response, which must have the
+// given tag. The caller holds c.mu.
+func (c *Conn) roundTrip(ctx context.Context, op *Packet, tag int) (*Packet, error) {
+	id, stop, err := c.begin(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	resp, err := c.receive(id)
+	if err != nil {
+		return nil, c.wrap(ctx, err)
+	}
+	if !resp.Is(ClassApplication, tag) {
+		return nil, fmt.Errorf("ldap: unexpected response tag %d", resp.Tag)
+	}
+	return resp, nil
+}
+
+// begin applies the operation deadline and sends op. stop undoes the
+// deadline and must be called once the operation is over.
+func (c *Conn) begin(ctx context.Context, op *Packet) (int64, func(), error) {
+	deadline, ok := ctx.Deadline()
+	if c.timeout > 0 {
+		if d := time.Now().Add(c.timeout); !ok || d.Before(deadline) {
+			deadline, ok = d, true
+		}
+	}
+	if ok {
+		c.conn.SetDeadline(deadline)
+	}
+
+	// Cancelling the context interrupts a blocked read or write.
+	cancel := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+	stop := func() {
+		cancel()
+		c.conn.SetDeadline(time.Time{})
+	}
+
+	c.nextID++
+	id := c.nextID
+	if err := c.send(NewSequence(NewInteger(id), op)); err != nil {
+		stop()
+		return 0, nil, c.wrap(ctx, err)
+	}
+	return id, stop, nil
+}
+
+func (c *Conn) send(msg *Packet) error {
+	_, err := c.conn.Write(msg.Bytes())
+	return err
+}
+
+// receive reads the next message for id and returns its protocolOp.
+func (c *Conn) receive(id int64) (*Packet, error) {
+	for {
+		msg, err := ReadPacket(c.r)
+		if err != nil {
+			return nil, err
+		}
+		if !msg.Is(ClassUniversal, TagSequence) || len(msg.Children) < 2 {
+			return nil, errMalformed
+		}
+
+		msgID, err := msg.Children[0].Int()
+		if err != nil {
+			return nil, err
+		}
+		// Message ID 0 is an unsolicited notification, e.g. a notice of
+		// disconnection, which ends the session anyway.
+		if msgID == 0 {
+			return nil, errors.New("ldap: server closed the session")
+		}
+		if msgID == id {
+			return msg.Children[1], nil
+		}
+	}
+}
+
+// wrap reports a context error in place of the I/O error it caused.
+func (c *Conn) wrap(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("ldap: %w", ctxErr)
+	}
+	return fmt.Errorf("ldap: %w", err)
+}
+
+// resultError decodes the LDAPResult at the start of a response.
+func resultError(resp *Packet) error {
+	if len(resp.Children) < 3 {
+		return errMalformed
+	}
+	code, err := resp.Children[0].Int()
+	if err != nil {
+		return err
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{ResultCode: int(code), Message: string(resp.Children[2].Value)}
+}
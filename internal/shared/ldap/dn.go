@@ -0,0 +1,14 @@
+package ldap
+
+import "strings"
+
+// NormalizeDN lowercases a DN and drops the spaces around its RDNs, so
+// DNs written by hand compare equal to the ones a server returns. It
+// does not unescape values or reorder multi-valued RDNs.
+func NormalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,268 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// FilterOp is the kind of a Filter.
+type FilterOp int
+
+const (
+	FilterAnd FilterOp = iota
+	FilterOr
+	FilterNot
+	FilterEqual
+	FilterPresent
+)
+
+// Context tags of the Filter CHOICE (RFC 4511 section 4.5.1).
+const (
+	filterTagAnd     = 0
+	filterTagOr      = 1
+	filterTagNot     = 2
+	filterTagEqual   = 3
+	filterTagPresent = 7
+)
+
+// Filter is a search filter. Only the forms a login needs are supported:
+// and, or, not, equality and presence.
+type Filter struct {
+	Op       FilterOp
+	Attr     string
+	Value    string
+	Children []Filter
+}
+
+// EscapeFilter escapes a value for use in a filter string (RFC 4515
+// section 3), so user input cannot change the filter's structure.
+func EscapeFilter(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// ParseFilter parses the string form of a filter, e.g.
+// "(&(objectClass=person)(uid=alice))".
+func ParseFilter(s string) (Filter, error) {
+	p := &filterParser{s: strings.TrimSpace(s)}
+	f, err := p.filter()
+	if err != nil {
+		return Filter{}, err
+	}
+	if p.pos != len(p.s) {
+		return Filter{}, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	s   string
+	pos int
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("ldap: filter %q at %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) filter() (Filter, error) {
+	if p.pos >= len(p.s) || p.s[p.pos] != '(' {
+		return Filter{}, p.errorf("expected (")
+	}
+	p.pos++
+
+	var f Filter
+	var err error
+	switch {
+	case p.pos < len(p.s) && p.s[p.pos] == '&':
+		p.pos++
+		f, err = p.list(FilterAnd)
+	case p.pos < len(p.s) && p.s[p.pos] == '|':
+		p.pos++
+		f, err = p.list(FilterOr)
+	case p.pos < len(p.s) && p.s[p.pos] == '!':
+		p.pos++
+		var child Filter
+		child, err = p.filter()
+		f = Filter{Op: FilterNot, Children: []Filter{child}}
+	default:
+		f, err = p.item()
+	}
+	if err != nil {
+		return Filter{}, err
+	}
+
+	if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+		return Filter{}, p.errorf("expected )")
+	}
+	p.pos++
+	return f, nil
+}
+
+func (p *filterParser) list(op FilterOp) (Filter, error) {
+	f := Filter{Op: op}
+	for p.pos < len(p.s) && p.s[p.pos] == '(' {
+		child, err := p.filter()
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Children = append(f.Children, child)
+	}
+	if len(f.Children) == 0 {
+		return Filter{}, p.errorf("empty filter list")
+	}
+	return f, nil
+}
+
+func (p *filterParser) item() (Filter, error) {
+	end := strings.IndexByte(p.s[p.pos:], ')')
+	if end < 0 {
+		return Filter{}, p.errorf("expected )")
+	}
+	item := p.s[p.pos : p.pos+end]
+
+	attr, value, ok := strings.Cut(item, "=")
+	if !ok || attr == "" {
+		return Filter{}, p.errorf("expected attr=value")
+	}
+	if strings.ContainsAny(attr, "<>~:") {
+		return Filter{}, p.errorf("only equality and presence filters are supported")
+	}
+
+	p.pos += end
+	if value == "*" {
+		return Filter{Op: FilterPresent, Attr: attr}, nil
+	}
+	if strings.Contains(value, "*") {
+		return Filter{}, p.errorf("substring filters are not supported")
+	}
+
+	v, err := unescapeFilter(value)
+	if err != nil {
+		return Filter{}, p.errorf("%v", err)
+	}
+	return Filter{Op: FilterEqual, Attr: attr, Value: v}, nil
+}
+
+func unescapeFilter(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("truncated escape")
+		}
+		c, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("bad escape \\%s", s[i+1:i+3])
+		}
+		b.Write(c)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// Packet encodes the filter.
+func (f Filter) Packet() *Packet {
+	switch f.Op {
+	case FilterAnd, FilterOr:
+		tag := filterTagAnd
+		if f.Op == FilterOr {
+			tag = filterTagOr
+		}
+		p := NewConstructed(ClassContext, tag)
+		for _, c := range f.Children {
+			p.Children = append(p.Children, c.Packet())
+		}
+		return p
+	case FilterNot:
+		return NewConstructed(ClassContext, filterTagNot, f.Children[0].Packet())
+	case FilterPresent:
+		return NewPrimitive(ClassContext, filterTagPresent, []byte(f.Attr))
+	default:
+		return NewConstructed(ClassContext, filterTagEqual, NewOctetString(f.Attr), NewOctetString(f.Value))
+	}
+}
+
+// DecodeFilter is the inverse of Filter.Packet.
+func DecodeFilter(p *Packet) (Filter, error) {
+	if p.Class != ClassContext {
+		return Filter{}, errMalformed
+	}
+
+	switch p.Tag {
+	case filterTagAnd, filterTagOr, filterTagNot:
+		op := map[int]FilterOp{filterTagAnd: FilterAnd, filterTagOr: FilterOr, filterTagNot: FilterNot}[p.Tag]
+		if !p.Constructed || len(p.Children) == 0 || (op == FilterNot && len(p.Children) != 1) {
+			return Filter{}, errMalformed
+		}
+		f := Filter{Op: op}
+		for _, c := range p.Children {
+			child, err := DecodeFilter(c)
+			if err != nil {
+				return Filter{}, err
+			}
+			f.Children = append(f.Children, child)
+		}
+		return f, nil
+	case filterTagEqual:
+		if !p.Constructed || len(p.Children) != 2 {
+			return Filter{}, errMalformed
+		}
+		return Filter{Op: FilterEqual, Attr: string(p.Children[0].Value), Value: string(p.Children[1].Value)}, nil
+	case filterTagPresent:
+		if p.Constructed {
+			return Filter{}, errMalformed
+		}
+		return Filter{Op: FilterPresent, Attr: string(p.Value)}, nil
+	default:
+		return Filter{}, fmt.Errorf("ldap: unsupported filter type %d", p.Tag)
+	}
+}
+
+// Match evaluates the filter against an entry. Attribute names and
+// values compare case-insensitively, as caseIgnoreMatch and
+// distinguishedNameMatch mostly do.
+func (f Filter) Match(e *Entry) bool {
+	switch f.Op {
+	case FilterAnd:
+		for _, c := range f.Children {
+			if !c.Match(e) {
+				return false
+			}
+		}
+		return true
+	case FilterOr:
+		for _, c := range f.Children {
+			if c.Match(e) {
+				return true
+			}
+		}
+		return false
+	case FilterNot:
+		return !f.Children[0].Match(e)
+	case FilterPresent:
+		return strings.EqualFold(f.Attr, "objectClass") || len(e.Values(f.Attr)) > 0
+	default:
+		for _, v := range e.Values(f.Attr) {
+			if strings.EqualFold(v, f.Value) {
+				return true
+			}
+		}
+		return false
+	}
+}
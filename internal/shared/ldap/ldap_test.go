@@ -0,0 +1,265 @@
+package ldap_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"admin-portal/internal/shared/ldap"
+	"admin-portal/internal/shared/ldap/ldaptest"
+)
+
+const (
+	aliceDN = "uid=alice,ou=people,dc=example,dc=com"
+	bobDN   = "uid=bob,ou=people,dc=example,dc=com"
+)
+
+func TestEscapeFilter(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"alice", "alice"},
+		{"*", `\2a`},
+		{"a(b)c", `a\28b\29c`},
+		{`back\slash`, `back\5cslash`},
+		{"nul\x00", `nul\00`},
+		{"*)(uid=*", `\2a\29\28uid=\2a`},
+	}
+	for _, tc := range cases {
+		if got := ldap.EscapeFilter(tc.in); got != tc.want {
+			t.Errorf("EscapeFilter(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestEscapedInputStaysAValue(t *testing.T) {
+	alice := &ldap.Entry{DN: aliceDN, Attributes: []ldap.Attribute{
+		{Name: "objectClass", Values: []string{"person"}},
+		{Name: "uid", Values: []string{"alice"}},
+	}}
+
+	for _, input := range []string{"*", "*)(uid=*", "alice)(|(uid=*", `alice\`} {
+		f, err := ldap.ParseFilter("(&(objectClass=person)(uid=" + ldap.EscapeFilter(input) + "))")
+		if err != nil {
+			t.Errorf("%q: %v", input, err)
+			continue
+		}
+		if len(f.Children) != 2 || f.Children[1].Op != ldap.FilterEqual || f.Children[1].Value != input {
+			t.Errorf("%q: parsed as %+v", input, f)
+		}
+		if f.Match(alice) {
+			t.Errorf("%q matched alice", input)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := ldap.ParseFilter("(&(objectClass=person)(|(uid=alice)(mail=*))(!(cn=a\\2ab)))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ldap.Filter{Op: ldap.FilterAnd, Children: []ldap.Filter{
+		{Op: ldap.FilterEqual, Attr: "objectClass", Value: "person"},
+		{Op: ldap.FilterOr, Children: []ldap.Filter{
+			{Op: ldap.FilterEqual, Attr: "uid", Value: "alice"},
+			{Op: ldap.FilterPresent, Attr: "mail"},
+		}},
+		{Op: ldap.FilterNot, Children: []ldap.Filter{
+			{Op: ldap.FilterEqual, Attr: "cn", Value: "a*b"},
+		}},
+	}}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("parsed %+v, want %+v", f, want)
+	}
+
+	for _, s := range []string{"", "uid=alice", "(uid=alice", "(&)", "(uid=a*)", "(uid>=a)", `(uid=a\zz)`, "(uid=a)(cn=b)"} {
+		if _, err := ldap.ParseFilter(s); err == nil {
+			t.Errorf("%q: accepted", s)
+		}
+	}
+}
+
+func TestFilterRoundTrip(t *testing.T) {
+	f, err := ldap.ParseFilter("(&(objectClass=person)(|(uid=al\\29ice)(mail=*))(!(cn=x)))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ldap.ParsePacket(f.Packet().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ldap.DecodeFilter(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, f) {
+		t.Errorf("decoded %+v, want %+v", got, f)
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	long := make([]byte, 300)
+	msg := ldap.NewSequence(
+		ldap.NewInteger(-129),
+		ldap.NewInteger(1<<40),
+		ldap.NewEnumerated(49),
+		ldap.NewBoolean(true),
+		ldap.NewOctetString(string(long)),
+		ldap.NewConstructed(ldap.ClassApplication, ldap.TagBindRequest, ldap.NewSet()),
+	)
+
+	p, err := ldap.ParsePacket(msg.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int64{-129, 1 << 40, 49} {
+		if v, err := p.Children[i].Int(); err != nil || v != want {
+			t.Errorf("child %d = %d, %v; want %d", i, v, err, want)
+		}
+	}
+	if b, err := p.Children[3].Bool(); err != nil || !b {
+		t.Errorf("boolean = %v, %v", b, err)
+	}
+	if len(p.Children[4].Value) != len(long) {
+		t.Errorf("octet string has %d bytes, want %d", len(p.Children[4].Value), len(long))
+	}
+	if !p.Children[5].Is(ldap.ClassApplication, ldap.TagBindRequest) || !p.Children[5].Constructed {
+		t.Errorf("application element = %+v", p.Children[5])
+	}
+
+	for _, b := range [][]byte{{0x30}, {0x30, 0x05, 0x02}, {0x30, 0x84, 0xff, 0xff, 0xff, 0xff}} {
+		if _, err := ldap.ParsePacket(b); err == nil {
+			t.Errorf("% x: accepted", b)
+		}
+	}
+}
+
+//-------------------- Client --------------------//
+
+func newDirectory(t *testing.T) *ldaptest.Server {
+	t.Helper()
+
+	dir, err := ldaptest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dir.Close() })
+
+	dir.AddEntry("ou=people,dc=example,dc=com", "")
+	dir.AddEntry(aliceDN, "alice-secret",
+		ldap.Attribute{Name: "objectClass", Values: []string{"person"}},
+		ldap.Attribute{Name: "uid", Values: []string{"alice"}},
+		ldap.Attribute{Name: "mail", Values: []string{"alice@example.com"}})
+	dir.AddEntry(bobDN, "bob-secret",
+		ldap.Attribute{Name: "objectClass", Values: []string{"person"}},
+		ldap.Attribute{Name: "uid", Values: []string{"bob"}})
+	return dir
+}
+
+func dial(t *testing.T, dir *ldaptest.Server) *ldap.Conn {
+	t.Helper()
+
+	conn, err := ldap.Dial(context.Background(), dir.URL(), ldap.Config{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestBind(t *testing.T) {
+	ctx := context.Background()
+	conn := dial(t, newDirectory(t))
+
+	if err := conn.Bind(ctx, aliceDN, "alice-secret"); err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+
+	for _, tc := range []struct{ dn, password string }{
+		{aliceDN, "wrong"},
+		{aliceDN, "bob-secret"},
+		{"uid=nobody,ou=people,dc=example,dc=com", "alice-secret"},
+		{"ou=people,dc=example,dc=com", "x"},
+	} {
+		err := conn.Bind(ctx, tc.dn, tc.password)
+		if !ldap.IsResult(err, ldap.ResultInvalidCredentials) {
+			t.Errorf("bind %s / %s: err = %v, want invalid credentials", tc.dn, tc.password, err)
+		}
+	}
+
+	// A failed bind does not break the connection.
+	if err := conn.Bind(ctx, bobDN, "bob-secret"); err != nil {
+		t.Errorf("bind after failure: %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	conn := dial(t, newDirectory(t))
+
+	filter := func(s string) ldap.Filter {
+		f, err := ldap.ParseFilter(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	if _, err := conn.Search(ctx, ldap.SearchRequest{BaseDN: "dc=example,dc=com", Filter: filter("(uid=alice)")}); !ldap.IsResult(err, ldap.ResultInsufficientAccessRights) {
+		t.Errorf("anonymous search: err = %v", err)
+	}
+
+	if err := conn.Bind(ctx, bobDN, "bob-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := conn.Search(ctx, ldap.SearchRequest{
+		BaseDN:     "dc=example,dc=com",
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     filter("(&(objectClass=person)(uid=ALICE))"),
+		Attributes: []string{"mail"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].DN != aliceDN {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if got := entries[0].Value("MAIL"); got != "alice@example.com" {
+		t.Errorf("mail = %q", got)
+	}
+	if got := entries[0].Values("uid"); got != nil {
+		t.Errorf("uid was returned though not requested: %v", got)
+	}
+
+	entries, err = conn.Search(ctx, ldap.SearchRequest{
+		BaseDN:    "dc=example,dc=com",
+		Scope:     ldap.ScopeWholeSubtree,
+		Filter:    filter("(objectClass=person)"),
+		SizeLimit: 1,
+	})
+	if !ldap.IsResult(err, ldap.ResultSizeLimitExceeded) || len(entries) != 1 {
+		t.Errorf("size limit: %d entries, err = %v", len(entries), err)
+	}
+
+	_, err = conn.Search(ctx, ldap.SearchRequest{
+		BaseDN: "ou=groups,dc=example,dc=com",
+		Scope:  ldap.ScopeWholeSubtree,
+		Filter: filter("(objectClass=*)"),
+	})
+	if !ldap.IsResult(err, ldap.ResultNoSuchObject) {
+		t.Errorf("missing base: err = %v", err)
+	}
+}
+
+func TestDialErrors(t *testing.T) {
+	dir := newDirectory(t)
+	dir.Close()
+
+	if _, err := ldap.Dial(context.Background(), dir.URL(), ldap.Config{Timeout: time.Second}); err == nil {
+		t.Error("dialed a closed server")
+	}
+	if _, err := ldap.Dial(context.Background(), "http://127.0.0.1:389", ldap.Config{}); err == nil {
+		t.Error("dialed an http:// URL")
+	}
+}
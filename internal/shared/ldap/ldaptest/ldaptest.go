@@ -0,0 +1,307 @@
+// Package ldaptest is an in-process directory server. It answers simple
+// binds and searches over a fixed set of entries, which is enough to test
+// a login against it.
+//
+//	dir, _ := ldaptest.NewServer()
+//	defer dir.Close()
+//	dir.AddEntry("uid=alice,ou=people,dc=example,dc=com", "secret",
+//		ldap.Attribute{Name: "uid", Values: []string{"alice"}})
+//
+// StartTLS, referrals, controls and the rarer filter forms are not
+// supported.
+package ldaptest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	"admin-portal/internal/shared/ldap"
+)
+
+// Server is a directory listening on a loopback port.
+type Server struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mu        sync.Mutex
+	entries   []*entry
+	conns     map[net.Conn]struct{}
+	closed    bool
+	anonymous bool
+}
+
+type entry struct {
+	ldap.Entry
+	password string
+}
+
+// NewServer starts a server with no entries. Searches need a bind first.
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{ln: ln, conns: map[net.Conn]struct{}{}}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// URL is the ldap:// URL to dial.
+func (s *Server) URL() string {
+	return "ldap://" + s.ln.Addr().String()
+}
+
+// AddEntry adds an entry. Binding as it needs the password; an entry
+// with no password cannot bind.
+func (s *Server) AddEntry(dn, password string, attrs ...ldap.Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{Entry: ldap.Entry{DN: dn, Attributes: attrs}, password: password})
+}
+
+// AllowAnonymous lets searches run without a bind.
+func (s *Server) AllowAnonymous(allow bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.anonymous = allow
+}
+
+// Close stops the server and drops its connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(c)
+
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+//-------------------- Session --------------------//
+
+type session struct {
+	s     *Server
+	conn  net.Conn
+	bound bool
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer c.Close()
+
+	sess := &session{s: s, conn: c}
+	r := bufio.NewReader(c)
+	for {
+		msg, err := ldap.ReadPacket(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("ldaptest: read: %v", err)
+			}
+			return
+		}
+		if len(msg.Children) < 2 {
+			return
+		}
+		id, err := msg.Children[0].Int()
+		if err != nil {
+			return
+		}
+
+		op := msg.Children[1]
+		switch {
+		case op.Is(ldap.ClassApplication, ldap.TagBindRequest):
+			err = sess.bind(id, op)
+		case op.Is(ldap.ClassApplication, ldap.TagSearchRequest):
+			err = sess.search(id, op)
+		case op.Is(ldap.ClassApplication, ldap.TagUnbindRequest):
+			return
+		case op.Is(ldap.ClassApplication, ldap.TagExtendedRequest):
+			err = sess.reply(id, ldap.TagExtendedResponse, ldap.ResultProtocolError, "extended operations are not supported")
+		default:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (sess *session) bind(id int64, op *ldap.Packet) error {
+	if len(op.Children) < 3 {
+		return sess.reply(id, ldap.TagBindResponse, ldap.ResultProtocolError, "malformed bind")
+	}
+	dn := string(op.Children[1].Value)
+	auth := op.Children[2]
+	if !auth.Is(ldap.ClassContext, 0) {
+		return sess.reply(id, ldap.TagBindResponse, ldap.ResultUnwillingToPerform, "only simple binds are supported")
+	}
+	password := string(auth.Value)
+
+	sess.bound = false
+	if dn == "" && password == "" {
+		return sess.reply(id, ldap.TagBindResponse, ldap.ResultSuccess, "")
+	}
+
+	e := sess.s.find(dn)
+	if e == nil || e.password == "" || e.password != password {
+		return sess.reply(id, ldap.TagBindResponse, ldap.ResultInvalidCredentials, "")
+	}
+	sess.bound = true
+	return sess.reply(id, ldap.TagBindResponse, ldap.ResultSuccess, "")
+}
+
+func (sess *session) search(id int64, op *ldap.Packet) error {
+	if len(op.Children) != 8 {
+		return sess.reply(id, ldap.TagSearchResultDone, ldap.ResultProtocolError, "malformed search")
+	}
+
+	sess.s.mu.Lock()
+	anonymous := sess.s.anonymous
+	sess.s.mu.Unlock()
+	if !sess.bound && !anonymous {
+		return sess.reply(id, ldap.TagSearchResultDone, ldap.ResultInsufficientAccessRights, "bind first")
+	}
+
+	base := string(op.Children[0].Value)
+	scope, _ := op.Children[1].Int()
+	sizeLimit, _ := op.Children[3].Int()
+	filter, err := ldap.DecodeFilter(op.Children[6])
+	if err != nil {
+		return sess.reply(id, ldap.TagSearchResultDone, ldap.ResultProtocolError, err.Error())
+	}
+	var attrs []string
+	for _, a := range op.Children[7].Children {
+		attrs = append(attrs, string(a.Value))
+	}
+
+	entries := sess.s.snapshot()
+	if !slices.ContainsFunc(entries, func(e *entry) bool { return inScope(e.DN, base, ldap.ScopeWholeSubtree) }) {
+		return sess.reply(id, ldap.TagSearchResultDone, ldap.ResultNoSuchObject, "")
+	}
+
+	sent := int64(0)
+	for _, e := range entries {
+		if !inScope(e.DN, base, int(scope)) || !filter.Match(&e.Entry) {
+			continue
+		}
+		if sizeLimit > 0 && sent == sizeLimit {
+			return sess.reply(id, ldap.TagSearchResultDone, ldap.ResultSizeLimitExceeded, "")
+		}
+		if err := sess.send(id, entryPacket(&e.Entry, attrs)); err != nil {
+			return err
+		}
+		sent++
+	}
+	return sess.reply(id, ldap.TagSearchResultDone, ldap.ResultSuccess, "")
+}
+
+func (sess *session) reply(id int64, tag, code int, message string) error {
+	return sess.send(id, ldap.NewConstructed(ldap.ClassApplication, tag,
+		ldap.NewEnumerated(int64(code)),
+		ldap.NewOctetString(""),
+		ldap.NewOctetString(message),
+	))
+}
+
+func (sess *session) send(id int64, op *ldap.Packet) error {
+	_, err := sess.conn.Write(ldap.NewSequence(ldap.NewInteger(id), op).Bytes())
+	return err
+}
+
+func entryPacket(e *ldap.Entry, only []string) *ldap.Packet {
+	attrs := ldap.NewSequence()
+	for _, a := range e.Attributes {
+		if len(only) > 0 && !containsFold(only, a.Name) {
+			continue
+		}
+		vals := ldap.NewSet()
+		for _, v := range a.Values {
+			vals.Children = append(vals.Children, ldap.NewOctetString(v))
+		}
+		attrs.Children = append(attrs.Children, ldap.NewSequence(ldap.NewOctetString(a.Name), vals))
+	}
+	return ldap.NewConstructed(ldap.ClassApplication, ldap.TagSearchResultEntry, ldap.NewOctetString(e.DN), attrs)
+}
+
+//-------------------- Directory --------------------//
+
+func (s *Server) find(dn string) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if ldap.NormalizeDN(e.DN) == ldap.NormalizeDN(dn) {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *Server) snapshot() []*entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*entry(nil), s.entries...)
+}
+
+// inScope compares normalized DNs, which is good enough for the DNs
+// tests write.
+func inScope(dn, base string, scope int) bool {
+	dn, base = ldap.NormalizeDN(dn), ldap.NormalizeDN(base)
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		_, parent, _ := strings.Cut(dn, ",")
+		return parent == base
+	default:
+		return dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Package mail sends plain-text email through SMTP, or drops it into a
+// directory as .eml files for local development and tests.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"admin-portal/internal/shared/config"
+)
+
+// Drivers selectable with MAIL_DRIVER.
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages. Send may be retried by the caller, so a
+// message can arrive more than once.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Config struct {
+	Driver string
+	From   string
+
+	// SMTP. The connection is upgraded with STARTTLS when the server
+	// offers it; credentials are only sent over TLS.
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTimeout  time.Duration
+
+	// Dir receives one .eml file per message for DriverFile.
+	Dir string
+}
+
+func LoadConfig() Config {
+	return Config{
+		Driver:       config.String("MAIL_DRIVER", DriverFile),
+		From:         config.String("MAIL_FROM", "Admin Portal <no-reply@localhost>"),
+		SMTPAddr:     config.String("SMTP_ADDR", ""),
+		SMTPUsername: config.String("SMTP_USERNAME", ""),
+		SMTPPassword: config.String("SMTP_PASSWORD", ""),
+		SMTPTimeout:  config.Duration("SMTP_TIMEOUT", 30*time.Second),
+		Dir:          config.String("MAIL_DIR", "mail"),
+	}
+}
+
+// New builds the mailer selected by cfg.
+func New(cfg Config) (Mailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("MAIL_FROM: %w", err)
+	}
+
+	switch cfg.Driver {
+	case DriverSMTP:
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("SMTP_ADDR is required for the smtp driver")
+		}
+		return NewSMTPMailer(cfg, from), nil
+	case DriverFile:
+		return NewFileMailer(cfg.Dir, from)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// format renders msg as an RFC 5322 message with CRLF line endings.
+func format(from *mail.Address, msg Message) ([]byte, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("recipient: %w", err)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("subject contains a line break")
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+hex.EncodeToString(id)+"@"+domain+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	if !strings.HasSuffix(body, "\n") {
+		b.WriteString("\r\n")
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer sends each message over a new connection to an SMTP relay.
+type SMTPMailer struct {
+	cfg  Config
+	from *mail.Address
+}
+
+func NewSMTPMailer(cfg Config, from *mail.Address) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg, from: from}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := format(m.from, msg)
+	if err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(m.cfg.SMTPAddr)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: m.cfg.SMTPTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.cfg.SMTPAddr)
+	if err != nil {
+		return err
+	}
+
+	// net/smtp has no context support; bound the whole exchange instead.
+	deadline := time.Now().Add(m.cfg.SMTPTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+
+	// PlainAuth refuses to send credentials over plain text, except to
+	// localhost.
+	if m.cfg.SMTPUsername != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// AuthMetrics holds the auth domain counters. It satisfies
+// service.Metrics.
+type AuthMetrics struct {
+	logins        *prometheus.CounterVec
+	registrations *prometheus.CounterVec
+	lockouts      prometheus.Counter
+	refreshes     *prometheus.CounterVec
+	resends       *prometheus.CounterVec
+}
+
+func NewAuthMetrics(reg prometheus.Registerer) *AuthMetrics {
+	m := &AuthMetrics{
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "logins_total",
+			Help:      "Login attempts, by outcome.",
+		}, []string{"outcome"}),
+		registrations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "registrations_total",
+			Help:      "Registered users, by role.",
+		}, []string{"role"}),
+		lockouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "lockouts_total",
+			Help:      "Times repeated failed logins reached the alert threshold.",
+		}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "token_refreshes_total",
+			Help:      "Token refresh attempts, by outcome.",
+		}, []string{"outcome"}),
+		resends: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "activation_resends_total",
+			Help:      "Activation email resend requests, by outcome.",
+		}, []string{"outcome"}),
+	}
+
+	reg.MustRegister(m.logins, m.registrations, m.lockouts, m.refreshes, m.resends)
+	return m
+}
+
+func (m *AuthMetrics) LoginAttempt(outcome string) {
+	m.logins.WithLabelValues(outcome).Inc()
+}
+
+func (m *AuthMetrics) Registered(role string) {
+	m.registrations.WithLabelValues(role).Inc()
+}
+
+func (m *AuthMetrics) LockedOut() {
+	m.lockouts.Inc()
+}
+
+func (m *AuthMetrics) TokenRefreshed(outcome string) {
+	m.refreshes.WithLabelValues(outcome).Inc()
+}
+
+func (m *AuthMetrics) ActivationResent(outcome string) {
+	m.resends.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	sharedgrpc "admin-portal/internal/shared/grpc"
+)
+
+// GRPCMetrics records per-method request counts and latencies.
+type GRPCMetrics struct {
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewGRPCMetrics(reg prometheus.Registerer) *GRPCMetrics {
+	m := &GRPCMetrics{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_server",
+			Name:      "handled_total",
+			Help:      "Total RPCs completed on the server, by method and status code.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_server",
+			Name:      "handling_seconds",
+			Help:      "Latency of RPCs handled by the server.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+
+	reg.MustRegister(m.handled, m.duration)
+	return m
+}
+
+// UnaryInterceptor runs inside the error interceptor, so it resolves
+// application errors to the code the client will eventually see.
+func (m *GRPCMetrics) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		m.handled.WithLabelValues(info.FullMethod, sharedgrpc.Code(err).String()).Inc()
+		m.duration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	}
+}
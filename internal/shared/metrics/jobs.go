@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"admin-portal/internal/shared/scheduler"
+)
+
+// JobMetrics records background job runs. It satisfies
+// scheduler.Metrics.
+type JobMetrics struct {
+	runs        *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+func NewJobMetrics(reg prometheus.Registerer) *JobMetrics {
+	m := &JobMetrics{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "job_runs_total",
+			Help:      "Scheduled job runs, by job and result.",
+		}, []string{"job", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "job_duration_seconds",
+			Help:      "Duration of job runs that were not skipped.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+		}, []string{"job"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "job_last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful run of each job.",
+		}, []string{"job"}),
+	}
+
+	reg.MustRegister(m.runs, m.duration, m.lastSuccess)
+	return m
+}
+
+func (m *JobMetrics) JobRun(job, result string, duration time.Duration) {
+	m.runs.WithLabelValues(job, result).Inc()
+
+	if result == scheduler.ResultSkipped {
+		return
+	}
+
+	m.duration.WithLabelValues(job).Observe(duration.Seconds())
+	if result == scheduler.ResultSuccess {
+		m.lastSuccess.WithLabelValues(job).SetToCurrentTime()
+	}
+}
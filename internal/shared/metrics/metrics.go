@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"admin-portal/internal/shared/config"
+)
+
+const namespace = "admin_portal"
+
+type Config struct {
+	Addr string
+}
+
+func LoadConfig() Config {
+	return Config{
+		Addr: config.String("METRICS_ADDR", ":9090"),
+	}
+}
+
+// NewRegistry returns a registry with the Go runtime and process
+// collectors installed. Tests can use prometheus.NewRegistry() instead.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+// RegisterDBStats exports database/sql pool statistics for db.
+func RegisterDBStats(reg prometheus.Registerer, db *sql.DB, dbName string) {
+	reg.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Server exposes /metrics over HTTP.
+type Server struct {
+	server *http.Server
+}
+
+func NewServer(cfg Config, gatherer prometheus.Gatherer) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+
+	return &Server{
+		server: &http.Server{
+			Addr:    cfg.Addr,
+			Handler: mux,
+		},
+	}
+}
+
+// Start serves in the background until Shutdown is called.
+func (s *Server) Start() {
+	go func() {
+		log.Printf("📈 Metrics server started on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ Metrics server failed: %v", err)
+		}
+	}()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
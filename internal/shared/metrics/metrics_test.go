@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"admin-portal/internal/shared/scheduler"
+)
+
+func TestAuthMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewAuthMetrics(reg)
+
+	m.LoginAttempt("success")
+	m.LoginAttempt("success")
+	m.LoginAttempt("inactive")
+	m.Registered("admin")
+	m.LockedOut()
+	m.TokenRefreshed("invalid")
+	m.ActivationResent("throttled")
+
+	want := `
+# HELP admin_portal_auth_activation_resends_total Activation email resend requests, by outcome.
+# TYPE admin_portal_auth_activation_resends_total counter
+admin_portal_auth_activation_resends_total{outcome="throttled"} 1
+# HELP admin_portal_auth_lockouts_total Times repeated failed logins reached the alert threshold.
+# TYPE admin_portal_auth_lockouts_total counter
+admin_portal_auth_lockouts_total 1
+# HELP admin_portal_auth_logins_total Login attempts, by outcome.
+# TYPE admin_portal_auth_logins_total counter
+admin_portal_auth_logins_total{outcome="inactive"} 1
+admin_portal_auth_logins_total{outcome="success"} 2
+# HELP admin_portal_auth_registrations_total Registered users, by role.
+# TYPE admin_portal_auth_registrations_total counter
+admin_portal_auth_registrations_total{role="admin"} 1
+# HELP admin_portal_auth_token_refreshes_total Token refresh attempts, by outcome.
+# TYPE admin_portal_auth_token_refreshes_total counter
+admin_portal_auth_token_refreshes_total{outcome="invalid"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPCMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewGRPCMetrics(reg)
+	intercept := m.UnaryInterceptor()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/auth.AuthService/Login"}
+	ok := func(context.Context, interface{}) (interface{}, error) { return "ok", nil }
+	denied := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.PermissionDenied, "no")
+	}
+
+	for _, h := range []grpc.UnaryHandler{ok, ok, denied} {
+		_, _ = intercept(context.Background(), nil, info, h)
+	}
+
+	if got := testutil.ToFloat64(m.handled.WithLabelValues(info.FullMethod, "OK")); got != 2 {
+		t.Errorf("OK count = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.handled.WithLabelValues(info.FullMethod, "PermissionDenied")); got != 1 {
+		t.Errorf("PermissionDenied count = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(m.duration); got != 1 {
+		t.Errorf("duration series = %d, want 1", got)
+	}
+}
+
+func TestJobMetricsSkipped(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewJobMetrics(reg)
+
+	m.JobRun("purge", scheduler.ResultSkipped, time.Second)
+	if got := testutil.CollectAndCount(m.duration); got != 0 {
+		t.Errorf("skipped run observed a duration: %d series", got)
+	}
+	if got := testutil.CollectAndCount(m.lastSuccess); got != 0 {
+		t.Errorf("skipped run set the last success time: %d series", got)
+	}
+
+	m.JobRun("purge", scheduler.ResultSuccess, time.Second)
+	if got := testutil.ToFloat64(m.runs.WithLabelValues("purge", scheduler.ResultSuccess)); got != 1 {
+		t.Errorf("success runs = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.lastSuccess.WithLabelValues("purge")); got == 0 {
+		t.Error("last success time not set")
+	}
+}
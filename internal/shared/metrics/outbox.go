@@ -0,0 +1,31 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// OutboxMetrics records outbox publish attempts. It satisfies
+// outbox.Metrics.
+type OutboxMetrics struct {
+	published *prometheus.CounterVec
+}
+
+func NewOutboxMetrics(reg prometheus.Registerer) *OutboxMetrics {
+	m := &OutboxMetrics{
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "outbox",
+			Name:      "publish_attempts_total",
+			Help:      "Outbox publish attempts, by event type and result.",
+		}, []string{"event_type", "result"}),
+	}
+
+	reg.MustRegister(m.published)
+	return m
+}
+
+func (m *OutboxMetrics) EventPublished(eventType string, ok bool) {
+	result := "success"
+	if !ok {
+		result = "failure"
+	}
+	m.published.WithLabelValues(eventType, result).Inc()
+}
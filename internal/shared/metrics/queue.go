@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueueMetrics records processed queue jobs. It satisfies queue.Metrics.
+type QueueMetrics struct {
+	processed *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+func NewQueueMetrics(reg prometheus.Registerer) *QueueMetrics {
+	m := &QueueMetrics{
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "jobs_processed_total",
+			Help:      "Queue jobs processed, by kind and outcome.",
+		}, []string{"kind", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "job_duration_seconds",
+			Help:      "Time spent in queue job handlers.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"kind"}),
+	}
+
+	reg.MustRegister(m.processed, m.duration)
+	return m
+}
+
+func (m *QueueMetrics) JobProcessed(kind, outcome string, duration time.Duration) {
+	m.processed.WithLabelValues(kind, outcome).Inc()
+	m.duration.WithLabelValues(kind).Observe(duration.Seconds())
+}
@@ -0,0 +1,204 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JSONWebKey is the public subset of RFC 7517 needed for RS256 and ES256.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewJSONWebKey describes an RSA or P-256 public key for a JWKS document.
+func NewJSONWebKey(pub crypto.PublicKey, kid string) (JSONWebKey, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA", Kid: kid, Use: "sig", Alg: "RS256",
+			N: b64(k.N.Bytes()),
+			E: b64(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return JSONWebKey{}, fmt.Errorf("oidc: unsupported curve %s", k.Curve.Params().Name)
+		}
+		x, y := make([]byte, 32), make([]byte, 32)
+		k.X.FillBytes(x)
+		k.Y.FillBytes(y)
+		return JSONWebKey{
+			Kty: "EC", Kid: kid, Use: "sig", Alg: "ES256",
+			Crv: "P-256", X: b64(x), Y: b64(y),
+		}, nil
+	default:
+		return JSONWebKey{}, fmt.Errorf("oidc: unsupported key type %T", pub)
+	}
+}
+
+// PublicKey decodes the key into the type golang-jwt verifies with.
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	b64 := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := b64(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("oidc: key %q: bad modulus: %w", k.Kid, err)
+		}
+		e, err := b64(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("oidc: key %q: bad exponent", k.Kid)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("oidc: key %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, errX := b64(k.X)
+		y, errY := b64(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("oidc: key %q: bad coordinates", k.Kid)
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("oidc: key %q: point is not on the curve", k.Kid)
+		}
+		return pub, nil
+
+	default:
+		return nil, fmt.Errorf("oidc: key %q: unsupported key type %q", k.Kid, k.Kty)
+	}
+}
+
+// minRefetch limits how often an unknown kid triggers a JWKS download, so
+// tokens with made-up key IDs cannot hammer the provider.
+const minRefetch = time.Minute
+
+var errUnknownKey = errors.New("oidc: no key matches the token")
+
+// remoteKeySet caches a provider's JWKS and refetches it when a token is
+// signed with a key it has not seen, which is how providers rotate.
+type remoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newRemoteKeySet(url string, client *http.Client) *remoteKeySet {
+	return &remoteKeySet{url: url, client: client}
+}
+
+// key returns the key with the given ID. An empty kid matches the only
+// key of a single-key set.
+func (s *remoteKeySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k, ok := s.lookup(kid); ok {
+		return k, nil
+	}
+	if !s.fetchedAt.IsZero() && time.Since(s.fetchedAt) < minRefetch {
+		return nil, errUnknownKey
+	}
+
+	if err := s.fetch(ctx); err != nil {
+		return nil, err
+	}
+	if k, ok := s.lookup(kid); ok {
+		return k, nil
+	}
+	return nil, errUnknownKey
+}
+
+func (s *remoteKeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+func (s *remoteKeySet) fetch(ctx context.Context) error {
+	s.fetchedAt = time.Now()
+
+	var set JSONWebKeySet
+	if err := getJSON(ctx, s.client, s.url, &set); err != nil {
+		return fmt.Errorf("oidc: fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			// One odd key must not take down login.
+			continue
+		}
+		keys[jwk.Kid] = pub
+	}
+	s.keys = keys
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(http.MaxBytesReader(nil, resp.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,292 @@
+// Package oidc is a minimal OpenID Connect relying party: discovery, the
+// authorization code flow with PKCE, and ID token verification against
+// the provider's JWKS. Package oidctest is a mock provider for tests and
+// local development.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"admin-portal/internal/shared/config"
+)
+
+// Config identifies the provider and this client's registration with it.
+// SSO is off while Issuer is empty.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where the provider sends the browser back with the
+	// code. It must be registered with the provider.
+	RedirectURL string
+	Scopes      []string
+}
+
+func LoadConfig() Config {
+	return Config{
+		Issuer:       strings.TrimSuffix(config.String("OIDC_ISSUER", ""), "/"),
+		ClientID:     config.String("OIDC_CLIENT_ID", ""),
+		ClientSecret: config.String("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:  config.String("OIDC_REDIRECT_URL", ""),
+		Scopes:       strings.Fields(config.String("OIDC_SCOPES", "openid email profile")),
+	}
+}
+
+func (c Config) Enabled() bool {
+	return c.Issuer != ""
+}
+
+func (c Config) Validate() error {
+	if c.ClientID == "" {
+		return errors.New("OIDC_CLIENT_ID is required with OIDC_ISSUER")
+	}
+	if c.RedirectURL == "" {
+		return errors.New("OIDC_REDIRECT_URL is required with OIDC_ISSUER")
+	}
+	if !slices.Contains(c.Scopes, "openid") {
+		return errors.New(`OIDC_SCOPES must include "openid"`)
+	}
+	return nil
+}
+
+// Metadata is the part of the discovery document this package uses.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+	RevocationEndpoint    string `json:"revocation_endpoint,omitempty"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                  []string `json:"scopes_supported,omitempty"`
+	GrantTypesSupported              []string `json:"grant_types_supported,omitempty"`
+	CodeChallengeMethodsSupported    []string `json:"code_challenge_methods_supported,omitempty"`
+	TokenEndpointAuthMethods         []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	ClaimsSupported                  []string `json:"claims_supported,omitempty"`
+}
+
+// DiscoveryPath is appended to the issuer to find its Metadata.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// Tokens is a successful token endpoint response.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// TokenError is an OAuth2 error response (RFC 6749 section 5.2).
+type TokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return "oidc: token endpoint: " + e.Code + ": " + e.Description
+	}
+	return "oidc: token endpoint: " + e.Code
+}
+
+// IDToken holds the verified claims of an ID token.
+type IDToken struct {
+	jwt.RegisteredClaims
+
+	Nonce             string `json:"nonce,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
+
+var ErrInvalidIDToken = errors.New("oidc: invalid ID token")
+
+// clockSkew is tolerated between us and the provider.
+const clockSkew = time.Minute
+
+// Provider talks to one OpenID provider. Discovery happens on first use
+// and is retried on the next call if it fails, so an unreachable
+// provider does not stop the server from starting.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu   sync.Mutex
+	meta *Metadata
+	keys *remoteKeySet
+}
+
+// NewProvider returns a provider for cfg. A nil client uses one with a
+// 10 second timeout.
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+// Metadata returns the discovery document, fetching it if needed.
+func (p *Provider) Metadata(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	var meta Metadata
+	if err := getJSON(ctx, p.client, p.cfg.Issuer+DiscoveryPath, &meta); err != nil {
+		return nil, fmt.Errorf("oidc: discovery: %w", err)
+	}
+	// The document must be about the issuer we were configured with
+	// (OIDC Discovery section 4.3).
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc: discovery: issuer %q does not match %q", meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery: document lacks an authorization, token or JWKS endpoint")
+	}
+
+	p.meta = &meta
+	p.keys = newRemoteKeySet(meta.JWKSURI, p.client)
+	return p.meta, nil
+}
+
+// AuthCodeURL is where to send the browser to log in. state and nonce
+// must be unguessable and remembered until the callback, as must the
+// PKCE verifier behind challenge.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	meta, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*Tokens, error) {
+	meta, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic form-encodes both parts (RFC 6749 2.3.1).
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token endpoint: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var tokenErr TokenError
+		if json.Unmarshal(body, &tokenErr) == nil && tokenErr.Code != "" {
+			return nil, &tokenErr
+		}
+		return nil, fmt.Errorf("oidc: token endpoint: %s", resp.Status)
+	}
+
+	var tokens Tokens
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("oidc: token endpoint: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("oidc: token endpoint returned no id_token")
+	}
+	return &tokens, nil
+}
+
+// VerifyIDToken checks the signature against the provider's JWKS, the
+// issuer, audience, expiry and nonce, and returns the claims.
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*IDToken, error) {
+	if _, err := p.Metadata(ctx); err != nil {
+		return nil, err
+	}
+
+	claims := &IDToken{}
+	_, err := jwt.ParseWithClaims(raw, claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.keys.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
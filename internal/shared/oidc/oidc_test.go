@@ -0,0 +1,260 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"admin-portal/internal/shared/oidc"
+	"admin-portal/internal/shared/oidc/oidctest"
+)
+
+const redirectURL = "https://portal.example.com/sso/callback"
+
+var ctx = context.Background()
+
+func newProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+
+	idp := oidctest.NewServer()
+	t.Cleanup(idp.Close)
+	return idp, oidc.NewProvider(idp.Config(redirectURL), idp.Client())
+}
+
+// claims are valid ID token claims for idp, for tests to spoil.
+func claims(idp *oidctest.Server, nonce string) *oidc.IDToken {
+	now := time.Now()
+	return &oidc.IDToken{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    idp.Issuer(),
+			Subject:   "42",
+			Audience:  jwt.ClaimStrings{oidctest.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+		Nonce: nonce,
+	}
+}
+
+func TestCodeFlow(t *testing.T) {
+	idp, p := newProvider(t)
+	idp.SetUser(oidctest.User{Subject: "42", Email: "alice@example.com", EmailVerified: true, Name: "Alice"})
+
+	verifier, err := oidc.NewPKCEVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, "the-state", "the-nonce", oidc.PKCEChallenge(verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != "the-state" {
+		t.Errorf("state = %q", state)
+	}
+
+	tokens, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := p.VerifyIDToken(ctx, tokens.IDToken, "the-nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "42" || id.Email != "alice@example.com" || !id.EmailVerified || id.Name != "Alice" {
+		t.Errorf("claims = %+v", id)
+	}
+
+	// Codes are single use.
+	var tokenErr *oidc.TokenError
+	if _, err := p.Exchange(ctx, code, verifier); !errors.As(err, &tokenErr) || tokenErr.Code != "invalid_grant" {
+		t.Errorf("reused code: err = %v", err)
+	}
+}
+
+func TestExchangeChecksPKCE(t *testing.T) {
+	idp, p := newProvider(t)
+
+	verifier, _ := oidc.NewPKCEVerifier()
+	authURL, err := p.AuthCodeURL(ctx, "state", "nonce", oidc.PKCEChallenge(verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := oidc.NewPKCEVerifier()
+	var tokenErr *oidc.TokenError
+	if _, err := p.Exchange(ctx, code, other); !errors.As(err, &tokenErr) || tokenErr.Code != "invalid_grant" {
+		t.Errorf("wrong verifier: err = %v", err)
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	v, err := oidc.NewPKCEVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 43 {
+		t.Errorf("verifier has %d characters, want 43", len(v))
+	}
+	if !oidc.VerifyPKCE(oidc.PKCEChallenge(v), v) || oidc.VerifyPKCE(oidc.PKCEChallenge(v), v+"x") {
+		t.Error("VerifyPKCE disagrees with PKCEChallenge")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp, p := newProvider(t)
+
+	sign := func(c jwt.Claims) string {
+		t.Helper()
+		raw, err := idp.SignIDToken(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	if _, err := p.VerifyIDToken(ctx, sign(claims(idp, "n")), "n"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	stranger := oidctest.NewServer()
+	defer stranger.Close()
+	forged, err := stranger.SignIDToken(claims(idp, "n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(idp, "n")).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknownKid := jwt.NewWithClaims(jwt.SigningMethodRS256, claims(idp, "n"))
+	unknownKid.Header["kid"] = "rotated-away"
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	unknown, err := unknownKid.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spoil := func(f func(c *oidc.IDToken)) string {
+		c := claims(idp, "n")
+		f(c)
+		return sign(c)
+	}
+
+	cases := []struct {
+		name  string
+		raw   string
+		nonce string
+	}{
+		{"wrong nonce", sign(claims(idp, "n")), "other"},
+		{"missing nonce", sign(claims(idp, "")), "n"},
+		{"signed by another key with the same kid", forged, "n"},
+		{"HS256", hmac, "n"},
+		{"unknown kid", unknown, "n"},
+		{"garbage", "not.a.jwt", "n"},
+		{"wrong issuer", spoil(func(c *oidc.IDToken) { c.Issuer = "https://evil.example.com" }), "n"},
+		{"wrong audience", spoil(func(c *oidc.IDToken) { c.Audience = jwt.ClaimStrings{"another-app"} }), "n"},
+		{"expired", spoil(func(c *oidc.IDToken) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Minute)) }), "n"},
+		{"no expiry", spoil(func(c *oidc.IDToken) { c.ExpiresAt = nil }), "n"},
+		{"issued in the future", spoil(func(c *oidc.IDToken) { c.IssuedAt = jwt.NewNumericDate(time.Now().Add(10 * time.Minute)) }), "n"},
+		{"no subject", spoil(func(c *oidc.IDToken) { c.Subject = "" }), "n"},
+	}
+
+	for _, tc := range cases {
+		if _, err := p.VerifyIDToken(ctx, tc.raw, tc.nonce); !errors.Is(err, oidc.ErrInvalidIDToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidIDToken", tc.name, err)
+		}
+	}
+
+	// Within the allowed clock skew.
+	late := spoil(func(c *oidc.IDToken) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-30 * time.Second)) })
+	if _, err := p.VerifyIDToken(ctx, late, "n"); err != nil {
+		t.Errorf("token expired within the skew rejected: %v", err)
+	}
+}
+
+func TestDiscoveryChecksIssuer(t *testing.T) {
+	// A provider that claims to be someone else.
+	idp, err := oidctest.New("https://idp.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(idp)
+	defer srv.Close()
+
+	p := oidc.NewProvider(oidc.Config{Issuer: srv.URL, ClientID: oidctest.ClientID}, srv.Client())
+	if _, err := p.Metadata(ctx); err == nil {
+		t.Error("accepted a discovery document for another issuer")
+	}
+
+	p = oidc.NewProvider(oidc.Config{Issuer: srv.URL + "/missing", ClientID: oidctest.ClientID}, srv.Client())
+	if _, err := p.AuthCodeURL(ctx, "s", "n", "c"); err == nil {
+		t.Error("built an authorization URL without discovery")
+	}
+}
+
+func TestJSONWebKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pub := range []any{&rsaKey.PublicKey, &ecKey.PublicKey} {
+		jwk, err := oidc.NewJSONWebKey(pub, "k1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("%s: %v", jwk.Kty, err)
+		}
+		if !reflect.DeepEqual(got, pub) {
+			t.Errorf("%s key did not round trip", jwk.Kty)
+		}
+	}
+
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := oidc.NewJSONWebKey(&p384.PublicKey, "k2"); err == nil {
+		t.Error("described a P-384 key")
+	}
+
+	jwk, _ := oidc.NewJSONWebKey(&ecKey.PublicKey, "k3")
+	jwk.Y = jwk.X
+	if _, err := jwk.PublicKey(); err == nil {
+		t.Error("accepted a point off the curve")
+	}
+
+	for _, bad := range []oidc.JSONWebKey{
+		{Kty: "RSA", N: "AQAB", E: ""},
+		{Kty: "RSA", N: "!!", E: "AQAB"},
+		{Kty: "EC", Crv: "P-521", X: "AA", Y: "AA"},
+		{Kty: "oct"},
+	} {
+		if _, err := bad.PublicKey(); err == nil {
+			t.Errorf("%+v: accepted", bad)
+		}
+	}
+}
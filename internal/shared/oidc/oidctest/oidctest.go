@@ -0,0 +1,342 @@
+// Package oidctest is a mock OpenID provider. It signs in a configurable
+// user without asking for credentials, but otherwise checks what a real
+// provider would: the client, redirect URI, single-use codes and PKCE.
+//
+//	idp := oidctest.NewServer()
+//	defer idp.Close()
+//	idp.SetUser(oidctest.User{Subject: "42", Email: "alice@example.com", EmailVerified: true})
+//
+// cmd/mock-idp serves one on a fixed address for local development.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"admin-portal/internal/shared/oidc"
+)
+
+// Default client registration.
+const (
+	ClientID     = "admin-portal"
+	ClientSecret = "mock-secret"
+)
+
+const (
+	keyID   = "mock-1"
+	codeTTL = time.Minute
+)
+
+// User is who the provider signs in.
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+type authCode struct {
+	user        User
+	redirectURI string
+	nonce       string
+	challenge   string
+	expires     time.Time
+}
+
+// IdP is the provider as an http.Handler.
+type IdP struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+	mux          *http.ServeMux
+
+	mu    sync.Mutex
+	user  User
+	codes map[string]authCode
+}
+
+// New returns a provider that identifies as issuer, with the default
+// client registration and a fresh signing key.
+func New(issuer string) (*IdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &IdP{
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     ClientID,
+		clientSecret: ClientSecret,
+		key:          key,
+		mux:          http.NewServeMux(),
+		user:         User{Subject: "mock-user", Email: "mock.user@example.com", EmailVerified: true, Name: "Mock User"},
+		codes:        map[string]authCode{},
+	}
+
+	p.mux.HandleFunc("GET "+oidc.DiscoveryPath, p.discovery)
+	p.mux.HandleFunc("GET /jwks", p.jwks)
+	p.mux.HandleFunc("GET /authorize", p.authorize)
+	p.mux.HandleFunc("POST /token", p.token)
+	return p, nil
+}
+
+func (p *IdP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+func (p *IdP) Issuer() string { return p.issuer }
+
+// SetUser changes who the next authorization signs in.
+func (p *IdP) SetUser(u User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = u
+}
+
+// SignIDToken signs arbitrary claims with the provider's key, for tests
+// of what a relying party rejects.
+func (p *IdP) SignIDToken(claims jwt.Claims) (string, error) {
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	t.Header["kid"] = keyID
+	return t.SignedString(p.key)
+}
+
+func (p *IdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.Metadata{
+		Issuer:                           p.issuer,
+		AuthorizationEndpoint:            p.issuer + "/authorize",
+		TokenEndpoint:                    p.issuer + "/token",
+		JWKSURI:                          p.issuer + "/jwks",
+		ResponseTypesSupported:           []string{"code"},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{"RS256"},
+		ScopesSupported:                  []string{"openid", "email", "profile"},
+		GrantTypesSupported:              []string{"authorization_code"},
+		CodeChallengeMethodsSupported:    []string{"S256"},
+		TokenEndpointAuthMethods:         []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (p *IdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	jwk, err := oidc.NewJSONWebKey(&p.key.PublicKey, keyID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, oidc.JSONWebKeySet{Keys: []oidc.JSONWebKey{jwk}})
+}
+
+// authorize approves at once and redirects back with a code.
+func (p *IdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != p.clientID || redirectURI == "" {
+		http.Error(w, "unknown client or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	back, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "bad redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	fail := func(code, desc string) {
+		v := back.Query()
+		v.Set("error", code)
+		v.Set("error_description", desc)
+		v.Set("state", q.Get("state"))
+		back.RawQuery = v.Encode()
+		http.Redirect(w, r, back.String(), http.StatusFound)
+	}
+
+	switch {
+	case q.Get("response_type") != "code":
+		fail("unsupported_response_type", "only code is supported")
+		return
+	case !strings.Contains(" "+q.Get("scope")+" ", " openid "):
+		fail("invalid_scope", "openid scope is required")
+		return
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		fail("invalid_request", "PKCE with S256 is required")
+		return
+	}
+
+	code, err := oidc.RandomString(24)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p.mu.Lock()
+	p.codes[code] = authCode{
+		user:        p.user,
+		redirectURI: redirectURI,
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		expires:     time.Now().Add(codeTTL),
+	}
+	p.mu.Unlock()
+
+	v := back.Query()
+	v.Set("code", code)
+	v.Set("state", q.Get("state"))
+	back.RawQuery = v.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+func (p *IdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || secret != p.clientSecret {
+		tokenError(w, http.StatusUnauthorized, "invalid_client", "unknown client or wrong secret")
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+
+	p.mu.Lock()
+	code, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	switch {
+	case !ok || time.Now().After(code.expires):
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "unknown, used or expired code")
+		return
+	case r.PostForm.Get("redirect_uri") != code.redirectURI:
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match")
+		return
+	case !oidc.VerifyPKCE(code.challenge, r.PostForm.Get("code_verifier")):
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	now := time.Now()
+	idToken, err := p.SignIDToken(&oidc.IDToken{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.issuer,
+			Subject:   code.user.Subject,
+			Audience:  jwt.ClaimStrings{p.clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+		Nonce:             code.nonce,
+		Email:             code.user.Email,
+		EmailVerified:     code.user.EmailVerified,
+		Name:              code.user.Name,
+		PreferredUsername: code.user.PreferredUsername,
+	})
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	access, _ := oidc.RandomString(24)
+	writeJSON(w, http.StatusOK, oidc.Tokens{
+		AccessToken: access,
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   300,
+	})
+}
+
+// Server is an IdP listening on a local httptest server.
+type Server struct {
+	*IdP
+	srv *httptest.Server
+}
+
+// NewServer starts a provider on a random local port. It panics if the
+// signing key cannot be generated, like httptest.NewServer does on
+// listen errors.
+func NewServer() *Server {
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Start()
+
+	idp, err := New(srv.URL)
+	if err != nil {
+		srv.Close()
+		panic(fmt.Sprintf("oidctest: %v", err))
+	}
+	srv.Config.Handler = idp
+
+	return &Server{IdP: idp, srv: srv}
+}
+
+func (s *Server) Close() { s.srv.Close() }
+
+// Client returns an HTTP client for the server.
+func (s *Server) Client() *http.Client { return s.srv.Client() }
+
+// Config is an oidc.Config registered with the server.
+func (s *Server) Config(redirectURL string) oidc.Config {
+	return oidc.Config{
+		Issuer:       s.issuer,
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// Authorize plays the browser: it follows authURL to the provider and
+// returns the code and state the provider redirects back with.
+func (s *Server) Authorize(authURL string) (code, state string, err error) {
+	client := *s.srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	resp.Body.Close()
+
+	loc, err := resp.Location()
+	if err != nil {
+		return "", "", fmt.Errorf("oidctest: no redirect (%s)", resp.Status)
+	}
+	q := loc.Query()
+	if e := q.Get("error"); e != "" {
+		return "", "", fmt.Errorf("oidctest: %s: %s", e, q.Get("error_description"))
+	}
+	return q.Get("code"), q.Get("state"), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func tokenError(w http.ResponseWriter, status int, code, desc string) {
+	writeJSON(w, status, oidc.TokenError{Code: code, Description: desc})
+}
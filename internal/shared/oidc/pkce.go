@@ -0,0 +1,35 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// RandomString returns n random bytes, base64url encoded. It is used for
+// state, nonce and PKCE verifiers.
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewPKCEVerifier returns a code_verifier of 43 characters (RFC 7636).
+func NewPKCEVerifier() (string, error) {
+	return RandomString(32)
+}
+
+// PKCEChallenge is the S256 code_challenge for verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyPKCE checks an S256 challenge against the verifier presented at
+// the token endpoint.
+func VerifyPKCE(challenge, verifier string) bool {
+	return subtle.ConstantTimeCompare([]byte(PKCEChallenge(verifier)), []byte(challenge)) == 1
+}
@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/shared/database"
+)
+
+type Event struct {
+	ID  uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Seq int64     `gorm:"autoIncrement;not null;uniqueIndex;index:idx_outbox_events_unpublished,where:published_at IS NULL"`
+
+	EventType     string `gorm:"type:varchar(100);not null"`
+	AggregateType string `gorm:"type:varchar(50);not null;index:idx_outbox_events_aggregate,priority:1,where:published_at IS NULL"`
+	AggregateID   string `gorm:"type:varchar(100);not null;index:idx_outbox_events_aggregate,priority:2"`
+
+	Payload []byte `gorm:"type:jsonb;not null;default:'{}'"`
+
+	OccurredAt time.Time `gorm:"not null;default:now()"`
+
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;default:now()"`
+	LastError     *string   `gorm:"type:text"`
+
+	PublishedAt *time.Time
+}
+
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// Message is what sinks receive. ID is stable across redeliveries and
+// should be used by consumers to drop duplicates.
+type Message struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+func (e *Event) Message() Message {
+	return Message{
+		ID:            e.ID.String(),
+		Type:          e.EventType,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		OccurredAt:    e.OccurredAt,
+		Payload:       e.Payload,
+	}
+}
+
+// Record writes an event with payload encoded as JSON. Called inside
+// database.Transaction it joins that transaction, so the event exists
+// if and only if the state change it describes was committed.
+func Record(ctx context.Context, db *gorm.DB, eventType, aggregateType, aggregateID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return database.Conn(ctx, db).Create(&Event{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+	}).Error
+}
+
+// Recorder records events like Record, so that services can be unit
+// tested without a database.
+type Recorder interface {
+	Record(ctx context.Context, eventType, aggregateType, aggregateID string, payload interface{}) error
+}
+
+// NewRecorder returns a Recorder that calls Record on db.
+func NewRecorder(db *gorm.DB) Recorder {
+	return recorder{db: db}
+}
+
+type recorder struct {
+	db *gorm.DB
+}
+
+func (r recorder) Record(ctx context.Context, eventType, aggregateType, aggregateID string, payload interface{}) error {
+	return Record(ctx, r.db, eventType, aggregateType, aggregateID, payload)
+}
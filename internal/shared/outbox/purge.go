@@ -0,0 +1,40 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeJob is a scheduler.Job that deletes events published more than
+// retention ago.
+type PurgeJob struct {
+	db        *gorm.DB
+	retention time.Duration
+	batchSize int
+}
+
+func NewPurgeJob(db *gorm.DB, retention time.Duration, batchSize int) *PurgeJob {
+	return &PurgeJob{db: db, retention: retention, batchSize: batchSize}
+}
+
+func (j *PurgeJob) Name() string { return "purge_outbox_events" }
+
+func (j *PurgeJob) Run(ctx context.Context) (int64, error) {
+	var total int64
+
+	for {
+		res := j.db.WithContext(ctx).Exec(`
+			DELETE FROM outbox_events
+			WHERE id IN (
+				SELECT id FROM outbox_events
+				WHERE published_at < NOW() - (? * INTERVAL '1 millisecond')
+				LIMIT ?
+			)`, j.retention.Milliseconds(), j.batchSize)
+		total += res.RowsAffected
+		if res.Error != nil || res.RowsAffected < int64(j.batchSize) {
+			return total, res.Error
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/shared/config"
+)
+
+// Sinks selectable with OUTBOX_SINK.
+const (
+	SinkNone    = "none"
+	SinkWebhook = "webhook"
+	SinkFile    = "file"
+)
+
+// Metrics receives the result of every publish attempt.
+type Metrics interface {
+	EventPublished(eventType string, ok bool)
+}
+
+type Config struct {
+	Sink         string
+	WebhookURL   string
+	FilePath     string
+	Timeout      time.Duration
+	PollInterval time.Duration
+	BatchSize    int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	Retention    time.Duration
+}
+
+func LoadConfig() Config {
+	return Config{
+		Sink:         config.String("OUTBOX_SINK", SinkNone),
+		WebhookURL:   config.String("OUTBOX_WEBHOOK_URL", ""),
+		FilePath:     config.String("OUTBOX_FILE_PATH", "outbox-events.jsonl"),
+		Timeout:      config.Duration("OUTBOX_PUBLISH_TIMEOUT", 10*time.Second),
+		PollInterval: config.Duration("OUTBOX_POLL_INTERVAL", time.Second),
+		BatchSize:    config.Int("OUTBOX_BATCH_SIZE", 100),
+		BackoffBase:  config.Duration("OUTBOX_BACKOFF_BASE", 5*time.Second),
+		BackoffMax:   config.Duration("OUTBOX_BACKOFF_MAX", 10*time.Minute),
+		Retention:    config.Duration("OUTBOX_RETENTION", 7*24*time.Hour),
+	}
+}
+
+// NewSink builds the sink selected by cfg. It returns nil for SinkNone.
+func NewSink(cfg Config) (Sink, error) {
+	switch cfg.Sink {
+	case SinkNone, "":
+		return nil, nil
+	case SinkWebhook:
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for the webhook sink")
+		}
+		return NewWebhookSink(cfg.WebhookURL, cfg.Timeout), nil
+	case SinkFile:
+		return NewFileSink(cfg.FilePath)
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", cfg.Sink)
+	}
+}
+
+// Relay publishes committed events to a sink. Events are locked with
+// FOR UPDATE SKIP LOCKED, so several relays can run side by side, and an
+// event is only picked once every earlier event of the same aggregate
+// has been published, which keeps per-aggregate order.
+type Relay struct {
+	db      *gorm.DB
+	sink    Sink
+	cfg     Config
+	log     *slog.Logger
+	metrics Metrics
+}
+
+func NewRelay(db *gorm.DB, sink Sink, cfg Config, log *slog.Logger, metrics Metrics) *Relay {
+	return &Relay{db: db, sink: sink, cfg: cfg, log: log, metrics: metrics}
+}
+
+// Run publishes events until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	r.log.Info("outbox relay started", "sink", r.cfg.Sink)
+
+	for {
+		n, err := r.publishBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.log.Error("outbox batch failed", "error", err)
+		}
+
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+// publishBatch publishes one batch and returns how many events it
+// attempted.
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	var attempted int
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []*Event
+		err := tx.Raw(`
+			SELECT * FROM outbox_events e
+			WHERE e.published_at IS NULL
+				AND e.next_attempt_at <= NOW()
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox_events prev
+					WHERE prev.published_at IS NULL
+						AND prev.aggregate_type = e.aggregate_type
+						AND prev.aggregate_id = e.aggregate_id
+						AND prev.seq < e.seq
+				)
+			ORDER BY e.seq
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`, r.cfg.BatchSize).
+			Scan(&events).Error
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			attempted++
+			if err := r.publish(ctx, tx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return attempted, err
+}
+
+func (r *Relay) publish(ctx context.Context, tx *gorm.DB, e *Event) error {
+	pubCtx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	err := r.sink.Publish(pubCtx, e.Message())
+	cancel()
+
+	r.metrics.EventPublished(e.EventType, err == nil)
+
+	if err == nil {
+		return tx.Exec(`
+			UPDATE outbox_events
+			SET published_at = NOW(), attempts = attempts + 1, last_error = NULL
+			WHERE id = ?`, e.ID).Error
+	}
+
+	delay := r.backoff(e.Attempts + 1)
+	r.log.Warn("outbox publish failed",
+		"event_id", e.ID.String(),
+		"event_type", e.EventType,
+		"attempt", e.Attempts+1,
+		"retry_in", delay.String(),
+		"error", err,
+	)
+
+	return tx.Exec(`
+		UPDATE outbox_events
+		SET attempts = attempts + 1,
+			next_attempt_at = NOW() + (? * INTERVAL '1 millisecond'),
+			last_error = ?
+		WHERE id = ?`, delay.Milliseconds(), err.Error(), e.ID).Error
+}
+
+func (r *Relay) backoff(attempt int) time.Duration {
+	d := r.cfg.BackoffMax
+	if attempt < 32 {
+		if exp := r.cfg.BackoffBase << (attempt - 1); exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	return d
+}
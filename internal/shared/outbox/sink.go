@@ -0,0 +1,118 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sink delivers events to other services. Delivery is at least once: a
+// message may be published again if the relay stops before recording
+// success, so Publish must tolerate repeats of the same Message.ID.
+type Sink interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// WebhookSink POSTs each message as JSON to URL. The message id is sent
+// in the Idempotency-Key header; any 2xx response counts as delivered.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", msg.ID)
+	req.Header.Set("X-Event-Type", msg.Type)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// SubjectPublisher is the subset of a NATS-style client SubjectSink
+// needs. Adapt *nats.Conn or a JetStream context to it.
+type SubjectPublisher interface {
+	Publish(ctx context.Context, subject string, header map[string]string, data []byte) error
+}
+
+// SubjectSink publishes each message on Prefix + "." + type. The message
+// id is sent in the Nats-Msg-Id header, which JetStream uses to drop
+// duplicates within its window.
+type SubjectSink struct {
+	Publisher SubjectPublisher
+	Prefix    string
+}
+
+func (s *SubjectSink) Publish(ctx context.Context, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	subject := msg.Type
+	if s.Prefix != "" {
+		subject = s.Prefix + "." + msg.Type
+	}
+
+	return s.Publisher.Publish(ctx, subject, map[string]string{"Nats-Msg-Id": msg.ID}, data)
+}
+
+// FileSink appends each message as a line of JSON to a file. It is meant
+// for local development and tests.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: f}, nil
+}
+
+func (s *FileSink) Publish(_ context.Context, msg Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
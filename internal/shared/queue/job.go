@@ -0,0 +1,139 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"admin-portal/internal/shared/database"
+)
+
+// Job statuses. Dead jobs exhausted their attempts or failed permanently
+// and stay in the table until retried by an operator.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// DefaultMaxAttempts applies when Enqueue is not given WithMaxAttempts.
+const DefaultMaxAttempts = 10
+
+// ErrDuplicate is returned by Enqueue when a pending or running job
+// already holds the unique key. It does not abort the caller's
+// transaction.
+var ErrDuplicate = errors.New("queue: a job with this unique key is already queued")
+
+type Job struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	Kind    string `gorm:"type:varchar(100);not null"`
+	Payload []byte `gorm:"type:jsonb;not null;default:'{}'"`
+
+	Status string `gorm:"type:varchar(20);not null;default:'pending';index:idx_jobs_status_run_at,priority:1;check:status IN ('pending','running','succeeded','dead')"`
+
+	UniqueKey *string `gorm:"type:varchar(200);uniqueIndex:uq_jobs_unique_key_active,where:status = 'pending' OR status = 'running'"`
+
+	Attempts    int `gorm:"not null;default:0"`
+	MaxAttempts int `gorm:"not null;default:10"`
+
+	RunAt    time.Time `gorm:"not null;default:now();index:idx_jobs_status_run_at,priority:2"`
+	LockedAt *time.Time
+	LockedBy *string `gorm:"type:varchar(200)"`
+
+	LastError *string `gorm:"type:text"`
+
+	CreatedAt  time.Time `gorm:"not null;default:now()"`
+	UpdatedAt  time.Time `gorm:"not null;default:now()"`
+	FinishedAt *time.Time
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Decode unmarshals the job payload into v.
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+type Option func(*Job)
+
+// WithUniqueKey stops a second job with the same key from being queued
+// while the first is pending or running.
+func WithUniqueKey(key string) Option {
+	return func(j *Job) { j.UniqueKey = &key }
+}
+
+// WithRunAt delays the job until t. Without it the job is due as soon
+// as it is committed.
+func WithRunAt(t time.Time) Option {
+	return func(j *Job) { j.RunAt = t }
+}
+
+func WithMaxAttempts(n int) Option {
+	return func(j *Job) { j.MaxAttempts = n }
+}
+
+// Enqueue inserts a job of the given kind with payload encoded as JSON.
+//
+// Called inside database.Transaction, or with the *gorm.DB of an open
+// transaction, the job becomes part of it and is only visible to workers
+// once the transaction commits.
+func Enqueue(ctx context.Context, db *gorm.DB, kind string, payload interface{}, opts ...Option) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		Kind:        kind,
+		Payload:     data,
+		Status:      StatusPending,
+		MaxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	res := database.Conn(ctx, db).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "unique_key"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status = 'pending' OR status = 'running'"}}},
+			DoNothing:   true,
+		}).
+		Create(job)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrDuplicate
+	}
+
+	return job, nil
+}
+
+// Enqueuer queues jobs like Enqueue, so that services can be unit tested
+// without a database.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, kind string, payload interface{}, opts ...Option) (*Job, error)
+}
+
+// NewEnqueuer returns an Enqueuer that calls Enqueue on db.
+func NewEnqueuer(db *gorm.DB) Enqueuer {
+	return enqueuer{db: db}
+}
+
+type enqueuer struct {
+	db *gorm.DB
+}
+
+func (e enqueuer) Enqueue(ctx context.Context, kind string, payload interface{}, opts ...Option) (*Job, error) {
+	return Enqueue(ctx, e.db, kind, payload, opts...)
+}
@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// PurgeJob is a scheduler.Job that deletes succeeded jobs past their
+// retention. Dead jobs are kept for inspection.
+type PurgeJob struct {
+	store     *Store
+	retention time.Duration
+	batchSize int
+}
+
+func NewPurgeJob(store *Store, retention time.Duration, batchSize int) *PurgeJob {
+	return &PurgeJob{store: store, retention: retention, batchSize: batchSize}
+}
+
+func (j *PurgeJob) Name() string { return "purge_jobs" }
+
+func (j *PurgeJob) Run(ctx context.Context) (int64, error) {
+	var total int64
+
+	for {
+		n, err := j.store.PurgeSucceeded(ctx, j.retention, j.batchSize)
+		total += n
+		if err != nil || n < int64(j.batchSize) {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotRetryable is returned by Retry for jobs that are not dead.
+var ErrNotRetryable = errors.New("queue: only dead jobs can be retried")
+
+// Filter narrows List. Empty fields match everything.
+type Filter struct {
+	Status string
+	Kind   string
+	Limit  int
+	Offset int
+}
+
+// Store reads and updates the jobs table. Times are taken from the
+// database clock so that workers on different hosts agree.
+type Store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Claim marks up to limit due jobs as running for worker and returns
+// them. Running jobs whose lease has expired are claimed again, which
+// recovers work from crashed workers.
+func (s *Store) Claim(ctx context.Context, worker string, lease time.Duration, limit int) ([]*Job, error) {
+	var jobs []*Job
+	err := s.db.WithContext(ctx).Raw(`
+		UPDATE jobs
+		SET status = 'running',
+			attempts = attempts + 1,
+			locked_at = NOW(),
+			locked_by = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE (status = 'pending' AND run_at <= NOW())
+				OR (status = 'running' AND locked_at < NOW() - (? * INTERVAL '1 millisecond'))
+			ORDER BY run_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, worker, lease.Milliseconds(), limit).
+		Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Complete marks a job claimed by worker as succeeded.
+func (s *Store) Complete(ctx context.Context, job *Job, worker string) error {
+	return s.db.WithContext(ctx).Exec(`
+		UPDATE jobs
+		SET status = 'succeeded', finished_at = NOW(), locked_at = NULL, locked_by = NULL, last_error = NULL
+		WHERE id = ? AND status = 'running' AND locked_by = ?`,
+		job.ID, worker).Error
+}
+
+// Reschedule puts a failed job back in the queue after delay.
+func (s *Store) Reschedule(ctx context.Context, job *Job, worker string, delay time.Duration, cause error) error {
+	return s.db.WithContext(ctx).Exec(`
+		UPDATE jobs
+		SET status = 'pending',
+			run_at = NOW() + (? * INTERVAL '1 millisecond'),
+			locked_at = NULL, locked_by = NULL, last_error = ?
+		WHERE id = ? AND status = 'running' AND locked_by = ?`,
+		delay.Milliseconds(), cause.Error(), job.ID, worker).Error
+}
+
+// Bury dead-letters a failed job.
+func (s *Store) Bury(ctx context.Context, job *Job, worker string, cause error) error {
+	return s.db.WithContext(ctx).Exec(`
+		UPDATE jobs
+		SET status = 'dead', finished_at = NOW(), locked_at = NULL, locked_by = NULL, last_error = ?
+		WHERE id = ? AND status = 'running' AND locked_by = ?`,
+		cause.Error(), job.ID, worker).Error
+}
+
+// List returns jobs matching f, newest first, and the total number of
+// matches.
+func (s *Store) List(ctx context.Context, f Filter) ([]*Job, int64, error) {
+	q := s.db.WithContext(ctx).Model(&Job{})
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.Kind != "" {
+		q = q.Where("kind = ?", f.Kind)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []*Job
+	err := q.Order("created_at DESC, id").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+func (s *Store) Get(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Retry moves a dead job back to pending with a fresh attempt budget.
+// It returns gorm.ErrRecordNotFound for unknown ids, ErrNotRetryable for
+// jobs that are not dead and ErrDuplicate if another job with the same
+// unique key is already queued.
+func (s *Store) Retry(ctx context.Context, id string) (*Job, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != StatusDead {
+		return nil, ErrNotRetryable
+	}
+
+	res := s.db.WithContext(ctx).Exec(`
+		UPDATE jobs
+		SET status = 'pending', attempts = 0, run_at = NOW(), finished_at = NULL
+		WHERE id = ? AND status = 'dead'
+			AND (unique_key IS NULL OR NOT EXISTS (
+				SELECT 1 FROM jobs other
+				WHERE other.unique_key = jobs.unique_key
+					AND other.status IN ('pending', 'running')
+			))`, id)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		// Either someone else retried it first or its key is taken.
+		if job.UniqueKey != nil {
+			return nil, ErrDuplicate
+		}
+		return nil, ErrNotRetryable
+	}
+
+	return s.Get(ctx, id)
+}
+
+// PurgeSucceeded deletes up to limit jobs that succeeded before the
+// given age.
+func (s *Store) PurgeSucceeded(ctx context.Context, olderThan time.Duration, limit int) (int64, error) {
+	res := s.db.WithContext(ctx).Exec(`
+		DELETE FROM jobs
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE status = 'succeeded'
+				AND finished_at < NOW() - (? * INTERVAL '1 millisecond')
+			LIMIT ?
+		)`, olderThan.Milliseconds(), limit)
+	return res.RowsAffected, res.Error
+}
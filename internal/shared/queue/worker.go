@@ -0,0 +1,211 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"admin-portal/internal/shared/config"
+)
+
+// Handler processes one job. Returning an error schedules a retry unless
+// the error is wrapped with Permanent or the job is out of attempts.
+type Handler func(ctx context.Context, job *Job) error
+
+// Metrics receives the outcome of every processed job.
+type Metrics interface {
+	JobProcessed(kind, outcome string, duration time.Duration)
+}
+
+// Outcomes reported to Metrics.JobProcessed.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeRetried   = "retried"
+	OutcomeDead      = "dead"
+)
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; the job is dead-lettered.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+type Config struct {
+	Concurrency  int
+	PollInterval time.Duration
+	// Lease is how long a job may run before another worker assumes its
+	// owner died and claims it again. Handlers are cancelled at the lease.
+	Lease       time.Duration
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	Retention   time.Duration
+}
+
+func LoadConfig() Config {
+	return Config{
+		Concurrency:  config.Int("QUEUE_CONCURRENCY", 4),
+		PollInterval: config.Duration("QUEUE_POLL_INTERVAL", time.Second),
+		Lease:        config.Duration("QUEUE_LEASE", 5*time.Minute),
+		BackoffBase:  config.Duration("QUEUE_BACKOFF_BASE", 10*time.Second),
+		BackoffMax:   config.Duration("QUEUE_BACKOFF_MAX", time.Hour),
+		Retention:    config.Duration("QUEUE_RETENTION", 7*24*time.Hour),
+	}
+}
+
+// Worker claims due jobs with SELECT ... FOR UPDATE SKIP LOCKED and runs
+// them through the handler registered for their kind. Any number of
+// workers may share a table.
+type Worker struct {
+	store    *Store
+	cfg      Config
+	log      *slog.Logger
+	metrics  Metrics
+	id       string
+	handlers map[string]Handler
+}
+
+func NewWorker(store *Store, cfg Config, log *slog.Logger, metrics Metrics) *Worker {
+	host, _ := os.Hostname()
+
+	return &Worker{
+		store:    store,
+		cfg:      cfg,
+		log:      log,
+		metrics:  metrics,
+		id:       fmt.Sprintf("%s:%d", host, os.Getpid()),
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers h for jobs of the given kind. Jobs without a handler
+// are dead-lettered.
+func (w *Worker) Handle(kind string, h Handler) {
+	w.handlers[kind] = h
+}
+
+// Run processes jobs until ctx is done. Jobs already started are allowed
+// to finish.
+func (w *Worker) Run(ctx context.Context) {
+	w.log.Info("queue worker started", "worker", w.id, "concurrency", w.cfg.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		jobs, err := w.store.Claim(ctx, w.id, w.cfg.Lease, 1)
+		if err != nil && ctx.Err() == nil {
+			w.log.Error("claim jobs failed", "error", err)
+		}
+
+		for _, job := range jobs {
+			w.process(context.WithoutCancel(ctx), job)
+		}
+
+		if len(jobs) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *Job) {
+	log := w.log.With("job_id", job.ID.String(), "kind", job.Kind, "attempt", job.Attempts)
+
+	runCtx, cancel := context.WithTimeout(ctx, w.cfg.Lease)
+	defer cancel()
+
+	start := time.Now()
+	err := w.run(runCtx, job)
+	duration := time.Since(start)
+
+	if err == nil {
+		if err := w.store.Complete(ctx, job, w.id); err != nil {
+			log.Error("complete job failed", "error", err)
+		}
+		log.Info("job succeeded", "duration_ms", duration.Milliseconds())
+		w.metrics.JobProcessed(job.Kind, OutcomeSucceeded, duration)
+		return
+	}
+
+	if IsPermanent(err) || job.Attempts >= job.MaxAttempts {
+		if err := w.store.Bury(ctx, job, w.id, err); err != nil {
+			log.Error("dead-letter job failed", "error", err)
+		}
+		log.Error("job dead-lettered", "error", err, "duration_ms", duration.Milliseconds())
+		w.metrics.JobProcessed(job.Kind, OutcomeDead, duration)
+		return
+	}
+
+	delay := w.backoff(job.Attempts)
+	if err := w.store.Reschedule(ctx, job, w.id, delay, err); err != nil {
+		log.Error("reschedule job failed", "error", err)
+	}
+	log.Warn("job failed, retrying", "error", err, "retry_in", delay.String(), "duration_ms", duration.Milliseconds())
+	w.metrics.JobProcessed(job.Kind, OutcomeRetried, duration)
+}
+
+func (w *Worker) run(ctx context.Context, job *Job) (err error) {
+	// A reclaimed job may already be past its budget.
+	if job.Attempts > job.MaxAttempts {
+		return Permanent(errors.New("attempts exhausted"))
+	}
+
+	h, ok := w.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	return h(ctx, job)
+}
+
+// backoff doubles the delay with each attempt, up to BackoffMax, and
+// adds jitter so failing jobs do not retry in lockstep.
+func (w *Worker) backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := w.cfg.BackoffMax
+	if attempt < 32 {
+		if exp := w.cfg.BackoffBase << (attempt - 1); exp > 0 && exp < d {
+			d = exp
+		}
+	}
+
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a unit of periodic background work.
+type Job interface {
+	Name() string
+	// Run performs one pass and returns how many records it touched.
+	Run(ctx context.Context) (int64, error)
+}
+
+// Metrics receives the outcome of every scheduled run.
+type Metrics interface {
+	JobRun(job, result string, duration time.Duration)
+}
+
+// Results reported to Metrics.JobRun.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultSkipped = "skipped"
+)
+
+type entry struct {
+	job      Job
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// Scheduler runs each job on its own interval. Before every run it takes
+// a Postgres advisory lock keyed on the job name, so runs never overlap,
+// and claims the run in scheduler_runs, so when several workers are
+// deployed a job still runs once per interval rather than once per
+// worker.
+type Scheduler struct {
+	db      *sql.DB
+	log     *slog.Logger
+	metrics Metrics
+	entries []entry
+}
+
+func New(db *sql.DB, log *slog.Logger, metrics Metrics) *Scheduler {
+	return &Scheduler{db: db, log: log, metrics: metrics}
+}
+
+// Add schedules job every interval. A run is cancelled after timeout.
+func (s *Scheduler) Add(job Job, interval, timeout time.Duration) {
+	s.entries = append(s.entries, entry{job: job, interval: interval, timeout: timeout})
+}
+
+// Run starts every job immediately and then on its interval, and blocks
+// until ctx is done and in-flight runs have returned.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, e := range s.entries {
+		wg.Add(1)
+		go func(e entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	s.log.Info("job scheduled", "job", e.job.Name(), "interval", e.interval.String())
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, e)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, e entry) {
+	name := e.job.Name()
+	log := s.log.With("job", name)
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	unlock, acquired, err := s.tryLock(ctx, name)
+	if err != nil {
+		log.Error("job lock failed", "error", err)
+		s.metrics.JobRun(name, ResultFailure, 0)
+		return
+	}
+	if !acquired {
+		log.Debug("job skipped, another worker holds the lock")
+		s.metrics.JobRun(name, ResultSkipped, 0)
+		return
+	}
+	defer unlock()
+
+	claimed, err := s.claim(ctx, name, e.interval)
+	if err != nil {
+		log.Error("job claim failed", "error", err)
+		s.metrics.JobRun(name, ResultFailure, 0)
+		return
+	}
+	if !claimed {
+		log.Debug("job skipped, another worker ran it this interval")
+		s.metrics.JobRun(name, ResultSkipped, 0)
+		return
+	}
+
+	start := time.Now()
+	affected, err := e.job.Run(ctx)
+	duration := time.Since(start)
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			log.Warn("job interrupted", "duration_ms", duration.Milliseconds())
+		} else {
+			log.Error("job failed", "error", err, "duration_ms", duration.Milliseconds())
+		}
+		s.metrics.JobRun(name, ResultFailure, duration)
+		return
+	}
+
+	log.Info("job finished", "affected", affected, "duration_ms", duration.Milliseconds())
+	s.metrics.JobRun(name, ResultSuccess, duration)
+}
+
+// tryLock takes the job's advisory lock on a dedicated connection without
+// waiting.
+func (s *Scheduler) tryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := LockKey("job:" + name)
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, key)
+		conn.Close()
+	}, true, nil
+}
+
+// claim records that the job starts now, unless a run started less than
+// most of an interval ago. The tenth of slack absorbs ticker jitter, so
+// the worker that claimed the last run also claims the next one.
+func (s *Scheduler) claim(ctx context.Context, name string, interval time.Duration) (bool, error) {
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO scheduler_runs (job, last_run_at) VALUES ($1, NOW())
+		ON CONFLICT (job) DO UPDATE SET last_run_at = EXCLUDED.last_run_at
+		WHERE scheduler_runs.last_run_at <= NOW() - make_interval(secs => $2)
+		RETURNING job`,
+		name, (interval - interval/10).Seconds(),
+	).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// LockKey maps a name onto the int64 keyspace of pg_advisory_lock.
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,67 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// API keys look like "ak_<prefix>_<secret>". The prefix is stored in
+// clear to find the key; only a SHA-256 of the whole key is kept, which
+// is enough because the secret carries 256 random bits.
+const apiKeyTag = "ak_"
+
+// GenerateAPIKey returns a new key, its lookup prefix and its hash.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	p := make([]byte, 6)
+	if _, err := rand.Read(p); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(p)
+	key = apiKeyTag + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// APIKeyPrefix returns the lookup prefix of key, or false if key is not
+// shaped like one GenerateAPIKey returns.
+func APIKeyPrefix(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, apiKeyTag)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 12 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckAPIKey compares key with a stored hash in constant time.
+func CheckAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
+
+// GenerateOpaqueToken returns 256 random bits, base64url encoded, and
+// their hash. It is used for secrets that, like API keys, are looked up
+// by hash and never stored.
+func GenerateOpaqueToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashAPIKey(token), nil
+}
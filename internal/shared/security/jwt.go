@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JWTConfig struct {
@@ -39,7 +40,9 @@ func GenerateAccessToken(cfg JWTConfig, userID, username, role string) (string,
 func GenerateRefreshToken(cfg JWTConfig) (string, time.Time, error) {
 	expires := time.Now().Add(cfg.RefreshTokenTTL)
 
+	// The jti keeps tokens issued in the same second distinct.
 	claims := jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
 		IssuedAt:  jwt.NewNumericDate(time.Now()),
 		ExpiresAt: jwt.NewNumericDate(expires),
 	}
@@ -0,0 +1,18 @@
+package security
+
+import "github.com/golang-jwt/jwt/v5"
+
+// OIDCClaims are the claims of ID and access tokens issued to OAuth
+// clients: the portal's own Claims plus the standard OpenID Connect ones,
+// so clients can read either.
+type OIDCClaims struct {
+	Claims
+
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	Nonce             string           `json:"nonce,omitempty"`
+	AuthTime          *jwt.NumericDate `json:"auth_time,omitempty"`
+	// AuthorizedParty is the client the token was issued to.
+	AuthorizedParty string `json:"azp,omitempty"`
+	// Scope is set on access tokens only.
+	Scope string `json:"scope,omitempty"`
+}
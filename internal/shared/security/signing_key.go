@@ -0,0 +1,110 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey signs tokens that other services verify with the public
+// half, published as a JWKS. Access tokens for the portal itself stay
+// HMAC-signed with JWTConfig.Secret.
+type SigningKey struct {
+	// ID is the "kid" header of signed tokens.
+	ID     string
+	Method jwt.SigningMethod
+	Key    crypto.Signer
+}
+
+// LoadSigningKey reads a PEM RSA or P-256 private key (PKCS #1, SEC 1 or
+// PKCS #8).
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported key type %T", path, key)
+	}
+	return NewSigningKey(signer)
+}
+
+// NewSigningKey picks RS256 or ES256 for key and derives its ID from the
+// public key, so the ID changes whenever the key does.
+func NewSigningKey(key crypto.Signer) (*SigningKey, error) {
+	var method jwt.SigningMethod
+	switch k := key.Public().(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA signing key must have at least 2048 bits")
+		}
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("EC signing key must use P-256")
+		}
+		method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", k)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+
+	return &SigningKey{
+		ID:     base64.RawURLEncoding.EncodeToString(sum[:12]),
+		Method: method,
+		Key:    key,
+	}, nil
+}
+
+// Sign signs claims with the key and sets the kid header, and the typ
+// header unless typ is empty.
+func (k *SigningKey) Sign(typ string, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Method, claims)
+	token.Header["kid"] = k.ID
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+	return token.SignedString(k.Key)
+}
+
+// Parse verifies a token signed with the key and decodes it into claims.
+func (k *SigningKey) Parse(tokenStr string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	opts = append(opts, jwt.WithValidMethods([]string{k.Method.Alg()}))
+	return jwt.ParseWithClaims(tokenStr, claims, func(*jwt.Token) (any, error) {
+		return k.Key.Public(), nil
+	}, opts...)
+}
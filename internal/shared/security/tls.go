@@ -0,0 +1,194 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"admin-portal/internal/shared/config"
+)
+
+// Client certificate modes for TLSConfig.ClientAuth.
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// TLSConfig configures the server side of TLS. TLS is off while CertFile
+// is empty.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile enables mutual TLS: client certificates are verified
+	// against the CAs in it.
+	ClientCAFile string
+	// ClientAuth is ClientAuthNone, ClientAuthOptional (verify a
+	// certificate if one is sent) or ClientAuthRequire.
+	ClientAuth string
+
+	MinVersion uint16
+
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+func LoadTLSConfig() (TLSConfig, error) {
+	cfg := TLSConfig{
+		CertFile:       config.String("GRPC_TLS_CERT_FILE", ""),
+		KeyFile:        config.String("GRPC_TLS_KEY_FILE", ""),
+		ClientCAFile:   config.String("GRPC_TLS_CLIENT_CA_FILE", ""),
+		ReloadInterval: config.Duration("GRPC_TLS_RELOAD_INTERVAL", 30*time.Second),
+	}
+
+	defaultAuth := ClientAuthNone
+	if cfg.ClientCAFile != "" {
+		defaultAuth = ClientAuthOptional
+	}
+	cfg.ClientAuth = config.String("GRPC_TLS_CLIENT_AUTH", defaultAuth)
+
+	var err error
+	if cfg.MinVersion, err = ParseTLSVersion(config.String("GRPC_TLS_MIN_VERSION", "1.2")); err != nil {
+		return TLSConfig{}, err
+	}
+
+	return cfg, cfg.validate()
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+func (c TLSConfig) validate() error {
+	if !c.Enabled() {
+		if c.KeyFile != "" || c.ClientCAFile != "" {
+			return fmt.Errorf("TLS key or client CA given without a certificate")
+		}
+		return nil
+	}
+
+	if c.KeyFile == "" {
+		return fmt.Errorf("TLS certificate given without a key")
+	}
+
+	switch c.ClientAuth {
+	case ClientAuthNone:
+	case ClientAuthOptional, ClientAuthRequire:
+		if c.ClientCAFile == "" {
+			return fmt.Errorf("client auth %q needs a client CA file", c.ClientAuth)
+		}
+	default:
+		return fmt.Errorf("unknown client auth mode %q (want none, optional or require)", c.ClientAuth)
+	}
+
+	return nil
+}
+
+// ParseTLSVersion accepts "1.2" or "1.3".
+func ParseTLSVersion(v string) (uint16, error) {
+	switch v {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q (want 1.2 or 1.3)", v)
+	}
+}
+
+// ServerTLS builds the server configuration. Certificates and client CAs
+// are read from r on every handshake, so reloads apply to new
+// connections without a restart.
+func ServerTLS(cfg TLSConfig, r *CertReloader) *tls.Config {
+	clientAuth := tls.NoClientCert
+	switch cfg.ClientAuth {
+	case ClientAuthOptional:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	base := &tls.Config{
+		MinVersion: cfg.MinVersion,
+		ClientAuth: clientAuth,
+		// Configs returned by GetConfigForClient are used as is, so they
+		// must offer HTTP/2 themselves.
+		NextProtos: []string{"h2"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.Certificate(), nil
+		},
+	}
+
+	return &tls.Config{
+		MinVersion: cfg.MinVersion,
+		NextProtos: base.NextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := base.Clone()
+			c.ClientCAs = r.CAs()
+			return c, nil
+		},
+	}
+}
+
+// ClientTLSConfig configures the client side of TLS.
+type ClientTLSConfig struct {
+	// CAFile verifies the server. Empty uses the system roots.
+	CAFile string
+
+	// CertFile and KeyFile are the client certificate for mutual TLS.
+	CertFile string
+	KeyFile  string
+
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// ClientTLS builds a client configuration. With a client certificate it
+// also returns the reloader serving it; long-lived clients should Watch
+// it so rotated certificates are picked up.
+func ClientTLS(cfg ClientTLSConfig) (*tls.Config, *CertReloader, error) {
+	c := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := LoadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.RootCAs = pool
+	}
+
+	if cfg.CertFile == "" && cfg.KeyFile == "" {
+		return c, nil, nil
+	}
+
+	r, err := NewCertReloader(cfg.CertFile, cfg.KeyFile, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return r.Certificate(), nil
+	}
+
+	return c, r, nil
+}
+
+// LoadCertPool reads the PEM certificates in path into a pool.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s: no certificates found", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,131 @@
+package security
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertReloader holds a key pair and an optional CA pool loaded from
+// files, and reloads them when the files change.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	cert atomic.Pointer[tls.Certificate]
+	cas  atomic.Pointer[x509.CertPool]
+
+	mu    sync.Mutex
+	stamp string
+}
+
+// NewCertReloader loads the files once. caFile may be empty.
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CertReloader) Certificate() *tls.Certificate {
+	return r.cert.Load()
+}
+
+// CAs returns the CA pool, or nil without a CA file.
+func (r *CertReloader) CAs() *x509.CertPool {
+	return r.cas.Load()
+}
+
+// Reload reads the files again. On error the previous certificate and
+// CAs stay in use.
+func (r *CertReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reload()
+}
+
+func (r *CertReloader) reload() error {
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+
+	var cas *x509.CertPool
+	if r.caFile != "" {
+		if cas, err = LoadCertPool(r.caFile); err != nil {
+			return err
+		}
+	}
+
+	r.cert.Store(&cert)
+	r.cas.Store(cas)
+	r.stamp = stamp
+	return nil
+}
+
+// Watch polls the files every interval and reloads them when their size
+// or modification time changes, until ctx is done.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := r.reloadIfChanged()
+		if err != nil {
+			// Usually a rotation caught half way; the next tick retries.
+			log.Printf("⚠️ TLS reload failed, keeping current certificate: %v", err)
+			continue
+		}
+		if changed {
+			log.Printf("🔐 Reloaded TLS certificate %s", r.certFile)
+		}
+	}
+}
+
+func (r *CertReloader) reloadIfChanged() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stamp, err := r.fileStamp()
+	if err != nil || stamp == r.stamp {
+		return false, err
+	}
+
+	return true, r.reload()
+}
+
+func (r *CertReloader) fileStamp() (string, error) {
+	var parts []string
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", path, fi.Size(), fi.ModTime().UnixNano()))
+	}
+	return strings.Join(parts, "|"), nil
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin creates a client span for every GORM statement, tagged with
+// the table and operation.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"INSERT", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"SELECT", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"UPDATE", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"DELETE", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"ROW", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"RAW", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before("tracing:before", p.before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after("tracing:after", p.after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// Only trace queries that belong to a traced request.
+			return
+		}
+
+		table := db.Statement.Table
+		name := operation
+		if table != "" {
+			name += " " + table
+		}
+
+		ctx, span := otel.Tracer(instrumentationName).Start(
+			ctx,
+			name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(table),
+			),
+		)
+
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (GormPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	defer span.End()
+
+	// Table is resolved while the statement runs, so set it again here.
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		semconv.DBResponseReturnedRows(int(db.RowsAffected)),
+	)
+
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		RecordError(span, db.Error)
+	}
+}
@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOTLPEndpoint(t *testing.T) {
+	ctx := context.Background()
+
+	for _, endpoint := range []string{"http://localhost:4317", "https://collector.example.com:4317"} {
+		exporter, err := newExporter(ctx, Config{Exporter: ExporterOTLP, OTLPEndpoint: endpoint})
+		if err != nil {
+			t.Errorf("%s: %v", endpoint, err)
+			continue
+		}
+		_ = exporter.Shutdown(ctx)
+	}
+
+	for _, endpoint := range []string{"localhost:4317", "collector", "grpc://localhost:4317"} {
+		if _, err := newExporter(ctx, Config{Exporter: ExporterOTLP, OTLPEndpoint: endpoint}); err == nil {
+			t.Errorf("%s: accepted an endpoint that is not a URL", endpoint)
+		}
+	}
+}
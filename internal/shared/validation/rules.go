@@ -0,0 +1,108 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Rule checks a field value and returns a description of the problem,
+// or "" if the value is acceptable.
+type Rule func(value string) string
+
+func Required() Rule {
+	return func(v string) string {
+		if strings.TrimSpace(v) == "" {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+// MinLen and MaxLen count characters; MaxBytes counts bytes.
+func MinLen(n int) Rule {
+	return func(v string) string {
+		if utf8.RuneCountInString(v) < n {
+			return fmt.Sprintf("must be at least %d characters", n)
+		}
+		return ""
+	}
+}
+
+func MaxLen(n int) Rule {
+	return func(v string) string {
+		if utf8.RuneCountInString(v) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+func MaxBytes(n int) Rule {
+	return func(v string) string {
+		if len(v) > n {
+			return fmt.Sprintf("must be at most %d bytes", n)
+		}
+		return ""
+	}
+}
+
+func OneOf(allowed ...string) Rule {
+	return func(v string) string {
+		for _, a := range allowed {
+			if v == a {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+func Pattern(re *regexp.Regexp, description string) Rule {
+	return func(v string) string {
+		if !re.MatchString(v) {
+			return description
+		}
+		return ""
+	}
+}
+
+func UUID() Rule {
+	return func(v string) string {
+		if _, err := uuid.Parse(v); err != nil {
+			return "must be a valid UUID"
+		}
+		return ""
+	}
+}
+
+// Email accepts a bare address such as "jo@example.com", without a
+// display name or angle brackets.
+func Email() Rule {
+	return func(v string) string {
+		addr, err := mail.ParseAddress(v)
+		if err != nil || addr.Name != "" || addr.Address != v {
+			return "must be an email address"
+		}
+		return ""
+	}
+}
+
+// Optional applies rules only to non-empty values.
+func Optional(rules ...Rule) Rule {
+	return func(v string) string {
+		if v == "" {
+			return ""
+		}
+		for _, rule := range rules {
+			if desc := rule(v); desc != "" {
+				return desc
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Violation describes a single invalid field.
+type Violation struct {
+	Field       string
+	Description string
+}
+
+// FieldRule binds a set of rules to a proto field by name.
+type FieldRule struct {
+	name  protoreflect.Name
+	rules []Rule
+}
+
+// Field declares the rules for the proto field called name.
+func Field(name string, rules ...Rule) FieldRule {
+	return FieldRule{name: protoreflect.Name(name), rules: rules}
+}
+
+type messageRules struct {
+	fields []boundField
+}
+
+type boundField struct {
+	desc  protoreflect.FieldDescriptor
+	rules []Rule
+}
+
+// Registry holds the rules for each proto message type.
+type Registry struct {
+	mu       sync.RWMutex
+	messages map[protoreflect.FullName]messageRules
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		messages: make(map[protoreflect.FullName]messageRules),
+	}
+}
+
+// Register declares the rules for msg's type. Rules are keyed by message
+// type, so types shared between RPCs, like emptypb.Empty, should not be
+// registered. It panics if the type already has rules or a field does
+// not exist or is not a singular string, so mistakes surface at startup.
+func (r *Registry) Register(msg proto.Message, fields ...FieldRule) {
+	desc := msg.ProtoReflect().Descriptor()
+
+	mr := messageRules{}
+	for _, f := range fields {
+		fd := desc.Fields().ByName(f.name)
+		if fd == nil {
+			panic(fmt.Sprintf("validation: %s has no field %q", desc.FullName(), f.name))
+		}
+		if fd.Kind() != protoreflect.StringKind || fd.IsList() || fd.IsMap() {
+			panic(fmt.Sprintf("validation: %s.%s is not a string field", desc.FullName(), f.name))
+		}
+		mr.fields = append(mr.fields, boundField{desc: fd, rules: f.rules})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.messages[desc.FullName()]; ok {
+		panic(fmt.Sprintf("validation: %s is already registered", desc.FullName()))
+	}
+	r.messages[desc.FullName()] = mr
+}
+
+// Validate checks msg against its registered rules. Messages without
+// rules are considered valid. Only the first failing rule per field is
+// reported.
+func (r *Registry) Validate(msg proto.Message) []Violation {
+	m := msg.ProtoReflect()
+
+	r.mu.RLock()
+	mr, ok := r.messages[m.Descriptor().FullName()]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	for _, f := range mr.fields {
+		value := m.Get(f.desc).String()
+		for _, rule := range f.rules {
+			if desc := rule(value); desc != "" {
+				violations = append(violations, Violation{
+					Field:       string(f.desc.Name()),
+					Description: desc,
+				})
+				break
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,140 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// CBOR major types (RFC 8949 section 3.1).
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborSimple = 7
+)
+
+// maxCBORDepth bounds nesting, which attestation objects and COSE keys
+// keep shallow.
+const maxCBORDepth = 8
+
+var errMalformedCBOR = errors.New("webauthn: malformed CBOR")
+
+// decodeCBOR decodes the first item in b and returns the bytes after it.
+// Integers decode to int64, byte and text strings to []byte and string,
+// arrays to []any and maps to map[any]any. Only the definite-length
+// encodings WebAuthn uses (CTAP2 canonical CBOR) are accepted; tags and
+// floats are not.
+func decodeCBOR(b []byte) (any, []byte, error) {
+	return decodeItem(b, 0)
+}
+
+func decodeItem(b []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth || len(b) == 0 {
+		return nil, nil, errMalformedCBOR
+	}
+
+	major, info := b[0]>>5, b[0]&0x1f
+	b = b[1:]
+
+	if major == cborSimple {
+		switch info {
+		case 20:
+			return false, b, nil
+		case 21:
+			return true, b, nil
+		case 22:
+			return nil, b, nil
+		default:
+			return nil, nil, errMalformedCBOR
+		}
+	}
+
+	arg, b, err := readArgument(info, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return nil, nil, errMalformedCBOR
+		}
+		return int64(arg), b, nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, nil, errMalformedCBOR
+		}
+		return -1 - int64(arg), b, nil
+	case cborBytes, cborText:
+		if arg > uint64(len(b)) {
+			return nil, nil, errMalformedCBOR
+		}
+		s := b[:arg]
+		if major == cborText {
+			return string(s), b[arg:], nil
+		}
+		return append([]byte(nil), s...), b[arg:], nil
+	case cborArray:
+		// Every item takes at least one byte.
+		if arg > uint64(len(b)) {
+			return nil, nil, errMalformedCBOR
+		}
+		items := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item any
+			if item, b, err = decodeItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, b, nil
+	case cborMap:
+		if arg > uint64(len(b))/2 {
+			return nil, nil, errMalformedCBOR
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			var k, v any
+			if k, b, err = decodeItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errMalformedCBOR
+			}
+			if _, dup := m[k]; dup {
+				return nil, nil, errMalformedCBOR
+			}
+			if v, b, err = decodeItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[k] = v
+		}
+		return m, b, nil
+	default:
+		return nil, nil, errMalformedCBOR
+	}
+}
+
+// readArgument reads the integer that follows an initial byte.
+func readArgument(info byte, b []byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), b, nil
+	case info == 24 && len(b) >= 1:
+		return uint64(b[0]), b[1:], nil
+	case info == 25 && len(b) >= 2:
+		return uint64(binary.BigEndian.Uint16(b)), b[2:], nil
+	case info == 26 && len(b) >= 4:
+		return uint64(binary.BigEndian.Uint32(b)), b[4:], nil
+	case info == 27 && len(b) >= 8:
+		return binary.BigEndian.Uint64(b), b[8:], nil
+	default:
+		return 0, nil, errMalformedCBOR
+	}
+}
@@ -0,0 +1,95 @@
+package webauthn
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// Examples from RFC 8949 appendix A.
+	cases := []struct {
+		hex  string
+		want any
+	}{
+		{"00", int64(0)},
+		{"17", int64(23)},
+		{"1818", int64(24)},
+		{"1903e8", int64(1000)},
+		{"1a000f4240", int64(1000000)},
+		{"1b000000e8d4a51000", int64(1000000000000)},
+		{"20", int64(-1)},
+		{"3903e7", int64(-1000)},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"40", []byte(nil)},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"60", ""},
+		{"6449455446", "IETF"},
+		{"62c3bc", "ü"},
+		{"80", []any{}},
+		{"83010203", []any{int64(1), int64(2), int64(3)}},
+		{"8301820203820405", []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{"a0", map[any]any{}},
+		{"a201020304", map[any]any{int64(1): int64(2), int64(3): int64(4)}},
+		{"a26161016162820203", map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+	}
+
+	for _, tc := range cases {
+		b, _ := hex.DecodeString(tc.hex)
+		got, rest, err := decodeCBOR(b)
+		if err != nil {
+			t.Errorf("%s: %v", tc.hex, err)
+			continue
+		}
+		if len(rest) != 0 {
+			t.Errorf("%s: %d bytes left over", tc.hex, len(rest))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s = %#v, want %#v", tc.hex, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeCBORReturnsRest(t *testing.T) {
+	got, rest, err := decodeCBOR([]byte{0x01, 0x02, 0x03})
+	if err != nil || got != int64(1) || len(rest) != 2 {
+		t.Errorf("got %v, rest % x, err %v", got, rest, err)
+	}
+}
+
+func TestDecodeCBORRejects(t *testing.T) {
+	cases := []struct {
+		name string
+		hex  string
+	}{
+		{"empty", ""},
+		{"truncated argument", "19 03"},
+		{"reserved additional info", "1c"},
+		{"indefinite length", "5f 41 01 ff"},
+		{"byte string past the end", "44 0102"},
+		{"array longer than its input", "9a ffffffff 00"},
+		{"map longer than its input", "ba ffffffff 0000"},
+		{"uint past int64", "1b ffffffffffffffff"},
+		{"negative int past int64", "3b ffffffffffffffff"},
+		{"tag", "c1 1a 514b67b0"},
+		{"float", "fa 47c35000"},
+		{"undefined", "f7"},
+		{"byte string map key", "a1 4101 00"},
+		{"duplicate map key", "a2 01 00 01 00"},
+		{"map missing a value", "a1 01"},
+		{"too deep", "81818181818181818100"},
+	}
+
+	for _, tc := range cases {
+		b, err := hex.DecodeString(strings.ReplaceAll(tc.hex, " ", ""))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if _, _, err := decodeCBOR(b); err == nil {
+			t.Errorf("%s: accepted", tc.name)
+		}
+	}
+}
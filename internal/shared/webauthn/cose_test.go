@@ -0,0 +1,183 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"testing"
+)
+
+// coseKey encodes COSE key parameters, given as alternating int64 labels
+// and int64 or []byte values, as a CBOR map.
+func coseKey(params ...any) []byte {
+	b := cborHead(nil, cborMap, uint64(len(params)/2))
+	for _, p := range params {
+		switch v := p.(type) {
+		case int64:
+			if v >= 0 {
+				b = cborHead(b, cborUint, uint64(v))
+			} else {
+				b = cborHead(b, cborNegInt, uint64(-1-v))
+			}
+		case []byte:
+			b = append(cborHead(b, cborBytes, uint64(len(v))), v...)
+		default:
+			panic("coseKey: unsupported value")
+		}
+	}
+	return b
+}
+
+func cborHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= 0xff:
+		return append(b, major<<5|24, byte(n))
+	default:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	}
+}
+
+func ec2Key(pub *ecdsa.PublicKey) []byte {
+	x, y := make([]byte, 32), make([]byte, 32)
+	pub.X.FillBytes(x)
+	pub.Y.FillBytes(y)
+	return coseKey(
+		int64(coseKty), int64(coseKtyEC2),
+		int64(coseAlg), int64(AlgES256),
+		int64(coseCrv), int64(coseCrvP256),
+		int64(coseX), x,
+		int64(coseY), y,
+	)
+}
+
+func rsaKey(pub *rsa.PublicKey) []byte {
+	return coseKey(
+		int64(coseKty), int64(coseKtyRSA),
+		int64(coseAlg), int64(AlgRS256),
+		int64(coseN), pub.N.Bytes(),
+		int64(coseE), big.NewInt(int64(pub.E)).Bytes(),
+	)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	data := []byte("authenticator data || client data hash")
+	digest := sha256.Sum256(data)
+
+	ecPriv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSig, _ := ecdsa.SignASN1(rand.Reader, ecPriv, digest[:])
+
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	edSig := ed25519.Sign(edPriv, data)
+
+	rsaPriv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaSig, _ := rsa.SignPKCS1v15(rand.Reader, rsaPriv, crypto.SHA256, digest[:])
+
+	cases := []struct {
+		name string
+		cose []byte
+		alg  int64
+		sig  []byte
+	}{
+		{"ES256", ec2Key(&ecPriv.PublicKey), AlgES256, ecSig},
+		{"EdDSA", coseKey(
+			int64(coseKty), int64(coseKtyOKP),
+			int64(coseAlg), int64(AlgEdDSA),
+			int64(coseCrv), int64(coseCrvEd25519),
+			int64(coseX), []byte(edPub),
+		), AlgEdDSA, edSig},
+		{"RS256", rsaKey(&rsaPriv.PublicKey), AlgRS256, rsaSig},
+	}
+
+	for _, tc := range cases {
+		key, err := ParsePublicKey(tc.cose)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if key.Alg != tc.alg {
+			t.Errorf("%s: alg = %d", tc.name, key.Alg)
+		}
+		if !key.Verify(data, tc.sig) {
+			t.Errorf("%s: good signature rejected", tc.name)
+		}
+		if key.Verify([]byte("something else"), tc.sig) {
+			t.Errorf("%s: signature verified over other data", tc.name)
+		}
+	}
+
+	// A signature from one key does not verify under another.
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key, err := ParsePublicKey(ec2Key(&other.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Verify(data, ecSig) {
+		t.Error("ES256 signature verified under another key")
+	}
+}
+
+func TestParsePublicKeyRejects(t *testing.T) {
+	ecPriv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	x := make([]byte, 32)
+	ecPriv.X.FillBytes(x)
+
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	shortRSA, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	cases := []struct {
+		name string
+		cose []byte
+	}{
+		{"not CBOR", []byte{0xff}},
+		{"not a map", []byte{0x80}},
+		{"trailing bytes", append(ec2Key(&ecPriv.PublicKey), 0x00)},
+		{"point off the curve", coseKey(
+			int64(coseKty), int64(coseKtyEC2),
+			int64(coseAlg), int64(AlgES256),
+			int64(coseCrv), int64(coseCrvP256),
+			int64(coseX), x,
+			int64(coseY), x,
+		)},
+		{"P-384 coordinates", coseKey(
+			int64(coseKty), int64(coseKtyEC2),
+			int64(coseAlg), int64(AlgES256),
+			int64(coseCrv), int64(2),
+			int64(coseX), p384.X.Bytes(),
+			int64(coseY), p384.Y.Bytes(),
+		)},
+		{"EC2 key claiming RS256", coseKey(
+			int64(coseKty), int64(coseKtyEC2),
+			int64(coseAlg), int64(AlgRS256),
+		)},
+		{"ES384", coseKey(
+			int64(coseKty), int64(coseKtyEC2),
+			int64(coseAlg), int64(-35),
+		)},
+		{"short Ed25519 key", coseKey(
+			int64(coseKty), int64(coseKtyOKP),
+			int64(coseAlg), int64(AlgEdDSA),
+			int64(coseCrv), int64(coseCrvEd25519),
+			int64(coseX), make([]byte, 31),
+		)},
+		{"1024-bit RSA", rsaKey(&shortRSA.PublicKey)},
+		{"RSA without exponent", coseKey(
+			int64(coseKty), int64(coseKtyRSA),
+			int64(coseAlg), int64(AlgRS256),
+			int64(coseN), make([]byte, 256),
+		)},
+	}
+
+	for _, tc := range cases {
+		if _, err := ParsePublicKey(tc.cose); err == nil {
+			t.Errorf("%s: accepted", tc.name)
+		}
+	}
+}
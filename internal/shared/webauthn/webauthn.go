@@ -0,0 +1,523 @@
+// Package webauthn is a minimal WebAuthn relying party (Web
+// Authentication Level 2): registration and authentication ceremonies
+// for passkeys, exchanged with the browser in the JSON forms of
+// PublicKeyCredential.parseCreationOptionsFromJSON() and toJSON().
+//
+// Attestation statements are not verified. Options ask for none, and the
+// portal trusts no authenticator model over another.
+//
+// Package webauthntest provides a virtual authenticator for tests.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"admin-portal/internal/shared/config"
+)
+
+// User verification requirements.
+const (
+	UVRequired    = "required"
+	UVPreferred   = "preferred"
+	UVDiscouraged = "discouraged"
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent     = 0x01
+	flagUserVerified    = 0x04
+	flagBackupEligible  = 0x08
+	flagBackupState     = 0x10
+	flagAttestedData    = 0x40
+	flagExtensionData   = 0x80
+	maxCredentialIDSize = 1023
+)
+
+var (
+	// ErrInvalidResponse is wrapped by every verification failure.
+	ErrInvalidResponse = errors.New("webauthn: invalid response")
+	// ErrSignCount means the authenticator's signature counter went
+	// backwards, a sign that the credential was cloned.
+	ErrSignCount = fmt.Errorf("%w: signature counter did not increase", ErrInvalidResponse)
+)
+
+// Config identifies the relying party. Passkeys are off while RPID is
+// empty.
+type Config struct {
+	// RPID is the domain passkeys are scoped to, e.g. example.com.
+	RPID   string
+	RPName string
+	// Origins are the web origins the ceremonies may run on. Each must be
+	// the RP ID or a subdomain of it.
+	Origins []string
+	// Timeout is how long the browser gives the user to respond.
+	Timeout time.Duration
+}
+
+func LoadConfig() Config {
+	cfg := Config{
+		RPID:    config.String("WEBAUTHN_RP_ID", ""),
+		RPName:  config.String("WEBAUTHN_RP_NAME", "Admin Portal"),
+		Origins: strings.Fields(config.String("WEBAUTHN_ORIGINS", "")),
+		Timeout: config.Duration("WEBAUTHN_TIMEOUT", 5*time.Minute),
+	}
+	if len(cfg.Origins) == 0 && cfg.RPID != "" {
+		cfg.Origins = []string{"https://" + cfg.RPID}
+	}
+	return cfg
+}
+
+func (c Config) Enabled() bool {
+	return c.RPID != ""
+}
+
+func (c Config) Validate() error {
+	if c.Timeout <= 0 {
+		return errors.New("WEBAUTHN_TIMEOUT must be positive")
+	}
+	for _, origin := range c.Origins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host == "" || u.Path != "" {
+			return fmt.Errorf("WEBAUTHN_ORIGINS: %q is not an origin", origin)
+		}
+		host := u.Hostname()
+		if u.Scheme != "https" && !(u.Scheme == "http" && host == "localhost") {
+			return fmt.Errorf("WEBAUTHN_ORIGINS: %q must be https", origin)
+		}
+		if host != c.RPID && !strings.HasSuffix(host, "."+c.RPID) {
+			return fmt.Errorf("WEBAUTHN_ORIGINS: %q is not within WEBAUTHN_RP_ID %q", origin, c.RPID)
+		}
+	}
+	return nil
+}
+
+// RelyingParty builds ceremony options and verifies their responses.
+type RelyingParty struct {
+	cfg      Config
+	rpIDHash [32]byte
+}
+
+func NewRelyingParty(cfg Config) *RelyingParty {
+	return &RelyingParty{cfg: cfg, rpIDHash: sha256.Sum256([]byte(cfg.RPID))}
+}
+
+// Timeout is how long a ceremony may take; challenges should expire
+// with it.
+func (rp *RelyingParty) Timeout() time.Duration {
+	return rp.cfg.Timeout
+}
+
+// NewChallenge returns a random challenge in its base64url form, which is
+// how it appears in options and in the client data.
+func NewChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Bytes is binary data, base64url-encoded in JSON. Padded input is also
+// accepted.
+type Bytes []byte
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+//-------------------- Options --------------------//
+
+// UserEntity is the account a passkey is created for. ID is opaque to
+// the authenticator and comes back as the user handle when signing in.
+type UserEntity struct {
+	ID          Bytes  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialDescriptor names a credential to exclude or allow.
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         Bytes    `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// NewCredentialDescriptor describes a registered credential.
+func NewCredentialDescriptor(id []byte, transports []string) CredentialDescriptor {
+	return CredentialDescriptor{Type: "public-key", ID: id, Transports: transports}
+}
+
+type creationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []credentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey        string `json:"residentKey"`
+		RequireResidentKey bool   `json:"requireResidentKey"`
+		UserVerification   string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+type credentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type requestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// CreationOptions returns the options for navigator.credentials.create().
+// They ask for a discoverable credential, so it can later sign in
+// without a username.
+func (rp *RelyingParty) CreationOptions(challenge string, user UserEntity, exclude []CredentialDescriptor) ([]byte, error) {
+	o := creationOptions{
+		Challenge:          challenge,
+		User:               user,
+		Timeout:            rp.cfg.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		Attestation:        "none",
+	}
+	o.RP.ID = rp.cfg.RPID
+	o.RP.Name = rp.cfg.RPName
+	for _, alg := range SupportedAlgorithms {
+		o.PubKeyCredParams = append(o.PubKeyCredParams, credentialParameter{Type: "public-key", Alg: alg})
+	}
+	if o.ExcludeCredentials == nil {
+		o.ExcludeCredentials = []CredentialDescriptor{}
+	}
+	o.AuthenticatorSelection.ResidentKey = "preferred"
+	o.AuthenticatorSelection.UserVerification = UVPreferred
+	return json.Marshal(o)
+}
+
+// RequestOptions returns the options for navigator.credentials.get(). An
+// empty allow list lets the user pick any discoverable credential.
+func (rp *RelyingParty) RequestOptions(challenge string, allow []CredentialDescriptor, userVerification string) ([]byte, error) {
+	o := requestOptions{
+		Challenge:        challenge,
+		RPID:             rp.cfg.RPID,
+		Timeout:          rp.cfg.Timeout.Milliseconds(),
+		AllowCredentials: allow,
+		UserVerification: userVerification,
+	}
+	if o.AllowCredentials == nil {
+		o.AllowCredentials = []CredentialDescriptor{}
+	}
+	return json.Marshal(o)
+}
+
+//-------------------- Responses --------------------//
+
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+type credentialJSON struct {
+	ID       string `json:"id"`
+	RawID    Bytes  `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Bytes    `json:"clientDataJSON"`
+		AttestationObject Bytes    `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+		AuthenticatorData Bytes    `json:"authenticatorData"`
+		Signature         Bytes    `json:"signature"`
+		UserHandle        Bytes    `json:"userHandle"`
+	} `json:"response"`
+}
+
+func parseCredential(data []byte) (*credentialJSON, *clientData, error) {
+	var c credentialJSON
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if c.Type != "public-key" || len(c.RawID) == 0 || c.ID != base64.RawURLEncoding.EncodeToString(c.RawID) {
+		return nil, nil, fmt.Errorf("%w: not a public key credential", ErrInvalidResponse)
+	}
+
+	var cd clientData
+	if err := json.Unmarshal(c.Response.ClientDataJSON, &cd); err != nil {
+		return nil, nil, fmt.Errorf("%w: client data: %v", ErrInvalidResponse, err)
+	}
+	return &c, &cd, nil
+}
+
+// Registration is the response to a registration ceremony.
+type Registration struct {
+	CredentialID []byte
+	// Challenge is the challenge the browser signed, to look up the
+	// ceremony by.
+	Challenge  string
+	Transports []string
+
+	clientDataJSON    []byte
+	clientData        *clientData
+	attestationObject []byte
+}
+
+// ParseRegistration decodes the JSON of the PublicKeyCredential returned
+// by navigator.credentials.create().
+func ParseRegistration(data []byte) (*Registration, error) {
+	c, cd, err := parseCredential(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Response.AttestationObject) == 0 {
+		return nil, fmt.Errorf("%w: no attestation object", ErrInvalidResponse)
+	}
+	return &Registration{
+		CredentialID:      c.RawID,
+		Challenge:         cd.Challenge,
+		Transports:        c.Response.Transports,
+		clientDataJSON:    c.Response.ClientDataJSON,
+		clientData:        cd,
+		attestationObject: c.Response.AttestationObject,
+	}, nil
+}
+
+// Credential is a verified new credential.
+type Credential struct {
+	ID []byte
+	// PublicKey is the COSE_Key, as ParsePublicKey takes it.
+	PublicKey  []byte
+	SignCount  uint32
+	AAGUID     []byte
+	Transports []string
+
+	UserVerified   bool
+	BackupEligible bool
+	BackupState    bool
+}
+
+// VerifyRegistration checks a registration response against the
+// challenge the ceremony was started with (section 7.1).
+func (rp *RelyingParty) VerifyRegistration(r *Registration, challenge string, userVerification string) (*Credential, error) {
+	if err := rp.checkClientData(r.clientData, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	v, rest, err := decodeCBOR(r.attestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: attestation object: %v", ErrInvalidResponse, errMalformedCBOR)
+	}
+	att, ok := v.(map[any]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation object is not a map", ErrInvalidResponse)
+	}
+	authData, ok := att["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation object has no authData", ErrInvalidResponse)
+	}
+
+	ad, err := rp.parseAuthData(authData, userVerification)
+	if err != nil {
+		return nil, err
+	}
+	if ad.flags&flagAttestedData == 0 {
+		return nil, fmt.Errorf("%w: no attested credential data", ErrInvalidResponse)
+	}
+	if !bytes.Equal(ad.credentialID, r.CredentialID) {
+		return nil, fmt.Errorf("%w: credential ID does not match", ErrInvalidResponse)
+	}
+
+	key, err := ParsePublicKey(ad.publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if !slices.Contains(SupportedAlgorithms, key.Alg) {
+		return nil, fmt.Errorf("%w: algorithm %d was not offered", ErrInvalidResponse, key.Alg)
+	}
+
+	return &Credential{
+		ID:             ad.credentialID,
+		PublicKey:      ad.publicKey,
+		SignCount:      ad.signCount,
+		AAGUID:         ad.aaguid,
+		Transports:     r.Transports,
+		UserVerified:   ad.flags&flagUserVerified != 0,
+		BackupEligible: ad.flags&flagBackupEligible != 0,
+		BackupState:    ad.flags&flagBackupState != 0,
+	}, nil
+}
+
+// Assertion is the response to an authentication ceremony.
+type Assertion struct {
+	CredentialID []byte
+	// UserHandle is the user ID the credential was created with. It is
+	// always set for discoverable credentials.
+	UserHandle []byte
+	Challenge  string
+
+	clientDataJSON    []byte
+	clientData        *clientData
+	authenticatorData []byte
+	signature         []byte
+}
+
+// ParseAssertion decodes the JSON of the PublicKeyCredential returned by
+// navigator.credentials.get().
+func ParseAssertion(data []byte) (*Assertion, error) {
+	c, cd, err := parseCredential(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Response.AuthenticatorData) == 0 || len(c.Response.Signature) == 0 {
+		return nil, fmt.Errorf("%w: no authenticator data or signature", ErrInvalidResponse)
+	}
+	return &Assertion{
+		CredentialID:      c.RawID,
+		UserHandle:        c.Response.UserHandle,
+		Challenge:         cd.Challenge,
+		clientDataJSON:    c.Response.ClientDataJSON,
+		clientData:        cd,
+		authenticatorData: c.Response.AuthenticatorData,
+		signature:         c.Response.Signature,
+	}, nil
+}
+
+// VerifyAssertion checks an authentication response against the
+// challenge and the stored credential (section 7.2), and returns the new
+// signature counter to store.
+func (rp *RelyingParty) VerifyAssertion(
+	a *Assertion,
+	challenge string,
+	publicKey []byte,
+	signCount uint32,
+	userVerification string,
+) (uint32, error) {
+	if err := rp.checkClientData(a.clientData, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	ad, err := rp.parseAuthData(a.authenticatorData, userVerification)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(a.clientDataJSON)
+	signed := append(append([]byte(nil), a.authenticatorData...), clientDataHash[:]...)
+	if !key.Verify(signed, a.signature) {
+		return 0, fmt.Errorf("%w: bad signature", ErrInvalidResponse)
+	}
+
+	// Authenticators that keep no counter always report zero.
+	if (ad.signCount != 0 || signCount != 0) && ad.signCount <= signCount {
+		return 0, ErrSignCount
+	}
+	return ad.signCount, nil
+}
+
+func (rp *RelyingParty) checkClientData(cd *clientData, typ, challenge string) error {
+	switch {
+	case cd.Type != typ:
+		return fmt.Errorf("%w: client data type %q", ErrInvalidResponse, cd.Type)
+	case challenge == "" || cd.Challenge != challenge:
+		return fmt.Errorf("%w: challenge does not match", ErrInvalidResponse)
+	case !slices.Contains(rp.cfg.Origins, cd.Origin):
+		return fmt.Errorf("%w: origin %q is not allowed", ErrInvalidResponse, cd.Origin)
+	case cd.CrossOrigin:
+		return fmt.Errorf("%w: cross-origin ceremony", ErrInvalidResponse)
+	}
+	return nil
+}
+
+type authData struct {
+	flags     byte
+	signCount uint32
+
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// parseAuthData decodes authenticator data (section 6.1) and checks the
+// RP ID hash and user presence and verification flags.
+func (rp *RelyingParty) parseAuthData(b []byte, userVerification string) (*authData, error) {
+	if len(b) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrInvalidResponse)
+	}
+	if !bytes.Equal(b[:32], rp.rpIDHash[:]) {
+		return nil, fmt.Errorf("%w: RP ID does not match", ErrInvalidResponse)
+	}
+
+	ad := &authData{flags: b[32], signCount: binary.BigEndian.Uint32(b[33:37])}
+	if ad.flags&flagUserPresent == 0 {
+		return nil, fmt.Errorf("%w: user not present", ErrInvalidResponse)
+	}
+	if userVerification == UVRequired && ad.flags&flagUserVerified == 0 {
+		return nil, fmt.Errorf("%w: user not verified", ErrInvalidResponse)
+	}
+
+	rest := b[37:]
+	if ad.flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: attested credential data too short", ErrInvalidResponse)
+		}
+		ad.aaguid = rest[:16]
+		n := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if n == 0 || n > maxCredentialIDSize || len(rest) < n {
+			return nil, fmt.Errorf("%w: bad credential ID length", ErrInvalidResponse)
+		}
+		ad.credentialID, rest = rest[:n], rest[n:]
+
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: credential public key: %v", ErrInvalidResponse, err)
+		}
+		ad.publicKey, rest = rest[:len(rest)-len(after)], after
+	}
+	if ad.flags&flagExtensionData != 0 {
+		var err error
+		if _, rest, err = decodeCBOR(rest); err != nil {
+			return nil, fmt.Errorf("%w: extensions: %v", ErrInvalidResponse, err)
+		}
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: trailing authenticator data", ErrInvalidResponse)
+	}
+	return ad, nil
+}
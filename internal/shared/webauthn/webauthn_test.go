@@ -0,0 +1,210 @@
+package webauthn_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"admin-portal/internal/shared/webauthn"
+	"admin-portal/internal/shared/webauthn/webauthntest"
+)
+
+const origin = "https://portal.example.com"
+
+func newRP(rpID string) *webauthn.RelyingParty {
+	return webauthn.NewRelyingParty(webauthn.Config{
+		RPID:    rpID,
+		RPName:  "Admin Portal",
+		Origins: []string{origin},
+		Timeout: time.Minute,
+	})
+}
+
+func challenge(t *testing.T) string {
+	t.Helper()
+	c, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// register runs a registration ceremony and returns the verified
+// credential.
+func register(t *testing.T, rp *webauthn.RelyingParty, a *webauthntest.Authenticator) *webauthn.Credential {
+	t.Helper()
+
+	ch := challenge(t)
+	options, err := rp.CreationOptions(ch, webauthn.UserEntity{ID: []byte("user-1"), Name: "alice"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := a.Create(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, err := webauthn.ParseRegistration(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reg.Challenge != ch {
+		t.Errorf("registration challenge = %q, want %q", reg.Challenge, ch)
+	}
+
+	cred, err := rp.VerifyRegistration(reg, ch, webauthn.UVPreferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cred
+}
+
+// assert runs an authentication ceremony up to the parsed assertion.
+func assert(t *testing.T, rp *webauthn.RelyingParty, a *webauthntest.Authenticator, ch string, allow ...webauthn.CredentialDescriptor) *webauthn.Assertion {
+	t.Helper()
+
+	options, err := rp.RequestOptions(ch, allow, webauthn.UVPreferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := a.Get(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertion, err := webauthn.ParseAssertion(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assertion
+}
+
+func TestCeremonies(t *testing.T) {
+	rp := newRP("portal.example.com")
+	a := webauthntest.New(origin)
+
+	cred := register(t, rp, a)
+	if len(cred.ID) == 0 || !cred.UserVerified || cred.SignCount != 0 {
+		t.Errorf("credential = %+v", cred)
+	}
+	if _, err := webauthn.ParsePublicKey(cred.PublicKey); err != nil {
+		t.Errorf("stored public key does not parse: %v", err)
+	}
+
+	count := cred.SignCount
+	for i := 0; i < 2; i++ {
+		ch := challenge(t)
+		assertion := assert(t, rp, a, ch, webauthn.NewCredentialDescriptor(cred.ID, cred.Transports))
+		if string(assertion.UserHandle) != "user-1" {
+			t.Errorf("user handle = %q", assertion.UserHandle)
+		}
+
+		next, err := rp.VerifyAssertion(assertion, ch, cred.PublicKey, count, webauthn.UVRequired)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next <= count {
+			t.Errorf("sign count went from %d to %d", count, next)
+		}
+		count = next
+	}
+}
+
+func TestVerifyRegistrationRejects(t *testing.T) {
+	rp := newRP("portal.example.com")
+
+	cases := []struct {
+		name      string
+		rp        *webauthn.RelyingParty
+		origin    string
+		challenge func(issued string) string
+		uv        bool
+		want      string
+	}{
+		{"wrong challenge", rp, origin, func(string) string { return "another-challenge" }, true, webauthn.UVPreferred},
+		{"no challenge", rp, origin, func(string) string { return "" }, true, webauthn.UVPreferred},
+		{"foreign origin", rp, "https://evil.example.net", func(c string) string { return c }, true, webauthn.UVPreferred},
+		{"other RP ID", newRP("example.com"), origin, func(c string) string { return c }, true, webauthn.UVPreferred},
+		{"user not verified", rp, origin, func(c string) string { return c }, false, webauthn.UVRequired},
+	}
+
+	for _, tc := range cases {
+		a := webauthntest.New(tc.origin)
+		a.SetUserVerified(tc.uv)
+
+		ch := challenge(t)
+		options, err := rp.CreationOptions(ch, webauthn.UserEntity{ID: []byte("user-1"), Name: "alice"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := a.Create(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reg, err := webauthn.ParseRegistration(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tc.rp.VerifyRegistration(reg, tc.challenge(ch), tc.want); !errors.Is(err, webauthn.ErrInvalidResponse) {
+			t.Errorf("%s: err = %v, want ErrInvalidResponse", tc.name, err)
+		}
+	}
+}
+
+func TestVerifyAssertionRejects(t *testing.T) {
+	rp := newRP("portal.example.com")
+	a := webauthntest.New(origin)
+	cred := register(t, rp, a)
+	allow := webauthn.NewCredentialDescriptor(cred.ID, nil)
+
+	ch := challenge(t)
+	assertion := assert(t, rp, a, ch, allow)
+	if _, err := rp.VerifyAssertion(assertion, challenge(t), cred.PublicKey, 0, webauthn.UVPreferred); !errors.Is(err, webauthn.ErrInvalidResponse) {
+		t.Errorf("wrong challenge: err = %v", err)
+	}
+
+	// Signed by a different credential.
+	other := register(t, rp, webauthntest.New(origin))
+	if _, err := rp.VerifyAssertion(assertion, ch, other.PublicKey, 0, webauthn.UVPreferred); !errors.Is(err, webauthn.ErrInvalidResponse) {
+		t.Errorf("wrong key: err = %v", err)
+	}
+
+	// Replayed: the stored counter is already past the response's.
+	count, err := rp.VerifyAssertion(assertion, ch, cred.PublicKey, 0, webauthn.UVPreferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rp.VerifyAssertion(assertion, ch, cred.PublicKey, count, webauthn.UVPreferred); !errors.Is(err, webauthn.ErrSignCount) {
+		t.Errorf("replay: err = %v, want ErrSignCount", err)
+	}
+
+	a.SetUserVerified(false)
+	ch = challenge(t)
+	assertion = assert(t, rp, a, ch, allow)
+	if _, err := rp.VerifyAssertion(assertion, ch, cred.PublicKey, count, webauthn.UVRequired); !errors.Is(err, webauthn.ErrInvalidResponse) {
+		t.Errorf("user not verified: err = %v", err)
+	}
+	if _, err := rp.VerifyAssertion(assertion, ch, cred.PublicKey, count, webauthn.UVPreferred); err != nil {
+		t.Errorf("unverified user rejected though verification was only preferred: %v", err)
+	}
+
+	if _, err := newRP("example.com").VerifyAssertion(assert(t, rp, a, ch, allow), ch, cred.PublicKey, 0, webauthn.UVPreferred); !errors.Is(err, webauthn.ErrInvalidResponse) {
+		t.Errorf("other RP ID: err = %v", err)
+	}
+}
+
+func TestParseResponsesRejectMalformedJSON(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"type":"public-key","id":"AQ","rawId":"AQ","response":{"clientDataJSON":"e30"}}`,
+		`{"type":"password","id":"AQ","rawId":"AQ","response":{"clientDataJSON":"e30","attestationObject":"oA","authenticatorData":"AA","signature":"AA"}}`,
+		`{"type":"public-key","id":"AgM","rawId":"AQ","response":{"clientDataJSON":"e30","attestationObject":"oA","authenticatorData":"AA","signature":"AA"}}`,
+		`{"type":"public-key","id":"AQ","rawId":"AQ","response":{"clientDataJSON":"bm90IGpzb24","attestationObject":"oA","authenticatorData":"AA","signature":"AA"}}`,
+	} {
+		if _, err := webauthn.ParseRegistration([]byte(data)); !errors.Is(err, webauthn.ErrInvalidResponse) {
+			t.Errorf("registration %s: err = %v", data, err)
+		}
+		if _, err := webauthn.ParseAssertion([]byte(data)); !errors.Is(err, webauthn.ErrInvalidResponse) {
+			t.Errorf("assertion %s: err = %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Package webauthntest is a virtual authenticator. It answers the
+// options a webauthn.RelyingParty builds the way a browser and a passkey
+// would, which is enough to test registration and login.
+//
+//	a := webauthntest.New("https://portal.example.com")
+//	resp, _ := a.Create(creationOptionsJSON)
+//	...
+//	resp, _ = a.Get(requestOptionsJSON)
+//
+// Credentials are ES256 keys held in memory. Attestation is always none.
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"admin-portal/internal/shared/webauthn"
+)
+
+// Authenticator holds passkeys for any number of relying parties and
+// users.
+type Authenticator struct {
+	origin string
+
+	mu           sync.Mutex
+	creds        []*credential
+	userVerified bool
+}
+
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+// New returns an authenticator reached from pages on origin.
+func New(origin string) *Authenticator {
+	return &Authenticator{origin: origin, userVerified: true}
+}
+
+// SetUserVerified controls whether responses claim user verification, as
+// if the user entered a PIN or used a biometric. It is on by default.
+func (a *Authenticator) SetUserVerified(uv bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.userVerified = uv
+}
+
+type creationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID string `json:"id"`
+	} `json:"rp"`
+	User struct {
+		ID webauthn.Bytes `json:"id"`
+	} `json:"user"`
+	PubKeyCredParams []struct {
+		Alg int64 `json:"alg"`
+	} `json:"pubKeyCredParams"`
+	ExcludeCredentials []webauthn.CredentialDescriptor `json:"excludeCredentials"`
+}
+
+type requestOptions struct {
+	Challenge        string                          `json:"challenge"`
+	RPID             string                          `json:"rpId"`
+	AllowCredentials []webauthn.CredentialDescriptor `json:"allowCredentials"`
+}
+
+type response struct {
+	ClientDataJSON    webauthn.Bytes `json:"clientDataJSON"`
+	AttestationObject webauthn.Bytes `json:"attestationObject,omitempty"`
+	Transports        []string       `json:"transports,omitempty"`
+	AuthenticatorData webauthn.Bytes `json:"authenticatorData,omitempty"`
+	Signature         webauthn.Bytes `json:"signature,omitempty"`
+	UserHandle        webauthn.Bytes `json:"userHandle,omitempty"`
+}
+
+type publicKeyCredential struct {
+	ID       string         `json:"id"`
+	RawID    webauthn.Bytes `json:"rawId"`
+	Type     string         `json:"type"`
+	Response response       `json:"response"`
+}
+
+// Create makes a new credential for creation options and returns the
+// registration response JSON.
+func (a *Authenticator) Create(options []byte) ([]byte, error) {
+	var o creationOptions
+	if err := json.Unmarshal(options, &o); err != nil {
+		return nil, err
+	}
+
+	offered := false
+	for _, p := range o.PubKeyCredParams {
+		offered = offered || p.Alg == webauthn.AlgES256
+	}
+	if !offered {
+		return nil, errors.New("webauthntest: ES256 not offered")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, ex := range o.ExcludeCredentials {
+		if a.find(o.RP.ID, ex.ID) != nil {
+			return nil, errors.New("webauthntest: credential already registered")
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	c := &credential{id: id, rpID: o.RP.ID, userHandle: o.User.ID, key: key}
+	a.creds = append(a.creds, c)
+
+	ecdh, err := key.PublicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	point := ecdh.Bytes() // 0x04 || X || Y
+	coseKey := encodeMap(
+		[2]any{int64(1), int64(2)},                 // kty: EC2
+		[2]any{int64(3), int64(webauthn.AlgES256)}, // alg
+		[2]any{int64(-1), int64(1)},                // crv: P-256
+		[2]any{int64(-2), point[1:33]},             // x
+		[2]any{int64(-3), point[33:65]},            // y
+	)
+
+	attested := make([]byte, 0, 18+len(id)+len(coseKey))
+	attested = append(attested, make([]byte, 16)...) // AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(id)))
+	attested = append(attested, id...)
+	attested = append(attested, coseKey...)
+
+	authData := a.authData(o.RP.ID, 0x40, c.signCount, attested)
+	attObj := encodeMap(
+		[2]any{"fmt", "none"},
+		[2]any{"attStmt", encodedMap{}},
+		[2]any{"authData", authData},
+	)
+
+	return json.Marshal(publicKeyCredential{
+		ID:    base64.RawURLEncoding.EncodeToString(id),
+		RawID: id,
+		Type:  "public-key",
+		Response: response{
+			ClientDataJSON:    a.clientData("webauthn.create", o.Challenge),
+			AttestationObject: attObj,
+			Transports:        []string{"internal"},
+		},
+	})
+}
+
+// Get signs request options with a matching credential and returns the
+// authentication response JSON. With an empty allow list it uses the
+// most recent credential for the RP ID.
+func (a *Authenticator) Get(options []byte) ([]byte, error) {
+	var o requestOptions
+	if err := json.Unmarshal(options, &o); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var c *credential
+	for _, allowed := range o.AllowCredentials {
+		if c = a.find(o.RPID, allowed.ID); c != nil {
+			break
+		}
+	}
+	if len(o.AllowCredentials) == 0 {
+		for _, cand := range a.creds {
+			if cand.rpID == o.RPID {
+				c = cand
+			}
+		}
+	}
+	if c == nil {
+		return nil, errors.New("webauthntest: no matching credential")
+	}
+
+	c.signCount++
+	authData := a.authData(o.RPID, 0, c.signCount, nil)
+	clientData := a.clientData("webauthn.get", o.Challenge)
+
+	hash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), hash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(publicKeyCredential{
+		ID:    base64.RawURLEncoding.EncodeToString(c.id),
+		RawID: c.id,
+		Type:  "public-key",
+		Response: response{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authData,
+			Signature:         sig,
+			UserHandle:        c.userHandle,
+		},
+	})
+}
+
+func (a *Authenticator) find(rpID string, id []byte) *credential {
+	for _, c := range a.creds {
+		if c.rpID == rpID && string(c.id) == string(id) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) authData(rpID string, flags byte, signCount uint32, attested []byte) []byte {
+	flags |= 0x01 // UP
+	if a.userVerified {
+		flags |= 0x04
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	b := append([]byte(nil), rpIDHash[:]...)
+	b = append(b, flags)
+	b = binary.BigEndian.AppendUint32(b, signCount)
+	return append(b, attested...)
+}
+
+func (a *Authenticator) clientData(typ, challenge string) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"type":        typ,
+		"challenge":   challenge,
+		"origin":      a.origin,
+		"crossOrigin": false,
+	})
+	return b
+}
+
+//-------------------- CBOR --------------------//
+
+// encodedMap is a CBOR map already encoded, or an empty one.
+type encodedMap []byte
+
+// encodeMap encodes key/value pairs in the order given, which callers
+// keep canonical.
+func encodeMap(pairs ...[2]any) []byte {
+	b := appendHead(nil, 5, uint64(len(pairs)))
+	for _, p := range pairs {
+		b = appendItem(b, p[0])
+		b = appendItem(b, p[1])
+	}
+	return b
+}
+
+func appendItem(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case int64:
+		if v >= 0 {
+			return appendHead(b, 0, uint64(v))
+		}
+		return appendHead(b, 1, uint64(-1-v))
+	case string:
+		return append(appendHead(b, 3, uint64(len(v))), v...)
+	case []byte:
+		return append(appendHead(b, 2, uint64(len(v))), v...)
+	case encodedMap:
+		if len(v) == 0 {
+			return appendHead(b, 5, 0)
+		}
+		return append(b, v...)
+	default:
+		panic("webauthntest: cannot encode value")
+	}
+}
+
+func appendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= 0xff:
+		return append(b, major<<5|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	}
+}
@@ -0,0 +1,299 @@
+// Package testharness boots the API against a throwaway Postgres for
+// integration tests. Each Harness gets its own database, cloned from a
+// migrated template, and talks to the real interceptor chain over an
+// in-memory bufconn listener.
+//
+//	func TestMain(m *testing.M) { testharness.Main(m) }
+//
+//	func TestLogin(t *testing.T) {
+//		h := testharness.New(t)
+//		s := h.LoginAs(t, model.RoleAdmin)
+//		_, err := h.Auth.Deactivate(s.Context(ctx), &authpb.DeactivateRequest{...})
+//	}
+package testharness
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/app"
+	"admin-portal/internal/shared/database"
+	sharedgrpc "admin-portal/internal/shared/grpc"
+	"admin-portal/internal/shared/security"
+	webhookservice "admin-portal/internal/webhook-module/service"
+	"admin-portal/migrations"
+	authpb "admin-portal/proto/auth"
+	jobpb "admin-portal/proto/job"
+	webhookpb "admin-portal/proto/webhook"
+)
+
+const (
+	templateDB = "harness_template"
+	bufSize    = 1 << 20
+)
+
+// Harness is one isolated API instance.
+type Harness struct {
+	App  *app.App
+	DB   *gorm.DB
+	JWT  security.JWTConfig
+	Conn *grpc.ClientConn
+
+	Auth     authpb.AuthServiceClient
+	Jobs     jobpb.JobServiceClient
+	Webhooks webhookpb.WebhookServiceClient
+
+	users atomic.Int64
+}
+
+// cluster is the Postgres shared by every harness in the test binary.
+var cluster struct {
+	mu      sync.Mutex
+	pg      *Postgres
+	err     error
+	managed bool // stopped by Main rather than by the first harness
+	dbs     int
+}
+
+// Main starts one Postgres for the whole test binary, runs the tests and
+// stops it. Without it, each harness starts (and stops) its own cluster.
+func Main(m *testing.M) {
+	cluster.mu.Lock()
+	cluster.managed = true
+	cluster.mu.Unlock()
+
+	code := m.Run()
+
+	cluster.mu.Lock()
+	if cluster.pg != nil {
+		if err := cluster.pg.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "testharness: stop postgres: %v\n", err)
+		}
+	}
+	cluster.mu.Unlock()
+
+	os.Exit(code)
+}
+
+// Option adjusts the dependencies the API is built from, e.g. to turn on
+// LDAP or single sign-on.
+type Option func(*app.Deps)
+
+// New boots the API on a fresh database. Tests are skipped when no
+// Postgres installation is available.
+func New(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+
+	ctx := context.Background()
+	db := NewDB(t)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("testharness: %v", err)
+	}
+	cipher, err := security.NewCipher(key)
+	if err != nil {
+		t.Fatalf("testharness: %v", err)
+	}
+
+	jwtCfg := security.JWTConfig{
+		Secret:          "testharness-secret",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 7 * 24 * time.Hour,
+		Issuer:          "admin-portal",
+	}
+
+	deps := app.Deps{
+		DB:     db,
+		JWT:    jwtCfg,
+		Cipher: cipher,
+		Webhook: webhookservice.Config{
+			Timeout:          5 * time.Second,
+			MaxAttempts:      3,
+			MaxResponseBytes: 4096,
+		},
+	}
+	for _, opt := range opts {
+		opt(&deps)
+	}
+	api := app.New(serverConfig(), deps)
+
+	lis := bufconn.Listen(bufSize)
+	serveCtx, stop := context.WithCancel(ctx)
+	served := make(chan error, 1)
+	go func() { served <- api.Server.Serve(serveCtx, lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		stop()
+		t.Fatalf("testharness: dial: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		stop()
+		if err := <-served; err != nil {
+			t.Errorf("testharness: serve: %v", err)
+		}
+	})
+
+	return &Harness{
+		App:      api,
+		DB:       db,
+		JWT:      jwtCfg,
+		Conn:     conn,
+		Auth:     authpb.NewAuthServiceClient(conn),
+		Jobs:     jobpb.NewJobServiceClient(conn),
+		Webhooks: webhookpb.NewWebhookServiceClient(conn),
+	}
+}
+
+// NewDB returns a connection to a fresh, migrated database without
+// booting the API, for repository-level tests.
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	pg, dbName, err := createDatabase(context.Background(), t)
+	if errors.Is(err, ErrNoPostgres) {
+		t.Skipf("testharness: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("testharness: %v", err)
+	}
+
+	db, err := database.OpenGorm(pg.Config(dbName))
+	if err != nil {
+		t.Fatalf("testharness: open %s: %v", dbName, err)
+	}
+	t.Cleanup(func() {
+		_ = database.CloseGorm(db)
+		dropDatabase(pg, dbName)
+	})
+
+	return db
+}
+
+func serverConfig() sharedgrpc.Config {
+	return sharedgrpc.Config{
+		MaxRecvMsgSize:    4 << 20,
+		MaxSendMsgSize:    4 << 20,
+		KeepaliveTime:     time.Hour,
+		KeepaliveTimeout:  20 * time.Second,
+		KeepaliveMinTime:  time.Minute,
+		MaxConnectionIdle: time.Hour,
+		ShutdownTimeout:   5 * time.Second,
+	}
+}
+
+//-------------------- Databases --------------------//
+
+// createDatabase clones the migrated template into a new database,
+// starting the cluster and building the template on first use.
+func createDatabase(ctx context.Context, t testing.TB) (*Postgres, string, error) {
+	cluster.mu.Lock()
+	defer cluster.mu.Unlock()
+
+	pg := cluster.pg
+	if !cluster.managed {
+		// No Main: the cluster belongs to this harness alone.
+		var err error
+		if pg, err = startCluster(ctx); err != nil {
+			return nil, "", err
+		}
+		t.Cleanup(func() { _ = pg.Stop() })
+	} else if pg == nil {
+		if cluster.err != nil {
+			return nil, "", cluster.err
+		}
+		if cluster.pg, cluster.err = startCluster(ctx); cluster.err != nil {
+			return nil, "", cluster.err
+		}
+		pg = cluster.pg
+	}
+
+	cluster.dbs++
+	name := fmt.Sprintf("harness_%d_%d", os.Getpid(), cluster.dbs)
+
+	admin, err := pg.Open("postgres")
+	if err != nil {
+		return nil, "", err
+	}
+	defer admin.Close()
+
+	if _, err := admin.ExecContext(ctx,
+		fmt.Sprintf(`CREATE DATABASE %q TEMPLATE %q`, name, templateDB)); err != nil {
+		return nil, "", fmt.Errorf("create database %s: %w", name, err)
+	}
+
+	return pg, name, nil
+}
+
+// startCluster starts Postgres and migrates the template database.
+func startCluster(ctx context.Context) (*Postgres, error) {
+	pg, err := StartPostgres(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateTemplate(ctx, pg); err != nil {
+		_ = pg.Stop()
+		return nil, err
+	}
+
+	return pg, nil
+}
+
+func migrateTemplate(ctx context.Context, pg *Postgres) error {
+	admin, err := pg.Open("postgres")
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	if _, err := admin.ExecContext(ctx,
+		fmt.Sprintf(`CREATE DATABASE %q`, templateDB)); err != nil {
+		return fmt.Errorf("create template: %w", err)
+	}
+
+	// The template must have no open connections when it is cloned, so
+	// migrate through a pool that is closed before returning.
+	db, err := pg.Open(templateDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := database.MigrateUp(ctx, db, migrations.FS, database.MigrateOptions{Out: io.Discard}); err != nil {
+		return fmt.Errorf("migrate template: %w", err)
+	}
+
+	return nil
+}
+
+func dropDatabase(pg *Postgres, name string) {
+	admin, err := pg.Open("postgres")
+	if err != nil {
+		return
+	}
+	defer admin.Close()
+
+	_, _ = admin.Exec(fmt.Sprintf(`DROP DATABASE IF EXISTS %q`, name))
+}
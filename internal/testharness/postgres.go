@@ -0,0 +1,219 @@
+package testharness
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"admin-portal/internal/shared/database"
+)
+
+// ErrNoPostgres is returned when no usable Postgres installation is found.
+// Tests treat it as a reason to skip rather than fail.
+var ErrNoPostgres = errors.New("postgres binaries not found (set PG_BIN)")
+
+const (
+	pgUser     = "postgres"
+	pgPassword = "postgres"
+	pgPort     = "5432"
+
+	startTimeout = 30 * time.Second
+)
+
+// Postgres is a throwaway cluster in a temp directory. It only listens on
+// a unix socket, so parallel test binaries never fight over ports.
+type Postgres struct {
+	dir       string
+	socketDir string
+	cmd       *exec.Cmd
+	exited    chan error
+}
+
+// StartPostgres runs initdb and starts a server from the binaries in
+// PG_BIN, on PATH, or under /usr/lib/postgresql/*/bin.
+func StartPostgres(ctx context.Context) (*Postgres, error) {
+	if os.Geteuid() == 0 {
+		return nil, fmt.Errorf("%w: postgres refuses to run as root", ErrNoPostgres)
+	}
+
+	binDir, err := findBinDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "pgharness-")
+	if err != nil {
+		return nil, err
+	}
+	p := &Postgres{
+		dir:       dir,
+		socketDir: dir,
+		exited:    make(chan error, 1),
+	}
+	dataDir := filepath.Join(dir, "data")
+	logPath := filepath.Join(dir, "postgres.log")
+
+	initdb := exec.CommandContext(ctx, filepath.Join(binDir, "initdb"),
+		"-D", dataDir,
+		"-U", pgUser,
+		"-A", "trust",
+		"-E", "UTF8",
+		"--no-sync",
+	)
+	if out, err := initdb.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb: %w\n%s", err, out)
+	}
+
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	defer logFile.Close()
+
+	p.cmd = exec.Command(filepath.Join(binDir, "postgres"),
+		"-D", dataDir,
+		"-k", p.socketDir,
+		"-p", pgPort,
+		"-c", "listen_addresses=",
+		"-c", "fsync=off",
+		"-c", "synchronous_commit=off",
+		"-c", "full_page_writes=off",
+	)
+	p.cmd.Stdout = logFile
+	p.cmd.Stderr = logFile
+	if err := p.cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("start postgres: %w", err)
+	}
+	go func() { p.exited <- p.cmd.Wait() }()
+
+	if err := p.waitReady(ctx); err != nil {
+		log, _ := os.ReadFile(logPath)
+		p.Stop()
+		return nil, fmt.Errorf("%w\n%s", err, log)
+	}
+
+	return p, nil
+}
+
+// Config returns connection settings for dbName on this cluster.
+func (p *Postgres) Config(dbName string) database.Config {
+	return database.Config{
+		Host:     p.socketDir,
+		Port:     pgPort,
+		User:     pgUser,
+		Password: pgPassword,
+		DBName:   dbName,
+		SSLMode:  "disable",
+
+		MaxOpenConns:    10,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: time.Minute,
+	}
+}
+
+// DSN returns a key/value connection string for dbName.
+func (p *Postgres) DSN(dbName string) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		p.socketDir, pgPort, pgUser, pgPassword, dbName)
+}
+
+// Open connects to dbName as the superuser.
+func (p *Postgres) Open(dbName string) (*sql.DB, error) {
+	return sql.Open("pgx", p.DSN(dbName))
+}
+
+// Stop shuts the server down and removes its data directory.
+func (p *Postgres) Stop() error {
+	var err error
+	if p.cmd != nil && p.cmd.Process != nil {
+		// SIGINT is postgres' "fast" shutdown: abort clients, skip waiting.
+		_ = p.cmd.Process.Signal(syscall.SIGINT)
+
+		select {
+		case <-p.exited:
+		case <-time.After(startTimeout):
+			err = p.cmd.Process.Kill()
+			<-p.exited
+		}
+	}
+
+	return errors.Join(err, os.RemoveAll(p.dir))
+}
+
+func (p *Postgres) waitReady(ctx context.Context) error {
+	db, err := p.Open("postgres")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	deadline := time.Now().Add(startTimeout)
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, time.Second)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case exitErr := <-p.exited:
+			p.exited <- exitErr
+			return fmt.Errorf("postgres exited during startup: %v", exitErr)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("postgres not ready after %s: %w", startTimeout, err)
+		}
+	}
+}
+
+func findBinDir() (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		if hasBinaries(dir) {
+			return dir, nil
+		}
+		return "", fmt.Errorf("%w: PG_BIN=%s has no initdb/postgres", ErrNoPostgres, dir)
+	}
+
+	if initdb, err := exec.LookPath("initdb"); err == nil {
+		if dir := filepath.Dir(initdb); hasBinaries(dir) {
+			return dir, nil
+		}
+	}
+
+	// Debian/Ubuntu keep the server binaries off PATH; prefer the newest.
+	candidates, _ := filepath.Glob("/usr/lib/postgresql/*/bin")
+	sort.Sort(sort.Reverse(sort.StringSlice(candidates)))
+	for _, dir := range candidates {
+		if hasBinaries(dir) {
+			return dir, nil
+		}
+	}
+
+	return "", ErrNoPostgres
+}
+
+func hasBinaries(dir string) bool {
+	for _, name := range []string{"initdb", "postgres"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"admin-portal/internal/auth-module/model"
+	authpb "admin-portal/proto/auth"
+)
+
+// DefaultPassword is the password LoginAs gives the users it creates.
+const DefaultPassword = "correct-horse-battery"
+
+// Session is what a browser would hold after Login: the cookies the
+// server set, plus the token values pulled out of them.
+type Session struct {
+	UserID       string
+	AccessToken  string
+	RefreshToken string
+	Cookies      []*http.Cookie
+}
+
+// Context returns ctx carrying the session cookies, the way the gateway
+// forwards them.
+func (s *Session) Context(ctx context.Context) context.Context {
+	var pairs []string
+	for _, c := range s.Cookies {
+		if c.Value != "" {
+			pairs = append(pairs, c.Name+"="+c.Value)
+		}
+	}
+	return metadata.AppendToOutgoingContext(ctx, "cookie", strings.Join(pairs, "; "))
+}
+
+// Bearer returns ctx carrying the access token as an Authorization header.
+func (s *Session) Bearer(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+s.AccessToken)
+}
+
+// Cookie returns the named cookie from the session, or nil.
+func (s *Session) Cookie(name string) *http.Cookie {
+	for _, c := range s.Cookies {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Cookies parses the set-cookie entries of a response header.
+func Cookies(md metadata.MD) []*http.Cookie {
+	resp := http.Response{Header: http.Header{"Set-Cookie": md.Get("set-cookie")}}
+	return resp.Cookies()
+}
+
+//-------------------- Fixtures --------------------//
+
+// CreateUser registers a user through the API, with the email
+// <username>@example.com, gives it role, and optionally activates it the
+// way an admin would, returning the new user ID. Register only creates
+// users, so other roles are set in the database.
+func (h *Harness) CreateUser(t testing.TB, username, password, role string, activate bool) string {
+	t.Helper()
+
+	ctx := context.Background()
+	resp, err := h.Auth.Register(ctx, &authpb.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: password,
+		Role:     model.RoleUser,
+	})
+	if err != nil {
+		t.Fatalf("register %s: %v", username, err)
+	}
+
+	if role != model.RoleUser {
+		if err := h.DB.Model(&model.User{}).Where("id = ?", resp.GetUserId()).Update("role", role).Error; err != nil {
+			t.Fatalf("set role of %s: %v", username, err)
+		}
+	}
+
+	if activate {
+		if err := h.App.Auth.AuthService.ActivateUser(ctx, resp.GetUserId()); err != nil {
+			t.Fatalf("activate %s: %v", username, err)
+		}
+	}
+
+	return resp.GetUserId()
+}
+
+// Login signs in and captures the cookies from the response header.
+func (h *Harness) Login(t testing.TB, username, password string) *Session {
+	t.Helper()
+
+	var header metadata.MD
+	resp, err := h.Auth.Login(context.Background(), &authpb.LoginRequest{
+		Username: username,
+		Password: password,
+	}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("login %s: %v", username, err)
+	}
+
+	s := &Session{UserID: resp.GetUserId(), Cookies: Cookies(header)}
+	if c := s.Cookie("access_token"); c != nil {
+		s.AccessToken = c.Value
+	}
+	if c := s.Cookie("refresh_token"); c != nil {
+		s.RefreshToken = c.Value
+	}
+	if s.AccessToken == "" {
+		t.Fatalf("login %s: no access_token cookie in %v", username, header.Get("set-cookie"))
+	}
+
+	return s
+}
+
+// LoginAs creates an active user with role and signs in as it.
+func (h *Harness) LoginAs(t testing.TB, role string) *Session {
+	t.Helper()
+
+	username := fmt.Sprintf("%s-%d", role, h.users.Add(1))
+	h.CreateUser(t, username, DefaultPassword, role, true)
+
+	return h.Login(t, username, DefaultPassword)
+}
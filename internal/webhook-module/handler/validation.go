@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"regexp"
+
+	"admin-portal/internal/shared/validation"
+	"admin-portal/internal/webhook-module/model"
+	webhookpb "admin-portal/proto/webhook"
+)
+
+var (
+	urlPattern       = regexp.MustCompile(`^https?://[^\s/$.?#][^\s]*$`)
+	pageTokenPattern = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+// RegisterValidators declares the request rules for every WebhookService
+// RPC. Event type filters are checked by the service.
+func RegisterValidators(r *validation.Registry) {
+	r.Register(&webhookpb.CreateSubscriptionRequest{},
+		validation.Field("url",
+			validation.Required(),
+			validation.MaxLen(2048),
+			validation.Pattern(urlPattern, "must be an http or https URL"),
+		),
+		validation.Field("description",
+			validation.MaxLen(255), // webhook_subscriptions.description VARCHAR(255)
+		),
+	)
+
+	r.Register(&webhookpb.DeleteSubscriptionRequest{},
+		validation.Field("id",
+			validation.Required(),
+			validation.UUID(),
+		),
+	)
+
+	r.Register(&webhookpb.ListDeliveriesRequest{},
+		validation.Field("subscription_id",
+			validation.Optional(validation.UUID()),
+		),
+		validation.Field("event_type",
+			validation.MaxLen(100), // webhook_deliveries.event_type VARCHAR(100)
+		),
+		validation.Field("status",
+			validation.Optional(validation.OneOf(
+				model.DeliveryPending,
+				model.DeliverySucceeded,
+				model.DeliveryFailed,
+			)),
+		),
+		validation.Field("page_token",
+			validation.Optional(validation.Pattern(pageTokenPattern, "must be a token from a previous response")),
+		),
+	)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"admin-portal/internal/webhook-module/model"
+	"admin-portal/internal/webhook-module/repository"
+	"admin-portal/internal/webhook-module/service"
+	webhookpb "admin-portal/proto/webhook"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type WebhookHandler struct {
+	webhookpb.UnimplementedWebhookServiceServer
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) CreateSubscription(
+	ctx context.Context,
+	req *webhookpb.CreateSubscriptionRequest,
+) (*webhookpb.CreateSubscriptionResponse, error) {
+
+	sub, secret, err := h.webhookService.CreateSubscription(
+		ctx,
+		req.GetUrl(),
+		req.GetDescription(),
+		req.GetEventTypes(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookpb.CreateSubscriptionResponse{
+		Subscription: subscriptionToProto(sub),
+		Secret:       secret,
+	}, nil
+}
+
+func (h *WebhookHandler) ListSubscriptions(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*webhookpb.ListSubscriptionsResponse, error) {
+
+	subs, err := h.webhookService.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &webhookpb.ListSubscriptionsResponse{}
+	for _, sub := range subs {
+		resp.Subscriptions = append(resp.Subscriptions, subscriptionToProto(sub))
+	}
+
+	return resp, nil
+}
+
+func (h *WebhookHandler) DeleteSubscription(
+	ctx context.Context,
+	req *webhookpb.DeleteSubscriptionRequest,
+) (*emptypb.Empty, error) {
+
+	if err := h.webhookService.DeleteSubscription(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (h *WebhookHandler) ListDeliveries(
+	ctx context.Context,
+	req *webhookpb.ListDeliveriesRequest,
+) (*webhookpb.ListDeliveriesResponse, error) {
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	// Page tokens are offsets; the validator only lets digits through.
+	offset := 0
+	if req.GetPageToken() != "" {
+		offset, _ = strconv.Atoi(req.GetPageToken())
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(ctx, repository.DeliveryFilter{
+		SubscriptionID: req.GetSubscriptionId(),
+		EventType:      req.GetEventType(),
+		Status:         req.GetStatus(),
+		Limit:          pageSize,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &webhookpb.ListDeliveriesResponse{TotalSize: total}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, deliveryToProto(d))
+	}
+	if next := offset + len(deliveries); int64(next) < total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+
+	return resp, nil
+}
+
+//-------------------- Helper functions for conversion --------------------//
+
+func subscriptionToProto(s *model.WebhookSubscription) *webhookpb.Subscription {
+	pb := &webhookpb.Subscription{
+		Id:          s.ID.String(),
+		Url:         s.URL,
+		Description: s.Description,
+		EventTypes:  s.EventTypes,
+		IsActive:    s.IsActive,
+		CreatedAt:   timestamppb.New(s.CreatedAt),
+	}
+	if s.CreatedBy != nil {
+		pb.CreatedBy = s.CreatedBy.String()
+	}
+	return pb
+}
+
+func deliveryToProto(d *model.WebhookDelivery) *webhookpb.Delivery {
+	pb := &webhookpb.Delivery{
+		Id:           d.ID.String(),
+		Url:          d.URL,
+		EventId:      d.EventID.String(),
+		EventType:    d.EventType,
+		Payload:      string(d.Payload),
+		Status:       d.Status,
+		Attempts:     int32(d.Attempts),
+		ResponseBody: deref(d.ResponseBody),
+		LastError:    deref(d.LastError),
+		CreatedAt:    timestamppb.New(d.CreatedAt),
+		UpdatedAt:    timestamppb.New(d.UpdatedAt),
+		DeliveredAt:  timestamp(d.DeliveredAt),
+	}
+	if d.SubscriptionID != nil {
+		pb.SubscriptionId = d.SubscriptionID.String()
+	}
+	if d.ResponseStatus != nil {
+		pb.ResponseStatus = int32(*d.ResponseStatus)
+	}
+	return pb
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
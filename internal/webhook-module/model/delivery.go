@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookDelivery struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	SubscriptionID *uuid.UUID `gorm:"type:uuid;index"`
+	URL            string     `gorm:"type:text;not null"`
+
+	EventID   uuid.UUID `gorm:"type:uuid;not null"`
+	EventType string    `gorm:"type:varchar(100);not null"`
+	Payload   []byte    `gorm:"type:jsonb;not null"`
+
+	Status string `gorm:"type:varchar(20);not null;default:'pending';check:status IN ('pending','succeeded','failed')"`
+
+	Attempts       int `gorm:"not null;default:0"`
+	ResponseStatus *int
+	ResponseBody   *string `gorm:"type:text"`
+	LastError      *string `gorm:"type:text"`
+
+	CreatedAt   time.Time `gorm:"not null;default:now();index"`
+	UpdatedAt   time.Time `gorm:"not null;default:now()"`
+	DeliveredAt *time.Time
+
+	// Relations
+	Subscription *WebhookSubscription `gorm:"constraint:OnDelete:SET NULL;"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
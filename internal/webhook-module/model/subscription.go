@@ -0,0 +1,36 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookSubscription struct {
+	ID uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+
+	URL         string `gorm:"type:text;not null"`
+	Description string `gorm:"type:varchar(255);not null;default:''"`
+
+	// Secret is encrypted with security.Cipher.
+	Secret string `gorm:"type:text;not null"`
+
+	EventTypes []string `gorm:"type:jsonb;not null;default:'[]';serializer:json"`
+
+	IsActive bool `gorm:"not null;default:true"`
+
+	CreatedBy *uuid.UUID `gorm:"type:uuid"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+	UpdatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Wants reports whether the subscription filters in eventType.
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	return len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, eventType)
+}
@@ -0,0 +1,62 @@
+package webhookmodule
+
+import (
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/shared/validation"
+	"admin-portal/internal/webhook-module/handler"
+	"admin-portal/internal/webhook-module/repository"
+	"admin-portal/internal/webhook-module/service"
+	webhookpb "admin-portal/proto/webhook"
+)
+
+// Module manages webhook subscriptions and fans events out to them.
+type Module struct {
+	WebhookService service.WebhookService
+	Dispatcher     *service.Dispatcher
+
+	handler *handler.WebhookHandler
+}
+
+// New builds the module. eventTypes lists the event types subscriptions
+// may filter on.
+func New(db *gorm.DB, cipher *security.Cipher, cfg service.Config, eventTypes []string) *Module {
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	deliveryRepo := repository.NewDeliveryRepository(db)
+
+	webhookService := service.NewWebhookService(subscriptionRepo, deliveryRepo, cipher, cfg, eventTypes)
+
+	return &Module{
+		WebhookService: webhookService,
+		Dispatcher:     service.NewDispatcher(db, subscriptionRepo, deliveryRepo, cfg.MaxAttempts),
+		handler:        handler.NewWebhookHandler(webhookService),
+	}
+}
+
+// NewDeliverer builds the queue handler that cmd/worker registers for
+// service.KindDeliver.
+func NewDeliverer(db *gorm.DB, cipher *security.Cipher, cfg service.Config) *service.Deliverer {
+	return service.NewDeliverer(repository.NewDeliveryRepository(db), cipher, cfg)
+}
+
+func (m *Module) Name() string {
+	return "webhook"
+}
+
+func (m *Module) Register(s *grpc.Server) {
+	webhookpb.RegisterWebhookServiceServer(s, m.handler)
+}
+
+func (m *Module) RegisterValidators(r *validation.Registry) {
+	handler.RegisterValidators(r)
+}
+
+// Policy restricts the whole WebhookService to administrators.
+func (m *Module) Policy() middleware.Policy {
+	return middleware.Policy{
+		"/webhook.WebhookService/": middleware.AdminRoles,
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/shared/database"
+	"admin-portal/internal/webhook-module/model"
+)
+
+// DeliveryFilter narrows List. Empty fields match everything.
+type DeliveryFilter struct {
+	SubscriptionID string
+	EventType      string
+	Status         string
+	Limit          int
+	Offset         int
+}
+
+type DeliveryRepository interface {
+	Create(ctx context.Context, d *model.WebhookDelivery) error
+	FindByID(ctx context.Context, id string) (*model.WebhookDelivery, error)
+	Update(ctx context.Context, d *model.WebhookDelivery) error
+	List(ctx context.Context, f DeliveryFilter) ([]*model.WebhookDelivery, int64, error)
+}
+
+type deliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliveryRepository(db *gorm.DB) DeliveryRepository {
+	return &deliveryRepository{db: db}
+}
+
+func (r *deliveryRepository) Create(ctx context.Context, d *model.WebhookDelivery) error {
+	return database.Conn(ctx, r.db).Create(d).Error
+}
+
+func (r *deliveryRepository) FindByID(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	err := database.Conn(ctx, r.db).
+		Preload("Subscription").
+		Where("id = ?", id).
+		First(&d).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *deliveryRepository) Update(ctx context.Context, d *model.WebhookDelivery) error {
+	return database.Conn(ctx, r.db).
+		Omit("Subscription").
+		Save(d).Error
+}
+
+// List returns deliveries matching f, newest first, and the total number
+// of matches.
+func (r *deliveryRepository) List(ctx context.Context, f DeliveryFilter) ([]*model.WebhookDelivery, int64, error) {
+	q := database.Conn(ctx, r.db).Model(&model.WebhookDelivery{})
+	if f.SubscriptionID != "" {
+		q = q.Where("subscription_id = ?", f.SubscriptionID)
+	}
+	if f.EventType != "" {
+		q = q.Where("event_type = ?", f.EventType)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []*model.WebhookDelivery
+	err := q.Order("created_at DESC, id").
+		Limit(f.Limit).
+		Offset(f.Offset).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/shared/database"
+	"admin-portal/internal/webhook-module/model"
+)
+
+type SubscriptionRepository interface {
+	Create(ctx context.Context, sub *model.WebhookSubscription) error
+	FindByID(ctx context.Context, id string) (*model.WebhookSubscription, error)
+	List(ctx context.Context) ([]*model.WebhookSubscription, error)
+	ListActive(ctx context.Context) ([]*model.WebhookSubscription, error)
+	Delete(ctx context.Context, id string) (bool, error)
+}
+
+type subscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) SubscriptionRepository {
+	return &subscriptionRepository{db: db}
+}
+
+func (r *subscriptionRepository) Create(ctx context.Context, sub *model.WebhookSubscription) error {
+	return database.Conn(ctx, r.db).Create(sub).Error
+}
+
+func (r *subscriptionRepository) FindByID(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := database.Conn(ctx, r.db).
+		Where("id = ?", id).
+		First(&sub).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *subscriptionRepository) List(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	var subs []*model.WebhookSubscription
+	err := database.Conn(ctx, r.db).
+		Order("created_at").
+		Find(&subs).Error
+	if err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (r *subscriptionRepository) ListActive(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	var subs []*model.WebhookSubscription
+	err := database.Conn(ctx, r.db).
+		Where("is_active = TRUE").
+		Find(&subs).Error
+	if err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// Delete removes the subscription and reports whether it existed. Its
+// deliveries are kept.
+func (r *subscriptionRepository) Delete(ctx context.Context, id string) (bool, error) {
+	res := database.Conn(ctx, r.db).
+		Where("id = ?", id).
+		Delete(&model.WebhookSubscription{})
+	return res.RowsAffected > 0, res.Error
+}
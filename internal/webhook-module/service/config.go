@@ -0,0 +1,27 @@
+package service
+
+import (
+	"time"
+
+	"admin-portal/internal/shared/config"
+)
+
+type Config struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	// MaxResponseBytes caps how much of each response body is logged.
+	MaxResponseBytes int
+	// AllowPrivateNetworks lets endpoints resolve to loopback, private
+	// and link-local addresses. Only for development.
+	AllowPrivateNetworks bool
+}
+
+func LoadConfig() Config {
+	return Config{
+		Timeout:          config.Duration("WEBHOOK_TIMEOUT", 10*time.Second),
+		MaxAttempts:      config.Int("WEBHOOK_MAX_ATTEMPTS", 8),
+		MaxResponseBytes: config.Int("WEBHOOK_MAX_RESPONSE_BYTES", 4096),
+
+		AllowPrivateNetworks: config.Bool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/webhook-module/model"
+	"admin-portal/internal/webhook-module/repository"
+)
+
+// Deliverer sends queued deliveries. Its Handle method is registered
+// with the queue worker for KindDeliver.
+type Deliverer struct {
+	deliveryRepo repository.DeliveryRepository
+	cipher       *security.Cipher
+	client       *http.Client
+	cfg          Config
+}
+
+func NewDeliverer(deliveryRepo repository.DeliveryRepository, cipher *security.Cipher, cfg Config) *Deliverer {
+	return &Deliverer{
+		deliveryRepo: deliveryRepo,
+		cipher:       cipher,
+		client:       newClient(cfg),
+		cfg:          cfg,
+	}
+}
+
+// Handle makes one delivery attempt and records its outcome. A failed
+// attempt returns an error so the queue retries it with backoff; the
+// delivery is marked failed once the job is out of attempts.
+func (d *Deliverer) Handle(ctx context.Context, job *queue.Job) error {
+	var payload DeliverPayload
+	if err := job.Decode(&payload); err != nil {
+		return queue.Permanent(err)
+	}
+
+	delivery, err := d.deliveryRepo.FindByID(ctx, payload.DeliveryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if delivery.Status != model.DeliveryPending {
+		return nil
+	}
+
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = nil
+
+	sendErr := d.send(ctx, delivery)
+	delivery.Attempts++
+
+	switch {
+	case sendErr == nil:
+		now := time.Now()
+		delivery.Status = model.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+	case job.Attempts >= job.MaxAttempts || queue.IsPermanent(sendErr):
+		delivery.Status = model.DeliveryFailed
+	}
+
+	if sendErr != nil {
+		msg := sendErr.Error()
+		delivery.LastError = &msg
+	}
+
+	if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
+		return err
+	}
+
+	return sendErr
+}
+
+func (d *Deliverer) send(ctx context.Context, delivery *model.WebhookDelivery) error {
+	if delivery.Subscription == nil {
+		return queue.Permanent(errors.New("subscription was deleted"))
+	}
+
+	secret, err := d.cipher.Decrypt(delivery.Subscription.Secret)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("decrypt secret: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return queue.Permanent(err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "admin-portal-webhooks/1")
+	req.Header.Set(HeaderID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(string(secret), timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if errors.Is(err, errBlockedAddress) {
+		return queue.Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, int64(d.cfg.MaxResponseBytes)))
+	responseBody := string(body)
+	delivery.ResponseStatus = &resp.StatusCode
+	delivery.ResponseBody = &responseBody
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return nil
+}
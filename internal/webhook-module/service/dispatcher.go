@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"admin-portal/internal/shared/queue"
+	"admin-portal/internal/webhook-module/model"
+	"admin-portal/internal/webhook-module/repository"
+)
+
+// KindDeliver is the queue job that sends one delivery.
+const KindDeliver = "webhook.deliver"
+
+type DeliverPayload struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// envelope is the JSON body subscribers receive.
+type envelope struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Dispatcher fans an event out to every matching subscription. It
+// satisfies the auth service's SecurityAlerts.
+type Dispatcher struct {
+	db               *gorm.DB
+	subscriptionRepo repository.SubscriptionRepository
+	deliveryRepo     repository.DeliveryRepository
+	maxAttempts      int
+}
+
+func NewDispatcher(
+	db *gorm.DB,
+	subscriptionRepo repository.SubscriptionRepository,
+	deliveryRepo repository.DeliveryRepository,
+	maxAttempts int,
+) *Dispatcher {
+	return &Dispatcher{
+		db:               db,
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		maxAttempts:      maxAttempts,
+	}
+}
+
+// Dispatch records a pending delivery per matching subscription and
+// queues it for the worker. Run inside database.Transaction, nothing is
+// sent unless the caller commits.
+func (d *Dispatcher) Dispatch(ctx context.Context, eventType string, payload interface{}) error {
+	subs, err := d.subscriptionRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	eventID := uuid.New()
+	var body []byte
+
+	for _, sub := range subs {
+		if !sub.Wants(eventType) {
+			continue
+		}
+
+		if body == nil {
+			body, err = json.Marshal(envelope{
+				ID:        eventID.String(),
+				Type:      eventType,
+				CreatedAt: time.Now().UTC(),
+				Data:      payload,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		delivery := &model.WebhookDelivery{
+			SubscriptionID: &sub.ID,
+			URL:            sub.URL,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        body,
+			Status:         model.DeliveryPending,
+		}
+		if err := d.deliveryRepo.Create(ctx, delivery); err != nil {
+			return err
+		}
+
+		_, err := queue.Enqueue(ctx, d.db, KindDeliver,
+			DeliverPayload{DeliveryID: delivery.ID.String()},
+			queue.WithMaxAttempts(d.maxAttempts),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// errBlockedAddress is returned when an endpoint resolves to an address
+// webhooks may not reach.
+var errBlockedAddress = errors.New("address is not publicly routable")
+
+// blockedPrefixes are special-purpose ranges the net/netip predicates do
+// not cover.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, maps onto IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4, maps onto IPv4
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+}
+
+// blocked reports whether addr is loopback, private, link-local (which
+// includes cloud metadata endpoints such as 169.254.169.254) or another
+// address that is not on the public internet.
+func blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEndpoint resolves the host of rawURL and rejects it if any of its
+// addresses is blocked.
+func checkEndpoint(ctx context.Context, resolver *net.Resolver, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if blocked(addr) {
+			return errBlockedAddress
+		}
+		return nil
+	}
+
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if blocked(addr) {
+			return errBlockedAddress
+		}
+	}
+	return nil
+}
+
+// newClient returns the client deliveries are sent with. Unless private
+// networks are allowed, its dialer refuses blocked addresses after DNS
+// resolution, so an endpoint cannot be re-pointed at the internal network
+// once its subscription has been checked, including through redirects.
+// Environment proxies are not used, since they would hide the address.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if blocked(addrPort.Addr()) {
+				return fmt.Errorf("dial %s: %w", address, errBlockedAddress)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: cfg.Timeout, Transport: transport}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestBlocked(t *testing.T) {
+	for _, s := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "100.64.0.1", "255.255.255.255", "224.0.0.1",
+		"::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254",
+		"64:ff9b::a9fe:a9fe", "2002:7f00:1::",
+	} {
+		if !blocked(netip.MustParseAddr(s)) {
+			t.Errorf("%s is not blocked", s)
+		}
+	}
+
+	for _, s := range []string{"93.184.215.14", "8.8.8.8", "2606:4700::1111"} {
+		if blocked(netip.MustParseAddr(s)) {
+			t.Errorf("%s is blocked", s)
+		}
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	ctx := context.Background()
+
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"https://localhost/hook",
+	} {
+		if err := checkEndpoint(ctx, net.DefaultResolver, u); !errors.Is(err, errBlockedAddress) {
+			t.Errorf("%s: err = %v, want errBlockedAddress", u, err)
+		}
+	}
+
+	if err := checkEndpoint(ctx, net.DefaultResolver, "https://203.0.113.10/hook"); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+}
+
+func TestClientRefusesBlockedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := newClient(Config{Timeout: time.Second}).Get(server.URL)
+	if !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("loopback request err = %v, want errBlockedAddress", err)
+	}
+
+	resp, err := newClient(Config{Timeout: time.Second, AllowPrivateNetworks: true}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("loopback request with private networks allowed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientRefusesMetadataEndpoint(t *testing.T) {
+	client := newClient(Config{Timeout: time.Second})
+	req, _ := http.NewRequest(http.MethodGet, "http://169.254.169.254/", nil)
+	if _, err := client.Do(req); !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("metadata request err = %v, want errBlockedAddress", err)
+	}
+}
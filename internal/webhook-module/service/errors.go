@@ -0,0 +1,9 @@
+package service
+
+import apperrors "admin-portal/internal/errors"
+
+var (
+	ErrSubscriptionNotFound = apperrors.New(apperrors.CodeNotFound, "SUBSCRIPTION_NOT_FOUND", "webhook subscription not found")
+	ErrUnknownEventType     = apperrors.New(apperrors.CodeInvalidArgument, "UNKNOWN_EVENT_TYPE", "unknown event type")
+	ErrEndpointNotAllowed   = apperrors.New(apperrors.CodeInvalidArgument, "ENDPOINT_NOT_ALLOWED", "webhook URL must resolve to public internet addresses")
+)
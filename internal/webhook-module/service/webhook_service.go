@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"net"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"admin-portal/internal/auth-module/middleware"
+	"admin-portal/internal/shared/security"
+	"admin-portal/internal/webhook-module/model"
+	"admin-portal/internal/webhook-module/repository"
+)
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, url, description string, eventTypes []string) (*model.WebhookSubscription, string, error)
+	ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, filter repository.DeliveryFilter) ([]*model.WebhookDelivery, int64, error)
+}
+
+type webhookService struct {
+	subscriptionRepo repository.SubscriptionRepository
+	deliveryRepo     repository.DeliveryRepository
+	cipher           *security.Cipher
+	cfg              Config
+	eventTypes       []string
+}
+
+// NewWebhookService builds the admin service. eventTypes lists the event
+// types subscriptions may filter on.
+func NewWebhookService(
+	subscriptionRepo repository.SubscriptionRepository,
+	deliveryRepo repository.DeliveryRepository,
+	cipher *security.Cipher,
+	cfg Config,
+	eventTypes []string,
+) WebhookService {
+	return &webhookService{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		cipher:           cipher,
+		cfg:              cfg,
+		eventTypes:       eventTypes,
+	}
+}
+
+/* CreateSubscription stores a new endpoint and returns it with its plaintext secret. Unless private networks are allowed, the endpoint must resolve to public addresses only. */
+func (s *webhookService) CreateSubscription(
+	ctx context.Context,
+	url, description string,
+	eventTypes []string,
+) (*model.WebhookSubscription, string, error) {
+
+	for _, t := range eventTypes {
+		if !slices.Contains(s.eventTypes, t) {
+			return nil, "", ErrUnknownEventType.WithDetail("event_type", t)
+		}
+	}
+
+	if !s.cfg.AllowPrivateNetworks {
+		if err := checkEndpoint(ctx, net.DefaultResolver, url); err != nil {
+			return nil, "", ErrEndpointNotAllowed.WithDetail("url", url).Wrap(err)
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	encrypted, err := s.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		return nil, "", err
+	}
+
+	sub := &model.WebhookSubscription{
+		URL:         url,
+		Description: description,
+		Secret:      encrypted,
+		EventTypes:  eventTypes,
+		IsActive:    true,
+	}
+	if sub.EventTypes == nil {
+		sub.EventTypes = []string{}
+	}
+
+	if id, ok := middleware.UserIDFromContext(ctx); ok {
+		if creator, err := uuid.Parse(id); err == nil {
+			sub.CreatedBy = &creator
+		}
+	}
+
+	if err := s.subscriptionRepo.Create(ctx, sub); err != nil {
+		return nil, "", err
+	}
+
+	return sub, secret, nil
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	return s.subscriptionRepo.List(ctx)
+}
+
+/* DeleteSubscription removes an endpoint. Its delivery log is kept. */
+func (s *webhookService) DeleteSubscription(ctx context.Context, id string) error {
+	found, err := s.subscriptionRepo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrSubscriptionNotFound.WithDetail("subscription_id", id)
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(
+	ctx context.Context,
+	filter repository.DeliveryFilter,
+) ([]*model.WebhookDelivery, int64, error) {
+	return s.deliveryRepo.List(ctx, filter)
+}
@@ -1,3 +1,4 @@
+-- +up
 -- Enable UUID generation (PostgreSQL)
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 
@@ -22,4 +23,7 @@ CREATE INDEX IF NOT EXISTS idx_users_username
     ON users(username);
 
 CREATE INDEX IF NOT EXISTS idx_users_id
-    ON users(username);
\ No newline at end of file
+    ON users(username);
+
+-- +down
+DROP TABLE IF EXISTS users;
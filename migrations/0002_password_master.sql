@@ -1,3 +1,4 @@
+-- +up
 CREATE TABLE IF NOT EXISTS password_master (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 
@@ -19,3 +20,6 @@ CREATE TABLE IF NOT EXISTS password_master (
 CREATE UNIQUE INDEX IF NOT EXISTS ux_password_master_user_active
     ON password_master(user_id)
     WHERE is_active = TRUE;
+
+-- +down
+DROP TABLE IF EXISTS password_master;
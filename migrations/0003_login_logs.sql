@@ -1,3 +1,4 @@
+-- +up
 CREATE TABLE IF NOT EXISTS login_logs (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 
@@ -27,3 +28,6 @@ CREATE INDEX IF NOT EXISTS idx_login_logs_user_id
 
 CREATE INDEX IF NOT EXISTS idx_login_logs_created_at
     ON login_logs(created_at);
+
+-- +down
+DROP TABLE IF EXISTS login_logs;
@@ -1,3 +1,4 @@
+-- +up
 CREATE OR REPLACE FUNCTION set_updated_at()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -12,4 +13,9 @@ FOR EACH ROW EXECUTE FUNCTION set_updated_at();
 
 CREATE TRIGGER trg_password_master_updated
 BEFORE UPDATE ON password_master
-FOR EACH ROW EXECUTE FUNCTION set_updated_at();
\ No newline at end of file
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +down
+DROP TRIGGER IF EXISTS trg_password_master_updated ON password_master;
+DROP TRIGGER IF EXISTS trg_users_updated ON users;
+DROP FUNCTION IF EXISTS set_updated_at();
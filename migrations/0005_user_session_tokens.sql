@@ -1,3 +1,4 @@
+-- +up
 CREATE TABLE IF NOT EXISTS user_sessions (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 
@@ -17,4 +18,7 @@ CREATE TABLE IF NOT EXISTS user_sessions (
 
 -- Index for session lookups
 CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id
-    ON user_sessions(user_id);
\ No newline at end of file
+    ON user_sessions(user_id);
+
+-- +down
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,33 @@
+-- +up
+-- Set by the worker once an activation reminder has been sent
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS activation_reminder_sent_at TIMESTAMP WITHOUT TIME ZONE NULL;
+
+-- Login logs past their retention period are moved here by the worker
+CREATE TABLE IF NOT EXISTS login_logs_archive (
+    id UUID PRIMARY KEY,
+
+    user_id UUID NULL,
+    message TEXT NOT NULL,
+    log_type VARCHAR(10) NOT NULL,
+
+    ip_address INET NULL,
+    user_agent TEXT NULL,
+
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    archived_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_logs_archive_user_id
+    ON login_logs_archive(user_id);
+
+-- Index for session purges
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires_at
+    ON user_sessions(expires_at);
+
+-- +down
+DROP INDEX IF EXISTS idx_user_sessions_expires_at;
+DROP TABLE IF EXISTS login_logs_archive;
+ALTER TABLE users DROP COLUMN IF EXISTS activation_reminder_sent_at;
@@ -0,0 +1,42 @@
+-- +up
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN ('pending', 'running', 'succeeded', 'dead')
+    ),
+
+    unique_key VARCHAR(200) NULL,
+
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 10,
+
+    run_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    locked_by VARCHAR(200) NULL,
+
+    last_error TEXT NULL,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITHOUT TIME ZONE NULL
+);
+
+-- Index for the dequeue loop
+CREATE INDEX IF NOT EXISTS idx_jobs_status_run_at
+    ON jobs(status, run_at);
+
+-- At most one queued or running job per unique key
+CREATE UNIQUE INDEX IF NOT EXISTS uq_jobs_unique_key_active
+    ON jobs(unique_key)
+    WHERE status = 'pending' OR status = 'running';
+
+CREATE TRIGGER trg_jobs_updated
+BEFORE UPDATE ON jobs
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +down
+DROP TABLE IF EXISTS jobs;
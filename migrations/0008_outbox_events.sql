@@ -0,0 +1,34 @@
+-- +up
+CREATE TABLE IF NOT EXISTS outbox_events (
+    -- Doubles as the idempotency key sent to sinks
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Commit order; events of one aggregate are published in this order
+    seq BIGSERIAL NOT NULL UNIQUE,
+
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(100) NOT NULL,
+
+    payload JSONB NOT NULL DEFAULT '{}',
+
+    occurred_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT NULL,
+
+    published_at TIMESTAMP WITHOUT TIME ZONE NULL
+);
+
+-- Indexes for the relay, which only reads unpublished events
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished
+    ON outbox_events(seq)
+    WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate
+    ON outbox_events(aggregate_type, aggregate_id)
+    WHERE published_at IS NULL;
+
+-- +down
+DROP TABLE IF EXISTS outbox_events;
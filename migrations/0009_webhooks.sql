@@ -0,0 +1,74 @@
+-- +up
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    url TEXT NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+
+    -- Signing secret, encrypted with ENCRYPTION_KEY
+    secret TEXT NOT NULL,
+
+    -- Event types to deliver; empty means all
+    event_types JSONB NOT NULL DEFAULT '[]',
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_by UUID NULL,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_webhook_subscriptions_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id)
+        ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Kept after the subscription is deleted, with the URL it targeted
+    subscription_id UUID NULL,
+    url TEXT NOT NULL,
+
+    event_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        status IN ('pending', 'succeeded', 'failed')
+    ),
+
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER NULL,
+    response_body TEXT NULL,
+    last_error TEXT NULL,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITHOUT TIME ZONE NULL,
+
+    CONSTRAINT fk_webhook_deliveries_subscription
+        FOREIGN KEY (subscription_id)
+        REFERENCES webhook_subscriptions(id)
+        ON DELETE SET NULL
+);
+
+-- Indexes for the delivery log
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id
+    ON webhook_deliveries(subscription_id);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at
+    ON webhook_deliveries(created_at);
+
+CREATE TRIGGER trg_webhook_subscriptions_updated
+BEFORE UPDATE ON webhook_subscriptions
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER trg_webhook_deliveries_updated
+BEFORE UPDATE ON webhook_deliveries
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,35 @@
+-- +up
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+
+    -- Public part of the key, used to look it up
+    prefix VARCHAR(16) NOT NULL,
+    -- SHA-256 of the whole key; the key itself is never stored
+    key_hash VARCHAR(64) NOT NULL,
+
+    -- Methods the key may call: full methods, services or "*"
+    scopes JSONB NOT NULL DEFAULT '[]',
+
+    expires_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    last_used_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NULL,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_api_keys_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix
+    ON api_keys(prefix);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id
+    ON api_keys(user_id);
+
+-- +down
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,44 @@
+-- +up
+-- Accounts at external identity providers linked to local users
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    user_id UUID NOT NULL,
+
+    issuer TEXT NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    -- Email claimed by the provider at the last login, for display
+    email VARCHAR(255) NULL,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMP WITHOUT TIME ZONE NULL,
+
+    CONSTRAINT fk_user_identities_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_issuer_subject
+    ON user_identities(issuer, subject);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id
+    ON user_identities(user_id);
+
+-- SSO logins in progress, consumed by the callback
+CREATE TABLE IF NOT EXISTS sso_login_states (
+    state VARCHAR(64) PRIMARY KEY,
+
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sso_login_states_expires_at
+    ON sso_login_states(expires_at);
+
+-- +down
+DROP TABLE IF EXISTS sso_login_states;
+DROP TABLE IF EXISTS user_identities;
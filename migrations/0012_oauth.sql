@@ -0,0 +1,91 @@
+-- +up
+-- Apps that sign their users in through this portal
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    name VARCHAR(100) NOT NULL,
+    -- SHA-256 of the client secret; NULL for public clients
+    secret_hash VARCHAR(64) NULL,
+    -- Exact URIs the authorization endpoint may redirect to
+    redirect_uris JSONB NOT NULL DEFAULT '[]',
+
+    created_by UUID NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NULL,
+
+    CONSTRAINT fk_oauth_clients_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id)
+        ON DELETE SET NULL
+);
+
+-- Authorization codes waiting to be redeemed at the token endpoint
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    code_hash VARCHAR(64) PRIMARY KEY,
+
+    client_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+
+    redirect_uri TEXT NOT NULL,
+    scope VARCHAR(255) NOT NULL,
+    nonce VARCHAR(255) NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    auth_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_oauth_authorization_codes_client
+        FOREIGN KEY (client_id)
+        REFERENCES oauth_clients(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_oauth_authorization_codes_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_authorization_codes_expires_at
+    ON oauth_authorization_codes(expires_at);
+
+-- Refresh tokens issued to clients; rotated on every use
+CREATE TABLE IF NOT EXISTS oauth_refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    token_hash VARCHAR(64) NOT NULL,
+
+    client_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+
+    scope VARCHAR(255) NOT NULL,
+    auth_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_oauth_refresh_tokens_client
+        FOREIGN KEY (client_id)
+        REFERENCES oauth_clients(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_oauth_refresh_tokens_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_token_hash
+    ON oauth_refresh_tokens(token_hash);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_client_id
+    ON oauth_refresh_tokens(client_id);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_expires_at
+    ON oauth_refresh_tokens(expires_at);
+
+-- +down
+DROP TABLE IF EXISTS oauth_refresh_tokens;
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS oauth_clients;
@@ -0,0 +1,63 @@
+-- +up
+-- Passkeys and security keys registered by users
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+
+    -- Credential ID chosen by the authenticator
+    credential_id BYTEA NOT NULL,
+    -- COSE_Key from the attested credential data
+    public_key BYTEA NOT NULL,
+    -- Last signature counter seen; 0 for authenticators without one
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    -- Hints such as "usb" or "internal" passed back to the browser
+    transports JSONB NOT NULL DEFAULT '[]',
+    aaguid BYTEA NULL,
+
+    -- Whether the passkey may be, and is, synced between devices
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITHOUT TIME ZONE NULL,
+
+    CONSTRAINT fk_webauthn_credentials_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webauthn_credentials_credential_id
+    ON webauthn_credentials(credential_id);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id
+    ON webauthn_credentials(user_id);
+
+-- Challenges handed out for ceremonies in progress, consumed by the
+-- response
+CREATE TABLE IF NOT EXISTS webauthn_challenges (
+    challenge VARCHAR(64) PRIMARY KEY,
+
+    ceremony VARCHAR(20) NOT NULL CHECK (
+        ceremony IN ('registration', 'login', 'second_factor')
+    ),
+    -- The user the ceremony is for; NULL for passwordless logins
+    user_id UUID NULL,
+
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_webauthn_challenges_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_challenges_expires_at
+    ON webauthn_challenges(expires_at);
+
+-- +down
+DROP TABLE IF EXISTS webauthn_challenges;
+DROP TABLE IF EXISTS webauthn_credentials;
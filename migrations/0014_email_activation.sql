@@ -0,0 +1,23 @@
+-- +up
+-- Email address given at registration, stored lowercased. NULL for
+-- accounts created before email activation and for provisioned users.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email VARCHAR(255) NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email
+    ON users(email);
+
+-- jti of the newest activation token; activating clears it, so each
+-- token works once and sending a new one invalidates the old
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS activation_token_id UUID NULL;
+
+-- When an activation email was last requested, for resend throttling
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS activation_sent_at TIMESTAMP WITHOUT TIME ZONE NULL;
+
+-- +down
+ALTER TABLE users DROP COLUMN IF EXISTS activation_sent_at;
+ALTER TABLE users DROP COLUMN IF EXISTS activation_token_id;
+DROP INDEX IF EXISTS idx_users_email;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
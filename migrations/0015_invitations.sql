@@ -0,0 +1,43 @@
+-- +up
+-- Invitations sent by admins; accepting one creates an activated account
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Stored lowercased, like users.email
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (
+        role IN ('user', 'admin', 'super-admin')
+    ),
+
+    -- SHA-256 of the token in the newest invitation email; NULL until
+    -- the email is sent
+    token_hash VARCHAR(64) NULL,
+
+    invited_by UUID NULL,
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+
+    accepted_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    accepted_user_id UUID NULL,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NULL,
+
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_invitations_invited_by
+        FOREIGN KEY (invited_by)
+        REFERENCES users(id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_invitations_accepted_user
+        FOREIGN KEY (accepted_user_id)
+        REFERENCES users(id)
+        ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_token_hash
+    ON invitations(token_hash);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_email
+    ON invitations(email);
+
+-- +down
+DROP TABLE IF EXISTS invitations;
@@ -0,0 +1,10 @@
+-- +up
+-- When each scheduled job last started on any worker, so a job runs once
+-- per interval however many workers are deployed
+CREATE TABLE IF NOT EXISTS scheduler_runs (
+    job VARCHAR(100) PRIMARY KEY,
+    last_run_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+-- +down
+DROP TABLE IF EXISTS scheduler_runs;
@@ -0,0 +1,18 @@
+-- +up
+-- When a session was revoked, so purges keep revoked sessions for the
+-- full retention period however old they are
+ALTER TABLE user_sessions
+    ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITHOUT TIME ZONE NULL;
+
+-- Sessions revoked before this column existed start their retention now
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE is_revoked = TRUE AND revoked_at IS NULL;
+
+-- Index for session purges
+CREATE INDEX IF NOT EXISTS idx_user_sessions_revoked_at
+    ON user_sessions(revoked_at);
+
+-- +down
+DROP INDEX IF EXISTS idx_user_sessions_revoked_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS revoked_at;
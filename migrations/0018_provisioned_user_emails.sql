@@ -0,0 +1,18 @@
+-- +up
+-- Users provisioned by single sign-on before users.email existed were
+-- named after their verified email; record it so they link by email.
+-- Only users with a linked identity qualify: accounts registered before
+-- usernames were validated may be named after someone else's email.
+UPDATE users u
+SET email = LOWER(u.username)
+WHERE u.email IS NULL
+  AND u.username LIKE '%@%'
+  AND EXISTS (
+      SELECT 1 FROM user_identities i WHERE i.user_id = u.id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users other WHERE other.email = LOWER(u.username)
+  );
+
+-- +down
+-- Nothing to undo: the column is dropped by 0014 if it is rolled back.